@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"awsm/internal/agent"
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var agentForeground bool
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Keep temporary credentials in memory for exec and credential-process",
+	Long: `The awsm agent is a background process holding temporary credentials per
+profile in memory until shortly before they expire. While it runs, 'awsm exec'
+and 'awsm credential-process' get credentials from it over a local unix socket
+readable only by you, so running many commands in a row needs no new STS or
+SSO calls and asks for an MFA code once per session. Editing a profile, e.g.
+its role_arn or source_profile, or a profile of its source_profile chain makes
+the agent drop its credentials and fetch new ones.
+
+Credentials are never written to disk by the agent and are gone when it stops.`,
+}
+
+var agentStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the awsm agent in the background",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if agentForeground {
+			return runAgent()
+		}
+		if agent.Running() {
+			util.InfoColor.Println("awsm agent is already running.")
+			return nil
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to find the awsm executable: %w", err)
+		}
+		// AWSM_HOME and the AWS file locations are inherited through the environment
+		child := exec.Command(executable, "agent", "start", "--foreground")
+		if err := child.Start(); err != nil {
+			return fmt.Errorf("failed to start awsm agent: %w", err)
+		}
+		child.Process.Release()
+
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+			if agent.Running() {
+				path, _ := agent.SocketPath()
+				util.SuccessColor.Printf("✔ awsm agent started on %s\n", path)
+				return nil
+			}
+		}
+		return fmt.Errorf("awsm agent did not start, run 'awsm agent start --foreground' to see why")
+	},
+}
+
+// runAgent serves credentials until the agent is stopped or interrupted.
+func runAgent() error {
+	listener, err := agent.Listen()
+	if err != nil {
+		return err
+	}
+	a := agent.New(agent.FetchCredentials)
+
+	// Keep running when the terminal that started the agent closes
+	signal.Ignore(syscall.SIGHUP)
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		a.Stop()
+	}()
+
+	err = a.Serve(listener)
+	if path, pathErr := agent.SocketPath(); pathErr == nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the awsm agent, dropping the credentials it holds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := agent.StopAgent(); err != nil {
+			if errors.Is(err, agent.ErrNotRunning) {
+				util.InfoColor.Println("awsm agent is not running.")
+				return nil
+			}
+			return err
+		}
+		util.SuccessColor.Println("✔ awsm agent stopped")
+		return nil
+	},
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the awsm agent runs and which credentials it holds",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := agent.Status()
+		if err != nil {
+			if errors.Is(err, agent.ErrNotRunning) {
+				util.WarnColor.Println("awsm agent is not running, start it with 'awsm agent start'.")
+				return nil
+			}
+			return err
+		}
+		util.SuccessColor.Println("awsm agent is running.")
+		if len(entries) == 0 {
+			fmt.Println("No credentials held.")
+			return nil
+		}
+		for _, entry := range entries {
+			fmt.Printf("  %s  expires in %s\n", util.BoldColor.Sprint(entry.Profile), time.Until(entry.Expires).Round(time.Minute))
+		}
+		return nil
+	},
+}
+
+// credentialsFromAgent gets credentials for a profile that stay valid for
+// minValidity from the agent, asking for an MFA code on stderr when the agent
+// needs one. ok is false when no
+// agent runs or it failed, so the caller resolves the credentials itself;
+// that way SSO logins still happen in the user's terminal.
+func credentialsFromAgent(profile string, minValidity time.Duration) (*aws.TempCredentials, bool) {
+	creds, err := agent.GetCredentials(profile, "", minValidity)
+	var mfaErr *agent.MFARequiredError
+	if errors.As(err, &mfaErr) {
+		token, promptErr := util.PromptForInputStderr(fmt.Sprintf("Enter MFA token for %s: ", util.BoldColor.Sprint(mfaErr.Serial)))
+		if promptErr != nil {
+			return nil, false
+		}
+		creds, err = agent.GetCredentials(profile, token, minValidity)
+	}
+	if err != nil || creds == nil {
+		return nil, false
+	}
+	return creds, true
+}
+
+func init() {
+	agentStartCmd.Flags().BoolVar(&agentForeground, "foreground", false, "Run the agent in this process instead of the background")
+	agentCmd.AddCommand(agentStartCmd)
+	agentCmd.AddCommand(agentStopCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	rootCmd.AddCommand(agentCmd)
+}
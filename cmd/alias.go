@@ -0,0 +1,278 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var aliasNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage command aliases",
+	Long: `Aliases are shortcuts for frequently used commands and flags, stored in
+awsm's config.toml. Like git aliases, the alias name is replaced by its
+expansion and any further arguments are appended.
+
+Examples:
+  awsm alias set cf "console --firefox-container"
+  awsm cf prod           # runs: awsm console --firefox-container prod`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <expansion>",
+	Short: "Create or change an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, expansion := strings.ToLower(args[0]), strings.TrimSpace(args[1])
+		if !aliasNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid alias name '%s', use lowercase letters, digits and dashes", name)
+		}
+		if isBuiltinCommand(name) {
+			return fmt.Errorf("'%s' is an awsm command and can't be an alias", name)
+		}
+		fields, err := splitAliasArgs(expansion)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("alias '%s' needs an expansion", name)
+		}
+		if !isBuiltinCommand(fields[0]) {
+			return fmt.Errorf("alias '%s' must start with an awsm command, '%s' is none", name, fields[0])
+		}
+
+		if err := awsmConfig.SetSetting("aliases."+name, expansion); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ awsm %s now runs: awsm %s\n", name, expansion)
+		return nil
+	},
+}
+
+var aliasUnsetCmd = &cobra.Command{
+	Use:               "unset <name>",
+	Short:             "Remove an alias",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeAliases),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if _, ok := awsmConfig.GetAliases()[name]; !ok {
+			return fmt.Errorf("alias '%s' does not exist", name)
+		}
+		if err := awsmConfig.UnsetSetting("aliases."+name, ""); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Alias '%s' removed\n", name)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List aliases",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		aliases := awsmConfig.GetAliases()
+		if aliases == nil {
+			aliases = map[string]string{}
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return output.Render(output.Selected, output.Result{
+			Data: aliases,
+			Table: func() error {
+				if len(aliases) == 0 {
+					util.InfoColor.Println("No aliases set. Create one with 'awsm alias set <name> <expansion>'.")
+					return nil
+				}
+				for _, name := range names {
+					fmt.Printf("%s = %s\n", util.BoldColor.Sprint(name), aliases[name])
+				}
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, name := range names {
+					fmt.Fprintf(w, "%s\t%s\n", name, aliases[name])
+				}
+				return nil
+			},
+		})
+	},
+}
+
+// isBuiltinCommand reports whether name is a top-level awsm command, or one
+// cobra adds on its own.
+func isBuiltinCommand(name string) bool {
+	switch name {
+	case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAliases loads the aliases, registers them as commands so they show up
+// in help and completion, and returns args with an alias in the command
+// position replaced by its expansion. Broken aliases only warn.
+func applyAliases(args []string) []string {
+	// Aliases live in the config dir, which --config-dir may move. The
+	// variable is only set while reading them, initAwsmHome applies the flag
+	// for the run itself.
+	previous, wasSet := os.LookupEnv(awsmConfig.HomeEnv)
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--config-dir="); ok {
+			os.Setenv(awsmConfig.HomeEnv, value)
+		} else if arg == "--config-dir" && i+1 < len(args) {
+			os.Setenv(awsmConfig.HomeEnv, args[i+1])
+		}
+	}
+	awsmConfig.InitConfig()
+	aliases := awsmConfig.GetAliases()
+	if wasSet {
+		os.Setenv(awsmConfig.HomeEnv, previous)
+	} else {
+		os.Unsetenv(awsmConfig.HomeEnv)
+	}
+	if len(aliases) == 0 {
+		return args
+	}
+
+	// Expand before the aliases become commands themselves
+	expanded, err := expandAliasArgs(args, aliases)
+	if err != nil {
+		util.ErrorColor.Fprintf(os.Stderr, "Error: %v\n", err)
+		expanded = args
+	}
+
+	for name, expansion := range aliases {
+		if isBuiltinCommand(name) {
+			continue
+		}
+		rootCmd.AddCommand(&cobra.Command{
+			Use:                name,
+			Short:              fmt.Sprintf("Alias for 'awsm %s'", expansion),
+			DisableFlagParsing: true,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return fmt.Errorf("alias '%s' could not be expanded", cmd.Name())
+			},
+		})
+	}
+	return expanded
+}
+
+// expandAliasArgs replaces the first positional argument with its alias
+// expansion. Completion requests are expanded too, so completing an alias
+// completes the command it stands for.
+func expandAliasArgs(args []string, aliases map[string]string) ([]string, error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-") {
+			if persistentFlagTakesValue(arg) {
+				i++ // Skip the flag's value
+			}
+			continue
+		}
+		if arg == cobra.ShellCompRequestCmd || arg == cobra.ShellCompNoDescRequestCmd {
+			// Only expand an alias the user finished typing
+			if i+2 < len(args) {
+				continue
+			}
+			return args, nil
+		}
+
+		expansion, ok := aliases[arg]
+		if !ok || isBuiltinCommand(arg) {
+			return args, nil
+		}
+		fields, err := splitAliasArgs(expansion)
+		if err != nil {
+			return nil, fmt.Errorf("alias '%s': %w", arg, err)
+		}
+		expanded := append([]string{}, args[:i]...)
+		expanded = append(expanded, fields...)
+		return append(expanded, args[i+1:]...), nil
+	}
+	return args, nil
+}
+
+// persistentFlagTakesValue reports whether arg is a persistent flag of the
+// root command whose value is the next argument, like '--output json'.
+// Global flags come before the command, so only those are known here.
+func persistentFlagTakesValue(arg string) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+	var flag *pflag.Flag
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		flag = rootCmd.PersistentFlags().Lookup(name)
+	} else if len(arg) == 2 {
+		flag = rootCmd.PersistentFlags().ShorthandLookup(arg[1:])
+	}
+	// Flags with a default for a bare use, like booleans, take no value
+	return flag != nil && flag.NoOptDefVal == ""
+}
+
+// splitAliasArgs splits an expansion into arguments like a shell would,
+// honoring single and double quotes.
+func splitAliasArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in '%s'", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd)
+	aliasCmd.AddCommand(aliasUnsetCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
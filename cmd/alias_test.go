@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func TestSplitAliasArgs(t *testing.T) {
+	got, err := splitAliasArgs(`console --firefox-container "My Work" --service 's3 buckets'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"console", "--firefox-container", "My Work", "--service", "s3 buckets"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if _, err := splitAliasArgs(`console "unterminated`); err == nil {
+		t.Error("Expected an error for an unterminated quote")
+	}
+}
+
+func TestExpandAliasArgs(t *testing.T) {
+	aliases := map[string]string{
+		"cf":      "console --firefox-container",
+		"profile": "console", // Commands always win over aliases
+	}
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"cf", "prod"}, []string{"console", "--firefox-container", "prod"}},
+		{[]string{"--config-dir", "cf", "cf"}, []string{"--config-dir", "cf", "console", "--firefox-container"}},
+		{[]string{"--output", "json", "cf"}, []string{"--output", "json", "console", "--firefox-container"}},
+		{[]string{"--output=json", "cf"}, []string{"--output=json", "console", "--firefox-container"}},
+		{[]string{"--no-browser", "cf"}, []string{"--no-browser", "console", "--firefox-container"}},
+		{[]string{"profile", "list"}, []string{"profile", "list"}},
+		{[]string{"unknown"}, []string{"unknown"}},
+		{[]string{"__complete", "cf", ""}, []string{"__complete", "console", "--firefox-container", ""}},
+		{[]string{"__complete", "co"}, []string{"__complete", "co"}},
+	}
+	for _, tt := range tests {
+		got, err := expandAliasArgs(tt.args, aliases)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("expandAliasArgs(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestApplyAliasesKeepsHomeEnv(t *testing.T) {
+	t.Setenv(awsmConfig.HomeEnv, "")
+	os.Unsetenv(awsmConfig.HomeEnv)
+	args := []string{"--config-dir", t.TempDir(), "version"}
+	if got := applyAliases(args); !slices.Equal(got, args) {
+		t.Errorf("applyAliases(%q) = %q", args, got)
+	}
+	if value, ok := os.LookupEnv(awsmConfig.HomeEnv); ok {
+		t.Errorf("%s leaked into the environment as %q", awsmConfig.HomeEnv, value)
+	}
+}
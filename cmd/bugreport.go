@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"awsm/internal/agent"
+	"awsm/internal/aws"
+	"awsm/internal/bugreport"
+	"awsm/internal/checks"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bugreportOutput string
+	bugreportShow   bool
+)
+
+var bugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Collect an anonymized report to attach to GitHub issues",
+	Long: `Writes a zip archive describing your awsm installation, to attach to a
+GitHub issue:
+
+  version.txt      awsm, Go and OS versions
+  environment.txt  AWS CLI, shell, browsers, agent and which AWS_*/AWSM_*
+                   variables are set (names only)
+  checks.txt       the results of 'awsm status'
+  aws-config.txt   section and key names of ~/.aws/config
+  aws-credentials.txt
+                   section and key names of ~/.aws/credentials
+  awsm-settings.txt
+                   awsm settings from config.toml
+  policy-overrides.txt
+                   the last policy overrides (time, command and count)
+
+Every configuration value is replaced by a short hash, so equal values can be
+told apart without revealing them. Paths under your home directory are
+shortened to ~. Nothing is sent anywhere; review the archive (or use --show)
+before attaching it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := collectBugreport()
+		if err != nil {
+			return err
+		}
+
+		if bugreportShow {
+			for _, f := range files {
+				util.BoldColor.Printf("==> %s <==\n", f.Name)
+				fmt.Println(f.Content)
+			}
+			return nil
+		}
+
+		output := bugreportOutput
+		if output == "" {
+			output = fmt.Sprintf("awsm-bugreport-%s.zip", time.Now().Format("20060102-150405"))
+		}
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer file.Close()
+		if err := bugreport.WriteZip(file, files, time.Now()); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		util.SuccessColor.Printf("✔ Bug report written to %s\n", output)
+		fmt.Println("Review it before attaching it to an issue (or run 'awsm bugreport --show').")
+		return nil
+	},
+}
+
+// collectBugreport gathers the files of the report.
+func collectBugreport() ([]bugreport.File, error) {
+	home, _ := os.UserHomeDir()
+	var files []bugreport.File
+	add := func(name, content string) {
+		if content == "" {
+			content = "(none)\n"
+		}
+		files = append(files, bugreport.File{Name: name, Content: bugreport.ReplaceHome(content, home)})
+	}
+
+	add("version.txt", fmt.Sprintf("awsm:    %s\ncommit:  %s\nbuilt:   %s\ngo:      %s\nos/arch: %s/%s\n",
+		version, commit, date, runtime.Version(), runtime.GOOS, runtime.GOARCH))
+	add("environment.txt", bugreportEnvironment())
+
+	var b strings.Builder
+	quick, _ := checks.Select(nil, false)
+	for _, r := range checks.Run(context.Background(), quick) {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", r.Result.Severity, r.Check.Name, r.Result.Message)
+	}
+	add("checks.txt", b.String())
+
+	configPath, err := aws.GetAWSConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	credentialsPath, err := aws.GetAWSCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []struct{ name, path string }{
+		{"aws-config.txt", configPath},
+		{"aws-credentials.txt", credentialsPath},
+	} {
+		redacted, err := bugreport.RedactINI(f.path)
+		if err != nil {
+			return nil, err
+		}
+		add(f.name, redacted)
+	}
+	add("awsm-settings.txt", bugreport.RedactSettings(awsmConfig.AllSettings()))
+
+	b.Reset()
+	overrides, err := policy.RecentOverrides(20)
+	if err != nil {
+		fmt.Fprintf(&b, "(could not read the override log: %v)\n", err)
+	}
+	for _, entry := range overrides {
+		// Only the command name, its arguments may hold account IDs or keys
+		command := strings.Fields(entry.Command)
+		if len(command) > 2 {
+			command = command[:2]
+		}
+		fmt.Fprintf(&b, "%s  %s  %d violation(s)\n", entry.Time.Format(time.RFC3339), strings.Join(command, " "), len(entry.Violations))
+	}
+	add("policy-overrides.txt", b.String())
+	return files, nil
+}
+
+// bugreportEnvironment describes the tools and settings awsm depends on.
+func bugreportEnvironment() string {
+	var b strings.Builder
+	line := func(name, value string) { fmt.Fprintf(&b, "%-18s %s\n", name+":", value) }
+
+	if v, err := aws.DetectAWSCLIVersion(); err != nil {
+		line("AWS CLI", err.Error())
+	} else {
+		line("AWS CLI", fmt.Sprintf("%s (%s)", v, v.Path))
+	}
+	line("Shell", detectShell())
+	line("Terminal", fmt.Sprintf("TERM=%s, stdin tty=%v, stdout tty=%v", os.Getenv("TERM"), isatty.IsTerminal(os.Stdin.Fd()), isatty.IsTerminal(os.Stdout.Fd())))
+
+	var tools []string
+	for _, name := range []string{"google-chrome", "firefox", "zen", "code", "session-manager-plugin"} {
+		if _, err := exec.LookPath(name); err == nil {
+			tools = append(tools, name)
+		}
+	}
+	line("Tools on PATH", strings.Join(tools, ", "))
+	line("Agent running", fmt.Sprint(agent.Running()))
+	line("Context", awsmConfig.ActiveContextName())
+	line("Active profile", fmt.Sprint(aws.GetCurrentProfileName() != ""))
+
+	var names []string
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, "AWS_") || strings.HasPrefix(name, "AWSM_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	line("Variables set", strings.Join(names, ", "))
+	return b.String()
+}
+
+func init() {
+	bugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Archive to write (default awsm-bugreport-<time>.zip)")
+	bugreportCmd.Flags().BoolVar(&bugreportShow, "show", false, "Print the report instead of writing the archive")
+	rootCmd.AddCommand(bugreportCmd)
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCompactInterval is how often awsm compacts its state directory on its own.
+const cacheCompactInterval = 24 * time.Hour
+
+var (
+	cacheClearAll      bool
+	cacheClearProfiles []string
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean up the data awsm keeps in its state directory",
+	Long: `awsm keeps cached credentials, a log of policy overrides and stashed
+sessions in its state directory (~/.awsm or AWSM_HOME). Credentials of role,
+MFA and SSO profiles are cached in cache/credentials/<profile>.json, readable
+only by you, and reused until they are about to expire. Once a day awsm
+removes expired credentials and entries older than cache.max_age (30d), and
+trims the oldest policy overrides when everything exceeds cache.max_size
+(10MB):
+
+  awsm config set cache.max_age 7d
+  awsm config set cache.max_size 1MB`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show how much data awsm keeps",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		usages, err := aws.CacheUsages(time.Now())
+		if err != nil {
+			return err
+		}
+		var total int64
+		for _, u := range usages {
+			total += u.Bytes
+		}
+		maxAge, maxSize := cacheLimits(true)
+		var compactedAt *time.Time
+		if state, err := config.LoadState(); err == nil && !state.CacheCompactedAt.IsZero() {
+			compactedAt = &state.CacheCompactedAt
+		}
+
+		return output.Render(output.Selected, output.Result{
+			Data: struct {
+				Kinds         []aws.CacheUsage `json:"kinds"`
+				TotalBytes    int64            `json:"total_bytes"`
+				MaxAgeDays    int              `json:"max_age_days"`
+				MaxBytes      int64            `json:"max_bytes"`
+				LastCompacted *time.Time       `json:"last_compacted,omitempty"`
+			}{usages, total, int(maxAge / (24 * time.Hour)), maxSize, compactedAt},
+			Table: func() error {
+				for _, u := range usages {
+					detail := ""
+					if u.Kind == aws.CacheKindCredentials && u.Expired > 0 {
+						detail = fmt.Sprintf(" (%d expired)", u.Expired)
+					}
+					fmt.Printf("%-18s %5d entries %10s%s\n", u.Kind, u.Entries, formatBytes(u.Bytes), detail)
+				}
+				fmt.Printf("%-18s %19s\n", "total", formatBytes(total))
+				fmt.Printf("\nLimits: entries older than %s, %s in total\n", formatDays(maxAge), formatBytes(maxSize))
+				if compactedAt != nil {
+					fmt.Printf("Last compacted: %s\n", compactedAt.Local().Format("2006-01-02 15:04"))
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the profiles with cached credentials and when they expire",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		entries, err := aws.ListCachedCredentials()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if entries == nil {
+			entries = []aws.CachedCredentialsEntry{}
+		}
+		return output.Render(output.Selected, output.Result{
+			Data: entries,
+			Table: func() error {
+				if len(entries) == 0 {
+					util.InfoColor.Println("No cached credentials.")
+					return nil
+				}
+				width := 0
+				for _, e := range entries {
+					width = max(width, len(e.Profile))
+				}
+				for _, e := range entries {
+					fmt.Printf("%s  ", util.BoldColor.Sprintf("%-*s", width, e.Profile))
+					switch {
+					case e.Unreadable:
+						util.ErrorColor.Print("unreadable")
+					case e.Expired(now):
+						util.WarnColor.Printf("expired %s ago", now.Sub(e.Expires).Round(time.Minute))
+					default:
+						util.SuccessColor.Printf("valid for %s", e.Expires.Sub(now).Round(time.Minute))
+					}
+					if !e.CachedAt.IsZero() {
+						fmt.Printf(" (cached %s)", e.CachedAt.Local().Format("2006-01-02 15:04"))
+					}
+					fmt.Println()
+				}
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, e := range entries {
+					fmt.Fprintf(w, "%s\t%s\n", e.Profile, e.Expires.UTC().Format(time.RFC3339))
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var cacheCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Apply the age and size limits now",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := compactCache(true)
+		if err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Removed %d entries, freed %s\n", result.Removed, formatBytes(result.FreedBytes))
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [kind...]",
+	Short: "Remove cached data",
+	Long: `Removes cached data of the given kinds: credentials (the default),
+policy-overrides or session-stash. --all removes all of them. --profile only
+removes the cached credentials of the given profiles.
+
+Examples:
+  awsm cache clear
+  awsm cache clear --profile prod
+  awsm cache clear session-stash
+  awsm cache clear --all`,
+	ValidArgs: aws.CacheKinds,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(cacheClearProfiles) > 0 {
+			if cacheClearAll || len(args) > 0 {
+				return fmt.Errorf("--profile cannot be combined with --all or cache kinds")
+			}
+			removed, err := aws.ClearCachedCredentials(cacheClearProfiles...)
+			if err != nil {
+				return err
+			}
+			util.SuccessColor.Printf("✔ Cleared cached credentials of %d profiles\n", removed)
+			return nil
+		}
+		kinds := args
+		switch {
+		case cacheClearAll && len(args) > 0:
+			return fmt.Errorf("--all cannot be combined with cache kinds")
+		case cacheClearAll:
+			kinds = aws.CacheKinds
+		case len(args) == 0:
+			kinds = []string{aws.CacheKindCredentials}
+		}
+		for _, kind := range kinds {
+			removed, err := aws.ClearCache(kind)
+			if err != nil {
+				return err
+			}
+			util.SuccessColor.Printf("✔ Cleared %s (%d entries)\n", kind, removed)
+		}
+		return nil
+	},
+}
+
+// cacheLimits returns the configured age and size limits, falling back to the
+// defaults for invalid settings, which are reported when verbose.
+func cacheLimits(verbose bool) (time.Duration, int64) {
+	maxAge, ageErr := config.GetCacheMaxAge()
+	maxSize, sizeErr := config.GetCacheMaxSize()
+	if verbose {
+		for _, err := range []error{ageErr, sizeErr} {
+			if err != nil {
+				util.WarnColor.Printf("%v, using the default\n", err)
+			}
+		}
+	}
+	return maxAge, maxSize
+}
+
+// compactCache applies the cache limits and records when it happened.
+func compactCache(verbose bool) (aws.CompactResult, error) {
+	maxAge, maxSize := cacheLimits(verbose)
+	result, err := aws.CompactCache(maxAge, maxSize, time.Now())
+	if err != nil {
+		return result, err
+	}
+	state, err := config.LoadState()
+	if err != nil {
+		return result, err
+	}
+	state.CacheCompactedAt = time.Now().UTC()
+	return result, config.SaveState(state)
+}
+
+// maybeCompactCache compacts the state directory once per
+// cacheCompactInterval. Failures never block the actual command.
+func maybeCompactCache(cmd *cobra.Command) {
+	if cmd.Name() == "completion" || strings.HasPrefix(cmd.Name(), "__") {
+		return
+	}
+	state, err := config.LoadState()
+	if err != nil || time.Since(state.CacheCompactedAt) < cacheCompactInterval {
+		return
+	}
+	compactCache(false)
+}
+
+// formatBytes prints a size with a binary unit.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// formatDays prints whole-day durations in days.
+func formatDays(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+func init() {
+	cacheClearCmd.Flags().BoolVarP(&cacheClearAll, "all", "a", false, "Remove all cached data, including stashed sessions")
+	cacheClearCmd.Flags().StringSliceVarP(&cacheClearProfiles, "profile", "p", nil, "Only clear the cached credentials of these profiles")
+	cacheCmd.AddCommand(cacheStatsCmd, cacheListCmd, cacheCompactCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
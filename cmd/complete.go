@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Shell completion of arguments and flags. Commands set the completion of
+// their arguments, usually with completeArgs; flags that mean the same thing
+// on every command, like --profile and --region, are completed through
+// flagCompletions wherever they appear.
+
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// flagCompletions completes string flags by name on every command that has
+// one and doesn't register a completion of its own.
+var flagCompletions = map[string]completionFunc{
+	"profile":        completeProfiles,
+	"source-profile": completeProfiles,
+	"region":         completeRegions,
+	"sso-session":    completeSSOSessions,
+	"context":        completeContexts,
+	"account":        completeAccountIDs,
+	"expect-account": completeAccountIDs,
+	"shell":          completeShells,
+}
+
+// registerCompletions sets up completion for the command tree: commands whose
+// usage takes no arguments complete no files, and flags in flagCompletions
+// get their completion. It runs before the command line is parsed.
+func registerCompletions(cmd *cobra.Command) {
+	if cmd.ValidArgsFunction == nil && len(cmd.ValidArgs) == 0 && !strings.ContainsAny(cmd.Use, "<[") {
+		cmd.ValidArgsFunction = cobra.NoFileCompletions
+	}
+	cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+		complete, ok := flagCompletions[flag.Name]
+		if !ok || !strings.HasPrefix(flag.Value.Type(), "string") {
+			return
+		}
+		if _, registered := cmd.GetFlagCompletionFunc(flag.Name); registered {
+			return
+		}
+		cmd.RegisterFlagCompletionFunc(flag.Name, complete)
+	})
+	for _, sub := range cmd.Commands() {
+		registerCompletions(sub)
+	}
+}
+
+// completeArgs completes each positional argument with its own function. A nil
+// function, or an argument past the last one, completes nothing.
+func completeArgs(funcs ...completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(funcs) || funcs[len(args)] == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return funcs[len(args)](cmd, args, toComplete)
+	}
+}
+
+// completeProfiles provides completion for profile arguments, excluding sso-session profiles
+var completeProfiles = aws.CompleteProfilesFiltered(func(profile string) bool {
+	return !strings.HasPrefix(profile, "sso-session")
+})
+
+// completeSSOSessions completes SSO session names, described by their start URL.
+func completeSSOSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	sessions, err := aws.ListSSOSessions()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var matches []string
+	for _, s := range sessions {
+		if aws.FuzzyMatch(s.Name, toComplete) {
+			matches = append(matches, s.Name+"\t"+s.StartURL)
+		}
+	}
+	return matches, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRegions completes the regions awsm knows, described by their location.
+func completeRegions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return regionCompletions(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// regionCompletions returns the regions with their location as description.
+func regionCompletions() []string {
+	var completions []string
+	for _, region := range aws.GetAllRegions() {
+		completions = append(completions, region+"\t"+aws.RegionDescription(region))
+	}
+	return completions
+}
+
+// completeAccountIDs completes the account IDs profiles point at, described
+// by the profiles.
+func completeAccountIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := aws.ListProfilesDetailed()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	byAccount := make(map[string][]string)
+	for _, p := range profiles {
+		if id := p.AccountID(); id != "" {
+			byAccount[id] = append(byAccount[id], p.Name)
+		}
+	}
+	completions := make([]string, 0, len(byAccount))
+	for id, names := range byAccount {
+		sort.Strings(names)
+		completions = append(completions, id+"\t"+strings.Join(names, ", "))
+	}
+	sort.Strings(completions)
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSearchTerms completes what 'awsm search' looks through: profile
+// names, account IDs and SSO sessions.
+func completeSearchTerms(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, _ := completeProfiles(cmd, args, toComplete)
+	accounts, _ := completeAccountIDs(cmd, args, toComplete)
+	sessions, _ := completeSSOSessions(cmd, args, toComplete)
+	completions := append(profiles, accounts...)
+	return append(completions, sessions...), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	aliases := awsmConfig.GetAliases()
+	var names []string
+	for name := range aliases {
+		names = append(names, name+"\t"+aliases[name])
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeContexts completes context names, including the default context.
+func completeContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := []string{awsmConfig.DefaultContext + "\tregular AWS files"}
+	for _, c := range awsmConfig.GetContexts() {
+		names = append(names, c.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfileTypes completes the profile types 'awsm profile list --type' filters by.
+func completeProfileTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	types := []aws.ProfileType{aws.ProfileTypeSSO, aws.ProfileTypeIAM, aws.ProfileTypeKey, aws.ProfileTypeProcess}
+	completions := make([]string, len(types))
+	for i, t := range types {
+		completions[i] = string(t)
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagKeys completes the tag keys set on profiles.
+func completeTagKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	tags, err := awsmConfig.LoadProfileTags()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, profileTags := range tags {
+		for key := range profileTags {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTagFilters completes --tag filters: the keys, and key=value once a
+// key is typed.
+func completeTagFilters(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	key, _, hasValue := strings.Cut(toComplete, "=")
+	if !hasValue {
+		keys, directive := completeTagKeys(cmd, args, toComplete)
+		return keys, directive | cobra.ShellCompDirectiveNoSpace
+	}
+	tags, err := awsmConfig.LoadProfileTags()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	seen := make(map[string]bool)
+	var filters []string
+	for _, profileTags := range tags {
+		if value, ok := profileTags[key]; ok && !seen[value] {
+			seen[value] = true
+			filters = append(filters, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	sort.Strings(filters)
+	return filters, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeShells completes the shells env output can be formatted for.
+func completeShells(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return supportedShells, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeOutputFormats completes the values of --output.
+func completeOutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	formats := make([]string, len(output.Formats))
+	for i, format := range output.Formats {
+		formats[i] = string(format)
+	}
+	return formats, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeValues completes a fixed list of values.
+func completeValues(values ...string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestCompleteArgs(t *testing.T) {
+	complete := completeArgs(completeValues("a", "b"), nil, completeValues("c"))
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"a", "b"}},
+		{[]string{"a"}, nil},
+		{[]string{"a", "x"}, []string{"c"}},
+		{[]string{"a", "x", "c"}, nil},
+	}
+	for _, tt := range tests {
+		got, directive := complete(&cobra.Command{}, tt.args, "")
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("completeArgs with args %v = %v, want %v", tt.args, got, tt.want)
+		}
+		if directive != cobra.ShellCompDirectiveNoFileComp {
+			t.Errorf("completeArgs with args %v returned directive %v", tt.args, directive)
+		}
+	}
+}
+
+func TestRegisterCompletions(t *testing.T) {
+	var profile, region string
+	var shell bool
+	root := &cobra.Command{Use: "root"}
+	withArgs := &cobra.Command{Use: "edit <file>", Run: func(*cobra.Command, []string) {}}
+	noArgs := &cobra.Command{Use: "list", Run: func(*cobra.Command, []string) {}}
+	noArgs.Flags().StringVar(&profile, "profile", "", "")
+	noArgs.Flags().StringVar(&region, "region", "", "")
+	noArgs.Flags().BoolVar(&shell, "shell", false, "")
+	own := completeValues("eu-west-1")
+	noArgs.RegisterFlagCompletionFunc("region", own)
+	root.AddCommand(withArgs, noArgs)
+
+	registerCompletions(root)
+
+	if withArgs.ValidArgsFunction != nil {
+		t.Error("a command taking arguments should keep the default completion")
+	}
+	if noArgs.ValidArgsFunction == nil {
+		t.Error("a command without arguments should complete no files")
+	}
+	if _, ok := noArgs.GetFlagCompletionFunc("profile"); !ok {
+		t.Error("--profile should complete profiles")
+	}
+	complete, _ := noArgs.GetFlagCompletionFunc("region")
+	if got, _ := complete(noArgs, nil, ""); !reflect.DeepEqual(got, []string{"eu-west-1"}) {
+		t.Errorf("a registered --region completion was replaced, got %v", got)
+	}
+	if _, ok := noArgs.GetFlagCompletionFunc("shell"); ok {
+		t.Error("boolean flags should not get value completions")
+	}
+}
+
+func TestEveryProfileAndRegionFlagCompletes(t *testing.T) {
+	registerCompletions(rootCmd)
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		cmd.LocalFlags().VisitAll(func(flag *pflag.Flag) {
+			if _, shared := flagCompletions[flag.Name]; !shared || flag.Value.Type() != "string" {
+				return
+			}
+			if _, ok := cmd.GetFlagCompletionFunc(flag.Name); !ok {
+				t.Errorf("'%s --%s' has no completion", cmd.CommandPath(), flag.Name)
+			}
+		})
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
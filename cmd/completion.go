@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	completionDiff   bool
+	completionUpdate bool
+	completionPaths  []string
+)
+
+// completionScript is an installed completion script.
+type completionScript struct {
+	Shell string
+	Path  string
+}
+
+// setupCompletionCmd adds the drift check to cobra's default completion
+// command, which only exists once it has been initialized.
+func setupCompletionCmd() {
+	rootCmd.InitDefaultCompletionCmd()
+	completionCmd, _, err := rootCmd.Find([]string{"completion"})
+	if err != nil || completionCmd == rootCmd {
+		return
+	}
+	completionCmd.Long += `
+With --diff, completion scripts installed in the usual places (or given with
+--path) are compared with the ones this version of awsm generates, since static
+scripts go stale after an upgrade. Stale scripts can be updated in place.
+
+Examples:
+  awsm completion --diff
+  awsm completion --diff --update
+  awsm completion --diff --path ~/.bash_completion.d/awsm`
+	completionCmd.Flags().BoolVar(&completionDiff, "diff", false, "Compare installed completion scripts with freshly generated ones")
+	completionCmd.Flags().BoolVar(&completionUpdate, "update", false, "With --diff, update stale scripts without asking")
+	completionCmd.Flags().StringSliceVar(&completionPaths, "path", nil, "With --diff, check this script instead of the usual places (repeatable)")
+	completionCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !completionDiff {
+			return cmd.Help()
+		}
+		return runCompletionDiff()
+	}
+}
+
+// installedCompletionScripts returns the completion scripts found in the
+// places the installation instructions use.
+func installedCompletionScripts() []completionScript {
+	home, _ := os.UserHomeDir()
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	candidates := []completionScript{
+		{"bash", "/etc/bash_completion.d/awsm"},
+		{"bash", "/usr/local/etc/bash_completion.d/awsm"},
+		{"bash", "/opt/homebrew/etc/bash_completion.d/awsm"},
+		{"bash", filepath.Join(dataHome, "bash-completion", "completions", "awsm")},
+		{"zsh", filepath.Join(home, ".zsh", "completions", "_awsm")},
+		{"zsh", "/usr/local/share/zsh/site-functions/_awsm"},
+		{"zsh", "/opt/homebrew/share/zsh/site-functions/_awsm"},
+		{"fish", filepath.Join(configHome, "fish", "completions", "awsm.fish")},
+		{"fish", "/usr/local/share/fish/vendor_completions.d/awsm.fish"},
+		{"fish", "/opt/homebrew/share/fish/vendor_completions.d/awsm.fish"},
+		{"fish", "/usr/share/fish/vendor_completions.d/awsm.fish"},
+	}
+	var found []completionScript
+	for _, c := range candidates {
+		if info, err := os.Stat(c.Path); err == nil && !info.IsDir() {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// completionShellForPath guesses the shell of a script from its file name.
+func completionShellForPath(path string) string {
+	base := filepath.Base(path)
+	switch {
+	case strings.HasPrefix(base, "_"):
+		return "zsh"
+	case strings.HasSuffix(base, ".fish"):
+		return "fish"
+	case strings.HasSuffix(base, ".ps1"):
+		return "powershell"
+	default:
+		return "bash"
+	}
+}
+
+// generateCompletion returns the completion script for shell, with or
+// without descriptions as 'awsm completion <shell> --no-descriptions' writes it.
+func generateCompletion(shell string, includeDesc bool) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	switch shell {
+	case "bash":
+		err = rootCmd.GenBashCompletionV2(&buf, includeDesc)
+	case "zsh":
+		if includeDesc {
+			err = rootCmd.GenZshCompletion(&buf)
+		} else {
+			err = rootCmd.GenZshCompletionNoDesc(&buf)
+		}
+	case "fish":
+		err = rootCmd.GenFishCompletion(&buf, includeDesc)
+	case "powershell":
+		if includeDesc {
+			err = rootCmd.GenPowerShellCompletionWithDesc(&buf)
+		} else {
+			err = rootCmd.GenPowerShellCompletion(&buf)
+		}
+	default:
+		return "", fmt.Errorf("unsupported shell '%s'", shell)
+	}
+	return buf.String(), err
+}
+
+// completionDrift compares an installed script with the one generated for
+// its shell, with descriptions unless the installed one was written with
+// --no-descriptions. It returns an empty diff when they match.
+func completionDrift(script completionScript) (diff, fresh string, err error) {
+	installed, err := os.ReadFile(script.Path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read %s: %w", script.Path, err)
+	}
+	// Scripts without descriptions request completions from __completeNoDesc
+	includeDesc := !bytes.Contains(installed, []byte("__completeNoDesc"))
+	fresh, err = generateCompletion(script.Shell, includeDesc)
+	if err != nil {
+		return "", "", err
+	}
+	return awsmConfig.UnifiedDiff(script.Path, script.Path+" (generated)", string(installed), fresh, 3), fresh, nil
+}
+
+func runCompletionDiff() error {
+	scripts := installedCompletionScripts()
+	if len(completionPaths) > 0 {
+		scripts = nil
+		for _, path := range completionPaths {
+			scripts = append(scripts, completionScript{Shell: completionShellForPath(path), Path: path})
+		}
+	}
+	if len(scripts) == 0 {
+		util.InfoColor.Println("No installed completion scripts found in the usual places; pass --path to check one elsewhere.")
+		return nil
+	}
+
+	stale := 0
+	for _, script := range scripts {
+		diff, fresh, err := completionDrift(script)
+		if err != nil {
+			return err
+		}
+		if diff == "" {
+			util.SuccessColor.Printf("✔ %s (%s) is up to date\n", script.Path, script.Shell)
+			continue
+		}
+		util.WarnColor.Printf("✗ %s (%s) differs from this version of awsm\n", script.Path, script.Shell)
+		printUnifiedDiff(diff)
+
+		update := completionUpdate
+		if !update && isatty.IsTerminal(os.Stdin.Fd()) {
+			answer, err := util.PromptForInput(fmt.Sprintf("Update %s? (y/N): ", script.Path))
+			if err != nil {
+				return err
+			}
+			update = strings.EqualFold(strings.TrimSpace(answer), "y")
+		}
+		if !update {
+			stale++
+			continue
+		}
+		if err := os.WriteFile(script.Path, []byte(fresh), 0644); err != nil {
+			if errors.Is(err, os.ErrPermission) {
+				return fmt.Errorf("failed to update %s: %w (run with sudo for system-wide scripts)", script.Path, err)
+			}
+			return fmt.Errorf("failed to update %s: %w", script.Path, err)
+		}
+		util.SuccessColor.Printf("✔ Updated %s; restart your shell to use it\n", script.Path)
+	}
+	if stale > 0 {
+		return fmt.Errorf("%d completion scripts are out of date, update them with 'awsm completion --diff --update'", stale)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompletionShellForPath(t *testing.T) {
+	tests := map[string]string{
+		"/etc/bash_completion.d/awsm":                 "bash",
+		"/home/me/.zsh/completions/_awsm":             "zsh",
+		"/home/me/.config/fish/completions/awsm.fish": "fish",
+		"/home/me/awsm.ps1":                           "powershell",
+	}
+	for path, want := range tests {
+		if got := completionShellForPath(path); got != want {
+			t.Errorf("completionShellForPath(%s) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestCompletionDrift(t *testing.T) {
+	dir := t.TempDir()
+	for _, includeDesc := range []bool{true, false} {
+		script, err := generateCompletion("zsh", includeDesc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "_awsm")
+		if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if diff, _, err := completionDrift(completionScript{Shell: "zsh", Path: path}); err != nil || diff != "" {
+			t.Errorf("expected a fresh script (descriptions %v) to match, got %v:\n%s", includeDesc, err, diff)
+		}
+
+		// A stale script is diffed against the same variant
+		stale := script + "# written by an older awsm\n"
+		if err := os.WriteFile(path, []byte(stale), 0644); err != nil {
+			t.Fatal(err)
+		}
+		diff, fresh, err := completionDrift(completionScript{Shell: "zsh", Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff == "" || fresh != script {
+			t.Errorf("expected drift against the script with descriptions %v, got:\n%s", includeDesc, diff)
+		}
+	}
+}
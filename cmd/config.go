@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintJSON   bool
+	lintStrict bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate AWS config files and change awsm settings",
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change an awsm setting",
+	Long: `Writes a setting to awsm's config.toml. Keys use dots for tables, e.g.
+sso.no_browser or chrome_profiles.work. For list settings such as
+warnings.suppress the value is added to the list.
+
+Examples:
+  awsm config set sso.no_browser true
+  awsm config set warnings.suppress W004`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value := normalizeSettingValue(args[0], args[1])
+		if err := awsmConfig.SetSetting(args[0], value); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ %s set to %s\n", util.BoldColor.Sprint(args[0]), value)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key> [value]",
+	Short: "Remove an awsm setting",
+	Long: `Removes a setting from awsm's config.toml. With a value, only that value is
+removed from a list setting.
+
+Example:
+  awsm config unset warnings.suppress W004`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value := ""
+		if len(args) == 2 {
+			value = normalizeSettingValue(args[0], args[1])
+		}
+		if err := awsmConfig.UnsetSetting(args[0], value); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ %s removed\n", util.BoldColor.Sprint(strings.TrimSpace(args[0]+" "+value)))
+		return nil
+	},
+}
+
+// normalizeSettingValue writes warning IDs as they are printed, e.g. W004.
+func normalizeSettingValue(key, value string) string {
+	if strings.EqualFold(key, "warnings.suppress") {
+		return strings.ToUpper(value)
+	}
+	return value
+}
+
+var configWarningsCmd = &cobra.Command{
+	Use:   "warnings",
+	Short: "List the warning IDs awsm prints",
+	Long: `Lists the warnings awsm prints with their IDs and whether they are
+suppressed. Silence a warning with 'awsm config set warnings.suppress <ID>'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		type warningOutput struct {
+			ID          util.WarningID `json:"id"`
+			Description string         `json:"description"`
+			Suppressed  bool           `json:"suppressed"`
+		}
+		warnings := make([]warningOutput, len(util.Warnings))
+		for i, w := range util.Warnings {
+			warnings[i] = warningOutput{w.ID, w.Description, util.IsWarningSuppressed(w.ID)}
+		}
+		return output.Render(output.Selected, output.Result{
+			Data: warnings,
+			Table: func() error {
+				for _, w := range warnings {
+					status := ""
+					if w.Suppressed {
+						status = util.InfoColor.Sprint(" (suppressed)")
+					}
+					fmt.Printf("%s  %s%s\n", util.BoldColor.Sprint(w.ID), w.Description, status)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint [path...]",
+	Short: "Validate AWS config files without changing them",
+	Long: `Checks AWS config files for syntax errors, duplicate sections, references to
+undefined SSO sessions or source profiles, malformed role ARNs and account IDs,
+unknown regions and organization policy violations.
+
+Any file can be checked, not only the live config, which makes the command
+suitable for CI of dotfile repositories. Use "-" to read from stdin. Files
+given together are checked as one config, so references may cross files.
+Without arguments the live AWS config and its ~/.aws/config.d fragments are
+checked.
+
+The command exits with status 1 when errors are found (or warnings, with --strict).
+
+Examples:
+  awsm config lint
+  awsm config lint dotfiles/aws/config --output json
+  git show HEAD:aws/config | awsm config lint - --strict`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if len(paths) == 0 {
+			configPath, err := aws.GetAWSConfigPath()
+			if err != nil {
+				return err
+			}
+			fragments, err := aws.ListConfigFragments()
+			if err != nil {
+				return err
+			}
+			paths = append([]string{configPath}, fragments...)
+		}
+
+		var inputs []aws.LintInput
+		for _, path := range paths {
+			if path == "-" {
+				inputs = append(inputs, aws.LintInput{File: "<stdin>", Reader: os.Stdin})
+				continue
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", path, err)
+			}
+			defer file.Close()
+			inputs = append(inputs, aws.LintInput{File: path, Reader: file})
+		}
+		findings, err := aws.LintConfigFiles(inputs...)
+		if err != nil {
+			return err
+		}
+		if findings == nil {
+			findings = []aws.LintFinding{}
+		}
+
+		summary := aws.LintSummary(findings)
+		format := output.Current(lintJSON)
+		err = output.Render(format, output.Result{
+			Data: struct {
+				Findings []aws.LintFinding `json:"findings"`
+				Summary  map[string]int    `json:"summary"`
+			}{findings, summary},
+			Table: func() error {
+				printLintFindings(findings, summary)
+				return nil
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if summary[aws.SeverityError] > 0 || (lintStrict && summary[aws.SeverityWarning] > 0) {
+			cmd.SilenceErrors = format.Structured()
+			return fmt.Errorf("config lint failed")
+		}
+		return nil
+	},
+}
+
+func printLintFindings(findings []aws.LintFinding, summary map[string]int) {
+	for _, f := range findings {
+		switch f.Severity {
+		case aws.SeverityError:
+			util.ErrorColor.Println(f)
+		case aws.SeverityWarning:
+			util.WarnColor.Println(f)
+		default:
+			util.InfoColor.Println(f)
+		}
+	}
+	if len(findings) == 0 {
+		util.SuccessColor.Println("✔ No problems found")
+		return
+	}
+	fmt.Printf("\n%d error(s), %d warning(s), %d info\n", summary[aws.SeverityError], summary[aws.SeverityWarning], summary[aws.SeverityInfo])
+}
+
+var materializeOutput string
+
+var configMaterializeCmd = &cobra.Command{
+	Use:   "materialize",
+	Short: "Flatten ~/.aws/config and its config.d fragments into one file",
+	Long: `awsm reads the *.conf fragments in ~/.aws/config.d together with ~/.aws/config,
+and writes generated SSO profiles to ~/.aws/config.d/` + aws.GeneratedFragmentName + ` when
+that directory exists. Tools that only read a single config file, like the AWS
+CLI, don't see the fragments; materialize prints the merged config so it can be
+used with AWS_CONFIG_FILE. When a fragment redefines a section its keys win and
+keys only set in earlier files are kept, as the SDK merges them.
+
+Examples:
+  awsm config materialize -o ~/.aws/config.full
+  AWS_CONFIG_FILE=~/.aws/config.full aws s3 ls --profile prod`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := aws.MaterializeConfig()
+		if err != nil {
+			return err
+		}
+		if materializeOutput == "" {
+			fmt.Print(content)
+			return nil
+		}
+		if err := os.WriteFile(materializeOutput, []byte(content), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", materializeOutput, err)
+		}
+		util.SuccessColor.Printf("✔ Materialized config written to %s\n", util.BoldColor.Sprint(materializeOutput))
+		return nil
+	},
+}
+
+func init() {
+	configLintCmd.Flags().BoolVarP(&lintJSON, "json", "j", false, "Output findings in JSON format (same as --output json)")
+	configLintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Fail on warnings too")
+	configMaterializeCmd.Flags().StringVarP(&materializeOutput, "output", "o", "", "Write to this file instead of stdout")
+	configCmd.AddCommand(configLintCmd)
+	configCmd.AddCommand(configMaterializeCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configWarningsCmd)
+	rootCmd.AddCommand(configCmd)
+}
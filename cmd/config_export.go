@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"awsm/internal/share"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	configExportOutput  string
+	configExportEncrypt bool
+)
+
+var configExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export profiles and SSO sessions to a bundle for another machine",
+	Long: `Writes all profiles and SSO sessions to a bundle that 'awsm config import'
+reads on another machine.
+
+With --encrypt the bundle is encrypted with a passphrase (age, scrypt) and
+includes the static access keys and the raw config and credentials files.
+Without it, static keys are left out so the bundle can't leak them.
+
+Examples:
+  awsm config export --output bundle.awsm --encrypt
+  awsm config import bundle.awsm`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if configExportOutput == "" {
+			return fmt.Errorf("--output is required")
+		}
+
+		exportData, err := buildExportData(configExportEncrypt)
+		if err != nil {
+			return err
+		}
+		payload, err := json.MarshalIndent(exportData, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode export data: %w", err)
+		}
+
+		if configExportEncrypt {
+			passphrase, err := promptNewPassphrase()
+			if err != nil {
+				return err
+			}
+			var encrypted bytes.Buffer
+			if err := share.EncryptWithPassphrase(&encrypted, payload, passphrase); err != nil {
+				return err
+			}
+			payload = encrypted.Bytes()
+		}
+
+		if err := os.WriteFile(configExportOutput, payload, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", configExportOutput, err)
+		}
+
+		util.SuccessColor.Printf("✔ Exported %d profiles, %d SSO sessions to %s\n", len(exportData.Profiles), len(exportData.SSOSessions), configExportOutput)
+		if !configExportEncrypt {
+			util.InfoColor.Println("Static access keys were left out, use --encrypt to include them.")
+		}
+		return nil
+	},
+}
+
+var configImportCmd = &cobra.Command{
+	Use:   "import <bundle>",
+	Short: "Import profiles and SSO sessions from an exported bundle",
+	Long: `Merges the profiles and SSO sessions of a bundle written by 'awsm config
+export' into the AWS config. Encrypted bundles ask for their passphrase.
+
+With --restore-files the config and credentials files of an encrypted bundle
+replace the local ones entirely (the originals are kept as .bak).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exportData, err := readExportFile(args[0])
+		if err != nil {
+			return err
+		}
+		if importRestoreFiles && exportData.ConfigFile == "" && exportData.CredentialsFile == "" {
+			return fmt.Errorf("bundle has no config files to restore, export it with --encrypt")
+		}
+		return importExportData(exportData)
+	},
+}
+
+// promptNewPassphrase asks for a passphrase twice so typos don't lock the
+// bundle for good.
+func promptNewPassphrase() (string, error) {
+	passphrase, err := util.PromptForSecret("Passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if len(passphrase) < 8 {
+		return "", fmt.Errorf("passphrase must have at least 8 characters")
+	}
+	confirm, err := util.PromptForSecret("Repeat passphrase: ")
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("passphrases don't match")
+	}
+	return passphrase, nil
+}
+
+func init() {
+	configExportCmd.Flags().StringVarP(&configExportOutput, "output", "o", "", "File to write the bundle to")
+	configExportCmd.Flags().BoolVar(&configExportEncrypt, "encrypt", false, "Encrypt the bundle with a passphrase and include static access keys")
+	configImportCmd.Flags().BoolVarP(&importForce, "force", "f", false, "Import without confirmation")
+	configImportCmd.Flags().BoolVar(&importRestoreFiles, "restore-files", false, "Restore the exact config and credentials files (overwrites everything)")
+	configCmd.AddCommand(configExportCmd)
+	configCmd.AddCommand(configImportCmd)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
 
 	"awsm/internal/aws"
 	"awsm/internal/tui"
@@ -30,13 +31,15 @@ type ConnectConfig struct {
 }
 
 var connectCmd = &cobra.Command{
-	Use:   "connect [instance-id]",
+	Use:   "connect [instance-id-or-name]",
 	Short: "Connect to an EC2 instance via SSM",
 	Long: `Connects to an EC2 instance using AWS Systems Manager (SSM) Session Manager.
 It also supports port forwarding to the instance or to a remote host (like RDS) via the instance.
+The instance can be given by ID or by its Name tag; without one, running instances are listed to pick from.
 
 	Examples:
   awsm connect i-0123456789abcdef0                     # Standard shell session
+  awsm connect web-server                              # Instance by Name tag
   awsm connect i-0123456789abcdef0 --port-forwarding --remote-port 80 --local-port 8080
   awsm connect i-0123456789abcdef0 -p -r 5432 -H database.internal
   awsm connect --config connect.json`,
@@ -98,6 +101,12 @@ func runConnect(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		instanceID = instance.InstanceID
+	} else if !strings.HasPrefix(instanceID, "i-") {
+		instance, err := resolveInstanceName(currentProfile, region, instanceID)
+		if err != nil {
+			return err
+		}
+		instanceID = instance.InstanceID
 	}
 
 	// Prepare aws ssm command
@@ -113,7 +122,7 @@ func runConnect(cmd *cobra.Command, args []string) error {
 
 		// Check for privileged ports on Unix-like systems
 		if localPort < 1024 && os.Geteuid() != 0 {
-			util.WarnColor.Fprintf(os.Stderr, "Warning: Local port %d is a privileged port. You might need sudo or to use a higher port (e.g., -l 8080).\n", localPort)
+			util.Warn(util.WarnPrivilegedPort, "Local port %d is a privileged port. You might need sudo or to use a higher port (e.g., -l 8080).", localPort)
 		}
 
 		if remoteHost != "" {
@@ -162,12 +171,36 @@ func runConnect(cmd *cobra.Command, args []string) error {
 	return ssmCmd.Run()
 }
 
+// resolveInstanceName finds the running instance whose Name tag matches name,
+// letting the user pick when several match.
+func resolveInstanceName(profile, region, name string) (aws.EC2Instance, error) {
+	instances, err := aws.ListRunningInstances(profile, region)
+	if err != nil {
+		return aws.EC2Instance{}, err
+	}
+	matches := aws.MatchInstances(instances, name)
+	switch len(matches) {
+	case 0:
+		return aws.EC2Instance{}, fmt.Errorf("no running instance named '%s' found in region %s", name, region)
+	case 1:
+		return matches[0], nil
+	default:
+		return tui.SelectEC2InstanceFrom(matches)
+	}
+}
+
+// addConnectFlags registers the session and port forwarding flags shared by
+// 'awsm connect' and 'awsm ec2 ssm'.
+func addConnectFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&portForwarding, "port-forwarding", "p", false, "Enable port forwarding")
+	cmd.Flags().IntVarP(&remotePort, "remote-port", "r", 0, "Remote port (default 80)")
+	cmd.Flags().IntVarP(&localPort, "local-port", "l", 0, "Local port (defaults to remote port)")
+	cmd.Flags().StringVarP(&remoteHost, "remote-host", "H", "", "Remote host (for RDS/external host forwarding)")
+	cmd.Flags().StringVarP(&configFile, "config", "f", "", "JSON config file for connection")
+}
+
 func init() {
-	connectCmd.Flags().BoolVarP(&portForwarding, "port-forwarding", "p", false, "Enable port forwarding")
-	connectCmd.Flags().IntVarP(&remotePort, "remote-port", "r", 0, "Remote port (default 80)")
-	connectCmd.Flags().IntVarP(&localPort, "local-port", "l", 0, "Local port (defaults to remote port)")
-	connectCmd.Flags().StringVarP(&remoteHost, "remote-host", "H", "", "Remote host (for RDS/external host forwarding)")
-	connectCmd.Flags().StringVarP(&configFile, "config", "f", "", "JSON config file for connection")
+	addConnectFlags(connectCmd)
 
 	rootCmd.AddCommand(connectCmd)
 }
@@ -15,15 +15,22 @@ import (
 	"awsm/internal/browser"
 	"awsm/internal/util"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dontOpenBrowser bool
-	useFirefox      bool
-	useZen          bool
-	chromeProfile   string
-	profileName     string
+	dontOpenBrowser  bool
+	consoleClipboard bool
+	consoleQR        bool
+	useFirefox       bool
+	useZen           bool
+	chromeProfile    string
+	profileName      string
+	consoleRegion    string
+
+	consoleExpectAccount string
+	consoleService       string
 )
 
 var consoleCmd = &cobra.Command{
@@ -40,10 +47,35 @@ By default, opens in the default browser.
 Use --chrome-profile to open in a specific Chrome profile.
 Use --firefox-container to open in a Firefox container matching your AWS profile name.
 Use --zen-container to open in a Zen Browser container matching your AWS profile name.
+A missing container is created, colored by account and with an icon for the
+profile type, so all profiles of an account look alike.
+
+The console opens in the region given with --region, AWS_REGION or the
+profile's region, on the regional console domain. China and GovCloud regions
+sign in through their own partition's endpoints.
+
+Use --service to land on a service page in that region instead of the console
+home: a known service (see completion), a page inside a service such as
+"lambda/functions/my-fn", or a raw console path starting with "/".
+
+With --expect-account the console only opens when the credentials belong to
+that account, checked with an STS call.
+
+Instead of opening a browser, --print prints the sign-in URL, --clipboard
+copies it to the clipboard and --qr renders it as a QR code in the terminal,
+e.g. to open the console on a tablet during an incident. The URL signs in
+anyone who has it, and only works for 15 minutes.
 
 Make sure to set a session first with 'awsm profile set <profile-name>' or use --profile flag to specify a profile.`,
 	Aliases: []string{"c", "open"},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if consoleRegion != "" && !aws.IsValidConsoleRegion(consoleRegion) {
+			return fmt.Errorf("invalid region: %s", consoleRegion)
+		}
+		if err := validateAccountID(consoleExpectAccount); err != nil {
+			return err
+		}
+
 		// Get profile to use - either from --profile flag or current profile
 		var currentProfile string
 		if profileName != "" {
@@ -72,7 +104,7 @@ Make sure to set a session first with 'awsm profile set <profile-name>' or use -
 			// Check if this is a credential expiration error
 			if errors.Is(err, aws.ErrSsoSessionExpired) || strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "InvalidGrantException") {
 				// Try to refresh the session
-				util.WarnColor.Fprintln(os.Stderr, "Credentials expired. Attempting to refresh session...")
+				util.Warn(util.WarnCredentialsExpired, "Credentials expired. Attempting to refresh session...")
 
 				// Get SSO session name for the current profile
 				ssoSession, err := aws.GetSsoSessionForProfile(currentProfile)
@@ -88,10 +120,10 @@ Make sure to set a session first with 'awsm profile set <profile-name>' or use -
 				// Retry retrieving credentials
 				tempCreds, isStatic, err = aws.GetCredentialsForProfile(currentProfile)
 				if err != nil {
-					return fmt.Errorf("failed to retrieve credentials after refresh: %w", err)
+					return aws.WrapProfileError("retrieve credentials after refresh", currentProfile, err)
 				}
 			} else {
-				return fmt.Errorf("failed to retrieve credentials for profile '%s': %w\n\nPlease check your profile configuration with:\n  awsm profile list --detailed", currentProfile, err)
+				return fmt.Errorf("%w\n\nPlease check your profile configuration with:\n  awsm profile list --detailed", aws.WrapProfileError("retrieve credentials", currentProfile, err))
 			}
 		}
 
@@ -128,9 +160,26 @@ Make sure to set a session first with 'awsm profile set <profile-name>' or use -
 			}
 		}
 
-		resp, err := http.PostForm("https://signin.aws.amazon.com/federation", formData)
+		// The sign-in token must come from the partition of the console region
+		region := consoleRegion
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		if region == "" {
+			region, _ = aws.GetProfileRegion(currentProfile)
+		}
+		if region == "" {
+			region = "us-east-1"
+			util.Warn(util.WarnNoRegion, "No region found, defaulting to us-east-1")
+		}
+		if err := checkExpectedAccount(currentProfile, tempCreds, region, consoleExpectAccount); err != nil {
+			return err
+		}
+		endpoints := aws.GetConsoleEndpoints(region)
+
+		resp, err := http.PostForm(endpoints.FederationURL, formData)
 		if err != nil {
-			return fmt.Errorf("failed to get sign-in token: %w", err)
+			return aws.WrapProfileError("get console sign-in token", currentProfile, err)
 		}
 		defer resp.Body.Close()
 
@@ -169,52 +218,102 @@ Make sure to set a session first with 'awsm profile set <profile-name>' or use -
 		if tokenResp.SigninToken == "" {
 			return fmt.Errorf("sign-in token not found in response. Response was: %s", string(body))
 		}
-		region := os.Getenv("AWS_REGION")
-		if region == "" {
-			region, _ = aws.GetProfileRegion(currentProfile)
-		}
-		if region == "" {
-			region = "us-east-1"
-			util.WarnColor.Fprintln(os.Stderr, "No region found, defaulting to us-east-1")
+		destination := endpoints.Destination
+		if consoleService != "" {
+			destination = aws.ConsoleServiceURL(region, consoleService)
 		}
-		destination := fmt.Sprintf("https://%s.console.aws.amazon.com/console/home?region=%s", region, region)
-		loginURL := fmt.Sprintf("https://signin.aws.amazon.com/federation?Action=login&Issuer=awsm&Destination=%s&SigninToken=%s", url.QueryEscape(destination), url.QueryEscape(tokenResp.SigninToken))
+		loginURL := fmt.Sprintf("%s?Action=login&Issuer=awsm&Destination=%s&SigninToken=%s", endpoints.FederationURL, url.QueryEscape(destination), url.QueryEscape(tokenResp.SigninToken))
 
-		if dontOpenBrowser {
-			fmt.Println(loginURL)
-		} else {
-			// If --firefox-container is used, use the profile we determined earlier
-			var firefoxContainer string
-			if useFirefox {
-				firefoxContainer = currentProfile
-			}
+		if dontOpenBrowser || consoleClipboard || consoleQR {
+			return shareConsoleURL(loginURL)
+		}
 
-			// If --zen-container is used, use the profile we determined earlier
-			var zenContainer string
-			if useZen {
-				zenContainer = currentProfile
+		// --firefox-container and --zen-container use a container named after the profile
+		if useFirefox || useZen {
+			app := browser.Firefox
+			if !useFirefox {
+				app = browser.Zen
 			}
+			return openConsoleInContainer(app, loginURL, currentProfile)
+		}
 
-			if err := browser.OpenURL(loginURL, chromeProfile, firefoxContainer, zenContainer); err != nil {
-				fmt.Fprintln(os.Stderr, "Could not open browser automatically. Please copy this URL:")
-				fmt.Println(loginURL)
-				return fmt.Errorf("could not open browser: %w", err)
-			}
+		if err := browser.OpenURL(loginURL, chromeProfile, "", ""); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not open browser automatically. Please copy this URL:")
+			fmt.Println(loginURL)
+			return fmt.Errorf("could not open browser: %w", err)
 		}
 
 		return nil
 	},
 }
 
+// openConsoleInContainer opens the sign-in URL in the container named after
+// profile, creating it with a color picked by account and an icon by profile
+// type when it doesn't exist yet.
+func openConsoleInContainer(app browser.App, loginURL, profile string) error {
+	style := browser.StyleForProfile("", profile, "")
+	if info, err := findProfileInfo(profile); err == nil {
+		style = browser.StyleForProfile(info.AccountID(), profile, string(info.Type))
+	}
+	created, err := browser.OpenURLInContainer(app, loginURL, profile, style)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not open browser automatically. Please copy this URL:")
+		fmt.Println(loginURL)
+		return fmt.Errorf("could not open browser: %w", err)
+	}
+	if created {
+		util.InfoColor.Fprintf(os.Stderr, "Creating %s container '%s' (%s, %s)\n", app, profile, style.Color, style.Icon)
+	}
+	return nil
+}
+
+// shareConsoleURL prints, copies or renders the sign-in URL as selected with
+// --print, --clipboard and --qr.
+func shareConsoleURL(loginURL string) error {
+	if dontOpenBrowser {
+		fmt.Println(loginURL)
+	}
+	if consoleQR {
+		code, width, err := browser.QRCode(loginURL)
+		if err != nil {
+			return err
+		}
+		if cols, _, err := term.GetSize(os.Stdout.Fd()); err == nil && cols < width {
+			util.WarnColor.Fprintf(os.Stderr, "The QR code is %d columns wide, widen the terminal to %d or zoom out to scan it\n", width, width)
+		}
+		fmt.Print(code)
+	}
+	if consoleClipboard {
+		viaTerminal, err := browser.CopyToClipboard(loginURL)
+		if err != nil {
+			return err
+		}
+		if viaTerminal {
+			util.InfoColor.Fprintln(os.Stderr, "Asked the terminal to copy the console URL to the clipboard")
+		} else {
+			util.SuccessColor.Fprintln(os.Stderr, "✔ Copied the console URL to the clipboard")
+		}
+	}
+	util.InfoColor.Fprintln(os.Stderr, "The URL is valid for 15 minutes, anyone who has it can sign in")
+	return nil
+}
+
 func init() {
-	consoleCmd.Flags().BoolVarP(&dontOpenBrowser, "no-open", "n", false, "Don't open the browser, just print the URL")
+	consoleCmd.Flags().BoolVar(&dontOpenBrowser, "print", false, "Print the sign-in URL instead of opening the browser")
+	consoleCmd.Flags().BoolVarP(&dontOpenBrowser, "no-open", "n", false, "Same as --print")
+	consoleCmd.Flags().BoolVar(&consoleClipboard, "clipboard", false, "Copy the sign-in URL to the clipboard instead of opening the browser")
+	consoleCmd.Flags().BoolVar(&consoleQR, "qr", false, "Render the sign-in URL as a QR code instead of opening the browser, to open the console on another device")
 	consoleCmd.Flags().BoolVarP(&useFirefox, "firefox-container", "f", false, "Open in Firefox using a container named after the AWS profile")
 	consoleCmd.Flags().BoolVarP(&useZen, "zen-container", "z", false, "Open in Zen Browser using a container named after the AWS profile")
 	consoleCmd.Flags().StringVarP(&chromeProfile, "chrome-profile", "c", "", "Specify a Chrome profile alias or directory name (e.g., 'work')")
 	consoleCmd.Flags().StringVarP(&profileName, "profile", "p", "", "Specify AWS profile to use (overrides current profile)")
-
-	// Add completion for the profile flag
-	consoleCmd.RegisterFlagCompletionFunc("profile", aws.CompleteProfiles)
+	consoleCmd.Flags().StringVarP(&consoleService, "service", "s", "", "Open a service page instead of the console home (e.g. s3, ec2, lambda/functions/my-fn)")
+	consoleCmd.RegisterFlagCompletionFunc("service", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return aws.ConsoleServices(), cobra.ShellCompDirectiveNoFileComp
+	})
+	consoleCmd.Flags().StringVar(&consoleExpectAccount, "expect-account", "", "Only open the console when the credentials belong to this account ID")
+	consoleCmd.Flags().StringVarP(&consoleRegion, "region", "r", "", "Region to open the console in (defaults to AWS_REGION or the profile's region)")
+	addRoleDurationFlag(consoleCmd)
 
 	rootCmd.AddCommand(consoleCmd)
 }
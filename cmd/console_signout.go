@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"awsm/internal/aws"
+	"awsm/internal/browser"
+	"awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	signoutBrowser   string
+	signoutContainer string
+	signoutAll       bool
+	signoutRegion    string
+	signoutNoOpen    bool
+)
+
+// signoutTarget is one browser context the sign-out page is opened in.
+type signoutTarget struct {
+	chromeProfile    string
+	firefoxContainer string
+	zenContainer     string
+}
+
+func (t signoutTarget) String() string {
+	switch {
+	case t.chromeProfile != "":
+		return "Chrome profile " + t.chromeProfile
+	case t.firefoxContainer != "":
+		return "Firefox container " + t.firefoxContainer
+	case t.zenContainer != "":
+		return "Zen container " + t.zenContainer
+	default:
+		return "default browser"
+	}
+}
+
+var consoleSignoutCmd = &cobra.Command{
+	Use:   "signout",
+	Short: "Sign out of the AWS console in one or more browser contexts",
+	Long: `Opens the AWS sign-out page so the console session of that browser context
+ends, without signing out of each console tab by hand.
+
+By default the default browser is signed out. With --browser chrome, firefox
+or zen the page opens in the Chrome profile or container given with
+--container; Firefox and Zen default to the container of the current profile,
+like 'awsm console --firefox-container'.
+
+With --all every context of the browser is signed out: each Chrome profile in
+[chrome_profiles] of the awsm config, or a Firefox/Zen container for each AWS
+profile.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if signoutRegion != "" && !aws.IsValidConsoleRegion(signoutRegion) {
+			return fmt.Errorf("invalid region: %s", signoutRegion)
+		}
+
+		var profiles []string
+		if signoutAll && (signoutBrowser == "firefox" || signoutBrowser == "zen") {
+			var err error
+			if profiles, err = aws.ListProfiles(); err != nil {
+				return fmt.Errorf("failed to list profiles: %w", err)
+			}
+		}
+		if !signoutAll && signoutContainer == "" && (signoutBrowser == "firefox" || signoutBrowser == "zen") {
+			signoutContainer = os.Getenv("AWS_PROFILE")
+			if signoutContainer == "" {
+				signoutContainer = aws.GetCurrentProfileName()
+			}
+		}
+
+		targets, err := signoutTargets(signoutBrowser, signoutContainer, signoutAll, profiles, config.GetChromeProfileAliases())
+		if err != nil {
+			return err
+		}
+
+		// Only the partition matters for the sign-out page
+		region := signoutRegion
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		signoutURL := aws.GetConsoleEndpoints(region).SignoutURL
+
+		if signoutNoOpen {
+			fmt.Println(signoutURL)
+			return nil
+		}
+
+		failed := 0
+		for _, target := range targets {
+			if err := browser.OpenURL(signoutURL, target.chromeProfile, target.firefoxContainer, target.zenContainer); err != nil {
+				util.ErrorColor.Fprintf(os.Stderr, "✗ %s: %v\n", target, err)
+				failed++
+				continue
+			}
+			util.SuccessColor.Fprintf(os.Stderr, "✔ Signed out of the console in %s\n", target)
+		}
+		if failed > 0 {
+			return fmt.Errorf("could not open the sign-out page in %d of %d browser context(s)", failed, len(targets))
+		}
+		return nil
+	},
+}
+
+// signoutTargets returns the browser contexts to sign out of. profiles are the
+// AWS profile names used as Firefox/Zen containers with --all, chromeAliases the
+// configured Chrome profiles.
+func signoutTargets(browserName, container string, all bool, profiles, chromeAliases []string) ([]signoutTarget, error) {
+	if all && container != "" {
+		return nil, fmt.Errorf("--all and --container cannot be used together")
+	}
+
+	var names []string
+	switch {
+	case !all:
+		names = []string{container}
+	case browserName == "chrome":
+		names = chromeAliases
+	case browserName == "firefox" || browserName == "zen":
+		names = profiles
+	}
+
+	var targets []signoutTarget
+	switch browserName {
+	case "", "default":
+		if all || container != "" {
+			return nil, fmt.Errorf("--all and --container need --browser chrome, firefox or zen")
+		}
+		return []signoutTarget{{}}, nil
+	case "chrome":
+		if len(names) == 1 && names[0] == "" {
+			return nil, fmt.Errorf("--browser chrome needs --container <chrome-profile> or --all")
+		}
+		for _, name := range names {
+			targets = append(targets, signoutTarget{chromeProfile: name})
+		}
+	case "firefox":
+		for _, name := range names {
+			if name != "" {
+				targets = append(targets, signoutTarget{firefoxContainer: name})
+			}
+		}
+	case "zen":
+		for _, name := range names {
+			if name != "" {
+				targets = append(targets, signoutTarget{zenContainer: name})
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown browser '%s' (use default, chrome, firefox or zen)", browserName)
+	}
+
+	if len(targets) == 0 {
+		if all && browserName == "chrome" {
+			return nil, fmt.Errorf("no Chrome profiles configured in [chrome_profiles]")
+		}
+		if all {
+			return nil, fmt.Errorf("no AWS profiles found to sign out of")
+		}
+		return nil, fmt.Errorf("no container given and no current profile set, use --container")
+	}
+	return targets, nil
+}
+
+func init() {
+	consoleSignoutCmd.Flags().StringVarP(&signoutBrowser, "browser", "b", "", "Browser to sign out in: default, chrome, firefox or zen")
+	consoleSignoutCmd.Flags().StringVar(&signoutContainer, "container", "", "Chrome profile or Firefox/Zen container to sign out of")
+	consoleSignoutCmd.Flags().BoolVarP(&signoutAll, "all", "a", false, "Sign out of every Chrome profile or Firefox/Zen container")
+	consoleSignoutCmd.Flags().StringVarP(&signoutRegion, "region", "r", "", "Region whose partition to sign out of (defaults to AWS_REGION or commercial)")
+	consoleSignoutCmd.Flags().BoolVarP(&signoutNoOpen, "no-open", "n", false, "Don't open the browser, just print the sign-out URL")
+
+	consoleSignoutCmd.RegisterFlagCompletionFunc("browser", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"default", "chrome", "firefox", "zen"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	consoleCmd.AddCommand(consoleSignoutCmd)
+}
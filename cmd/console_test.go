@@ -61,3 +61,45 @@ func TestConsoleCommand(t *testing.T) {
 		t.Error("Command should have a chrome-profile flag")
 	}
 }
+
+func TestSignoutTargets(t *testing.T) {
+	profiles := []string{"dev", "prod"}
+	chrome := []string{"personal", "work"}
+
+	tests := []struct {
+		name      string
+		browser   string
+		container string
+		all       bool
+		want      []string
+		wantErr   bool
+	}{
+		{"default browser", "", "", false, []string{"default browser"}, false},
+		{"firefox container", "firefox", "prod", false, []string{"Firefox container prod"}, false},
+		{"zen container", "zen", "dev", false, []string{"Zen container dev"}, false},
+		{"chrome profile", "chrome", "work", false, []string{"Chrome profile work"}, false},
+		{"all chrome", "chrome", "", true, []string{"Chrome profile personal", "Chrome profile work"}, false},
+		{"all firefox", "firefox", "", true, []string{"Firefox container dev", "Firefox container prod"}, false},
+		{"chrome without profile", "chrome", "", false, nil, true},
+		{"firefox without container", "firefox", "", false, nil, true},
+		{"all default browser", "", "", true, nil, true},
+		{"all and container", "firefox", "prod", true, nil, true},
+		{"unknown browser", "safari", "", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := signoutTargets(tt.browser, tt.container, tt.all, profiles, chrome)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("signoutTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, target := range targets {
+				got = append(got, target.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("signoutTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	contextDir             string
+	contextConfigFile      string
+	contextCredentialsFile string
+	contextEnvShell        string
+)
+
+var contextCmd = &cobra.Command{
+	Use:     "context",
+	Aliases: []string{"ctx"},
+	Short:   "Switch between sets of AWS config and credentials files",
+	Long: `A context is a named pair of AWS config and credentials files, e.g.
+~/.aws-work and ~/.aws-personal, so work and personal accounts never mix.
+
+'awsm context use <name>' makes every awsm command operate on that pair. Other
+tools (the AWS CLI, Terraform, SDKs) read AWS_CONFIG_FILE and
+AWS_SHARED_CREDENTIALS_FILE; export them into your shell with
+'awsm context env'. AWSM_CONTEXT selects a context for a single command.
+
+The "default" context is the regular ~/.aws files.`,
+}
+
+var contextAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add a context",
+	Long: `Adds a context using the config and credentials files in a directory, or
+the files given with --config-file and --credentials-file.
+
+Examples:
+  awsm context add work --dir ~/.aws-work
+  awsm context add personal --config-file ~/personal/config --credentials-file ~/personal/credentials`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := awsmConfig.ValidateContextName(name); err != nil {
+			return err
+		}
+		c, err := contextFromFlags(name)
+		if err != nil {
+			return err
+		}
+		for _, path := range []string{c.ConfigFile, c.CredentialsFile} {
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+			}
+		}
+		if err := awsmConfig.SaveContext(c); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Added context '%s'\n", name)
+		fmt.Printf("  config:      %s\n  credentials: %s\n", c.ConfigFile, c.CredentialsFile)
+		fmt.Printf("Switch to it with 'awsm context use %s'.\n", name)
+		return nil
+	},
+}
+
+// contextFromFlags builds a context from --dir or the two file flags.
+func contextFromFlags(name string) (awsmConfig.AWSContext, error) {
+	if contextDir != "" {
+		if contextConfigFile != "" || contextCredentialsFile != "" {
+			return awsmConfig.AWSContext{}, fmt.Errorf("use either --dir or --config-file and --credentials-file")
+		}
+		return awsmConfig.ContextFromDir(name, contextDir)
+	}
+	if contextConfigFile == "" || contextCredentialsFile == "" {
+		return awsmConfig.AWSContext{}, fmt.Errorf("--dir or both --config-file and --credentials-file are required")
+	}
+	configFile, err := filepath.Abs(contextConfigFile)
+	if err != nil {
+		return awsmConfig.AWSContext{}, err
+	}
+	credentialsFile, err := filepath.Abs(contextCredentialsFile)
+	if err != nil {
+		return awsmConfig.AWSContext{}, err
+	}
+	return awsmConfig.AWSContext{Name: name, ConfigFile: configFile, CredentialsFile: credentialsFile}, nil
+}
+
+var contextListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List contexts",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		active := awsmConfig.ActiveContextName()
+		type contextOutput struct {
+			Name            string `json:"name"`
+			ConfigFile      string `json:"config_file,omitempty"`
+			CredentialsFile string `json:"credentials_file,omitempty"`
+			Active          bool   `json:"active"`
+		}
+		contexts := []contextOutput{{Name: awsmConfig.DefaultContext, Active: active == awsmConfig.DefaultContext}}
+		for _, c := range awsmConfig.GetContexts() {
+			contexts = append(contexts, contextOutput{c.Name, c.ConfigFile, c.CredentialsFile, c.Name == active})
+		}
+		return output.Render(output.Selected, output.Result{
+			Data: contexts,
+			Table: func() error {
+				for _, c := range contexts {
+					marker := "  "
+					if c.Active {
+						marker = util.SuccessColor.Sprint("* ")
+					}
+					detail := "regular AWS files"
+					if c.ConfigFile != "" {
+						detail = fmt.Sprintf("%s, %s", c.ConfigFile, c.CredentialsFile)
+					}
+					fmt.Printf("%s%-16s %s\n", marker, c.Name, detail)
+				}
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, c := range contexts {
+					fmt.Fprintln(w, c.Name)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var contextCurrentCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the active context",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println(awsmConfig.ActiveContextName())
+		return nil
+	},
+}
+
+var contextUseCmd = &cobra.Command{
+	Use:               "use <name>",
+	Short:             "Make all awsm commands operate on a context",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeContexts),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := awsmConfig.UseContext(name); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Switched to context '%s'\n", name)
+		if env := os.Getenv(awsmConfig.ContextEnv); env != "" && env != name {
+			util.WarnColor.Printf("%s=%s still overrides it in this shell.\n", awsmConfig.ContextEnv, env)
+		}
+		fmt.Println("Run 'eval \"$(awsm context env)\"' to point other AWS tools in this shell at it.")
+		return nil
+	},
+}
+
+var contextRemoveCmd = &cobra.Command{
+	Use:               "remove <name>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove a context (its files are kept)",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeContexts),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if _, ok := awsmConfig.GetContext(name); !ok {
+			return fmt.Errorf("context '%s' not found", name)
+		}
+		if err := awsmConfig.RemoveContext(name); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Removed context '%s'\n", name)
+		return nil
+	},
+}
+
+var contextEnvCmd = &cobra.Command{
+	Use:   "env [name]",
+	Short: "Print shell commands that point AWS tools at a context",
+	Long: `Prints the statements exporting AWS_CONFIG_FILE and
+AWS_SHARED_CREDENTIALS_FILE for a context (the active one by default). For the
+default context they are unset.
+
+Examples:
+  eval "$(awsm context env)"
+  awsm context env work --shell fish | source`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeContexts),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := contextEnvShell
+		if shell == "" {
+			shell = detectShell()
+		}
+		shell = strings.ToLower(shell)
+		if !isSupportedShell(shell) {
+			return fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(supportedShells, ", "))
+		}
+
+		name := awsmConfig.ActiveContextName()
+		if len(args) > 0 {
+			name = args[0]
+		}
+		if name == awsmConfig.DefaultContext {
+			fmt.Print(formatEnv(shell, nil, []string{"AWS_CONFIG_FILE", "AWS_SHARED_CREDENTIALS_FILE"}))
+			return nil
+		}
+		c, ok := awsmConfig.GetContext(name)
+		if !ok {
+			return fmt.Errorf("context '%s' not found", name)
+		}
+		fmt.Print(formatEnv(shell, []envVar{
+			{"AWS_CONFIG_FILE", c.ConfigFile},
+			{"AWS_SHARED_CREDENTIALS_FILE", c.CredentialsFile},
+		}, nil))
+		return nil
+	},
+}
+
+// isContextCommand reports whether cmd is one of the context commands, which
+// still run when the active context is broken so it can be fixed.
+func isContextCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == contextCmd {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	contextAddCmd.Flags().StringVar(&contextDir, "dir", "", "Directory holding the config and credentials files")
+	contextAddCmd.Flags().StringVar(&contextConfigFile, "config-file", "", "AWS config file of the context")
+	contextAddCmd.Flags().StringVar(&contextCredentialsFile, "credentials-file", "", "AWS credentials file of the context")
+	contextEnvCmd.Flags().StringVarP(&contextEnvShell, "shell", "s", "", "Shell to format for: "+strings.Join(supportedShells, ", "))
+
+	contextCmd.AddCommand(contextAddCmd, contextListCmd, contextCurrentCmd, contextUseCmd, contextRemoveCmd, contextEnvCmd)
+	rootCmd.AddCommand(contextCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+
+	"github.com/spf13/cobra"
+)
+
+var credentialProcessRefreshWindow time.Duration
+
+var credentialProcessCmd = &cobra.Command{
+	Use:   "credential-process <profile>",
+	Short: "Print credentials for a profile in the credential_process format",
+	Long: `Resolves credentials for a profile and prints them as the JSON document the
+AWS CLI and SDKs expect from a credential_process, so other tools can get
+credentials through awsm:
+
+  [profile prod-via-awsm]
+  credential_process = awsm credential-process prod
+
+Prompts (MFA codes, SSO login) are written to stderr, stdout only carries the JSON.
+
+Temporary credentials are cached and handed out again while they stay valid
+for longer than --refresh-window, so tools that call the process often don't
+trigger an STS or SSO call each time. SDKs refresh credentials shortly before
+they expire; a window longer than theirs avoids handing out credentials they
+would immediately ask to refresh.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile := args[0]
+		// Keychain profiles point other tools at awsm on purpose; awsm reads their keys directly
+		if settings, err := aws.EffectiveProfileSettings(profile); err == nil && isSelfCredentialProcess(settings["credential_process"], profile) && !aws.IsKeychainProfile(profile) {
+			return fmt.Errorf("profile '%s' runs 'awsm credential-process %s' itself; point credential_process at another profile", profile, profile)
+		}
+
+		creds, err := getCachedCredentialsWithLogin(profile, credentialProcessRefreshWindow)
+		if err != nil {
+			return err
+		}
+		output, err := formatCredentialProcess(creds)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(output))
+		return nil
+	},
+}
+
+// getCachedCredentialsWithLogin hands out cached or agent credentials of a
+// profile that stay valid for refreshWindow, and otherwise fetches new ones,
+// logging in when needed, and caches them. It suits commands other tools run
+// often, like credential processes and credential plugins.
+func getCachedCredentialsWithLogin(profile string, refreshWindow time.Duration) (*aws.TempCredentials, error) {
+	if creds := aws.GetCachedCredentials(profile, refreshWindow); creds != nil {
+		return creds, nil
+	}
+	if creds, ok := credentialsFromAgent(profile, refreshWindow); ok {
+		return creds, nil
+	}
+	creds, err := getCredentialsWithLogin(profile)
+	if err != nil {
+		return nil, err
+	}
+	aws.CacheCredentials(profile, creds)
+	return creds, nil
+}
+
+// credentialProcessOutput is the document a credential_process prints, see
+// https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-sourcing-external.html
+type credentialProcessOutput struct {
+	Version         int    `json:"Version"`
+	AccessKeyId     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+	Expiration      string `json:"Expiration,omitempty"`
+}
+
+// formatCredentialProcess renders credentials as credential_process output.
+// Static credentials have no expiration, which tells the SDKs never to refresh them.
+func formatCredentialProcess(creds *aws.TempCredentials) ([]byte, error) {
+	out := credentialProcessOutput{
+		Version:         1,
+		AccessKeyId:     creds.AccessKeyId,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+	}
+	if !creds.Expires.IsZero() {
+		out.Expiration = creds.Expires.UTC().Format(time.RFC3339)
+	}
+	return json.Marshal(out)
+}
+
+// isSelfCredentialProcess reports whether a credential_process command asks
+// awsm for the credentials of the same profile, which would recurse forever.
+func isSelfCredentialProcess(command, profile string) bool {
+	fields := strings.Fields(command)
+	return slices.Contains(fields, "credential-process") && len(fields) > 0 && strings.Trim(fields[len(fields)-1], `"'`) == profile
+}
+
+func init() {
+	credentialProcessCmd.Flags().DurationVar(&credentialProcessRefreshWindow, "refresh-window", 15*time.Minute, "Fetch new credentials when cached ones expire within this window")
+	rootCmd.AddCommand(credentialProcessCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffLive bool
+	diffAll  bool
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <profile-a> <profile-b>",
+	Short: "Compare the effective configuration of two profiles",
+	Long: `Compares the settings two profiles resolve to: their keys from the config
+and credentials files (secrets excluded) plus the profile type, account, role
+and SSO session awsm derives from them, following source_profile chains.
+
+With --live both profiles are resolved to credentials and STS is asked who
+they are, so differences that only show at runtime become visible too.
+
+Examples:
+  awsm diff prod-admin prod-admin-alice
+  awsm diff staging prod --live`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeArgs(completeProfiles, completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		a, b := args[0], args[1]
+		settingsA, err := aws.EffectiveProfileSettings(a)
+		if err != nil {
+			return err
+		}
+		settingsB, err := aws.EffectiveProfileSettings(b)
+		if err != nil {
+			return err
+		}
+
+		util.InfoColor.Printf("Comparing %s (-) with %s (+)\n\n", util.BoldColor.Sprint(a), util.BoldColor.Sprint(b))
+		fmt.Println(util.BoldColor.Sprint("Configuration"))
+		printSettingsDiff(settingsA, settingsB)
+
+		if !diffLive {
+			return nil
+		}
+		identityA, err := liveIdentitySettings(a, settingsA["region"])
+		if err != nil {
+			return err
+		}
+		identityB, err := liveIdentitySettings(b, settingsB["region"])
+		if err != nil {
+			return err
+		}
+		fmt.Println()
+		fmt.Println(util.BoldColor.Sprint("Live identity"))
+		printSettingsDiff(identityA, identityB)
+		return nil
+	},
+}
+
+// printSettingsDiff prints the settings that differ, and the identical ones
+// too with --all.
+func printSettingsDiff(a, b map[string]string) {
+	diff := awsmConfig.DiffKeys(a, b)
+	for _, d := range diff {
+		switch {
+		case d.Old == "":
+			util.SuccessColor.Printf("  + %s = %s\n", d.Key, d.New)
+		case d.New == "":
+			util.ErrorColor.Printf("  - %s = %s\n", d.Key, d.Old)
+		default:
+			util.WarnColor.Printf("  ~ %s: %s → %s\n", d.Key, d.Old, d.New)
+		}
+	}
+
+	var same []string
+	for key, value := range a {
+		if v, ok := b[key]; ok && v == value {
+			same = append(same, key)
+		}
+	}
+	slices.Sort(same)
+	if diffAll {
+		for _, key := range same {
+			fmt.Printf("    %s = %s\n", key, a[key])
+		}
+	} else if len(same) > 0 {
+		fmt.Printf("    (%d identical setting(s), use --all to show them)\n", len(same))
+	}
+	if len(diff) == 0 && len(same) == 0 {
+		fmt.Println("    (nothing to compare)")
+	}
+}
+
+// liveIdentitySettings resolves a profile's credentials and returns the
+// identity STS reports for them.
+func liveIdentitySettings(profile, region string) (map[string]string, error) {
+	creds, err := getCredentialsWithLogin(profile)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := aws.GetIdentity(profile, creds, region)
+	if err != nil {
+		return nil, err
+	}
+	settings := map[string]string{
+		"account":   identity.Account,
+		"arn":       identity.Arn,
+		"principal": identity.Principal,
+	}
+	if identity.AccountAlias != "" {
+		settings["account_alias"] = identity.AccountAlias
+	}
+	return settings, nil
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffLive, "live", false, "Also compare the identities STS reports for both profiles")
+	diffCmd.Flags().BoolVar(&diffAll, "all", false, "Show identical settings too")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,279 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/checks"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON bool
+	doctorFix  bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [check...]",
+	Short: "Diagnose the awsm setup, including checks that call AWS",
+	Long: `Runs every environment check, the ones calling AWS included, and prints a
+pass/warn/fail report with a suggested fix for each problem:
+
+  - AWS CLI presence and version
+  - config and credentials file syntax
+  - file permissions
+  - expired SSO token caches
+  - the active region
+  - installed shell completion scripts
+  - clock skew against AWS
+  - reachability of the STS and SSO endpoints
+  - generated profiles whose account or role is no longer accessible
+
+The orphaned profile check only looks at SSO sessions that are logged in, it
+never starts a login.
+
+With --json the report also names the awsm version and platform, ready to be
+attached to a support ticket. With --fix, problems that can be fixed
+automatically are fixed without asking.
+
+The command exits with a non-zero status when a check fails.
+
+Examples:
+  awsm doctor
+  awsm doctor --json > doctor.json
+  awsm doctor endpoints clock-skew`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return checks.Names(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		selected, err := checks.Select(args, true)
+		if err != nil {
+			return err
+		}
+		format := output.Current(doctorJSON)
+		if !format.Structured() {
+			util.InfoColor.Fprintf(os.Stderr, "Running %d checks...\n", len(selected))
+		}
+		reports := checks.Run(context.Background(), selected)
+
+		if format.Structured() {
+			report := doctorOutput{
+				Version:  version,
+				Platform: runtime.GOOS + "/" + runtime.GOARCH,
+				Checks:   make([]checkOutput, len(reports)),
+				Summary:  summarizeReports(reports),
+			}
+			for i, r := range reports {
+				report.Checks[i] = checkReportOutput(r, doctorFix)
+			}
+			if err := output.Render(format, output.Result{Data: report}); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range reports {
+				printCheckReport(r)
+				if r.Result.Fix == nil || r.Result.Severity < checks.Warning {
+					continue
+				}
+				if !doctorFix {
+					fmt.Printf("  → Fix automatically with 'awsm doctor %s --fix': %s\n", r.Check.Name, r.Result.Fix.Description)
+				} else if err := r.Result.Fix.Apply(); err != nil {
+					util.ErrorColor.Printf("  Fix failed: %v\n", err)
+				} else {
+					util.SuccessColor.Printf("  ✔ Fixed: %s\n", r.Result.Fix.Description)
+				}
+			}
+			fmt.Println()
+			printDoctorSummary(summarizeReports(reports))
+		}
+
+		if checks.Worst(reports) == checks.Error {
+			cmd.SilenceErrors = format.Structured()
+			return fmt.Errorf("some checks failed")
+		}
+		return nil
+	},
+}
+
+// doctorOutput is what 'awsm doctor --json' prints.
+type doctorOutput struct {
+	Version  string        `json:"version"`
+	Platform string        `json:"platform"`
+	Checks   []checkOutput `json:"checks"`
+	Summary  doctorSummary `json:"summary"`
+}
+
+// doctorSummary counts the check results by severity.
+type doctorSummary struct {
+	Passed   int `json:"passed"`
+	Warnings int `json:"warnings"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
+}
+
+func summarizeReports(reports []checks.Report) doctorSummary {
+	var s doctorSummary
+	for _, r := range reports {
+		switch r.Result.Severity {
+		case checks.OK:
+			s.Passed++
+		case checks.Skipped:
+			s.Skipped++
+		case checks.Warning:
+			s.Warnings++
+		default:
+			s.Failed++
+		}
+	}
+	return s
+}
+
+func printDoctorSummary(s doctorSummary) {
+	util.SuccessColor.Printf("%d passed", s.Passed)
+	fmt.Print(", ")
+	if s.Warnings > 0 {
+		util.WarnColor.Printf("%d warnings", s.Warnings)
+	} else {
+		fmt.Print("0 warnings")
+	}
+	fmt.Print(", ")
+	if s.Failed > 0 {
+		util.ErrorColor.Printf("%d failed", s.Failed)
+	} else {
+		fmt.Print("0 failed")
+	}
+	if s.Skipped > 0 {
+		fmt.Printf(", %d skipped", s.Skipped)
+	}
+	fmt.Println()
+}
+
+// checkCompletionScripts compares the installed completion scripts with the
+// ones this version of awsm generates.
+func checkCompletionScripts(ctx context.Context) checks.Result {
+	scripts := installedCompletionScripts()
+	if len(scripts) == 0 {
+		return checks.Result{Severity: checks.Skipped, Message: "no completion scripts installed in the usual places"}
+	}
+
+	var stale, current []string
+	fresh := make(map[string]string)
+	for _, script := range scripts {
+		diff, generated, err := completionDrift(script)
+		if err != nil {
+			return checks.Result{Severity: checks.Warning, Message: err.Error()}
+		}
+		if diff == "" {
+			current = append(current, script.Shell)
+			continue
+		}
+		stale = append(stale, fmt.Sprintf("%s (%s)", script.Path, script.Shell))
+		fresh[script.Path] = generated
+	}
+	if len(stale) == 0 {
+		return checks.Result{Severity: checks.OK, Message: "up to date for " + strings.Join(current, ", ")}
+	}
+	return checks.Result{
+		Severity: checks.Warning,
+		Message:  "out of date after an upgrade:\n" + strings.Join(stale, "\n"),
+		Hint:     "Run 'awsm completion --diff --update'.",
+		Fix: &checks.Fix{
+			Description: fmt.Sprintf("rewrite %d completion scripts", len(stale)),
+			Apply: func() error {
+				for path, script := range fresh {
+					if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+						return fmt.Errorf("failed to update %s: %w", path, err)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// checkOrphanedProfiles looks for generated profiles whose account or role
+// the SSO session no longer grants, like 'awsm sso prune' does. Sessions that
+// need a login are left out rather than logged in.
+func checkOrphanedProfiles(ctx context.Context) checks.Result {
+	sessions, err := aws.ListSSOSessions()
+	if err != nil {
+		return checks.Result{Severity: checks.Warning, Message: err.Error()}
+	}
+	configPath, err := aws.GeneratedProfilesPath()
+	if err != nil {
+		return checks.Result{Severity: checks.Warning, Message: err.Error()}
+	}
+	existingConfig, err := awsmConfig.ReadConfigFile(configPath)
+	if err != nil {
+		return checks.Result{Severity: checks.Warning, Message: fmt.Sprintf("failed to read %s: %v", configPath, err)}
+	}
+	namer, err := ssoProfileNamer()
+	if err != nil {
+		return checks.Result{Severity: checks.Warning, Message: err.Error()}
+	}
+
+	var checked, unchecked, orphans, hints []string
+	for _, session := range sessions {
+		// Without desired profiles every generated profile is an orphan, so an
+		// empty plan means the session has none to check
+		if len(awsmConfig.FindOrphanedProfiles(existingConfig, nil, session.Name).Changes) == 0 {
+			continue
+		}
+		accessToken, err := aws.CachedSSOAccessToken(session.Name)
+		if err != nil {
+			unchecked = append(unchecked, session.Name)
+			continue
+		}
+		discovery, err := listSSOProfiles(session.Name, session.Region, accessToken, namer, false)
+		if err != nil {
+			unchecked = append(unchecked, session.Name)
+			continue
+		}
+		checked = append(checked, session.Name)
+		plan := awsmConfig.FindOrphanedProfiles(existingConfig, discovery.Profiles, session.Name)
+		plan.KeepAccounts(discovery.FailedAccounts...)
+		for _, c := range plan.Changes {
+			orphans = append(orphans, c.Profile)
+		}
+		if len(plan.Changes) > 0 {
+			hints = append(hints, fmt.Sprintf("Run 'awsm sso prune %s'.", session.Name))
+		}
+	}
+
+	if len(orphans) > 0 {
+		return checks.Result{
+			Severity: checks.Warning,
+			Message:  fmt.Sprintf("%d generated profiles are no longer accessible: %s", len(orphans), strings.Join(orphans, ", ")),
+			Hint:     strings.Join(hints, "\n"),
+		}
+	}
+	if len(checked) == 0 {
+		if len(unchecked) > 0 {
+			return checks.Result{Severity: checks.Skipped, Message: "not checked, SSO sessions need a login: " + strings.Join(unchecked, ", ")}
+		}
+		return checks.Result{Severity: checks.Skipped, Message: "no generated SSO profiles"}
+	}
+	message := "all generated profiles of " + strings.Join(checked, ", ") + " are accessible"
+	if len(unchecked) > 0 {
+		message += " (not checked, need a login: " + strings.Join(unchecked, ", ") + ")"
+	}
+	return checks.Result{Severity: checks.OK, Message: message}
+}
+
+func init() {
+	// These checks need the completion generator and the SSO discovery of this package
+	checks.Register(checks.Check{Name: "completion-scripts", Title: "Shell completion", Run: checkCompletionScripts})
+	checks.Register(checks.Check{Name: "orphaned-profiles", Title: "Generated profiles", Network: true, Run: checkOrphanedProfiles})
+
+	doctorCmd.Flags().BoolVarP(&doctorJSON, "json", "j", false, "Print the report as JSON, e.g. for a support ticket (same as --output json)")
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply automatic fixes without asking")
+	rootCmd.AddCommand(doctorCmd)
+}
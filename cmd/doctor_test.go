@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"awsm/internal/checks"
+)
+
+func TestSummarizeReports(t *testing.T) {
+	var reports []checks.Report
+	for _, severity := range []checks.Severity{checks.OK, checks.OK, checks.Warning, checks.Error, checks.Skipped} {
+		reports = append(reports, checks.Report{Result: checks.Result{Severity: severity}})
+	}
+	want := doctorSummary{Passed: 2, Warnings: 1, Failed: 1, Skipped: 1}
+	if got := summarizeReports(reports); got != want {
+		t.Errorf("summarizeReports() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckCompletionScripts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	if result := checkCompletionScripts(context.Background()); result.Severity != checks.Skipped {
+		t.Errorf("without scripts the check should be skipped, got %v: %s", result.Severity, result.Message)
+	}
+
+	path := filepath.Join(home, ".config", "fish", "completions", "awsm.fish")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("# written by an older awsm\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := checkCompletionScripts(context.Background())
+	if result.Severity != checks.Warning || result.Fix == nil {
+		t.Fatalf("a stale script should be a fixable warning, got %v: %s", result.Severity, result.Message)
+	}
+	if err := result.Fix.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if result := checkCompletionScripts(context.Background()); result.Severity != checks.OK {
+		t.Errorf("after the fix the script should be current, got %v: %s", result.Severity, result.Message)
+	}
+}
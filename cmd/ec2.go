@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var ec2Cmd = &cobra.Command{
+	Use:   "ec2",
+	Short: "Work with EC2 instances of the active profile",
+}
+
+var ec2SSMCmd = &cobra.Command{
+	Use:   "ssm [instance-id-or-name]",
+	Short: "Start a Session Manager session on an EC2 instance",
+	Long: `Starts an SSM Session Manager session on a running EC2 instance of the
+active profile and region, the same as 'awsm connect'.
+
+The instance can be given by ID or by its Name tag, which is fuzzy matched.
+Without an argument, or when several instances match, the running instances
+are listed to pick from.
+
+	Examples:
+  awsm ec2 ssm                                # Pick from running instances
+  awsm ec2 ssm web-server                     # Instance by Name tag
+  awsm ec2 ssm i-0123456789abcdef0 -p -r 5432 -H database.internal`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConnect,
+}
+
+func init() {
+	addConnectFlags(ec2SSMCmd)
+	ec2Cmd.AddCommand(ec2SSMCmd)
+	rootCmd.AddCommand(ec2Cmd)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	ecrProfile        string
+	ecrRegion         string
+	ecrRegistryIDs    []string
+	ecrPublic         bool
+	ecrPasswordStdout bool
+)
+
+var ecrCmd = &cobra.Command{
+	Use:   "ecr",
+	Short: "Work with Amazon ECR registries",
+}
+
+var ecrLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log docker in to ECR with the profile's credentials",
+	Long: `Gets an ECR authorization token with the credentials of the profile and logs
+docker in to the registry of the profile's account in its region, like
+'aws ecr get-login-password | docker login' with the right profile set.
+
+--registry-ids logs in to the registries of other accounts the profile can
+pull from, --public to ECR Public (public.ecr.aws). With --password-stdout the
+password is printed instead, for other clients such as podman or helm; the
+registries it is valid for are listed on stderr.
+
+Tokens are valid for 12 hours.
+
+Examples:
+  awsm ecr login
+  awsm ecr login --profile prod --region eu-west-1
+  awsm ecr login --registry-ids 111111111111,222222222222
+  awsm ecr login --public
+  awsm ecr login --password-stdout | podman login --username AWS --password-stdin 123456789012.dkr.ecr.eu-west-1.amazonaws.com`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if ecrPublic && len(ecrRegistryIDs) > 0 {
+			return fmt.Errorf("--public and --registry-ids can't be combined")
+		}
+		for _, id := range ecrRegistryIDs {
+			if err := validateAccountID(id); err != nil {
+				return err
+			}
+		}
+		region := ecrRegion
+		if ecrPublic && region == "" {
+			// ECR Public tokens come from us-east-1 whatever the profile's region
+			region = "us-east-1"
+		}
+		profile, region, err := resolveProfileAndRegion(ecrProfile, region)
+		if err != nil {
+			return err
+		}
+		creds, err := getCredentialsWithLogin(profile)
+		if err != nil {
+			return err
+		}
+
+		var logins []aws.ECRLogin
+		if ecrPublic {
+			login, err := aws.GetECRPublicLogin(creds)
+			if err != nil {
+				return aws.WrapProfileError("get ECR Public token", profile, err)
+			}
+			logins = append(logins, *login)
+		} else {
+			if logins, err = aws.GetECRLogins(creds, region, ecrRegistryIDs); err != nil {
+				return aws.WrapProfileError("get ECR token", profile, err)
+			}
+		}
+
+		if ecrPasswordStdout {
+			// One token is valid for every registry the identity can reach
+			for _, login := range logins {
+				util.InfoColor.Fprintf(os.Stderr, "Password for %s (user %s), valid until %s\n", login.Registry, login.Username, login.ExpiresAt.Local().Format("15:04 MST"))
+			}
+			fmt.Fprintln(os.Stdout, logins[0].Password)
+			return nil
+		}
+
+		docker, err := exec.LookPath("docker")
+		if err != nil {
+			return fmt.Errorf("docker not found in PATH; use --password-stdout to log another client in")
+		}
+		for _, login := range logins {
+			if err := dockerLogin(docker, login); err != nil {
+				return err
+			}
+			util.SuccessColor.Printf("✔ Logged in to %s as profile '%s'\n", login.Registry, profile)
+		}
+		return nil
+	},
+}
+
+// dockerLogin runs 'docker login', handing the password over stdin so it
+// doesn't show up in the process list.
+func dockerLogin(docker string, login aws.ECRLogin) error {
+	child := exec.Command(docker, "login", "--username", login.Username, "--password-stdin", login.Registry)
+	child.Stdin = strings.NewReader(login.Password)
+	output, err := child.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker login to %s failed: %w\n%s", login.Registry, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func init() {
+	ecrCmd.PersistentFlags().StringVarP(&ecrProfile, "profile", "p", "", "Profile to use (default: AWS_PROFILE or the active profile)")
+	ecrCmd.PersistentFlags().StringVarP(&ecrRegion, "region", "r", "", "Region of the registry (default: AWS_REGION or the profile's region)")
+	ecrLoginCmd.Flags().StringSliceVar(&ecrRegistryIDs, "registry-ids", nil, "Account IDs of the registries to log in to (default: the profile's account)")
+	ecrLoginCmd.Flags().BoolVar(&ecrPublic, "public", false, "Log in to ECR Public (public.ecr.aws)")
+	ecrLoginCmd.Flags().BoolVar(&ecrPasswordStdout, "password-stdout", false, "Print the password instead of running docker login")
+	ecrCmd.AddCommand(ecrLoginCmd)
+	rootCmd.AddCommand(ecrCmd)
+}
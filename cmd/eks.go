@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/tui"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	eksProfile    string
+	eksRegion     string
+	eksAlias      string
+	eksKubeconfig string
+	eksCluster    string
+)
+
+var eksCmd = &cobra.Command{
+	Use:   "eks",
+	Short: "Connect kubectl to Amazon EKS clusters",
+}
+
+var eksKubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig [cluster]",
+	Short: "Add an EKS cluster to the kubeconfig, authenticated through awsm",
+	Long: `Adds a cluster, user and context for an EKS cluster to the kubeconfig and
+makes the context the current one. Without a cluster name the clusters of the
+region are listed to pick from.
+
+The user runs 'awsm eks token' as an exec credential plugin, so kubectl gets
+its tokens from the credentials awsm manages for the profile: the entry keeps
+working as awsm refreshes them, and SSO logins and MFA prompts happen when
+kubectl needs them.
+
+The kubeconfig is the first file in KUBECONFIG, or ~/.kube/config.
+
+Examples:
+  awsm eks kubeconfig
+  awsm eks kubeconfig prod-cluster --profile prod --region eu-west-1
+  awsm eks kubeconfig prod-cluster --alias prod`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, region, err := resolveProfileAndRegion(eksProfile, eksRegion)
+		if err != nil {
+			return err
+		}
+		creds, err := getCredentialsWithLogin(profile)
+		if err != nil {
+			return err
+		}
+
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		} else if name, err = selectEKSCluster(creds, profile, region); err != nil {
+			return err
+		}
+
+		cluster, err := aws.DescribeEKSCluster(creds, region, name)
+		if err != nil {
+			return aws.WrapProfileError("describe EKS cluster", profile, err)
+		}
+		if cluster.Status != "" && cluster.Status != "ACTIVE" {
+			util.WarnColor.Printf("⚠ Cluster '%s' is %s\n", cluster.Name, cluster.Status)
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate the awsm executable: %w", err)
+		}
+		path := eksKubeconfig
+		if path == "" {
+			if path, err = aws.KubeconfigPath(); err != nil {
+				return err
+			}
+		}
+		entry := aws.KubeconfigEntry{
+			ClusterArn:           cluster.Arn,
+			Alias:                eksAlias,
+			Server:               cluster.Endpoint,
+			CertificateAuthority: cluster.CertificateAuthority,
+			Command:              executable,
+			Args:                 []string{"eks", "token", "--cluster", cluster.Name, "--profile", profile, "--region", region},
+		}
+		if err := aws.UpdateKubeconfig(path, entry, true); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Added context '%s' for cluster '%s' to %s\n", entry.ContextName(), cluster.Name, path)
+		util.InfoColor.Printf("kubectl now authenticates as profile '%s'\n", profile)
+		return nil
+	},
+}
+
+var eksTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print an EKS token as an exec credential for kubectl",
+	Long: `Prints a token for an EKS cluster as the ExecCredential JSON document kubectl
+expects from an exec credential plugin. 'awsm eks kubeconfig' sets kubeconfig
+users up to run it; it is rarely run by hand.
+
+Credentials are cached like those of 'awsm credential-process'. Prompts are
+written to stderr, stdout only carries the JSON.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, region, err := resolveProfileAndRegion(eksProfile, eksRegion)
+		if err != nil {
+			return err
+		}
+		creds, err := getCachedCredentialsWithLogin(profile, eksTokenRefreshWindow)
+		if err != nil {
+			return err
+		}
+		token, err := aws.GetEKSToken(creds, region, eksCluster)
+		if err != nil {
+			return aws.WrapProfileError("create EKS token", profile, err)
+		}
+		output, err := formatExecCredential(token)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(output))
+		return nil
+	},
+}
+
+// eksTokenRefreshWindow is how long the credentials behind an EKS token must
+// stay valid, so the token doesn't outlive them.
+const eksTokenRefreshWindow = 15 * time.Minute
+
+// execCredential is the document an exec credential plugin prints, see
+// https://kubernetes.io/docs/reference/access-authn-authz/authentication/#client-go-credential-plugins
+type execCredential struct {
+	Kind       string               `json:"kind"`
+	APIVersion string               `json:"apiVersion"`
+	Spec       struct{}             `json:"spec"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp string `json:"expirationTimestamp"`
+	Token               string `json:"token"`
+}
+
+func formatExecCredential(token *aws.EKSToken) ([]byte, error) {
+	return json.Marshal(execCredential{
+		Kind:       "ExecCredential",
+		APIVersion: aws.ExecCredentialAPIVersion,
+		Status: execCredentialStatus{
+			ExpirationTimestamp: token.Expiration.UTC().Format(time.RFC3339),
+			Token:               token.Token,
+		},
+	})
+}
+
+// resolveProfileAndRegion fills in the profile and region flags of commands
+// that call AWS: AWS_PROFILE and AWS_REGION, then the active profile and its region.
+func resolveProfileAndRegion(profile, region string) (string, string, error) {
+	if profile == "" {
+		profile = os.Getenv("AWS_PROFILE")
+	}
+	if profile == "" {
+		profile = aws.GetCurrentProfileName()
+	}
+	if profile == "" {
+		return "", "", fmt.Errorf("no AWS profile set. Use --profile or run 'awsm profile set <profile-name>' first")
+	}
+
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region, _ = aws.GetProfileRegion(profile)
+	}
+	if region == "" {
+		return "", "", fmt.Errorf("no region for profile '%s'. Use --region or set one with 'awsm profile change-default-region'", profile)
+	}
+	return profile, region, nil
+}
+
+// selectEKSCluster lists the clusters of a region and lets the user pick one.
+// A single cluster is taken without asking.
+func selectEKSCluster(creds *aws.TempCredentials, profile, region string) (string, error) {
+	clusters, err := aws.ListEKSClusters(creds, region)
+	if err != nil {
+		return "", aws.WrapProfileError("list EKS clusters", profile, err)
+	}
+	switch len(clusters) {
+	case 0:
+		return "", fmt.Errorf("no EKS clusters found in %s for profile '%s'", region, profile)
+	case 1:
+		util.InfoColor.Printf("Using cluster '%s', the only one in %s\n", clusters[0], region)
+		return clusters[0], nil
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("%d clusters found in %s; name one: awsm eks kubeconfig <cluster>", len(clusters), region)
+	}
+	return tui.SelectCluster(clusters, region)
+}
+
+func init() {
+	eksCmd.PersistentFlags().StringVarP(&eksProfile, "profile", "p", "", "Profile to use (default: AWS_PROFILE or the active profile)")
+	eksCmd.PersistentFlags().StringVarP(&eksRegion, "region", "r", "", "Region of the cluster (default: AWS_REGION or the profile's region)")
+	eksKubeconfigCmd.Flags().StringVar(&eksAlias, "alias", "", "Name of the kubeconfig context (default: the cluster ARN)")
+	eksKubeconfigCmd.Flags().StringVar(&eksKubeconfig, "kubeconfig", "", "Kubeconfig file to update (default: first file in KUBECONFIG or ~/.kube/config)")
+	eksTokenCmd.Flags().StringVar(&eksCluster, "cluster", "", "Name of the EKS cluster")
+	eksTokenCmd.MarkFlagRequired("cluster")
+	eksCmd.AddCommand(eksKubeconfigCmd)
+	eksCmd.AddCommand(eksTokenCmd)
+	rootCmd.AddCommand(eksCmd)
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	envShell string
+	envUnset bool
+)
+
+// supportedShells lists the shells `awsm env` knows how to format output for.
+var supportedShells = []string{"bash", "zsh", "sh", "fish", "powershell"}
+
+var envCmd = &cobra.Command{
+	Use:   "env [profile]",
+	Short: "Print shell commands that export credentials for a profile",
+	Long: `Resolves credentials for a profile and prints the statements needed to export
+them into the current shell, correctly quoted for the selected shell.
+
+If no profile is given, the active profile is used. Unless --shell is provided,
+the shell is detected from the process awsm was started from, then SHELL.
+
+For temporary credentials AWSM_EXPIRES_AT (Unix time of expiry) and
+AWSM_EXPIRES_IN (seconds left at export) are exported as well, so shell
+prompts can show the remaining session time without calling awsm.
+
+Examples:
+  eval "$(awsm env prod)"                        # bash / zsh
+  awsm env prod --shell fish | source             # fish
+  awsm env prod --shell powershell | Invoke-Expression
+  eval "$(awsm env --unset)"                     # remove exported credentials`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		shell := envShell
+		if shell == "" {
+			shell = detectShell()
+		}
+		shell = strings.ToLower(shell)
+		if !isSupportedShell(shell) {
+			return fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(supportedShells, ", "))
+		}
+
+		if envUnset {
+			fmt.Print(formatEnv(shell, nil, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_CREDENTIAL_EXPIRATION", "AWSM_EXPIRES_AT", "AWSM_EXPIRES_IN", "AWS_REGION", "AWS_DEFAULT_REGION"}))
+			return nil
+		}
+
+		var profile string
+		if len(args) > 0 {
+			profile = args[0]
+		} else {
+			profile = os.Getenv("AWS_PROFILE")
+			if profile == "" {
+				profile = aws.GetCurrentProfileName()
+			}
+			if profile == "" {
+				return fmt.Errorf("no AWS profile set. Please specify a profile or run 'awsm profile set <profile-name>' first")
+			}
+		}
+
+		creds, err := getCredentialsWithLogin(profile)
+		if err != nil {
+			return err
+		}
+
+		vars := []envVar{
+			{"AWS_ACCESS_KEY_ID", creds.AccessKeyId},
+			{"AWS_SECRET_ACCESS_KEY", creds.SecretAccessKey},
+		}
+		var unset []string
+		if creds.SessionToken != "" {
+			vars = append(vars, envVar{"AWS_SESSION_TOKEN", creds.SessionToken})
+		} else {
+			unset = append(unset, "AWS_SESSION_TOKEN")
+		}
+		if !creds.Expires.IsZero() {
+			vars = append(vars, envVar{"AWS_CREDENTIAL_EXPIRATION", creds.Expires.UTC().Format(time.RFC3339)})
+			vars = append(vars, expiryVars(creds.Expires, time.Now())...)
+		} else {
+			unset = append(unset, "AWS_CREDENTIAL_EXPIRATION", "AWSM_EXPIRES_AT", "AWSM_EXPIRES_IN")
+		}
+		if region, err := aws.GetProfileRegion(profile); err == nil && region != "" {
+			vars = append(vars, envVar{"AWS_REGION", region}, envVar{"AWS_DEFAULT_REGION", region})
+		}
+
+		fmt.Print(formatEnv(shell, vars, unset))
+		return nil
+	},
+}
+
+// envVar is a single environment variable assignment.
+type envVar struct {
+	Name  string
+	Value string
+}
+
+// expiryVars returns the variables prompts and scripts can read to show the
+// remaining session time without calling awsm or STS. AWSM_EXPIRES_IN is the
+// number of seconds left when the variables were exported; AWSM_EXPIRES_AT is
+// the Unix time of expiry, so the current remaining time can be computed as
+// $((AWSM_EXPIRES_AT - $(date +%s))).
+func expiryVars(expires, now time.Time) []envVar {
+	remaining := int64(expires.Sub(now).Seconds())
+	if remaining < 0 {
+		remaining = 0
+	}
+	return []envVar{
+		{"AWSM_EXPIRES_AT", strconv.FormatInt(expires.Unix(), 10)},
+		{"AWSM_EXPIRES_IN", strconv.FormatInt(remaining, 10)},
+	}
+}
+
+// getCredentialsWithLogin resolves credentials for a profile, prompting for MFA on stderr
+// when needed and running the SSO login flow once if the session has expired.
+func getCredentialsWithLogin(profile string) (*aws.TempCredentials, error) {
+	var mfaToken string
+	if needsMFA, mfaSerial, mfaErr := aws.ProfileNeedsMFA(profile); mfaErr == nil && needsMFA && !aws.HasValidCachedCredentials(profile) {
+		prompt := fmt.Sprintf("Enter MFA token for %s: ", util.BoldColor.Sprint(mfaSerial))
+		token, err := util.PromptForInputStderr(prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read MFA token: %w", err)
+		}
+		mfaToken = token
+	}
+
+	creds, _, err := aws.GetCredentialsForProfile(profile, mfaToken)
+	if err != nil {
+		if !errors.Is(err, aws.ErrSsoSessionExpired) {
+			return nil, aws.WrapProfileError("retrieve credentials", profile, err)
+		}
+		ssoSession, ssoErr := aws.GetSsoSessionForProfile(profile)
+		if ssoErr != nil {
+			return nil, fmt.Errorf("failed to get SSO session for profile '%s': %w", profile, ssoErr)
+		}
+		if loginErr := aws.PerformSSOLogin(ssoSession); loginErr != nil {
+			return nil, loginErr
+		}
+		creds, _, err = aws.GetCredentialsForProfile(profile)
+		if err != nil {
+			return nil, aws.WrapProfileError("retrieve credentials after login", profile, err)
+		}
+	}
+	if creds == nil || creds.AccessKeyId == "" {
+		return nil, fmt.Errorf("no credentials available for profile '%s'", profile)
+	}
+	return creds, nil
+}
+
+// formatEnv renders export and unset statements for the given shell.
+func formatEnv(shell string, vars []envVar, unset []string) string {
+	var b strings.Builder
+	for _, v := range vars {
+		switch shell {
+		case "fish":
+			fmt.Fprintf(&b, "set -gx %s %s;\n", v.Name, quoteFish(v.Value))
+		case "powershell":
+			fmt.Fprintf(&b, "$env:%s = %s\n", v.Name, quotePowerShell(v.Value))
+		default:
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, quotePosix(v.Value))
+		}
+	}
+	for _, name := range unset {
+		switch shell {
+		case "fish":
+			fmt.Fprintf(&b, "set -e %s;\n", name)
+		case "powershell":
+			fmt.Fprintf(&b, "Remove-Item Env:%s -ErrorAction SilentlyContinue\n", name)
+		default:
+			fmt.Fprintf(&b, "unset %s\n", name)
+		}
+	}
+	return b.String()
+}
+
+// quotePosix single-quotes a value for sh-compatible shells.
+func quotePosix(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes a value for fish, where only \ and ' need escaping.
+func quoteFish(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return "'" + strings.ReplaceAll(value, "'", `\'`) + "'"
+}
+
+// quotePowerShell single-quotes a value for PowerShell, which escapes ' by doubling it.
+func quotePowerShell(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// detectShell guesses the user's shell. The process awsm was started from is
+// the most reliable hint, since SHELL only names the login shell and stays
+// set when another shell is started from it.
+func detectShell() string {
+	return pickShell(detectShellFromParentProcess(), os.Getenv("SHELL"), runtime.GOOS)
+}
+
+// pickShell chooses between the parent process name and SHELL, falling back
+// to the platform default when neither names a supported shell.
+func pickShell(parent, shellEnv, goos string) string {
+	if shell := normalizeShellName(parent); isSupportedShell(shell) {
+		return shell
+	}
+	if shellEnv != "" {
+		return normalizeShellName(shellEnv)
+	}
+	if goos == "windows" {
+		return "powershell"
+	}
+	return "bash"
+}
+
+// normalizeShellName maps a process name or path to the name used by --shell.
+// Login shells show up with a leading dash (-zsh) in process listings.
+func normalizeShellName(name string) string {
+	name = strings.TrimPrefix(filepath.Base(strings.TrimSpace(name)), "-")
+	name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	switch name {
+	case "pwsh", "powershell":
+		return "powershell"
+	case "dash", "ash":
+		return "sh"
+	}
+	return name
+}
+
+func isSupportedShell(shell string) bool {
+	for _, s := range supportedShells {
+		if s == shell {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	envCmd.Flags().StringVar(&envShell, "shell", "", "Shell to format output for (bash, zsh, sh, fish, powershell)")
+	envCmd.Flags().BoolVar(&envUnset, "unset", false, "Print statements that remove previously exported credentials")
+	addRoleDurationFlag(envCmd)
+	rootCmd.AddCommand(envCmd)
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatEnv(t *testing.T) {
+	vars := []envVar{{"AWS_ACCESS_KEY_ID", "AKIA'TEST"}}
+	unset := []string{"AWS_SESSION_TOKEN"}
+
+	tests := []struct {
+		shell    string
+		expected string
+	}{
+		{"bash", "export AWS_ACCESS_KEY_ID='AKIA'\\''TEST'\nunset AWS_SESSION_TOKEN\n"},
+		{"zsh", "export AWS_ACCESS_KEY_ID='AKIA'\\''TEST'\nunset AWS_SESSION_TOKEN\n"},
+		{"fish", "set -gx AWS_ACCESS_KEY_ID 'AKIA\\'TEST';\nset -e AWS_SESSION_TOKEN;\n"},
+		{"powershell", "$env:AWS_ACCESS_KEY_ID = 'AKIA''TEST'\nRemove-Item Env:AWS_SESSION_TOKEN -ErrorAction SilentlyContinue\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			result := formatEnv(tt.shell, vars, unset)
+			if result != tt.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestQuoteFishBackslash(t *testing.T) {
+	if got := quoteFish(`a\b`); got != `'a\\b'` {
+		t.Errorf("Expected 'a\\\\b', got %s", got)
+	}
+}
+
+func TestExpiryVars(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	vars := expiryVars(now.Add(90*time.Minute), now)
+	if len(vars) != 2 || vars[0] != (envVar{"AWSM_EXPIRES_AT", "1700005400"}) || vars[1] != (envVar{"AWSM_EXPIRES_IN", "5400"}) {
+		t.Errorf("Unexpected vars: %v", vars)
+	}
+
+	vars = expiryVars(now.Add(-time.Minute), now)
+	if vars[1].Value != "0" {
+		t.Errorf("Expected AWSM_EXPIRES_IN 0 for expired credentials, got %s", vars[1].Value)
+	}
+}
+
+func TestPickShell(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   string
+		shellEnv string
+		goos     string
+		expected string
+	}{
+		{"Parent wins over SHELL", "fish", "/bin/bash", "linux", "fish"},
+		{"Login shell", "-zsh", "", "darwin", "zsh"},
+		{"Dash as sh", "dash", "/bin/bash", "linux", "sh"},
+		{"Windows pwsh", "pwsh.exe", "", "windows", "powershell"},
+		{"Non-shell parent falls back to SHELL", "make", "/usr/bin/zsh", "linux", "zsh"},
+		{"Unknown parent on Windows", "explorer.exe", "", "windows", "powershell"},
+		{"Nothing known", "", "", "linux", "bash"},
+	}
+	for _, tt := range tests {
+		if got := pickShell(tt.parent, tt.shellEnv, tt.goos); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
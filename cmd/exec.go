@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"time"
+
+	"awsm/internal/agent"
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var execExpectAccount string
+
+var execCmd = &cobra.Command{
+	Use:   "exec <profile> -- <command> [args...]",
+	Short: "Run a command with credentials for a profile",
+	Long: `Resolves credentials for a profile and runs a command with them in its
+environment (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and
+AWS_REGION). The default profile in ~/.aws/credentials is left untouched, so
+commands for different profiles can run side by side.
+
+awsm exits with the exit code of the command.
+
+Use '-' as the profile to run with the previously used one, like 'cd -'. The
+profile is recorded as recently used, as 'awsm profile set' does.
+
+With --expect-account the credentials are checked with an STS call first and
+the command only runs when they belong to that account, a cheap safeguard
+against mis-wired source_profile chains.
+
+Examples:
+  awsm exec prod -- aws s3 ls
+  awsm exec staging -- terraform plan
+  awsm exec prod-admin --expect-account 123456789012 -- terraform apply
+  awsm exec - -- aws sts get-caller-identity`,
+	Args: cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeProfiles(cmd, args, toComplete)
+		}
+		// The command to run is up to the shell
+		return nil, cobra.ShellCompDirectiveDefault
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateAccountID(execExpectAccount); err != nil {
+			return err
+		}
+		profile, command := args[0], args[1:]
+		if profile == "-" {
+			previous, err := previousProfile()
+			if err != nil {
+				return err
+			}
+			util.InfoColor.Fprintf(os.Stderr, "Using the previous profile '%s'\n", previous)
+			profile = previous
+		}
+		// Parsing stops at the profile, so the -- separator arrives as an argument
+		if command[0] == "--" {
+			command = command[1:]
+		}
+		if len(command) == 0 {
+			return fmt.Errorf("no command given after --")
+		}
+		if err := preflight("config-files"); err != nil {
+			return err
+		}
+
+		// The agent hands out the sessions it holds, whatever their length
+		var creds *aws.TempCredentials
+		ok := false
+		if aws.RoleDuration == 0 {
+			creds, ok = credentialsFromAgent(profile, agent.MinValidity)
+		}
+		if !ok {
+			var err error
+			if creds, err = getCredentialsWithLogin(profile); err != nil {
+				return err
+			}
+		}
+		region, _ := aws.GetProfileRegion(profile)
+		if err := checkExpectedAccount(profile, creds, region, execExpectAccount); err != nil {
+			return err
+		}
+		recordProfileUse(profile)
+
+		child := exec.Command(command[0], command[1:]...)
+		child.Env = execEnv(os.Environ(), creds, region, time.Now())
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stdout
+		child.Stderr = os.Stderr
+
+		// The terminal sends Ctrl+C to the command as well; awsm waits for it
+		// to exit instead of dying first.
+		signal.Ignore(os.Interrupt)
+		defer signal.Reset(os.Interrupt)
+
+		if err := child.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				os.Exit(exitErr.ExitCode())
+			}
+			return fmt.Errorf("failed to run %s: %w", command[0], err)
+		}
+		return nil
+	},
+}
+
+// validateAccountID checks an --expect-account value. Empty means no check.
+func validateAccountID(accountID string) error {
+	if accountID != "" && !accountIDPattern.MatchString(accountID) {
+		return fmt.Errorf("invalid account ID '%s', expected 12 digits", accountID)
+	}
+	return nil
+}
+
+var accountIDPattern = regexp.MustCompile(`^\d{12}$`)
+
+// checkExpectedAccount verifies with STS that creds belong to the expected
+// account before they are used. Empty expected skips the check.
+func checkExpectedAccount(profile string, creds *aws.TempCredentials, region, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	identity, err := aws.GetIdentity(profile, creds, region)
+	if err != nil {
+		return fmt.Errorf("could not verify the account of profile '%s': %w", profile, err)
+	}
+	if identity.Account != expected {
+		return fmt.Errorf("profile '%s' resolves to account %s (%s), expected %s; check its source_profile chain", profile, identity.Account, identity.Arn, expected)
+	}
+	return nil
+}
+
+// execEnv returns the environment for a command run with creds. Variables that
+// would make the AWS CLI or SDKs pick other credentials are removed.
+func execEnv(base []string, creds *aws.TempCredentials, region string, now time.Time) []string {
+	drop := map[string]bool{
+		"AWS_PROFILE":               true,
+		"AWS_DEFAULT_PROFILE":       true,
+		"AWS_ACCESS_KEY_ID":         true,
+		"AWS_SECRET_ACCESS_KEY":     true,
+		"AWS_SESSION_TOKEN":         true,
+		"AWS_SECURITY_TOKEN":        true,
+		"AWS_CREDENTIAL_EXPIRATION": true,
+		"AWSM_EXPIRES_AT":           true,
+		"AWSM_EXPIRES_IN":           true,
+	}
+	if region != "" {
+		drop["AWS_REGION"] = true
+		drop["AWS_DEFAULT_REGION"] = true
+	}
+
+	var env []string
+	for _, entry := range base {
+		name, _, _ := strings.Cut(entry, "=")
+		if !drop[name] {
+			env = append(env, entry)
+		}
+	}
+
+	vars := []envVar{
+		{"AWS_ACCESS_KEY_ID", creds.AccessKeyId},
+		{"AWS_SECRET_ACCESS_KEY", creds.SecretAccessKey},
+	}
+	if creds.SessionToken != "" {
+		vars = append(vars, envVar{"AWS_SESSION_TOKEN", creds.SessionToken})
+	}
+	if !creds.Expires.IsZero() {
+		vars = append(vars, envVar{"AWS_CREDENTIAL_EXPIRATION", creds.Expires.UTC().Format(time.RFC3339)})
+		vars = append(vars, expiryVars(creds.Expires, now)...)
+	}
+	if region != "" {
+		vars = append(vars, envVar{"AWS_REGION", region}, envVar{"AWS_DEFAULT_REGION", region})
+	}
+	for _, v := range vars {
+		env = append(env, v.Name+"="+v.Value)
+	}
+	return env
+}
+
+func init() {
+	// Flags after the profile belong to the command
+	execCmd.Flags().SetInterspersed(false)
+	execCmd.Flags().StringVar(&execExpectAccount, "expect-account", "", "Only run the command when the credentials belong to this account ID")
+	addRoleDurationFlag(execCmd)
+	rootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+)
+
+func TestExecEnv(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	base := []string{"PATH=/usr/bin", "AWS_PROFILE=other", "AWS_SESSION_TOKEN=stale", "AWS_REGION=us-east-1"}
+	creds := &aws.TempCredentials{AccessKeyId: "AKIA", SecretAccessKey: "secret", SessionToken: "token", Expires: now.Add(time.Hour)}
+
+	env := execEnv(base, creds, "eu-west-1", now)
+	for _, want := range []string{"PATH=/usr/bin", "AWS_ACCESS_KEY_ID=AKIA", "AWS_SECRET_ACCESS_KEY=secret", "AWS_SESSION_TOKEN=token", "AWS_REGION=eu-west-1", "AWS_DEFAULT_REGION=eu-west-1", "AWSM_EXPIRES_IN=3600"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Expected %s in %v", want, env)
+		}
+	}
+	for _, unwanted := range []string{"AWS_PROFILE=other", "AWS_SESSION_TOKEN=stale", "AWS_REGION=us-east-1"} {
+		if slices.Contains(env, unwanted) {
+			t.Errorf("Expected %s to be removed from %v", unwanted, env)
+		}
+	}
+
+	// Static keys drop inherited session tokens and keep the inherited region
+	env = execEnv(base, &aws.TempCredentials{AccessKeyId: "AKIA", SecretAccessKey: "secret"}, "", now)
+	if slices.Contains(env, "AWS_SESSION_TOKEN=stale") || !slices.Contains(env, "AWS_REGION=us-east-1") {
+		t.Errorf("Unexpected environment %v", env)
+	}
+}
+
+func TestValidateAccountID(t *testing.T) {
+	for _, valid := range []string{"", "123456789012"} {
+		if err := validateAccountID(valid); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", valid, err)
+		}
+	}
+	for _, invalid := range []string{"12345", "12345678901a", "arn:aws:iam::123456789012:root"} {
+		if err := validateAccountID(invalid); err == nil {
+			t.Errorf("Expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestExecPreviousProfile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(home, ".awsm"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, "credentials"))
+
+	err := execCmd.RunE(execCmd, []string{"-", "--", "true"})
+	if err == nil || !strings.Contains(err.Error(), "no previous profile") {
+		t.Errorf("Expected '-' to resolve the previous profile, got %v", err)
+	}
+}
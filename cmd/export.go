@@ -26,46 +26,9 @@ var exportCmd = &cobra.Command{
 
 		util.InfoColor.Printf("Exporting AWS configuration to: %s\n", util.BoldColor.Sprint(outputFile))
 
-		// Get profiles
-		profiles, err := aws.ListProfilesDetailed()
+		exportData, err := buildExportData(includeSecrets)
 		if err != nil {
-			return fmt.Errorf("failed to list profiles: %w", err)
-		}
-
-		// Get SSO sessions
-		ssoSessions, err := aws.ListSSOSessions()
-		if err != nil {
-			return fmt.Errorf("failed to list SSO sessions: %w", err)
-		}
-
-		var configContent, credentialsContent string
-		if includeSecrets {
-			// Read config file content
-			configPath, _ := aws.GetAWSConfigPath()
-			if data, err := os.ReadFile(configPath); err == nil {
-				configContent = string(data)
-			}
-
-			// Read credentials file content
-			credentialsPath, _ := aws.GetAWSCredentialsPath()
-			if data, err := os.ReadFile(credentialsPath); err == nil {
-				credentialsContent = string(data)
-			}
-		} else {
-			// Redact secrets from profiles
-			for i := range profiles {
-				profiles[i].AccessKey = ""
-				profiles[i].SecretKey = ""
-			}
-		}
-
-		exportData := ExportData{
-			ExportedAt:      time.Now(),
-			Version:         "1.0",
-			Profiles:        profiles,
-			SSOSessions:     ssoSessions,
-			ConfigFile:      configContent,
-			CredentialsFile: credentialsContent,
+			return err
 		}
 
 		// Create output file
@@ -81,12 +44,59 @@ var exportCmd = &cobra.Command{
 			return fmt.Errorf("failed to write export data: %w", err)
 		}
 
-		util.SuccessColor.Printf("✔ Export complete: %d profiles, %d SSO sessions\n", len(profiles), len(ssoSessions))
+		util.SuccessColor.Printf("✔ Export complete: %d profiles, %d SSO sessions\n", len(exportData.Profiles), len(exportData.SSOSessions))
 		util.InfoColor.Printf("File saved: %s\n", outputFile)
 		return nil
 	},
 }
 
+// buildExportData collects all profiles and SSO sessions. Without secrets the
+// static keys are redacted; with them the raw config and credentials files
+// are included so they can be restored exactly.
+func buildExportData(withSecrets bool) (*ExportData, error) {
+	// Get profiles
+	profiles, err := aws.ListProfilesDetailed()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	// Get SSO sessions
+	ssoSessions, err := aws.ListSSOSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list SSO sessions: %w", err)
+	}
+
+	var configContent, credentialsContent string
+	if withSecrets {
+		// Read config file content
+		configPath, _ := aws.GetAWSConfigPath()
+		if data, err := os.ReadFile(configPath); err == nil {
+			configContent = string(data)
+		}
+
+		// Read credentials file content
+		credentialsPath, _ := aws.GetAWSCredentialsPath()
+		if data, err := os.ReadFile(credentialsPath); err == nil {
+			credentialsContent = string(data)
+		}
+	} else {
+		// Redact secrets from profiles
+		for i := range profiles {
+			profiles[i].AccessKey = ""
+			profiles[i].SecretKey = ""
+		}
+	}
+
+	return &ExportData{
+		ExportedAt:      time.Now(),
+		Version:         "1.0",
+		Profiles:        profiles,
+		SSOSessions:     ssoSessions,
+		ConfigFile:      configContent,
+		CredentialsFile: credentialsContent,
+	}, nil
+}
+
 func init() {
 	exportCmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Include credentials and raw config files in the export")
 	rootCmd.AddCommand(exportCmd)
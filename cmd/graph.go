@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+
+	"awsm/internal/aws"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	graphFormat  string
+	graphAccount string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Draw which accounts the configured profiles can reach",
+	Long: `Prints a graph of the SSO sessions, profiles and accounts in the AWS config,
+linked by sso_session, source_profile and role_arn, to audit who can reach
+which account through which chain of roles. Source profiles that no profile
+defines are marked as missing.
+
+The graph is written in Graphviz DOT (the default) or as a Mermaid flowchart,
+which GitHub renders inline. --account keeps only the paths into one account.
+
+Examples:
+  awsm graph | dot -Tsvg > access.svg
+  awsm graph --format mermaid
+  awsm graph --account 123456789012`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if graphFormat != "dot" && graphFormat != "mermaid" {
+			return fmt.Errorf("unsupported format '%s', use dot or mermaid", graphFormat)
+		}
+		profiles, err := aws.ListProfilesDetailed()
+		if err != nil {
+			return err
+		}
+		sessions, err := aws.ListSSOSessions()
+		if err != nil {
+			return err
+		}
+
+		graph := aws.BuildAccessGraph(profiles, sessions)
+		if graphAccount != "" {
+			if graph = graph.Reaching(graphAccount); graph == nil {
+				return fmt.Errorf("no profile reaches account %s", graphAccount)
+			}
+		}
+
+		if graphFormat == "mermaid" {
+			fmt.Print(graph.Mermaid())
+		} else {
+			fmt.Print(graph.DOT())
+		}
+		return nil
+	},
+}
+
+func init() {
+	graphCmd.Flags().StringVarP(&graphFormat, "format", "f", "dot", "Output format: dot or mermaid")
+	graphCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"dot", "mermaid"}, cobra.ShellCompDirectiveNoFileComp
+	})
+	graphCmd.Flags().StringVar(&graphAccount, "account", "", "Only show the paths into this account ID")
+	rootCmd.AddCommand(graphCmd)
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"awsm/internal/aws"
+	"awsm/internal/share"
 	"awsm/internal/util"
 	"encoding/json"
 	"fmt"
@@ -35,24 +36,60 @@ var importCmd = &cobra.Command{
 
 		util.InfoColor.Printf("Importing AWS configuration from: %s\n", util.BoldColor.Sprint(importFile))
 
-		// Read import file
-		file, err := os.Open(importFile)
+		exportData, err := readExportFile(importFile)
 		if err != nil {
-			return fmt.Errorf("failed to open import file: %w", err)
+			return err
 		}
-		defer file.Close()
+		return importExportData(exportData)
+	},
+}
+
+// readExportFile reads an export file, asking for the passphrase when it was
+// encrypted with 'awsm config export --encrypt'.
+func readExportFile(path string) (*ExportData, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
 
-		var exportData ExportData
-		decoder := json.NewDecoder(file)
-		if err := decoder.Decode(&exportData); err != nil {
-			return fmt.Errorf("failed to parse import file: %w", err)
+	if share.IsEncrypted(data) {
+		passphrase, err := util.PromptForSecret("Passphrase: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		if data, err = share.DecryptWithPassphrase(data, passphrase); err != nil {
+			return nil, err
 		}
+	}
+
+	var exportData ExportData
+	if err := json.Unmarshal(data, &exportData); err != nil {
+		return nil, fmt.Errorf("failed to parse import file: %w", err)
+	}
+	return &exportData, nil
+}
+
+// importExportData merges the profiles and SSO sessions of an export, or with
+// --restore-files replaces the config and credentials files with its copies.
+func importExportData(exportData *ExportData) error {
+	util.InfoColor.Printf("Import file contains: %d profiles, %d SSO sessions\n",
+		len(exportData.Profiles), len(exportData.SSOSessions))
 
-		util.InfoColor.Printf("Import file contains: %d profiles, %d SSO sessions\n",
-			len(exportData.Profiles), len(exportData.SSOSessions))
+	if !importForce {
+		confirm, err := util.PromptForInput("Continue with import? This may overwrite existing configurations (y/N): ")
+		if err != nil {
+			return err
+		}
+		if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+			util.InfoColor.Println("Import cancelled")
+			return nil
+		}
+	}
 
+	// Check for restore mode
+	if importRestoreFiles {
 		if !importForce {
-			confirm, err := util.PromptForInput("Continue with import? This may overwrite existing configurations (y/N): ")
+			confirm, err := util.PromptForInput("WARNING: --restore-files will OVERWRITE your local config and credentials files entirely. Continue? (y/N): ")
 			if err != nil {
 				return err
 			}
@@ -62,53 +99,39 @@ var importCmd = &cobra.Command{
 			}
 		}
 
-		// Check for restore mode
-		if importRestoreFiles {
-			if !importForce {
-				confirm, err := util.PromptForInput("WARNING: --restore-files will OVERWRITE your local config and credentials files entirely. Continue? (y/N): ")
-				if err != nil {
-					return err
-				}
-				if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
-					util.InfoColor.Println("Import cancelled")
-					return nil
-				}
-			}
-
-			if err := aws.RestoreConfigFiles(exportData.ConfigFile, exportData.CredentialsFile); err != nil {
-				return fmt.Errorf("failed to restore files: %w", err)
-			}
-
-			util.SuccessColor.Println("✔ AWS configuration files restored successfully (original files moved to .bak)")
-			return nil
+		if err := aws.RestoreConfigFiles(exportData.ConfigFile, exportData.CredentialsFile); err != nil {
+			return fmt.Errorf("failed to restore files: %w", err)
 		}
 
-		// Import SSO sessions first (Merge Mode)
-		importedSessions := 0
-		for _, session := range exportData.SSOSessions {
-			if err := aws.ImportSSOSession(session); err != nil {
-				util.ErrorColor.Printf("Failed to import SSO session '%s': %v\n", session.Name, err)
-			} else {
-				util.SuccessColor.Printf("✔ Imported SSO session '%s'\n", session.Name)
-				importedSessions++
-			}
+		util.SuccessColor.Println("✔ AWS configuration files restored successfully (original files moved to .bak)")
+		return nil
+	}
+
+	// Import SSO sessions first (Merge Mode)
+	importedSessions := 0
+	for _, session := range exportData.SSOSessions {
+		if err := aws.ImportSSOSession(session); err != nil {
+			util.ErrorColor.Printf("Failed to import SSO session '%s': %v\n", session.Name, err)
+		} else {
+			util.SuccessColor.Printf("✔ Imported SSO session '%s'\n", session.Name)
+			importedSessions++
 		}
-
-		// Import profiles (Merge Mode)
-		importedProfiles := 0
-		for _, profile := range exportData.Profiles {
-			if err := aws.ImportProfile(profile); err != nil {
-				util.ErrorColor.Printf("Failed to import profile '%s': %v\n", profile.Name, err)
-			} else {
-				util.SuccessColor.Printf("✔ Imported profile '%s'\n", profile.Name)
-				importedProfiles++
-			}
+	}
+
+	// Import profiles (Merge Mode)
+	importedProfiles := 0
+	for _, profile := range exportData.Profiles {
+		if err := aws.ImportProfile(profile); err != nil {
+			util.ErrorColor.Printf("Failed to import profile '%s': %v\n", profile.Name, err)
+		} else {
+			util.SuccessColor.Printf("✔ Imported profile '%s'\n", profile.Name)
+			importedProfiles++
 		}
+	}
 
-		util.SuccessColor.Printf("✔ Import complete: %d profiles, %d SSO sessions imported (Merge Mode)\n",
-			importedProfiles, importedSessions)
-		return nil
-	},
+	util.SuccessColor.Printf("✔ Import complete: %d profiles, %d SSO sessions imported (Merge Mode)\n",
+		importedProfiles, importedSessions)
+	return nil
 }
 
 func init() {
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"awsm/internal/aws"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var metadataURL string
+
+var metadataCmd = &cobra.Command{
+	Use:   "metadata",
+	Short: "Show or refresh the AWS partitions, regions and services awsm knows",
+	Long: `awsm validates and completes regions, and builds console links, from
+metadata about the AWS partitions, their regions and services. A copy is
+bundled with awsm; 'awsm metadata update' downloads the current endpoints file
+of the AWS SDKs so new regions work without waiting for an awsm release.
+
+Examples:
+  awsm metadata
+  awsm metadata update
+  awsm metadata reset`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := aws.GetMetadata()
+		return output.Render(output.Selected, output.Result{
+			Data: m,
+			Table: func() error {
+				if m.Updated.IsZero() {
+					fmt.Printf("Source:  %s (bundled)\n", m.Source)
+				} else {
+					fmt.Printf("Source:  %s\n", m.Source)
+					fmt.Printf("Updated: %s\n", m.Updated.Local().Format("2006-01-02 15:04"))
+				}
+				fmt.Println()
+				for _, p := range m.Partitions {
+					util.BoldColor.Printf("%-12s", p.ID)
+					fmt.Printf(" %-20s %3d regions", p.Name, len(p.Regions))
+					if len(p.Services) > 0 {
+						fmt.Printf(" %4d services", len(p.Services))
+					}
+					fmt.Println()
+				}
+				return nil
+			},
+		})
+	},
+}
+
+var metadataUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Download the current partitions, regions and services",
+	Long: `Downloads the endpoints file of the AWS SDKs and stores the partitions,
+regions and services it lists next to the awsm settings. From then on it is
+used instead of the bundled copy. --url points at another copy of the file,
+e.g. a mirror; the Go SDK's partitions.json works too but lists no services.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before := aws.GetAllRegions()
+
+		util.InfoColor.Printf("Downloading %s...\n", metadataURL)
+		m, err := aws.FetchMetadata(metadataURL)
+		if err != nil {
+			return err
+		}
+		if err := aws.SaveMetadata(m); err != nil {
+			return err
+		}
+
+		known := make(map[string]bool, len(before))
+		for _, region := range before {
+			known[region] = true
+		}
+		for _, region := range aws.GetAllRegions() {
+			if !known[region] {
+				util.SuccessColor.Printf("  + %s", region)
+				fmt.Printf(" (%s)\n", aws.RegionDescription(region))
+			}
+		}
+		path, _ := aws.MetadataPath()
+		util.SuccessColor.Printf("✔ Saved %d partitions and %d standard regions to %s\n", len(m.Partitions), len(aws.GetAllRegions()), util.BoldColor.Sprint(path))
+		return nil
+	},
+}
+
+var metadataResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Go back to the metadata bundled with awsm",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := aws.ResetMetadata(); err != nil {
+			return err
+		}
+		util.SuccessColor.Println("✔ Using the metadata bundled with awsm")
+		return nil
+	},
+}
+
+func init() {
+	metadataUpdateCmd.Flags().StringVar(&metadataURL, "url", aws.DefaultMetadataURL, "Endpoints file to download")
+	metadataCmd.AddCommand(metadataUpdateCmd)
+	metadataCmd.AddCommand(metadataResetCmd)
+	rootCmd.AddCommand(metadataCmd)
+}
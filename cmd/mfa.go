@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"awsm/internal/aws"
+	"awsm/internal/keychain"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var mfaCmd = &cobra.Command{
+	Use:   "mfa",
+	Short: "Get MFA codes without typing them",
+	Long: `Profiles with an mfa_serial normally prompt for a code. awsm can get the code
+itself, which scripts, 'awsm exec' without a terminal and the agent need:
+
+  mfa_process   a command in the profile that prints the code, e.g. a
+                password manager CLI:
+                  mfa_process = op item get AWS --otp
+  TOTP secret   the secret of a virtual MFA device, stored in the OS keychain
+                with 'awsm mfa add <profile>'; awsm computes the codes
+
+mfa_process wins when both are configured.`,
+}
+
+var mfaAddCmd = &cobra.Command{
+	Use:   "add <profile>",
+	Short: "Store the TOTP secret of a profile's MFA device in the OS keychain",
+	Long: `Stores the secret of the virtual MFA device named by the profile's mfa_serial
+in the OS keychain (macOS Keychain, Secret Service via secret-tool on Linux,
+Windows Credential Manager). Paste the secret shown as "Show secret key" when
+registering the device, or the otpauth:// URI of its QR code.
+
+Profiles sharing the device use the same secret.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serial, err := aws.ProfileMFASerial(args[0])
+		if err != nil {
+			return err
+		}
+		input, err := util.PromptForSecret(fmt.Sprintf("TOTP secret for %s: ", util.BoldColor.Sprint(serial)))
+		if err != nil {
+			return fmt.Errorf("failed to read the secret: %w", err)
+		}
+		secret, err := aws.ParseTOTPSecret(input)
+		if err != nil {
+			return err
+		}
+		if err := aws.SetTOTPSecret(serial, secret); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Stored the TOTP secret for %s\n", serial)
+		fmt.Printf("Check it with 'awsm mfa code %s' against your authenticator app.\n", args[0])
+		return nil
+	},
+}
+
+var mfaRemoveCmd = &cobra.Command{
+	Use:               "remove <profile>",
+	Aliases:           []string{"rm"},
+	Short:             "Remove the TOTP secret of a profile's MFA device from the OS keychain",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		serial, err := aws.ProfileMFASerial(args[0])
+		if err != nil {
+			return err
+		}
+		if err := aws.RemoveTOTPSecret(serial); err != nil {
+			if errors.Is(err, keychain.ErrNotFound) {
+				return fmt.Errorf("no TOTP secret stored for %s", serial)
+			}
+			return err
+		}
+		util.SuccessColor.Printf("✔ Removed the TOTP secret for %s\n", serial)
+		return nil
+	},
+}
+
+var mfaCodeCmd = &cobra.Command{
+	Use:               "code <profile>",
+	Short:             "Print the current MFA code of a profile",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		code, err := aws.MFACode(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(code)
+		return nil
+	},
+}
+
+func init() {
+	mfaCmd.AddCommand(mfaAddCmd, mfaRemoveCmd, mfaCodeCmd)
+	rootCmd.AddCommand(mfaCmd)
+}
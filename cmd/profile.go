@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/output"
 	"awsm/internal/util"
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -20,23 +22,29 @@ var (
 	sortBy       string
 	showHelp     bool
 	outputJSON   bool
+	tagFilters   []string
+	groupByTag   string
+	listLimit    int
+	listPage     int
+	countOnly    bool
 )
 
 // JSONProfileInfo represents the profile information in a scripting-friendly format
 type JSONProfileInfo struct {
-	Name          string `json:"name"`
-	Type          string `json:"type"`
-	Region        string `json:"region"`
-	AccountID     string `json:"account_id,omitempty"`
-	RoleARN       string `json:"role_arn,omitempty"`
-	SourceProfile string `json:"source_profile,omitempty"`
-	SSOStartURL   string `json:"sso_start_url,omitempty"`
-	SSORegion     string `json:"sso_region,omitempty"`
-	SSOAccountID  string `json:"sso_account_id,omitempty"`
-	SSORoleName   string `json:"sso_role_name,omitempty"`
-	SSOSession    string `json:"sso_session,omitempty"`
-	MFASerial     string `json:"mfa_serial,omitempty"`
-	IsActive      bool   `json:"is_active"`
+	Name          string            `json:"name"`
+	Type          string            `json:"type"`
+	Region        string            `json:"region"`
+	AccountID     string            `json:"account_id,omitempty"`
+	RoleARN       string            `json:"role_arn,omitempty"`
+	SourceProfile string            `json:"source_profile,omitempty"`
+	SSOStartURL   string            `json:"sso_start_url,omitempty"`
+	SSORegion     string            `json:"sso_region,omitempty"`
+	SSOAccountID  string            `json:"sso_account_id,omitempty"`
+	SSORoleName   string            `json:"sso_role_name,omitempty"`
+	SSOSession    string            `json:"sso_session,omitempty"`
+	MFASerial     string            `json:"mfa_serial,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	IsActive      bool              `json:"is_active"`
 }
 
 // Profile type descriptions
@@ -61,19 +69,38 @@ var profileListCmd = &cobra.Command{
 			printProfileTypeHelp()
 			return nil
 		}
+		if listLimit < 0 {
+			return fmt.Errorf("--limit must not be negative")
+		}
+		if listPage < 1 {
+			return fmt.Errorf("--page must be 1 or more")
+		}
+		if listPage > 1 && listLimit == 0 {
+			return fmt.Errorf("--page needs --limit")
+		}
+
+		// Counting everything doesn't need the full profile details
+		if countOnly && filterType == "" && filterRegion == "" && nameFilter == "" && len(tagFilters) == 0 {
+			counts, err := aws.CountProfiles()
+			if err != nil {
+				return err
+			}
+			return printProfileCounts(counts)
+		}
 
 		profiles, err := aws.ListProfilesDetailed()
 		if err != nil {
 			return err
 		}
 
+		format := output.Current(outputJSON)
 		if len(profiles) == 0 {
-			if !outputJSON {
-				util.WarnColor.Println("No profiles found.")
-			} else {
-				fmt.Println("[]") // Empty JSON array
-			}
-			return nil
+			return renderNoProfiles(format, "No profiles found.")
+		}
+
+		tags, err := config.LoadProfileTags()
+		if err != nil {
+			return err
 		}
 
 		// Apply filters
@@ -88,16 +115,22 @@ var profileListCmd = &cobra.Command{
 			if nameFilter != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(nameFilter)) {
 				continue
 			}
+			if !tags.Matches(p.Name, tagFilters) {
+				continue
+			}
 			filtered = append(filtered, p)
 		}
 
-		if len(filtered) == 0 {
-			if !outputJSON {
-				util.WarnColor.Println("No profiles match the specified filters.")
-			} else {
-				fmt.Println("[]") // Empty JSON array
+		if countOnly {
+			var counts aws.ProfileCounts
+			for _, p := range filtered {
+				counts.Add(p.Type, p.Region)
 			}
-			return nil
+			return printProfileCounts(counts)
+		}
+
+		if len(filtered) == 0 {
+			return renderNoProfiles(format, "No profiles match the specified filters.")
 		}
 
 		// Sort profiles based on sortBy flag
@@ -116,23 +149,145 @@ var profileListCmd = &cobra.Command{
 			})
 		}
 
-		if outputJSON {
-			return outputProfilesJSON(filtered)
+		page, pages := paginate(filtered, listLimit, listPage)
+		if len(page) == 0 {
+			return renderNoProfiles(format, fmt.Sprintf("No profiles on page %d, there are %d page(s).", listPage, pages))
 		}
 
-		// Print profiles
-		if listDetailed {
-			printDetailedProfiles(filtered)
-		} else {
-			printSimpleProfiles(filtered)
+		return output.Render(format, output.Result{
+			Data: profilesForOutput(page, tags),
+			Table: func() error {
+				if groupByTag != "" {
+					printProfilesByTag(page, tags, groupByTag)
+				} else if listDetailed {
+					printDetailedProfiles(page)
+				} else {
+					printSimpleProfiles(page)
+				}
+				printListFooter(filtered, len(page), pages)
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, p := range page {
+					fmt.Fprintln(w, p.Name)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+// renderNoProfiles reports an empty listing: a warning in the table, an empty
+// list in the other formats.
+func renderNoProfiles(format output.Format, message string) error {
+	return output.Render(format, output.Result{
+		Data: []JSONProfileInfo{},
+		Table: func() error {
+			util.WarnColor.Println(message)
+			return nil
+		},
+		Raw: func(io.Writer) error { return nil },
+	})
+}
+
+// paginate returns one page of profiles and the number of pages. A limit of
+// zero returns all profiles as a single page.
+func paginate(profiles []aws.ProfileInfo, limit, page int) ([]aws.ProfileInfo, int) {
+	if limit == 0 {
+		return profiles, 1
+	}
+	pages := (len(profiles) + limit - 1) / limit
+	start := (page - 1) * limit
+	if start >= len(profiles) {
+		return nil, pages
+	}
+	return profiles[start:min(start+limit, len(profiles))], pages
+}
+
+// printListFooter summarizes the listed profiles by type and region, and
+// which part of them is shown when paginating.
+func printListFooter(profiles []aws.ProfileInfo, shown, pages int) {
+	var counts aws.ProfileCounts
+	for _, p := range profiles {
+		counts.Add(p.Type, p.Region)
+	}
+	fmt.Println()
+	if pages > 1 {
+		start := (listPage-1)*listLimit + 1
+		next := ""
+		if listPage < pages {
+			next = fmt.Sprintf(", next: --page %d", listPage+1)
+		}
+		util.InfoColor.Printf("Showing %d-%d of %d profiles (page %d/%d%s)\n",
+			start, start+shown-1, counts.Total, listPage, pages, next)
+	}
+	fmt.Println(formatProfileCounts(counts))
+}
+
+// formatProfileCounts renders counts as "4 profile(s) (SSO: 3, Key: 1) · us-east-1: 2",
+// with the types and regions ordered by count.
+func formatProfileCounts(counts aws.ProfileCounts) string {
+	byCount := func(m map[string]int) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if m[keys[i]] != m[keys[j]] {
+				return m[keys[i]] > m[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+		parts := make([]string, len(keys))
+		for i, key := range keys {
+			parts[i] = fmt.Sprintf("%s: %d", key, m[key])
 		}
+		return parts
+	}
 
-		return nil
-	},
+	types := make(map[string]int, len(counts.ByType))
+	for profileType, n := range counts.ByType {
+		types[string(profileType)] = n
+	}
+	summary := fmt.Sprintf("%d profile(s)", counts.Total)
+	if len(types) > 0 {
+		summary += " (" + strings.Join(byCount(types), ", ") + ")"
+	}
+	if len(counts.ByRegion) > 0 {
+		summary += " · " + strings.Join(byCount(counts.ByRegion), ", ")
+	}
+	return summary
 }
 
-func outputProfilesJSON(profiles []aws.ProfileInfo) error {
-	var jsonProfiles []JSONProfileInfo
+func printProfileCounts(counts aws.ProfileCounts) error {
+	byType := make(map[string]int, len(counts.ByType))
+	for profileType, n := range counts.ByType {
+		byType[string(profileType)] = n
+	}
+	byRegion := counts.ByRegion
+	if byRegion == nil {
+		byRegion = map[string]int{}
+	}
+	return output.Render(output.Current(outputJSON), output.Result{
+		Data: struct {
+			Total    int            `json:"total"`
+			ByType   map[string]int `json:"by_type"`
+			ByRegion map[string]int `json:"by_region"`
+		}{counts.Total, byType, byRegion},
+		Table: func() error {
+			fmt.Println(formatProfileCounts(counts))
+			return nil
+		},
+		Raw: func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, counts.Total)
+			return err
+		},
+	})
+}
+
+// profilesForOutput is the document --output json and yaml print for profiles.
+func profilesForOutput(profiles []aws.ProfileInfo, tags config.ProfileTags) []JSONProfileInfo {
+	jsonProfiles := make([]JSONProfileInfo, 0, len(profiles))
 
 	for _, p := range profiles {
 		// Extract account ID from role ARN if available
@@ -157,15 +312,12 @@ func outputProfilesJSON(profiles []aws.ProfileInfo) error {
 			SSORoleName:   p.SSORoleName,
 			SSOSession:    p.SSOSession,
 			MFASerial:     p.MFASerial,
+			Tags:          tags[p.Name],
 			IsActive:      p.IsActive,
 		}
 		jsonProfiles = append(jsonProfiles, jsonProfile)
 	}
-
-	// Create JSON encoder with indentation for readability
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(jsonProfiles)
+	return jsonProfiles
 }
 
 func printProfileTypeHelp() {
@@ -335,6 +487,57 @@ func printSimpleProfiles(profiles []aws.ProfileInfo) {
 	fmt.Println("Region")
 }
 
+// printProfilesByTag prints profiles grouped by the value of a tag key, with
+// the profiles lacking the tag last.
+func printProfilesByTag(profiles []aws.ProfileInfo, tags config.ProfileTags, key string) {
+	groups := make(map[string][]aws.ProfileInfo)
+	var untagged []aws.ProfileInfo
+	for _, p := range profiles {
+		value, ok := tags[p.Name][key]
+		if !ok {
+			untagged = append(untagged, p)
+			continue
+		}
+		groups[value] = append(groups[value], p)
+	}
+	values := make([]string, 0, len(groups))
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	printGroup := func(title string, group []aws.ProfileInfo) {
+		util.BoldColor.Printf("● %s (%d)\n", title, len(group))
+		for _, p := range group {
+			if p.IsActive {
+				util.SuccessColor.Print("  ▶ ")
+			} else {
+				fmt.Print("    ")
+			}
+			fmt.Printf("%s %s", p.Name, colorizeProfileType(p.Type))
+			if account := p.AccountID(); account != "" {
+				util.InfoColor.Printf(" (%s)", account)
+			}
+			if p.Region != "" {
+				util.WarnColor.Printf(" [%s]", p.Region)
+			}
+			if formatted := tags.Format(p.Name); formatted != "" {
+				fmt.Printf("  %s", formatted)
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	for _, value := range values {
+		printGroup(key+"="+value, groups[value])
+	}
+	if len(untagged) > 0 {
+		printGroup("no "+key+" tag", untagged)
+	}
+}
+
 func printDetailedProfiles(profiles []aws.ProfileInfo) {
 	fmt.Println()
 	util.InfoColor.Println("AWS Profiles (Detailed)")
@@ -397,7 +600,16 @@ func init() {
 	profileListCmd.Flags().StringVarP(&nameFilter, "name", "n", "", "Filter by profile name (case-insensitive)")
 	profileListCmd.Flags().StringVarP(&sortBy, "sort", "s", "name", "Sort by field (name, type, region)")
 	profileListCmd.Flags().BoolVarP(&showHelp, "help-types", "H", false, "Show help about profile types")
-	profileListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output profiles in JSON format")
+	profileListCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output profiles in JSON format (same as --output json)")
+	profileListCmd.Flags().StringArrayVar(&tagFilters, "tag", nil, "Filter by tag, as key=value or just key (repeatable, all must match)")
+	profileListCmd.Flags().StringVar(&groupByTag, "group-by", "", "Group profiles by the value of a tag key")
+	profileListCmd.RegisterFlagCompletionFunc("type", completeProfileTypes)
+	profileListCmd.RegisterFlagCompletionFunc("sort", completeValues("name", "type", "region"))
+	profileListCmd.RegisterFlagCompletionFunc("tag", completeTagFilters)
+	profileListCmd.RegisterFlagCompletionFunc("group-by", completeTagKeys)
+	profileListCmd.Flags().IntVar(&listLimit, "limit", 0, "Show at most this many profiles per page (0 shows all)")
+	profileListCmd.Flags().IntVar(&listPage, "page", 1, "Page to show with --limit")
+	profileListCmd.Flags().BoolVar(&countOnly, "count-only", false, "Only print the number of profiles per type and region")
 
 	profileCmd.AddCommand(profileSetCmd)
 	profileCmd.AddCommand(profileListCmd)
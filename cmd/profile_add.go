@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var profileAddKeychain bool
+
 var profileAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add a new AWS profile",
@@ -17,7 +19,14 @@ var profileAddCmd = &cobra.Command{
 var profileAddIAMUserCmd = &cobra.Command{
 	Use:   "iam-user <profile-name>",
 	Short: "Add an IAM user profile with static credentials",
-	Args:  cobra.ExactArgs(1),
+	Long: `Adds an IAM user profile with static access keys, stored in
+~/.aws/credentials.
+
+With --keychain the keys are stored in the OS keychain (macOS Keychain,
+Windows Credential Manager, Secret Service via secret-tool on Linux) instead,
+and the profile gets 'credential_process = awsm credential-process <name>' so
+the AWS CLI and SDKs still find them.`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		profileName := args[0]
 
@@ -62,7 +71,11 @@ var profileAddIAMUserCmd = &cobra.Command{
 			return fmt.Errorf("invalid region: %s", region)
 		}
 
-		if err := aws.AddIAMUserProfile(profileName, accessKey, secretKey, region); err != nil {
+		addProfile := aws.AddIAMUserProfile
+		if profileAddKeychain {
+			addProfile = aws.AddKeychainIAMUserProfile
+		}
+		if err := addProfile(profileName, accessKey, secretKey, region); err != nil {
 			return fmt.Errorf("failed to add IAM user profile: %w", err)
 		}
 
@@ -158,6 +171,7 @@ func resolveProfileAddConflict(profileName, profileType string) (string, bool) {
 }
 
 func init() {
+	profileAddIAMUserCmd.Flags().BoolVar(&profileAddKeychain, "keychain", false, "Store the keys in the OS keychain instead of ~/.aws/credentials")
 	profileAddCmd.AddCommand(profileAddIAMUserCmd)
 	profileAddCmd.AddCommand(profileAddIAMRoleCmd)
 	profileCmd.AddCommand(profileAddCmd)
@@ -13,7 +13,7 @@ var profileChangeRegionCmd = &cobra.Command{
 	Short:             "Change the default region for a profile",
 	Long:              `Updates the region setting for the specified profile in ~/.aws/config.`,
 	Args:              cobra.ExactArgs(2),
-	ValidArgsFunction: completeProfiles,
+	ValidArgsFunction: completeArgs(completeProfiles, completeRegions),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		profileName := args[0]
 		region := args[1]
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"awsm/internal/aws"
+	"awsm/internal/output"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -16,8 +17,13 @@ var profileCurrentCmd = &cobra.Command{
 		if profileName == "" {
 			return fmt.Errorf("no active profile found")
 		}
-		fmt.Println(profileName)
-		return nil
+		return output.Render(output.Selected, output.Result{
+			Data: map[string]string{"profile": profileName},
+			Table: func() error {
+				fmt.Println(profileName)
+				return nil
+			},
+		})
 	},
 }
 
@@ -18,7 +18,7 @@ var profileDeleteCmd = &cobra.Command{
 	Use:               "delete <profile-name>",
 	Short:             "Delete an AWS profile",
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeProfiles,
+	ValidArgsFunction: completeArgs(completeProfiles),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		profileName := args[0]
 
@@ -36,6 +36,10 @@ var profileDeleteCmd = &cobra.Command{
 			return nil
 		}
 
+		if err := warnBrokenDependents([]string{profileName}); err != nil {
+			return err
+		}
+
 		// Confirm deletion unless forced
 		if !forceDelete {
 			confirm, err := util.PromptForInput(fmt.Sprintf("Delete profile '%s'? (y/N): ", profileName))
@@ -52,6 +56,7 @@ var profileDeleteCmd = &cobra.Command{
 			return fmt.Errorf("failed to delete profile: %w", err)
 		}
 
+		forgetProfileTags(profileName)
 		util.SuccessColor.Printf("✔ Profile '%s' deleted successfully\n", profileName)
 		return nil
 	},
@@ -72,6 +77,9 @@ func deleteAllSSOProfiles(ssoSession string) error {
 	for _, profile := range profiles {
 		fmt.Printf("  - %s\n", profile)
 	}
+	if err := warnBrokenDependents(profiles); err != nil {
+		return err
+	}
 
 	if !forceDelete {
 		confirm, err := util.PromptForInput(fmt.Sprintf("Delete all %d profiles? (y/N): ", len(profiles)))
@@ -91,7 +99,26 @@ func deleteAllSSOProfiles(ssoSession string) error {
 			util.SuccessColor.Printf("✔ Deleted profile '%s'\n", profile)
 		}
 	}
+	forgetProfileTags(profiles...)
+
+	return nil
+}
 
+// warnBrokenDependents lists the profiles that reach the deleted profiles
+// through source_profile and would no longer resolve credentials.
+func warnBrokenDependents(deleted []string) error {
+	index, err := aws.GetProfileIndex()
+	if err != nil {
+		return err
+	}
+	broken := index.BrokenDependents(deleted)
+	if len(broken) == 0 {
+		return nil
+	}
+	util.WarnColor.Printf("⚠ %d profile(s) use a deleted profile as source_profile and will stop working:\n", len(broken))
+	for _, profile := range broken {
+		fmt.Printf("  - %s\n", profile)
+	}
 	return nil
 }
 
@@ -13,7 +13,7 @@ var profileEditCmd = &cobra.Command{
 	Use:               "edit <profile-name>",
 	Short:             "Edit an existing AWS profile",
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeProfiles,
+	ValidArgsFunction: completeArgs(completeProfiles),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		profileName := args[0]
 
@@ -143,12 +143,16 @@ func editIAMUserProfile(profileName string, current *aws.ProfileInfo) error {
 	}
 
 	if accessKey != "" && secretKey != "" {
-		// Delete old profile and create new one with new keys
+		// Delete old profile and create new one with new keys, kept where the old ones were
+		addProfile := aws.AddIAMUserProfile
+		if aws.IsKeychainProfile(profileName) {
+			addProfile = aws.AddKeychainIAMUserProfile
+		}
 		if err := aws.DeleteProfile(profileName); err != nil {
 			return fmt.Errorf("failed to delete old profile: %w", err)
 		}
 
-		if err := aws.AddIAMUserProfile(profileName, accessKey, secretKey, region); err != nil {
+		if err := addProfile(profileName, accessKey, secretKey, region); err != nil {
 			return fmt.Errorf("failed to update profile: %w", err)
 		}
 	} else {
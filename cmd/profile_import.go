@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	profileImportCSV           string
+	profileImportTerraform     string
+	profileImportOrganizations string
+	profileImportSSOSession    string
+	profileImportSourceProfile string
+	profileImportRole          string
+	profileImportRegion        string
+	profileImportForce         bool
+	profileImportDryRun        bool
+)
+
+var profileImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Bulk-create profiles from an account inventory",
+	Long: `Creates one profile per account listed in an external inventory.
+
+Supported inventories:
+  --csv            CSV with a header row: account_id (required), role, name, region
+  --terraform      Terraform state containing aws_organizations_account or
+                   aws_organizations_organization resources
+  --organizations  JSON output of 'aws organizations list-accounts'
+
+Profiles are created as SSO profiles when --sso-session is given, or as
+assume-role profiles when --source-profile is given. The role comes from the
+inventory or from --role. For CSV inventories the name column is used as the
+profile name; otherwise names follow 'awsm sso generate' (<account>-<role>).
+
+Examples:
+  awsm profile import --csv accounts.csv --sso-session company
+  awsm profile import --organizations accounts.json --source-profile mgmt --role OrganizationAccountAccessRole
+  awsm profile import --terraform terraform.tfstate --sso-session company --role ReadOnly --dry-run`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (profileImportSSOSession == "") == (profileImportSourceProfile == "") {
+			return fmt.Errorf("exactly one of --sso-session or --source-profile is required")
+		}
+		if profileImportRegion != "" && !aws.IsValidRegion(profileImportRegion) {
+			return fmt.Errorf("invalid region: %s", profileImportRegion)
+		}
+
+		accounts, err := readInventory()
+		if err != nil {
+			return err
+		}
+		if len(accounts) == 0 {
+			util.WarnColor.Println("No accounts found in inventory.")
+			return nil
+		}
+
+		region := profileImportRegion
+		if region == "" && profileImportSSOSession != "" {
+			region, err = getSSORegionForSession(profileImportSSOSession)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Resolve every entry before writing so a bad inventory or a policy
+		// violation leaves the config untouched
+		type importEntry struct {
+			name, accountID, role, region string
+			exists                        bool
+		}
+		var entries []importEntry
+		var planned []policy.Profile
+		skipped := 0
+		for _, acc := range accounts {
+			role := acc.Role
+			if role == "" {
+				role = profileImportRole
+			}
+			if role == "" {
+				return fmt.Errorf("no role for account %s: add a role column or use --role", acc.AccountID)
+			}
+			profileRegion := acc.Region
+			if profileRegion == "" {
+				profileRegion = region
+			}
+			if profileRegion != "" && !aws.IsValidRegion(profileRegion) {
+				return fmt.Errorf("invalid region '%s' for account %s", profileRegion, acc.AccountID)
+			}
+			profileName := inventoryProfileName(acc, role)
+
+			exists, err := aws.ProfileExists(profileName)
+			if err != nil {
+				return fmt.Errorf("failed to check if profile exists: %w", err)
+			}
+			if exists && !profileImportForce {
+				util.WarnColor.Printf("  - %s already exists, skipping (use --force to overwrite)\n", profileName)
+				skipped++
+				continue
+			}
+			entries = append(entries, importEntry{profileName, acc.AccountID, role, profileRegion, exists})
+			planned = append(planned, policy.Profile{Name: profileName, Region: profileRegion, AccountID: acc.AccountID, RoleName: role})
+		}
+
+		// Check the policy up front: --force deletes a profile before rewriting it,
+		// so a violation found by the write itself would lose the old profile.
+		// With --override-policy the writes log the override themselves.
+		if !policy.Override {
+			if err := policy.Enforce(planned...); err != nil {
+				return err
+			}
+		}
+
+		created := 0
+		for _, e := range entries {
+			if profileImportDryRun {
+				fmt.Printf("  + %s (%s, %s)\n", e.name, e.accountID, e.role)
+				created++
+				continue
+			}
+
+			// Replace rather than merge so no keys of the previous profile type are left behind
+			if e.exists {
+				if err := aws.DeleteProfile(e.name); err != nil {
+					return fmt.Errorf("failed to replace profile '%s': %w", e.name, err)
+				}
+			}
+
+			if profileImportSSOSession != "" {
+				err = aws.AddSSOProfile(e.name, profileImportSSOSession, e.accountID, e.role, e.region)
+			} else {
+				roleArn := fmt.Sprintf("arn:aws:iam::%s:role/%s", e.accountID, e.role)
+				err = aws.AddIAMRoleProfile(e.name, roleArn, profileImportSourceProfile, "", e.region)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to add profile '%s': %w", e.name, err)
+			}
+			fmt.Printf("  + %s\n", e.name)
+			created++
+		}
+
+		if profileImportDryRun {
+			util.InfoColor.Printf("Dry run: %d profile(s) would be written, %d skipped\n", created, skipped)
+			return nil
+		}
+		aws.InvalidateProfileCache()
+		util.SuccessColor.Printf("✔ %d profile(s) written, %d skipped\n", created, skipped)
+		return nil
+	},
+}
+
+// readInventory parses the single inventory file selected by flags.
+func readInventory() ([]awsmConfig.InventoryAccount, error) {
+	var path string
+	var parse func(io.Reader) ([]awsmConfig.InventoryAccount, error)
+	sources := 0
+	if profileImportCSV != "" {
+		sources++
+		path = profileImportCSV
+		parse = awsmConfig.ParseInventoryCSV
+	}
+	if profileImportTerraform != "" {
+		sources++
+		path = profileImportTerraform
+		parse = awsmConfig.ParseTerraformState
+	}
+	if profileImportOrganizations != "" {
+		sources++
+		path = profileImportOrganizations
+		parse = awsmConfig.ParseOrganizationsExport
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("exactly one of --csv, --terraform or --organizations is required")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open inventory: %w", err)
+	}
+	defer file.Close()
+	return parse(file)
+}
+
+var profileNameCleaner = regexp.MustCompile(`[^a-zA-Z0-9-]`)
+
+// inventoryProfileName picks the profile name for an inventory entry.
+func inventoryProfileName(acc awsmConfig.InventoryAccount, role string) string {
+	if profileImportCSV != "" && acc.Name != "" {
+		return acc.Name
+	}
+	accountName := acc.Name
+	if accountName == "" {
+		accountName = acc.AccountID
+	}
+	cleanAccountName := profileNameCleaner.ReplaceAllString(strings.ToLower(accountName), "-")
+	cleanRoleName := profileNameCleaner.ReplaceAllString(strings.ToLower(role), "-")
+	return fmt.Sprintf("%s-%s", cleanAccountName, cleanRoleName)
+}
+
+func init() {
+	profileImportCmd.Flags().StringVar(&profileImportCSV, "csv", "", "CSV inventory (account_id, role, name, region)")
+	profileImportCmd.Flags().StringVar(&profileImportTerraform, "terraform", "", "Terraform state file with AWS Organizations resources")
+	profileImportCmd.Flags().StringVar(&profileImportOrganizations, "organizations", "", "Output of 'aws organizations list-accounts'")
+	profileImportCmd.Flags().StringVar(&profileImportSSOSession, "sso-session", "", "Create SSO profiles using this session")
+	profileImportCmd.Flags().StringVar(&profileImportSourceProfile, "source-profile", "", "Create assume-role profiles using this source profile")
+	profileImportCmd.Flags().StringVar(&profileImportRole, "role", "", "Role name for entries without a role")
+	profileImportCmd.Flags().StringVar(&profileImportRegion, "region", "", "Region for entries without a region")
+	profileImportCmd.Flags().BoolVarP(&profileImportForce, "force", "f", false, "Overwrite existing profiles")
+	profileImportCmd.Flags().BoolVar(&profileImportDryRun, "dry-run", false, "Show the profiles that would be written without changing anything")
+	profileCmd.AddCommand(profileImportCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var profileSecureCmd = &cobra.Command{
+	Use:   "secure <profile-name>...",
+	Short: "Move the plaintext keys of IAM user profiles into the OS keychain",
+	Long: `Moves the static access keys of IAM user profiles from ~/.aws/credentials
+into the OS keychain (macOS Keychain, Windows Credential Manager, Secret
+Service via secret-tool on Linux). The plaintext keys are removed once they
+are stored.
+
+The profiles get 'credential_process = awsm credential-process <name>', so the
+AWS CLI and SDKs keep working as long as awsm is on the PATH.
+
+Examples:
+  awsm profile secure personal
+  awsm profile secure ci-user legacy-admin`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeProfiles,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, profileName := range args {
+			if err := aws.SecureProfile(profileName); err != nil {
+				return fmt.Errorf("failed to secure profile '%s': %w", profileName, err)
+			}
+			util.SuccessColor.Printf("✔ Moved the keys of profile '%s' into the OS keychain\n", profileName)
+		}
+		if current := aws.GetCurrentProfileName(); current != "" {
+			for _, profileName := range args {
+				if profileName == current {
+					util.WarnColor.Println("The default credentials still hold a plaintext copy of the active profile's keys; run 'awsm clear' to remove it.")
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	profileCmd.AddCommand(profileSecureCmd)
+}
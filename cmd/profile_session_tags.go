@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var removeSessionTags []string
+
+var profileSessionTagsCmd = &cobra.Command{
+	Use:   "session-tags <profile-name> [key=value...]",
+	Short: "Set the STS session tags passed whenever a role profile is assumed",
+	Long: `Attaches key=value session tags to a role profile. awsm passes them to
+sts:AssumeRole every time it assumes the profile's role, so ABAC policies and
+cost attribution based on aws:PrincipalTag work without extra flags. The role's
+trust policy must allow sts:TagSession. Without tags the profile's current
+session tags are shown.
+
+Session tags are stored in awsm's own config, apart from the tags set with
+'awsm profile tag', which only organize profiles.
+
+Example:
+  awsm profile session-tags prod-admin team=platform cost-center=1234
+  awsm profile session-tags prod-admin --remove cost-center`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+		exists, err := aws.ProfileExists(profileName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("profile '%s' does not exist", profileName)
+		}
+
+		tags, err := config.LoadSessionTags()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 && len(removeSessionTags) == 0 {
+			if len(tags[profileName]) == 0 {
+				util.InfoColor.Printf("Profile '%s' has no session tags.\n", profileName)
+				return nil
+			}
+			keys := make([]string, 0, len(tags[profileName]))
+			for key := range tags[profileName] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Printf("%s=%s\n", key, tags[profileName][key])
+			}
+			return nil
+		}
+
+		for _, tag := range args[1:] {
+			key, value, err := config.ParseTag(tag)
+			if err != nil {
+				return err
+			}
+			if err := config.ValidateSessionTag(key, value); err != nil {
+				return err
+			}
+			tags.Set(profileName, key, value)
+		}
+		for _, key := range removeSessionTags {
+			tags.Remove(profileName, key)
+		}
+		if len(tags[profileName]) > config.MaxSessionTags {
+			return fmt.Errorf("STS accepts at most %d session tags, profile '%s' would have %d", config.MaxSessionTags, profileName, len(tags[profileName]))
+		}
+		if err := config.SaveSessionTags(tags); err != nil {
+			return err
+		}
+		// Cached credentials carry the old tags
+		aws.InvalidateCachedCredentials(profileName)
+
+		if formatted := tags.Format(profileName); formatted != "" {
+			util.SuccessColor.Printf("✔ Profile '%s' passes session tags: %s\n", profileName, formatted)
+		} else {
+			util.SuccessColor.Printf("✔ Profile '%s' has no session tags left\n", profileName)
+		}
+		if !aws.IsRoleProfile(profileName) {
+			util.WarnColor.Println("Session tags only apply to profiles that assume a role (role_arn).")
+		}
+		return nil
+	},
+}
+
+func init() {
+	profileSessionTagsCmd.Flags().StringSliceVar(&removeSessionTags, "remove", nil, "Remove session tags by key (repeatable)")
+	profileCmd.AddCommand(profileSessionTagsCmd)
+}
@@ -5,28 +5,49 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"strings"
+	"time"
 
 	"awsm/internal/aws"
+	"awsm/internal/config"
 	"awsm/internal/tui"
 	"awsm/internal/util"
 
 	"github.com/spf13/cobra"
 )
 
+var profileSetNoVerify bool
+
 // --- Command Definitions ---
 var profileSetCmd = &cobra.Command{
-	Use:               "set <profile>",
-	Short:             "Set credentials for a profile in the default AWS credentials file",
-	Long:              `Updates the default profile in ~/.aws/credentials with the specified profile's credentials.`,
+	Use:   "set <profile|->",
+	Short: "Set credentials for a profile in the default AWS credentials file",
+	Long: `Updates the default profile in ~/.aws/credentials with the specified profile's credentials.
+
+Use '-' as the profile to switch back to the previously used one, like 'cd -'.
+'awsm role recent' lists the recently used role profiles.
+
+Afterwards the credentials are checked with a single STS call and the account,
+role, region and expiry they resolve to are printed. Use --no-verify to skip
+the check.`,
 	Args:              cobra.ExactArgs(1),
 	RunE:              runProfileSet,
-	ValidArgsFunction: completeProfiles,
+	ValidArgsFunction: completeArgs(completeProfiles),
 }
 
 // --- Main Logic ---
 func runProfileSet(cmd *cobra.Command, args []string) error {
 	profileName := args[0]
+	if profileName == "-" {
+		previous, err := previousProfile()
+		if err != nil {
+			return err
+		}
+		util.InfoColor.Fprintf(os.Stderr, "Switching back to profile '%s'\n", previous)
+		profileName = previous
+	}
+	if err := preflight("config-files"); err != nil {
+		return err
+	}
 
 	// Get profile region first
 	region, err := aws.GetProfileRegion(profileName)
@@ -111,6 +132,8 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("failed to update credentials file")
 		}
 		fmt.Fprintln(os.Stderr, tui.SuccessStyle.Render("✓ Switched to profile '"+profileName+"' in default credentials."))
+		recordProfileUse(profileName)
+		printIdentitySummary(profileName, nil, region)
 		return nil
 	}
 
@@ -125,16 +148,82 @@ func runProfileSet(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Fprintln(os.Stderr, tui.SuccessStyle.Render("✓ Credentials for profile '"+profileName+"' are set."))
+	recordProfileUse(profileName)
+	printIdentitySummary(profileName, creds, region)
 	return nil
 }
 
-// --- Autocompletion Logic ---
-// completeProfiles provides completion for profile arguments, excluding sso-session profiles
-var completeProfiles = aws.CompleteProfilesFiltered(func(profile string) bool {
-	return !strings.HasPrefix(profile, "sso-session")
-})
+// previousProfile returns the profile used before the current one, for
+// 'awsm profile set -' and 'awsm exec -'.
+func previousProfile() (string, error) {
+	state, err := config.LoadState()
+	if err != nil {
+		return "", err
+	}
+	previous, ok := state.PreviousProfile(aws.GetCurrentProfileName())
+	if !ok {
+		return "", fmt.Errorf("no previous profile to switch back to")
+	}
+	return previous, nil
+}
+
+// recordProfileUse remembers an activated or executed profile for 'awsm role
+// recent' and 'awsm profile set -'. Failing to record it never fails the
+// command itself.
+func recordProfileUse(profileName string) {
+	state, err := config.LoadState()
+	if err == nil {
+		state.RecordProfileUse(profileName, time.Now().UTC())
+		err = config.SaveState(state)
+	}
+	if err != nil {
+		util.Warn(util.WarnStateNotSaved, "Could not record profile use: %v", err)
+	}
+}
+
+// printIdentitySummary confirms who the activated credentials belong to. A
+// failed check only warns, the profile is active either way.
+func printIdentitySummary(profileName string, creds *aws.TempCredentials, region string) {
+	if profileSetNoVerify {
+		return
+	}
+	identity, err := aws.GetIdentity(profileName, creds, region)
+	if err != nil {
+		util.Warn(util.WarnIdentityUnverified, "Could not verify the identity of profile '%s': %v", profileName, err)
+		return
+	}
+	for _, line := range identitySummary(identity, creds, region, time.Now()) {
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
+
+// identitySummary formats the compact identity lines printed after activation.
+func identitySummary(identity *aws.Identity, creds *aws.TempCredentials, region string, now time.Time) []string {
+	account := identity.Account
+	if identity.AccountAlias != "" {
+		account = fmt.Sprintf("%s (%s)", identity.Account, identity.AccountAlias)
+	}
+	lines := []string{
+		fmt.Sprintf("  Account: %s", account),
+		fmt.Sprintf("  Role:    %s", identity.Principal),
+	}
+	if region != "" {
+		lines = append(lines, fmt.Sprintf("  Region:  %s", region))
+	}
+	if creds != nil && !creds.Expires.IsZero() {
+		lines = append(lines, fmt.Sprintf("  Expires: %s (in %s)", creds.Expires.Local().Format("2006-01-02 15:04"), creds.Expires.Sub(now).Round(time.Minute)))
+	}
+	return lines
+}
+
+// addRoleDurationFlag registers --duration on a command that may assume roles.
+func addRoleDurationFlag(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&aws.RoleDuration, "duration", 0, "Session duration of assumed roles, overriding duration_seconds (15m to 12h)")
+}
 
 // --- Initialization ---
 func init() {
 	// Command will be added to profile subcommand in profile.go
+	profileSetCmd.Flags().BoolVar(&profileSetNoVerify, "no-verify", false, "Skip the STS identity check after activating the profile")
+	addRoleDurationFlag(profileSetCmd)
 }
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"awsm/internal/aws"
+)
+
+func TestIdentitySummary(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	identity := &aws.Identity{Account: "123456789012", AccountAlias: "acme-prod", Principal: "Admin"}
+	creds := &aws.TempCredentials{Expires: now.Add(59*time.Minute + 50*time.Second)}
+
+	expected := []string{
+		"  Account: 123456789012 (acme-prod)",
+		"  Role:    Admin",
+		"  Region:  eu-west-1",
+		"  Expires: 2025-01-01 12:59 (in 1h0m0s)",
+	}
+	if got := identitySummary(identity, creds, "eu-west-1", now); !slices.Equal(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	identity.AccountAlias = ""
+	expected = []string{"  Account: 123456789012", "  Role:    Admin"}
+	if got := identitySummary(identity, nil, "", now); !slices.Equal(got, expected) {
+		t.Errorf("Expected %q for static credentials, got %q", expected, got)
+	}
+}
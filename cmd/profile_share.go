@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+	"awsm/internal/share"
+	"awsm/internal/util"
+
+	"filippo.io/age"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shareRecipients     []string
+	shareIncludeSecrets bool
+	shareOutput         string
+	receiveIdentity     string
+	receiveName         string
+	receiveForce        bool
+)
+
+var profileShareCmd = &cobra.Command{
+	Use:   "share <profile-name>",
+	Short: "Export a single profile encrypted for a teammate",
+	Long: `Exports the definition of one profile (and its SSO session, if any),
+encrypted with age to one or more recipients. Recipients can be age public
+keys (age1...), SSH public keys (ssh-ed25519/ssh-rsa) or files containing one.
+
+Static access keys are never included unless --include-secrets is given and
+confirmed.
+
+Examples:
+  awsm profile share prod-admin --recipient age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p > prod-admin.age
+  awsm profile share prod-admin --recipient ~/.ssh/teammate.pub -o prod-admin.age`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+		if len(shareRecipients) == 0 {
+			return fmt.Errorf("at least one --recipient is required")
+		}
+
+		var recipients []age.Recipient
+		for _, value := range shareRecipients {
+			recipient, err := share.ParseRecipient(value)
+			if err != nil {
+				return err
+			}
+			recipients = append(recipients, recipient)
+		}
+
+		profile, err := findProfileInfo(profileName)
+		if err != nil {
+			return err
+		}
+		profile.IsActive = false
+
+		if profile.Type == aws.ProfileTypeKey && !shareIncludeSecrets {
+			return fmt.Errorf("profile '%s' only consists of static access keys, use --include-secrets to share them", profileName)
+		}
+		if shareIncludeSecrets && profile.AccessKey != "" {
+			util.WarnColor.Fprintf(os.Stderr, "The long-term access key %s will be included in the shared file.\n", profile.AccessKey)
+			confirm, err := util.PromptForInputStderr(fmt.Sprintf("Type the profile name (%s) to confirm: ", profileName))
+			if err != nil {
+				return err
+			}
+			if confirm != profileName {
+				util.InfoColor.Fprintln(os.Stderr, "Share cancelled")
+				return nil
+			}
+		} else {
+			profile.AccessKey = ""
+			profile.SecretKey = ""
+		}
+
+		bundle := &share.Bundle{
+			Version:  share.BundleVersion,
+			SharedAt: time.Now().UTC(),
+			Profile:  *profile,
+		}
+		if profile.SSOSession != "" {
+			sessions, err := aws.ListSSOSessions()
+			if err != nil {
+				return err
+			}
+			for _, s := range sessions {
+				if s.Name == profile.SSOSession {
+					session := s
+					bundle.SSOSession = &session
+					break
+				}
+			}
+		}
+
+		var out io.Writer = os.Stdout
+		if shareOutput != "" {
+			file, err := os.OpenFile(shareOutput, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %w", err)
+			}
+			defer file.Close()
+			out = file
+		}
+		if err := share.Encrypt(out, bundle, recipients...); err != nil {
+			return err
+		}
+
+		if shareOutput != "" {
+			util.SuccessColor.Fprintf(os.Stderr, "✔ Profile '%s' encrypted to %s\n", profileName, shareOutput)
+		}
+		if profile.SourceProfile != "" {
+			util.WarnColor.Fprintf(os.Stderr, "Note: the recipient needs their own '%s' source profile.\n", profile.SourceProfile)
+		}
+		return nil
+	},
+}
+
+var profileReceiveCmd = &cobra.Command{
+	Use:   "receive [file]",
+	Short: "Import a profile shared with 'awsm profile share'",
+	Long: `Decrypts a shared profile with your age identity or SSH private key and
+adds it to your AWS config. The SSO session it uses is added too if you don't
+have it yet; a session of the same name with a different start URL or region
+is an error. Reads from stdin when no file is given.
+
+Examples:
+  awsm profile receive prod-admin.age
+  awsm profile receive prod-admin.age --identity ~/.config/age/keys.txt --name prod`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		identityPath := receiveIdentity
+		if identityPath == "" {
+			var err error
+			identityPath, err = defaultSSHIdentity()
+			if err != nil {
+				return err
+			}
+		}
+		identities, err := share.LoadIdentity(identityPath, func() ([]byte, error) {
+			passphrase, err := util.PromptForSecret(fmt.Sprintf("Passphrase for %s: ", identityPath))
+			return []byte(passphrase), err
+		})
+		if err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		if len(args) > 0 {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open shared profile: %w", err)
+			}
+			defer file.Close()
+			in = file
+		}
+
+		bundle, err := share.Decrypt(in, identities...)
+		if err != nil {
+			return err
+		}
+		profile := bundle.Profile
+		if receiveName != "" {
+			profile.Name = receiveName
+		}
+
+		if !awsmConfig.IsValidProfileName(profile.Name) {
+			return fmt.Errorf("invalid profile name '%s', use --name to pick another name", profile.Name)
+		}
+
+		// Check everything that can fail before touching the config, so --force
+		// never deletes the existing profile without writing its replacement
+		importSession := false
+		if bundle.SSOSession != nil {
+			if !awsmConfig.IsValidProfileName(bundle.SSOSession.Name) {
+				return fmt.Errorf("invalid SSO session name '%s'", bundle.SSOSession.Name)
+			}
+			sessions, err := aws.ListSSOSessions()
+			if err != nil {
+				return err
+			}
+			importSession = true
+			for _, s := range sessions {
+				if s.Name != bundle.SSOSession.Name {
+					continue
+				}
+				if s.StartURL != bundle.SSOSession.StartURL || s.Region != bundle.SSOSession.Region {
+					return fmt.Errorf("SSO session '%s' already exists with a different start URL or region (%s, %s instead of %s, %s); rename yours with 'awsm sso rename-session' and receive again",
+						s.Name, s.StartURL, s.Region, bundle.SSOSession.StartURL, bundle.SSOSession.Region)
+				}
+				importSession = false
+				break
+			}
+		}
+		if !policy.Override {
+			if err := policy.Enforce(profile.PolicyProfile()); err != nil {
+				return err
+			}
+		}
+
+		if exists, err := aws.ProfileExists(profile.Name); err != nil {
+			return fmt.Errorf("failed to check if profile exists: %w", err)
+		} else if exists {
+			if !receiveForce {
+				return fmt.Errorf("profile '%s' already exists, use --name to pick another name or --force to replace it", profile.Name)
+			}
+			if err := aws.DeleteProfile(profile.Name); err != nil {
+				return fmt.Errorf("failed to replace profile '%s': %w", profile.Name, err)
+			}
+		}
+
+		if err := aws.ImportProfile(profile); err != nil {
+			return fmt.Errorf("failed to add profile '%s': %w", profile.Name, err)
+		}
+		if importSession {
+			if err := aws.ImportSSOSession(*bundle.SSOSession); err != nil {
+				return fmt.Errorf("failed to add SSO session '%s': %w", bundle.SSOSession.Name, err)
+			}
+			util.SuccessColor.Printf("✔ SSO session '%s' added\n", bundle.SSOSession.Name)
+		}
+		aws.InvalidateProfileCache()
+
+		util.SuccessColor.Printf("✔ Profile '%s' (%s) received, shared on %s\n", profile.Name, profile.Type, bundle.SharedAt.Local().Format("2006-01-02 15:04"))
+		if profile.SourceProfile != "" {
+			if exists, _ := aws.ProfileExists(profile.SourceProfile); !exists {
+				util.WarnColor.Printf("Source profile '%s' does not exist yet, create it before using this profile.\n", profile.SourceProfile)
+			}
+		}
+		return nil
+	},
+}
+
+// findProfileInfo returns the detailed information of a single profile.
+func findProfileInfo(profileName string) (*aws.ProfileInfo, error) {
+	profiles, err := aws.ListProfilesDetailed()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range profiles {
+		if p.Name == profileName {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("profile '%s' not found", profileName)
+}
+
+// defaultSSHIdentity returns the first of the usual SSH private keys that exists.
+func defaultSSHIdentity() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	for _, name := range []string{"id_ed25519", "id_rsa"} {
+		path := filepath.Join(home, ".ssh", name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no SSH key found in %s, use --identity", filepath.Join(home, ".ssh"))
+}
+
+func init() {
+	profileShareCmd.Flags().StringArrayVarP(&shareRecipients, "recipient", "r", nil, "age or SSH public key (or file containing one) to encrypt to; repeatable")
+	profileShareCmd.Flags().BoolVar(&shareIncludeSecrets, "include-secrets", false, "Include static access keys (asks for confirmation)")
+	profileShareCmd.Flags().StringVarP(&shareOutput, "output", "o", "", "Write to this file instead of stdout")
+	profileReceiveCmd.Flags().StringVarP(&receiveIdentity, "identity", "i", "", "age identity file or SSH private key (default ~/.ssh/id_ed25519 or ~/.ssh/id_rsa)")
+	profileReceiveCmd.Flags().StringVar(&receiveName, "name", "", "Save the profile under a different name")
+	profileReceiveCmd.Flags().BoolVarP(&receiveForce, "force", "f", false, "Replace an existing profile with the same name")
+	profileCmd.AddCommand(profileShareCmd)
+	profileCmd.AddCommand(profileReceiveCmd)
+}
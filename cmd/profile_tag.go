@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var removeTags []string
+
+var profileTagCmd = &cobra.Command{
+	Use:   "tag <profile-name> [key=value...]",
+	Short: "Tag a profile or show its tags",
+	Long: `Attaches key=value tags to a profile. Tags are stored in awsm's own config,
+so ~/.aws/config stays untouched, and can be used to filter and group
+'awsm profile list'. Without tags the profile's current tags are shown.
+
+Example:
+  awsm profile tag prod-admin env=prod team=platform
+  awsm profile tag prod-admin --remove team
+  awsm profile list --tag env=prod --group-by team`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profileName := args[0]
+		exists, err := aws.ProfileExists(profileName)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("profile '%s' does not exist", profileName)
+		}
+
+		tags, err := config.LoadProfileTags()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 && len(removeTags) == 0 {
+			if len(tags[profileName]) == 0 {
+				util.InfoColor.Printf("Profile '%s' has no tags.\n", profileName)
+				return nil
+			}
+			keys := make([]string, 0, len(tags[profileName]))
+			for key := range tags[profileName] {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				fmt.Printf("%s=%s\n", key, tags[profileName][key])
+			}
+			return nil
+		}
+
+		for _, tag := range args[1:] {
+			key, value, err := config.ParseTag(tag)
+			if err != nil {
+				return err
+			}
+			tags.Set(profileName, key, value)
+		}
+		for _, key := range removeTags {
+			tags.Remove(profileName, key)
+		}
+		if err := config.SaveProfileTags(tags); err != nil {
+			return err
+		}
+
+		if formatted := tags.Format(profileName); formatted != "" {
+			util.SuccessColor.Printf("✔ Profile '%s' tagged: %s\n", profileName, formatted)
+		} else {
+			util.SuccessColor.Printf("✔ Profile '%s' has no tags left\n", profileName)
+		}
+		return nil
+	},
+}
+
+// forgetProfileTags drops the tags and session tags of deleted profiles.
+func forgetProfileTags(profiles ...string) {
+	stores := []struct {
+		load func() (config.ProfileTags, error)
+		save func(config.ProfileTags) error
+	}{
+		{config.LoadProfileTags, config.SaveProfileTags},
+		{config.LoadSessionTags, config.SaveSessionTags},
+	}
+	for _, store := range stores {
+		tags, err := store.load()
+		if err != nil {
+			continue
+		}
+		changed := false
+		for _, profile := range profiles {
+			if _, ok := tags[profile]; ok {
+				delete(tags, profile)
+				changed = true
+			}
+		}
+		if changed {
+			if err := store.save(tags); err != nil {
+				util.Warn(util.WarnStateNotSaved, "failed to remove the tags of deleted profiles: %v", err)
+			}
+		}
+	}
+}
+
+func init() {
+	profileTagCmd.Flags().StringSliceVar(&removeTags, "remove", nil, "Remove tags by key (repeatable)")
+	profileCmd.AddCommand(profileTagCmd)
+}
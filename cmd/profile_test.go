@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"awsm/internal/aws"
+)
+
+func TestPaginate(t *testing.T) {
+	profiles := make([]aws.ProfileInfo, 5)
+	for i := range profiles {
+		profiles[i].Name = string(rune('a' + i))
+	}
+
+	tests := []struct {
+		limit, page int
+		want        string
+		wantPages   int
+	}{
+		{0, 1, "abcde", 1},
+		{2, 1, "ab", 3},
+		{2, 3, "e", 3},
+		{2, 4, "", 3},
+		{5, 1, "abcde", 1},
+	}
+	for _, tt := range tests {
+		page, pages := paginate(profiles, tt.limit, tt.page)
+		got := ""
+		for _, p := range page {
+			got += p.Name
+		}
+		if got != tt.want || pages != tt.wantPages {
+			t.Errorf("paginate(limit %d, page %d) = %q, %d pages; want %q, %d pages", tt.limit, tt.page, got, pages, tt.want, tt.wantPages)
+		}
+	}
+}
+
+func TestFormatProfileCounts(t *testing.T) {
+	var counts aws.ProfileCounts
+	counts.Add(aws.ProfileTypeSSO, "us-east-1")
+	counts.Add(aws.ProfileTypeSSO, "eu-west-1")
+	counts.Add(aws.ProfileTypeSSO, "us-east-1")
+	counts.Add(aws.ProfileTypeKey, "")
+
+	want := "4 profile(s) (SSO: 3, Key: 1) · us-east-1: 2, eu-west-1: 1"
+	if got := formatProfileCounts(counts); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got := formatProfileCounts(aws.ProfileCounts{}); got != "0 profile(s)" {
+		t.Errorf("Expected 0 profile(s), got %q", got)
+	}
+}
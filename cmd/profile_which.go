@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var profileWhichCmd = &cobra.Command{
+	Use:   "which [command...]",
+	Short: "Explain which credentials the AWS CLI/SDKs would use",
+	Long: `Walks the default credential chain used by the AWS CLI and SDKs for the
+current environment (--profile flag, environment variables, AWS_PROFILE,
+web identity, the default profile, container and instance credentials) and
+shows which step provides the credentials, where it is defined and whether
+awsm wrote it.
+
+If a command is given, a --profile flag in it is taken into account.
+
+Examples:
+  awsm profile which
+  awsm profile which aws s3 ls --profile prod`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		explicitProfile := profileFromArgs(args)
+		steps := aws.ExplainCredentialResolution(explicitProfile)
+
+		if len(args) > 0 {
+			util.InfoColor.Printf("Credential resolution for: %s\n\n", util.BoldColor.Sprint(strings.Join(args, " ")))
+		} else {
+			util.InfoColor.Println("Credential resolution for the current environment:")
+			fmt.Println()
+		}
+
+		for i, step := range steps {
+			switch {
+			case step.Selected:
+				util.SuccessColor.Printf("%d. ▶ %s\n", i+1, step.Source)
+				fmt.Printf("     %s\n", step.Detail)
+			case step.Reached:
+				fmt.Printf("%d.   %s: %s\n", i+1, step.Source, step.Detail)
+			default:
+				fmt.Printf("%d.   %s\n", i+1, util.WarnColor.Sprintf("%s (not consulted)", step.Source))
+			}
+		}
+		return nil
+	},
+}
+
+// profileFromArgs returns the value of a --profile flag inside a wrapped command line.
+func profileFromArgs(args []string) string {
+	for i, arg := range args {
+		if value, ok := strings.CutPrefix(arg, "--profile="); ok {
+			return value
+		}
+		if arg == "--profile" && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func init() {
+	// Everything after the first argument belongs to the explained command
+	profileWhichCmd.Flags().SetInterspersed(false)
+	profileCmd.AddCommand(profileWhichCmd)
+}
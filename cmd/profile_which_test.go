@@ -0,0 +1,22 @@
+package cmd
+
+import "testing"
+
+func TestProfileFromArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"aws", "s3", "ls"}, ""},
+		{[]string{"aws", "s3", "ls", "--profile", "prod"}, "prod"},
+		{[]string{"aws", "--profile=dev", "sts", "get-caller-identity"}, "dev"},
+		{[]string{"aws", "--profile"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := profileFromArgs(tt.args); got != tt.expected {
+			t.Errorf("profileFromArgs(%v) = %q, expected %q", tt.args, got, tt.expected)
+		}
+	}
+}
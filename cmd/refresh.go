@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+// refreshExitStillValid is the exit code of 'awsm refresh' when nothing had to
+// be refreshed, so scripts can branch without parsing output. Refreshing exits
+// with 0 and failures with 1 like every other command.
+const refreshExitStillValid = 2
+
+// refreshCacheMinValidity is how long cached credentials must stay valid for
+// a refresh to reuse them instead of getting new ones.
+const refreshCacheMinValidity = 15 * time.Minute
+
+var (
+	refreshIfExpiringWithin time.Duration
+	refreshForce            bool
+	refreshAll              bool
+	refreshSSOSession       string
+	refreshTags             []string
+)
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the credentials of the active profile",
+	Long: `Gets new credentials for the profile currently set in the default credentials,
+like 'awsm profile set' with the same profile.
+
+With --if-expiring-within, the credentials are only refreshed when they expire
+within the given window, based on the expiry recorded when they were set. This
+is cheap enough for cron jobs and shell hooks. Long-term keys never expire;
+temporary credentials with an unknown expiry are always refreshed.
+
+Credentials in awsm's cache that stay valid for at least 15 minutes (or the
+--if-expiring-within window) are reused without calling AWS; --force always
+gets new ones.
+
+With --all, every profile of the active profile's SSO session (or of
+--sso-session) is refreshed into awsm's cache, role profiles chained on top of
+it included; with --tag, every profile carrying the tag, e.g. profiles tagged
+pinned with 'awsm profile tag prod pinned=true'. The SSO login happens at most
+once, so this is the thing to run before going offline or into a demo. The
+active profile among them is also set again in the default credentials.
+
+Exit codes:
+  0  credentials were refreshed
+  1  refreshing failed
+  2  credentials are still valid, nothing was done
+
+Examples:
+  awsm refresh --if-expiring-within 10m
+  awsm refresh --all
+  awsm refresh --all --sso-session company
+  awsm refresh --tag pinned`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if refreshAll || refreshSSOSession != "" || len(refreshTags) > 0 {
+			return runRefreshMany(cmd)
+		}
+
+		profile := aws.GetCurrentProfileName()
+		if profile == "" {
+			return fmt.Errorf("no active profile to refresh, run 'awsm profile set <profile-name>' first")
+		}
+
+		if refreshIfExpiringWithin > 0 {
+			expires, known := aws.DefaultCredentialsExpiry()
+			if reason, valid := stillValid(expires, known, refreshIfExpiringWithin, time.Now()); valid {
+				util.InfoColor.Fprintf(os.Stderr, "Credentials for profile '%s' %s.\n", profile, reason)
+				os.Exit(refreshExitStillValid)
+			}
+		}
+
+		// Cached credentials are reused unless they are the expiring ones
+		window := max(refreshIfExpiringWithin, refreshCacheMinValidity)
+		if refreshForce || aws.GetCachedCredentials(profile, window) == nil {
+			aws.InvalidateCachedCredentials(profile)
+		}
+		// Refreshes usually run unattended, skip the identity check
+		profileSetNoVerify = true
+		return runProfileSet(cmd, []string{profile})
+	},
+}
+
+// runRefreshMany refreshes the cached credentials of every profile selected by
+// --all, --sso-session or --tag, and the default credentials when the active
+// profile is one of them.
+func runRefreshMany(cmd *cobra.Command) error {
+	if refreshAll && len(refreshTags) > 0 {
+		return fmt.Errorf("--all and --tag can't be combined")
+	}
+	profiles, err := refreshSelection()
+	if err != nil {
+		return err
+	}
+
+	window := max(refreshIfExpiringWithin, refreshCacheMinValidity)
+	active := aws.GetCurrentProfileName()
+	refreshed, valid, failed := 0, 0, 0
+	refreshActive := false
+	for _, p := range profiles {
+		if p.Type == aws.ProfileTypeKey {
+			util.InfoColor.Fprintf(os.Stderr, "- %s: long-term keys, nothing to refresh\n", p.Name)
+			valid++
+			continue
+		}
+		if !refreshForce {
+			if cached := aws.GetCachedCredentials(p.Name, window); cached != nil {
+				util.InfoColor.Fprintf(os.Stderr, "- %s: still valid for %s\n", p.Name, time.Until(cached.Expires).Round(time.Minute))
+				valid++
+				continue
+			}
+		}
+		aws.InvalidateCachedCredentials(p.Name)
+		creds, err := getCredentialsWithLogin(p.Name)
+		if err != nil {
+			util.ErrorColor.Fprintf(os.Stderr, "✘ %s: %v\n", p.Name, err)
+			failed++
+			continue
+		}
+		aws.CacheCredentials(p.Name, creds)
+		util.SuccessColor.Fprintf(os.Stderr, "✔ %s: valid until %s\n", p.Name, creds.Expires.Local().Format("15:04"))
+		refreshed++
+		refreshActive = refreshActive || p.Name == active
+	}
+
+	if refreshActive {
+		// The default credentials come from the fresh cache entry
+		profileSetNoVerify = true
+		if err := runProfileSet(cmd, []string{active}); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%d refreshed, %d still valid, %d failed\n", refreshed, valid, failed)
+	switch {
+	case failed > 0:
+		return fmt.Errorf("failed to refresh %d profiles", failed)
+	case refreshed == 0:
+		os.Exit(refreshExitStillValid)
+	}
+	return nil
+}
+
+// refreshSelection returns the profiles 'awsm refresh' works on for --tag, or
+// for --all and --sso-session.
+func refreshSelection() ([]aws.ProfileInfo, error) {
+	profiles, err := aws.ListProfilesDetailed()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []aws.ProfileInfo
+	if len(refreshTags) > 0 {
+		tags, err := awsmConfig.LoadProfileTags()
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range profiles {
+			if tags.Matches(p.Name, refreshTags) {
+				selected = append(selected, p)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("no profiles tagged %s", strings.Join(refreshTags, ", "))
+		}
+		return selected, nil
+	}
+
+	session := refreshSSOSession
+	if session == "" {
+		active := aws.GetCurrentProfileName()
+		if active == "" {
+			return nil, fmt.Errorf("no active profile, name the session with --sso-session")
+		}
+		if session, err = aws.GetSsoSessionForProfile(active); err != nil || session == "" {
+			return nil, fmt.Errorf("profile '%s' doesn't use an SSO session, name one with --sso-session or select profiles with --tag", active)
+		}
+	}
+	for _, p := range profiles {
+		// Role profiles chained on an SSO profile belong to its session too
+		if s, err := aws.GetSsoSessionForProfile(p.Name); err == nil && s == session {
+			selected = append(selected, p)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no profiles use SSO session '%s'", session)
+	}
+	return selected, nil
+}
+
+// stillValid reports whether credentials expiring at expires outlast window,
+// with a reason to print. Unknown expiries are never considered valid.
+func stillValid(expires time.Time, known bool, window time.Duration, now time.Time) (string, bool) {
+	if !known {
+		return "", false
+	}
+	if expires.IsZero() {
+		return "are long-term keys and never expire", true
+	}
+	remaining := expires.Sub(now)
+	if remaining <= window {
+		return "", false
+	}
+	return fmt.Sprintf("are still valid for %s", remaining.Round(time.Minute)), true
+}
+
+func init() {
+	refreshCmd.Flags().DurationVar(&refreshIfExpiringWithin, "if-expiring-within", 0, "Only refresh when the credentials expire within this window (e.g. 10m)")
+	refreshCmd.Flags().BoolVar(&refreshForce, "force", false, "Get new credentials even if cached ones are still valid")
+	refreshCmd.Flags().BoolVarP(&refreshAll, "all", "a", false, "Refresh every profile of the active profile's SSO session")
+	refreshCmd.Flags().StringVar(&refreshSSOSession, "sso-session", "", "With --all, refresh the profiles of this SSO session instead")
+	refreshCmd.Flags().StringArrayVar(&refreshTags, "tag", nil, "Refresh every profile with this tag, as key=value or just key (repeatable, all must match)")
+	refreshCmd.RegisterFlagCompletionFunc("tag", completeTagFilters)
+	addRoleDurationFlag(refreshCmd)
+	rootCmd.AddCommand(refreshCmd)
+}
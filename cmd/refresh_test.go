@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func TestStillValid(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		expires time.Time
+		known   bool
+		want    bool
+	}{
+		{"unknown expiry", time.Time{}, false, false},
+		{"long-term keys", time.Time{}, true, true},
+		{"outlasts window", now.Add(time.Hour), true, true},
+		{"expires within window", now.Add(5 * time.Minute), true, false},
+		{"expired", now.Add(-time.Minute), true, false},
+	}
+	for _, tt := range tests {
+		if _, got := stillValid(tt.expires, tt.known, 10*time.Minute, now); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRefreshSelection(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(home, ".awsm"))
+	configPath := filepath.Join(home, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, "credentials"))
+	config := `[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-west-1
+
+[sso-session lab]
+sso_start_url = https://lab.awsapps.com/start
+sso_region = eu-west-1
+
+[profile prod]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile prod-deploy]
+source_profile = prod
+role_arn = arn:aws:iam::111111111111:role/Deploy
+
+[profile sandbox]
+sso_session = lab
+sso_account_id = 222222222222
+sso_role_name = Admin
+`
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := awsmConfig.SaveProfileTags(awsmConfig.ProfileTags{"sandbox": {"pinned": "true"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	names := func() []string {
+		t.Helper()
+		profiles, err := refreshSelection()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, p := range profiles {
+			names = append(names, p.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	refreshSSOSession, refreshTags = "corp", nil
+	defer func() { refreshSSOSession, refreshTags = "", nil }()
+	if got, want := names(), []string{"prod", "prod-deploy"}; !slices.Equal(got, want) {
+		t.Errorf("session corp selected %v, want %v", got, want)
+	}
+
+	refreshSSOSession, refreshTags = "", []string{"pinned=true"}
+	if got, want := names(), []string{"sandbox"}; !slices.Equal(got, want) {
+		t.Errorf("tag pinned selected %v, want %v", got, want)
+	}
+
+	refreshTags = nil
+	if _, err := refreshSelection(); err == nil {
+		t.Error("expected an error without an active profile or session")
+	}
+}
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"awsm/internal/aws"
+	"awsm/internal/output"
 	"awsm/internal/tui"
 	"fmt"
+	"io"
 
 	"github.com/spf13/cobra"
 )
@@ -21,16 +23,34 @@ var regionListCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		regions := aws.GetAllRegions()
 
-		if len(regions) == 0 {
-			fmt.Fprintln(cmd.ErrOrStderr(), tui.WarningStyle.Render("⚠ No regions found."))
-			return nil
+		type regionOutput struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
 		}
-
-		fmt.Fprintln(cmd.OutOrStdout(), tui.HeaderStyle.Render("\n🌍 Available AWS Regions:"))
-		for _, region := range regions {
-			fmt.Printf("  %s %s\n", tui.InfoStyle.Render("•"), region)
+		data := make([]regionOutput, len(regions))
+		for i, region := range regions {
+			data[i] = regionOutput{region, aws.RegionDescription(region)}
 		}
-		return nil
+		return output.Render(output.Selected, output.Result{
+			Data: data,
+			Table: func() error {
+				if len(regions) == 0 {
+					fmt.Fprintln(cmd.ErrOrStderr(), tui.WarningStyle.Render("⚠ No regions found."))
+					return nil
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), tui.HeaderStyle.Render("\n🌍 Available AWS Regions:"))
+				for _, region := range regions {
+					fmt.Printf("  %s %-16s %s\n", tui.InfoStyle.Render("•"), region, tui.MutedStyle.Render(aws.RegionDescription(region)))
+				}
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, region := range regions {
+					fmt.Fprintln(w, region)
+				}
+				return nil
+			},
+		})
 	},
 }
 
@@ -38,7 +58,7 @@ var regionSetCmd = &cobra.Command{
 	Use:               "set <region>",
 	Short:             "Set the region for the default profile",
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeRegions,
+	ValidArgsFunction: completeArgs(completeRegions),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		region := args[0]
 
@@ -56,15 +76,6 @@ var regionSetCmd = &cobra.Command{
 	},
 }
 
-func completeRegions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if len(args) != 0 {
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
-
-	regions := aws.GetAllRegions()
-	return regions, cobra.ShellCompDirectiveNoFileComp
-}
-
 func init() {
 	regionCmd.AddCommand(regionListCmd)
 	regionCmd.AddCommand(regionSetCmd)
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/output"
+	"awsm/internal/tui"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+// regionLatencyTimeout bounds how long 'awsm regions --latency' waits for a region.
+const regionLatencyTimeout = 3 * time.Second
+
+var (
+	regionsProfile  string
+	regionsLatency  bool
+	regionsSelect   bool
+	regionsNoStatus bool
+)
+
+var regionsCmd = &cobra.Command{
+	Use:   "regions",
+	Short: "List AWS regions with their status and latency, and pick one",
+	Long: `Lists the AWS regions with their location and whether the account of the
+profile has them enabled, as reported by EC2. Without credentials the status is
+left out. --latency times a connection to every regional endpoint and sorts
+the regions from the closest.
+
+With --select the regions are shown in a fuzzy finder, and the chosen one
+becomes the region of the profile given with --profile, or of the default
+profile.
+
+Examples:
+  awsm regions
+  awsm regions --latency
+  awsm regions --select --latency
+  awsm regions --profile prod --select`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var status map[string]string
+		if !regionsNoStatus {
+			var err error
+			status, err = aws.RegionOptInStatus(regionsProfile)
+			if err != nil {
+				util.WarnColor.Fprintf(os.Stderr, "Could not look up which regions are enabled: %v\n", err)
+			}
+		}
+		regions := aws.ListRegionInfo(status)
+		if regionsLatency {
+			util.InfoColor.Fprintf(os.Stderr, "Measuring latency to %d regions...\n", len(regions))
+			aws.MeasureRegionLatency(regions, regionLatencyTimeout)
+			aws.SortRegionsByLatency(regions)
+		}
+
+		if regionsSelect {
+			if !isatty.IsTerminal(os.Stdin.Fd()) {
+				return fmt.Errorf("--select needs an interactive terminal")
+			}
+			return selectAndSetRegion(regions)
+		}
+
+		current := currentRegionOf(regionsProfile)
+		return output.Render(output.Selected, output.Result{
+			Data: regionsForOutput(regions, current),
+			Table: func() error {
+				printRegions(regions, current)
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				for _, r := range regions {
+					fmt.Fprintln(w, r.Name)
+				}
+				return nil
+			},
+		})
+	},
+}
+
+// regionOutput is how --output json and yaml print a region.
+type regionOutput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	OptInStatus string `json:"opt_in_status,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	LatencyMs   *int64 `json:"latency_ms,omitempty"`
+	Current     bool   `json:"current"`
+}
+
+func regionsForOutput(regions []aws.RegionInfo, current string) []regionOutput {
+	out := make([]regionOutput, len(regions))
+	for i, r := range regions {
+		out[i] = regionOutput{Name: r.Name, Description: r.Description, OptInStatus: r.OptInStatus, Enabled: r.Enabled(), Current: r.Name == current}
+		if r.Latency > 0 {
+			latency := r.Latency.Milliseconds()
+			out[i].LatencyMs = &latency
+		}
+	}
+	return out
+}
+
+func printRegions(regions []aws.RegionInfo, current string) {
+	for _, r := range regions {
+		marker := " "
+		if r.Name == current {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s %-16s %-28s", marker, r.Name, r.Description)
+		switch {
+		case r.Name == current:
+			util.SuccessColor.Print(line)
+		case !r.Enabled():
+			util.WarnColor.Print(line)
+		default:
+			fmt.Print(line)
+		}
+		fmt.Printf(" %-13s", regionStatusLabel(r))
+		if regionsLatency {
+			if r.Latency > 0 {
+				fmt.Printf(" %6s", tui.FormatLatency(r.Latency))
+			} else {
+				fmt.Printf(" %6s", "-")
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// regionStatusLabel describes whether the account can use a region.
+func regionStatusLabel(r aws.RegionInfo) string {
+	switch r.OptInStatus {
+	case aws.RegionOptInNotRequired:
+		return "enabled"
+	case aws.RegionOptedIn:
+		return "opted in"
+	case aws.RegionNotOptedIn:
+		return "not enabled"
+	default:
+		return ""
+	}
+}
+
+// currentRegionOf returns the region of a profile, or of the default profile.
+func currentRegionOf(profile string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	region, _ := aws.GetProfileRegion(profile)
+	return region
+}
+
+func selectAndSetRegion(regions []aws.RegionInfo) error {
+	region, err := tui.SelectRegion(regions)
+	if err != nil {
+		return err
+	}
+	for _, r := range regions {
+		if r.Name == region && !r.Enabled() {
+			util.WarnColor.Printf("⚠ %s is not enabled for this account; enable it in the account settings before using it\n", region)
+		}
+	}
+
+	if regionsProfile != "" {
+		if err := aws.ChangeProfileRegion(regionsProfile, region); err != nil {
+			return fmt.Errorf("failed to set region: %w", err)
+		}
+		util.SuccessColor.Printf("✔ Region of profile '%s' set to '%s'\n", regionsProfile, region)
+		return nil
+	}
+	if err := aws.SetRegion(region); err != nil {
+		return fmt.Errorf("failed to set region: %w", err)
+	}
+	util.SuccessColor.Printf("✔ Region set to '%s' in default profile\n", region)
+	return nil
+}
+
+func init() {
+	regionsCmd.Flags().StringVarP(&regionsProfile, "profile", "p", "", "Profile whose account status is shown and whose region --select sets")
+	regionsCmd.Flags().BoolVarP(&regionsLatency, "latency", "l", false, "Measure the latency to every region and sort by it")
+	regionsCmd.Flags().BoolVarP(&regionsSelect, "select", "s", false, "Pick a region interactively and set it")
+	regionsCmd.Flags().BoolVar(&regionsNoStatus, "no-status", false, "Don't ask AWS which regions are enabled")
+	rootCmd.AddCommand(regionsCmd)
+}
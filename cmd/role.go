@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var roleCmd = &cobra.Command{
+	Use:   "role",
+	Short: "Work with the roles your profiles assume",
+}
+
+var roleRecentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "List recently assumed role profiles",
+	Long: `Lists the role profiles activated most recently with 'awsm profile set',
+newest first, with the account and role each one assumes. The current profile
+is marked with '*'.
+
+Use 'awsm profile set -' to switch back to the previously used profile.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := config.LoadState()
+		if err != nil {
+			return err
+		}
+
+		current := aws.GetCurrentProfileName()
+		now := time.Now()
+		shown := 0
+		for _, recent := range state.RecentProfiles {
+			settings, err := aws.EffectiveProfileSettings(recent.Name)
+			if err != nil || settings["awsm:role"] == "" {
+				// Deleted profiles and profiles that don't assume a role
+				continue
+			}
+			marker := " "
+			if recent.Name == current {
+				marker = "*"
+			}
+			fmt.Printf("%s %s  %s  %s\n", marker, util.BoldColor.Sprint(recent.Name),
+				roleDescription(settings["awsm:account"], settings["awsm:role"]),
+				util.InfoColor.Sprint(usedAgo(now.Sub(recent.UsedAt))))
+			shown++
+		}
+		if shown == 0 {
+			util.WarnColor.Fprintln(os.Stderr, "No role profiles used yet. Activate one with 'awsm profile set <profile>'.")
+		}
+		return nil
+	},
+}
+
+// roleDescription formats the account and role a profile assumes.
+func roleDescription(account, role string) string {
+	if account == "" {
+		return role
+	}
+	return account + "/" + role
+}
+
+// usedAgo formats how long ago a profile was used.
+func usedAgo(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func init() {
+	roleCmd.AddCommand(roleRecentCmd)
+	rootCmd.AddCommand(roleCmd)
+}
@@ -11,6 +11,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strconv"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/output"
+	"awsm/internal/policy"
+	"awsm/internal/util"
 
 	"github.com/spf13/cobra"
 )
@@ -19,6 +26,13 @@ var (
 	version string
 	commit  string
 	date    string
+
+	configDir  string
+	isolateAWS bool
+
+	// contextErr is set when the active context can't be applied; only the
+	// context commands run then, so the wrong files are never touched.
+	contextErr error
 )
 
 var rootCmd = &cobra.Command{
@@ -27,9 +41,60 @@ var rootCmd = &cobra.Command{
 	Long:         `AWSM (AWS Manager) is a tool to simplify switching between AWS profiles, managing regions, and assuming roles with MFA.`,
 	Version:      version,
 	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if contextErr != nil && !isContextCommand(cmd) {
+			return contextErr
+		}
+		maybeRunFirstSecurityReview(cmd)
+		maybeCompactCache(cmd)
+		return nil
+	},
+}
+
+func init() {
+	cobra.OnInitialize(initAwsmHome)
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "Keep awsm config, state and cache in this directory (default $"+awsmConfig.HomeEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&isolateAWS, "isolate-aws", false, "Also keep the AWS config, credentials and SSO cache in the config dir (default $"+awsmConfig.IsolateAWSEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&aws.SSONoBrowser, "no-browser", false, "Print the SSO login URL and code instead of opening a browser")
+	rootCmd.PersistentFlags().Var(&output.Selected, "output", "Output format of listings and reports: table, json, yaml or raw")
+	rootCmd.RegisterFlagCompletionFunc("output", completeOutputFormats)
+	rootCmd.PersistentFlags().BoolVar(&policy.Override, "override-policy", false, "Write profiles even if they violate the awsm policy (the override is logged)")
+}
+
+// initAwsmHome applies --config-dir/AWSM_HOME before anything reads the awsm
+// config, so sandboxed runs never see the user's own files.
+func initAwsmHome() {
+	dir := configDir
+	if dir == "" {
+		dir = os.Getenv(awsmConfig.HomeEnv)
+	}
+	isolate := isolateAWS
+	if value := os.Getenv(awsmConfig.IsolateAWSEnv); value != "" && !isolate {
+		isolate, _ = strconv.ParseBool(value)
+	}
+
+	if dir == "" && isolate {
+		util.ErrorColor.Fprintf(os.Stderr, "Error: --isolate-aws needs --config-dir or %s\n", awsmConfig.HomeEnv)
+		os.Exit(1)
+	}
+	if dir != "" {
+		if err := awsmConfig.SetHome(dir, isolate); err != nil {
+			util.ErrorColor.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	awsmConfig.InitConfig()
+	util.SuppressWarnings(awsmConfig.GetSuppressedWarnings())
+	// Isolated runs have their own AWS files
+	if !isolate {
+		contextErr = awsmConfig.ApplyActiveContext()
+	}
 }
 
 func Execute() {
+	rootCmd.SetArgs(applyAliases(os.Args[1:]))
+	setupCompletionCmd()
+	registerCompletions(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
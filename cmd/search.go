@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"awsm/internal/aws"
+	"awsm/internal/output"
 	"awsm/internal/tui"
 
 	"github.com/spf13/cobra"
@@ -34,8 +36,9 @@ Examples:
   awsm search --account 123456789    # Search only account IDs
   awsm search --profile prod         # Search only profile names
   awsm search --sso my-session       # Search only SSO sessions`,
-	Args: cobra.ExactArgs(1),
-	RunE: runSearch,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeSearchTerms),
+	RunE:              runSearch,
 }
 
 func runSearch(cmd *cobra.Command, args []string) error {
@@ -104,26 +107,39 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if len(results) == 0 {
-		fmt.Fprintf(os.Stderr, "No results found for query: %s\n", query)
-		return nil
+	if results == nil {
+		results = []SearchResult{}
 	}
-
-	displayResults(results, query)
-	return nil
+	return output.Render(output.Selected, output.Result{
+		Data: results,
+		Table: func() error {
+			if len(results) == 0 {
+				fmt.Fprintf(os.Stderr, "No results found for query: %s\n", query)
+				return nil
+			}
+			displayResults(results, query)
+			return nil
+		},
+		Raw: func(w io.Writer) error {
+			for _, result := range results {
+				fmt.Fprintln(w, result.Name)
+			}
+			return nil
+		},
+	})
 }
 
 type SearchResult struct {
-	Type          string
-	Name          string
-	AccountID     string
-	Region        string
-	ProfileType   string
-	SSOSession    string
-	RoleARN       string
-	SourceProfile string
-	StartURL      string
-	SSORoleName   string
+	Type          string `json:"type"`
+	Name          string `json:"name"`
+	AccountID     string `json:"account_id,omitempty"`
+	Region        string `json:"region,omitempty"`
+	ProfileType   string `json:"profile_type,omitempty"`
+	SSOSession    string `json:"sso_session,omitempty"`
+	RoleARN       string `json:"role_arn,omitempty"`
+	SourceProfile string `json:"source_profile,omitempty"`
+	StartURL      string `json:"start_url,omitempty"`
+	SSORoleName   string `json:"sso_role_name,omitempty"`
 }
 
 func matchesQuery(text, query string) bool {
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var securityCmd = &cobra.Command{
+	Use:   "security",
+	Short: "Review local credential security",
+}
+
+var securityReviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Check credential files for common security issues and offer fixes",
+	Long: `Checks the AWS credentials file and awsm's cache for common issues:
+file permissions, long-term access keys stored in plaintext and leftover
+backups of the credentials file. Each fixable issue can be resolved with a
+single keystroke; fixes that delete files are only applied after an explicit yes.
+
+The review also runs once automatically the first time awsm is used
+interactively.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSecurityReview()
+	},
+}
+
+// runSecurityReview prints the findings, offers fixes and records that the review ran.
+func runSecurityReview() error {
+	findings, err := aws.ReviewSecurity()
+	if err != nil {
+		return fmt.Errorf("failed to run security review: %w", err)
+	}
+
+	if len(findings) == 0 {
+		util.SuccessColor.Println("✔ No security issues found.")
+	} else {
+		util.WarnColor.Printf("Security review found %d issue(s):\n\n", len(findings))
+		for _, f := range findings {
+			util.WarnColor.Printf("● %s\n", f.Title)
+			for _, line := range strings.Split(f.Detail, "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+			if f.Fix == nil {
+				fmt.Println()
+				continue
+			}
+			answer, err := util.PromptForInput(fmt.Sprintf("  %s? %s: ", f.FixDescription, fixPromptChoices(f)))
+			if err != nil {
+				return err
+			}
+			if acceptsFix(f, answer) {
+				if err := f.Fix(); err != nil {
+					util.ErrorColor.Printf("  Fix failed: %v\n", err)
+				} else {
+					util.SuccessColor.Println("  ✔ Fixed")
+				}
+			}
+			fmt.Println()
+		}
+	}
+
+	state, err := config.LoadState()
+	if err != nil {
+		return err
+	}
+	state.SecurityReviewAt = time.Now().UTC()
+	return config.SaveState(state)
+}
+
+// fixPromptChoices shows which answer applies a fix on enter: destructive fixes default to no.
+func fixPromptChoices(f aws.SecurityFinding) string {
+	if f.Destructive {
+		return "[y/N]"
+	}
+	return "[Y/n]"
+}
+
+// acceptsFix reports whether the answer to a fix prompt applies the fix.
+func acceptsFix(f aws.SecurityFinding, answer string) bool {
+	if answer == "" {
+		return !f.Destructive
+	}
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+}
+
+// maybeRunFirstSecurityReview runs the security review once, on the first
+// interactive invocation of awsm. Failures never block the actual command.
+func maybeRunFirstSecurityReview(cmd *cobra.Command) {
+	if cmd == securityReviewCmd || cmd.Name() == "completion" || strings.HasPrefix(cmd.Name(), "__") {
+		return
+	}
+	if !isatty.IsTerminal(os.Stdin.Fd()) || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return
+	}
+	state, err := config.LoadState()
+	if err != nil || !state.SecurityReviewAt.IsZero() {
+		return
+	}
+
+	util.InfoColor.Println("Running a one-time security review of your AWS credential files...")
+	if err := runSecurityReview(); err != nil {
+		util.Warn(util.WarnSecurityReviewSkipped, "Security review skipped: %v", err)
+	}
+}
+
+func init() {
+	securityCmd.AddCommand(securityReviewCmd)
+	rootCmd.AddCommand(securityCmd)
+}
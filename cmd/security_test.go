@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"testing"
+
+	"awsm/internal/aws"
+)
+
+func TestAcceptsFix(t *testing.T) {
+	safe := aws.SecurityFinding{FixDescription: "Restrict permissions"}
+	destructive := aws.SecurityFinding{FixDescription: "Delete the backup files", Destructive: true}
+
+	tests := []struct {
+		name     string
+		finding  aws.SecurityFinding
+		answer   string
+		expected bool
+	}{
+		{"Enter applies safe fix", safe, "", true},
+		{"No skips safe fix", safe, "n", false},
+		{"Enter skips destructive fix", destructive, "", false},
+		{"Yes applies destructive fix", destructive, "y", true},
+		{"Full yes applies destructive fix", destructive, "YES", true},
+		{"Anything else skips destructive fix", destructive, "sure", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := acceptsFix(tt.finding, tt.answer); got != tt.expected {
+				t.Errorf("acceptsFix(%q) = %v, want %v", tt.answer, got, tt.expected)
+			}
+		})
+	}
+	if fixPromptChoices(destructive) != "[y/N]" || fixPromptChoices(safe) != "[Y/n]" {
+		t.Error("Expected destructive fixes to default to no in the prompt")
+	}
+}
@@ -9,26 +9,44 @@ import (
 )
 
 var selectCmd = &cobra.Command{
-	Use:     "select",
-	Short:   "Interactively select and export an AWS profile",
-	Long:    `Opens an interactive profile selector to choose and export AWS credentials.`,
-	Aliases: []string{"s"},
-	RunE: func(cmd *cobra.Command, args []string) error {
-		profileName, err := tui.SelectProfile()
-		if err != nil {
-			return err
-		}
-
-		if profileName == "" {
-			fmt.Println(tui.MutedStyle.Render("No profile selected."))
-			return nil
-		}
-
-		// Export the selected profile
-		return runProfileSet(cmd, []string{profileName})
-	},
+	Use:   "select",
+	Short: "Interactively select and export an AWS profile",
+	Long: `Opens an interactive profile selector to choose and export AWS credentials.
+
+Every profile is listed with its type, region, account and role, the current
+and recently used profiles first. Press / to fuzzy search by name, account,
+role, region or SSO session; enter activates the profile like 'awsm profile set'.`,
+	Aliases: []string{"s", "switch"},
+	RunE:    runSelect,
+}
+
+// profileSwitchCmd is 'awsm select' under the profile command.
+var profileSwitchCmd = &cobra.Command{
+	Use:   "switch",
+	Short: "Interactively select and activate a profile",
+	Long:  selectCmd.Long,
+	Args:  cobra.NoArgs,
+	RunE:  runSelect,
+}
+
+func runSelect(cmd *cobra.Command, args []string) error {
+	profileName, err := tui.SelectProfile()
+	if err != nil {
+		return err
+	}
+
+	if profileName == "" {
+		fmt.Println(tui.MutedStyle.Render("No profile selected."))
+		return nil
+	}
+
+	// Export the selected profile
+	return runProfileSet(cmd, []string{profileName})
 }
 
 func init() {
+	selectCmd.Flags().BoolVar(&profileSetNoVerify, "no-verify", false, "Skip the STS identity check after activating the profile")
+	profileSwitchCmd.Flags().BoolVar(&profileSetNoVerify, "no-verify", false, "Skip the STS identity check after activating the profile")
+	profileCmd.AddCommand(profileSwitchCmd)
 	rootCmd.AddCommand(selectCmd)
 }
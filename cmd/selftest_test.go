@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSelftest(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+
+	if err := selftestCmd.RunE(selftestCmd, nil); err != nil {
+		t.Fatalf("selftest failed: %v", err)
+	}
+	if got := os.Getenv("HOME"); got != home {
+		t.Errorf("Expected HOME to be restored to %s, got %s", home, got)
+	}
+	entries, _ := os.ReadDir(home)
+	if len(entries) != 0 {
+		t.Errorf("Expected the real home to stay untouched, found %d entries", len(entries))
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Stash the active session and return to it later",
+	Long: `'awsm session stash' saves the default credentials, region and active
+profile, so you can switch to another profile for a task and get back to
+exactly where you were with 'awsm session pop'. Stashes nest: pop restores the
+most recent one.
+
+Examples:
+  awsm session stash prod-readonly    # stash, then switch to prod-readonly
+  aws s3 ls ...
+  awsm session pop`,
+}
+
+var sessionStashCmd = &cobra.Command{
+	Use:               "stash [profile]",
+	Short:             "Save the active session, optionally switching to another profile",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stashed, err := aws.StashDefaultSession()
+		if err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Stashed %s\n", describeStashedSession(stashed))
+		if len(args) == 0 {
+			return nil
+		}
+		return runProfileSet(cmd, args)
+	},
+}
+
+var sessionPopCmd = &cobra.Command{
+	Use:   "pop",
+	Short: "Restore the most recently stashed session",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		session, err := aws.PopDefaultSession()
+		if err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Restored %s\n", describeStashedSession(session))
+		if session.Profile != "" {
+			recordProfileUse(session.Profile)
+		}
+		if expires, ok := session.Expires(); ok && time.Now().After(expires) {
+			util.WarnColor.Printf("Its credentials expired at %s, run 'awsm refresh'.\n", expires.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+var sessionListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List stashed sessions, most recent first",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessions, err := aws.StashedSessions()
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			util.InfoColor.Println("No stashed sessions")
+			return nil
+		}
+		for i := len(sessions) - 1; i >= 0; i-- {
+			s := sessions[i]
+			fmt.Printf("%-40s stashed %s\n", describeStashedSession(&s), s.StashedAt.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+	},
+}
+
+// describeStashedSession names a stashed session by its profile and region.
+func describeStashedSession(s *aws.StashedSession) string {
+	if s.Profile == "" && len(s.Keys) == 0 {
+		return "an empty session (no default credentials)"
+	}
+	name := "session of profile " + s.Profile
+	if s.Profile == "" {
+		name = "default credentials not set by awsm"
+	}
+	if region := s.Region(); region != "" {
+		name += fmt.Sprintf(" (%s)", region)
+	}
+	return name
+}
+
+func init() {
+	sessionCmd.AddCommand(sessionStashCmd, sessionPopCmd, sessionListCmd)
+	rootCmd.AddCommand(sessionCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// detectShellFromParentProcess returns the name of the process that started
+// awsm, looked up with sysctl kern.proc. It is empty when it can't be determined.
+func detectShellFromParentProcess() string {
+	proc, err := unix.SysctlKinfoProc("kern.proc.pid", os.Getppid())
+	if err != nil {
+		return ""
+	}
+	comm := proc.Proc.P_comm[:]
+	if i := bytes.IndexByte(comm, 0); i >= 0 {
+		comm = comm[:i]
+	}
+	return string(comm)
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// detectShellFromParentProcess returns the name of the process that started
+// awsm, read from /proc. It is empty when it can't be determined.
+func detectShellFromParentProcess() string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", os.Getppid()))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package cmd
+
+// detectShellFromParentProcess is not supported on this platform, so the shell
+// is taken from SHELL.
+func detectShellFromParentProcess() string {
+	return ""
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// detectShellFromParentProcess returns the executable name of the process that
+// started awsm, found in a toolhelp process snapshot. It is empty when it can't
+// be determined.
+func detectShellFromParentProcess() string {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(snapshot)
+
+	ppid := uint32(os.Getppid())
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		if entry.ProcessID == ppid {
+			return windows.UTF16ToString(entry.ExeFile[:])
+		}
+	}
+	return ""
+}
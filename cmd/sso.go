@@ -11,33 +11,28 @@ var ssoCmd = &cobra.Command{
 	Short: "Manage AWS SSO (IAM Identity Center) sessions",
 }
 
-// Autocomplete for SSO session names
-func completeSSOSessions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	sessions, err := aws.ListSSOSessions()
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var matches []string
-	for _, s := range sessions {
-		if toComplete == "" || len(toComplete) == 0 || (len(s.Name) >= len(toComplete) && s.Name[:len(toComplete)] == toComplete) {
-			matches = append(matches, s.Name)
-		}
-	}
-	return matches, cobra.ShellCompDirectiveNoFileComp
-}
+var ssoLoginForce bool
 
 var ssoLoginCmd = &cobra.Command{
-	Use:               "login <sso-session>",
-	Short:             "Log in to an SSO session",
-	Long:              `Initiates the AWS SSO login flow for the specified SSO session.`,
+	Use:   "login <sso-session>",
+	Short: "Log in to an SSO session",
+	Long: `Renews the token of the specified SSO session.
+
+When the cached token has a refresh token that is still valid, it is renewed
+silently without opening a browser. Otherwise, or with --force, the AWS SSO
+login flow is started.`,
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeSSOSessions,
+	ValidArgsFunction: completeArgs(completeSSOSessions),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if ssoLoginForce {
+			return aws.PerformFullSSOLogin(args[0])
+		}
 		return aws.PerformSSOLogin(args[0])
 	},
 }
 
 func init() {
+	ssoLoginCmd.Flags().BoolVar(&ssoLoginForce, "force", false, "Always start a new login instead of refreshing the cached token")
 	ssoCmd.AddCommand(ssoLoginCmd)
 	rootCmd.AddCommand(ssoCmd)
 }
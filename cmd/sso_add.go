@@ -45,10 +45,6 @@ sso_registration_scopes = sso:account:access`,
 
 func init() {
 	ssoCmd.AddCommand(ssoAddCmd)
-	ssoAddCmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		if len(args) == 2 {
-			return aws.GetAllRegions(), cobra.ShellCompDirectiveNoFileComp
-		}
-		return nil, cobra.ShellCompDirectiveNoFileComp
-	}
+	// The name and start URL are new; only the region can be completed
+	ssoAddCmd.ValidArgsFunction = completeArgs(nil, nil, completeRegions)
 }
@@ -17,7 +17,7 @@ var ssoDeleteCmd = &cobra.Command{
 	Use:               "delete <sso-session>",
 	Short:             "Delete an SSO session and optionally its associated profiles",
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeSSOSessions,
+	ValidArgsFunction: completeArgs(completeSSOSessions),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ssoSession := args[0]
 
@@ -53,6 +53,9 @@ var ssoDeleteCmd = &cobra.Command{
 			for _, profile := range profiles {
 				fmt.Printf("  - %s\n", profile)
 			}
+			if err := warnBrokenDependents(profiles); err != nil {
+				return err
+			}
 		}
 
 		// Confirm deletion unless forced
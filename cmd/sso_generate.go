@@ -2,23 +2,30 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
-	"regexp"
 	"strings"
-	"time"
+	"sync"
 
 	"awsm/internal/aws"
 	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+	"awsm/internal/tui"
 	"awsm/internal/util"
 
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/sso"
+	ssoTypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
 	"github.com/spf13/cobra"
 )
 
+var (
+	generateMaxAccounts   int
+	generateDryRun        bool
+	generateNameTemplate  string
+	generateNameKeepCase  bool
+	generateNameMaxLength int
+)
+
 var generateCmd = &cobra.Command{
 	Use:   "generate <sso-session-name>",
 	Short: "Generates AWS config profiles for all accessible SSO accounts and roles",
@@ -26,175 +33,388 @@ var generateCmd = &cobra.Command{
 you have access to, and generates the corresponding AWS profile configurations.
 
 The generated profiles are saved to '~/.aws/config' using the region from the SSO session.
-Existing profiles are automatically updated without prompting.`,
+Existing profiles are automatically updated without prompting; the keys that
+change are shown as a colored diff.
+
+With --dry-run nothing is written: awsm lists which profiles would be added,
+updated or left untouched and prints a unified diff of the resulting config
+file. Logging in may still refresh the SSO token cache.
+
+--verify resolves credentials for every generated profile and calls
+sts:GetCallerIdentity, reporting which profiles work and which fail (for
+example a role that can't actually be assumed); --verify-sample n checks n
+profiles picked at random, which is quicker for hundreds of accounts.
+
+Profiles are named <account>-<role> by default. --name-template (or the
+sso.name_template setting) takes a Go template with .AccountID, .AccountName,
+.RoleName and .SessionName, and the lower, upper and trunc functions. Names are
+lowercased unless --keep-case (sso.name_keep_case) is given, and
+--name-max-length (sso.name_max_length) shortens long names, keeping them
+unique with a hash suffix.
+
+Examples:
+  awsm sso generate company --name-template "{{.SessionName}}-{{.AccountName}}/{{.RoleName}}"
+  awsm config set sso.name_template "{{.AccountID}}-{{.RoleName}}"`,
 	Args:              cobra.ExactArgs(1),
-	ValidArgsFunction: completeSSOSessions,
+	ValidArgsFunction: completeArgs(completeSSOSessions),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if generateDryRun && (generateVerify || generateVerifySample > 0) {
+			return fmt.Errorf("--verify and --verify-sample need the profiles written and cannot be combined with --dry-run")
+		}
 		return runSSOGenerate(args[0])
 	},
 }
 
 func runSSOGenerate(ssoSession string) error {
-	// Get region from SSO session configuration
-	awsRegion, err := getSSORegionForSession(ssoSession)
+	discovery, err := discoverSSOProfiles(ssoSession)
 	if err != nil {
-		return fmt.Errorf("failed to get region from SSO session '%s': %w", ssoSession, err)
+		return err
 	}
-	if !aws.IsValidRegion(awsRegion) {
-		return fmt.Errorf("invalid region in SSO session: %s", awsRegion)
+
+	outputFile, err := aws.GeneratedProfilesPath()
+	if err != nil {
+		return err
+	}
+	// If file doesn't exist, we'll start with empty config
+	existingConfig, _ := awsmConfig.ReadConfigFile(outputFile)
+
+	plan := awsmConfig.BuildPlan(existingConfig, discovery.Profiles, "")
+	if generateDryRun {
+		return printSSOGenerateDryRun(plan, outputFile, existingConfig)
+	}
+	for _, c := range plan.Changes {
+		if c.Action == awsmConfig.PlanUpdate {
+			util.WarnColor.Printf("  ~ %s\n", c.Profile)
+			printProfileDiff(c)
+		}
+	}
+
+	if len(plan.Changes) == 0 {
+		util.InfoColor.Println("All profiles are up to date.")
+	} else {
+		if err := applySSOPlan(plan, outputFile); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("\n✔ Done! %d profiles updated/added to %s\n", len(plan.Changes), util.BoldColor.Sprint(outputFile))
 	}
-	home, err := os.UserHomeDir()
+
+	if aws.ConfigFragmentsEnabled() {
+		util.InfoColor.Println("Profiles are kept in a config fragment; run 'awsm config materialize' for tools that only read ~/.aws/config.")
+	} else {
+		util.InfoColor.Println("You can now use the new profiles from your ~/.aws/config.")
+	}
+
+	names := make([]string, len(discovery.Profiles))
+	for i, p := range discovery.Profiles {
+		names[i] = p.Name
+	}
+	return runGeneratedProfileVerification(names)
+}
+
+// printSSOGenerateDryRun prints the changes 'sso generate' would make and
+// the resulting diff of the config file, without writing it.
+func printSSOGenerateDryRun(plan *awsmConfig.Plan, outputFile, existingConfig string) error {
+	fmt.Println()
+	printSSOPlan(plan)
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	content, err := plan.Apply(existingConfig)
 	if err != nil {
-		return fmt.Errorf("cannot find home directory: %w", err)
+		return err
 	}
-	outputFile := filepath.Join(home, ".aws", "config")
+	fmt.Println()
+	printUnifiedDiff(awsmConfig.UnifiedDiff(outputFile, outputFile+" (after sso generate)", existingConfig, content, 3))
+	util.InfoColor.Println("\nDry run: nothing was written. Run without --dry-run to apply these changes.")
+	return nil
+}
 
+// printUnifiedDiff prints a unified diff with removed lines in red, added
+// lines in green and hunk headers highlighted.
+func printUnifiedDiff(diff string) {
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			util.BoldColor.Print(line)
+		case strings.HasPrefix(line, "@@"):
+			util.InfoColor.Print(line)
+		case strings.HasPrefix(line, "-"):
+			util.ErrorColor.Print(line)
+		case strings.HasPrefix(line, "+"):
+			util.SuccessColor.Print(line)
+		default:
+			fmt.Print(line)
+		}
+	}
+}
+
+// ssoDiscovery is the result of discovering the accounts and roles of an SSO session.
+type ssoDiscovery struct {
+	Profiles []awsmConfig.DesiredProfile
+	// Truncated is set when --max-accounts left accounts out.
+	Truncated bool
+	// FailedAccounts lists the accounts whose roles could not all be listed.
+	FailedAccounts []string
+}
+
+// discoverSSOProfiles logs into the session and returns one generated profile
+// for every account and role the user has access to.
+func discoverSSOProfiles(ssoSession string) (*ssoDiscovery, error) {
+	namer, err := ssoProfileNamer()
+	if err != nil {
+		return nil, err
+	}
+	// Get region from SSO session configuration
+	awsRegion, err := getSSORegionForSession(ssoSession)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get region from SSO session '%s': %w", ssoSession, err)
+	}
+	if !aws.IsValidRegion(awsRegion) {
+		return nil, fmt.Errorf("invalid region in SSO session: %s", awsRegion)
+	}
 	// 1. Log in to get a fresh token cached by the AWS CLI
 	if err := aws.PerformSSOLogin(ssoSession); err != nil {
-		return err
+		return nil, err
 	}
 
-	// 2. Find the cached access token from the filesystem
+	// 2. Read the session's own cached access token, other sessions may belong to other organizations
 	util.InfoColor.Println("Finding cached SSO access token...")
 
-	accessToken, err := findLatestSsoToken(filepath.Join(home, ".aws", "sso", "cache"))
+	accessToken, err := aws.CachedSSOAccessToken(ssoSession)
 	if err != nil {
-		return fmt.Errorf("could not find cached SSO token: %w", err)
+		return nil, fmt.Errorf("could not find cached SSO token: %w", err)
 	}
 	util.SuccessColor.Println("✔ Found access token.")
 
-	// 3. Create SSO client with the region from session configuration
+	return listSSOProfiles(ssoSession, awsRegion, accessToken, namer, true)
+}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(awsRegion))
+// listSSOProfiles returns one generated profile for every account and role
+// an access token of the session gives access to. Progress and problems are
+// only printed when verbose.
+func listSSOProfiles(ssoSession, awsRegion, accessToken string, namer *aws.ProfileNamer, verbose bool) (*ssoDiscovery, error) {
+	// 3. Create SSO client with the region from session configuration
+	ssoClient, err := aws.NewSSOClient(awsRegion)
 	if err != nil {
-		return fmt.Errorf("could not create basic AWS config: %w", err)
+		return nil, err
 	}
-	ssoClient := sso.NewFromConfig(cfg)
 
 	// 4. List Accounts using the access token
-	util.InfoColor.Println("Fetching all accessible accounts...")
-	var accounts []*sso.ListAccountsOutput
+	if verbose {
+		util.InfoColor.Println("Fetching all accessible accounts...")
+	}
+	accounts, truncated, err := listSSOAccounts(ssoClient, accessToken, generateMaxAccounts)
+	if err != nil {
+		return nil, err
+	}
 
-	accountsPaginator := sso.NewListAccountsPaginator(ssoClient, &sso.ListAccountsInput{
-		AccessToken: &accessToken,
-	})
-	for accountsPaginator.HasMorePages() {
-		page, err := accountsPaginator.NextPage(context.TODO())
-		if err != nil {
-			if strings.Contains(err.Error(), "UnauthorizedException") || strings.Contains(err.Error(), "401") {
-				return fmt.Errorf("failed to list accounts: Session token not found or invalid.\n\nThis usually happens when:\n1. The SSO session has expired\n2. The cached token is stale\n3. There's a region mismatch\n\nTry running the command again, or clear your SSO cache with: rm -rf ~/.aws/sso/cache/*")
+	discovery := &ssoDiscovery{Truncated: truncated}
+	// generatedBy remembers the account and role behind each name, to catch
+	// templates that give several roles the same name
+	generatedBy := make(map[string]string)
+
+	var progress *tui.Progress
+	done := func() {}
+	if verbose {
+		util.SuccessColor.Printf("✔ Found %d accounts.\n", len(accounts))
+		util.InfoColor.Println("Listing roles...")
+		progress = tui.NewProgress("accounts", len(accounts))
+		done = progress.Increment
+	}
+	listings := listAllSSOAccountRoles(ssoClient, accessToken, accounts, ssoRoleWorkers, done)
+	if verbose {
+		progress.Finish()
+		util.InfoColor.Println("Generating profiles...")
+	}
+
+	for _, listing := range listings {
+		acc := listing.Account
+		if listing.Err != nil {
+			if verbose {
+				util.ErrorColor.Fprintf(os.Stderr, "    Could not list roles for account %s (%s): %v\n", *acc.AccountName, *acc.AccountId, listing.Err)
+			}
+			discovery.FailedAccounts = append(discovery.FailedAccounts, *acc.AccountId)
+		}
+		for _, role := range listing.Roles {
+			profileName, err := namer.Name(aws.ProfileNameVars{
+				AccountID:   *acc.AccountId,
+				AccountName: *acc.AccountName,
+				RoleName:    *role.RoleName,
+				SessionName: ssoSession,
+			})
+			if err != nil {
+				return nil, err
+			}
+			source := fmt.Sprintf("%s/%s", *acc.AccountId, *role.RoleName)
+			if previous, ok := generatedBy[profileName]; ok {
+				if verbose {
+					util.WarnColor.Fprintf(os.Stderr, "    Skipping %s: profile '%s' is already generated for %s. Add {{.AccountID}} to the name template to tell them apart.\n", source, profileName, previous)
+				}
+				continue
 			}
-			return fmt.Errorf("failed to list accounts: %w", err)
+			generatedBy[profileName] = source
+			discovery.Profiles = append(discovery.Profiles, awsmConfig.DesiredProfile{
+				Name:    profileName,
+				Content: aws.FormatGeneratedProfile(profileName, ssoSession, *acc.AccountId, *role.RoleName, awsRegion),
+			})
 		}
-		accounts = append(accounts, page)
 	}
+	return discovery, nil
+}
 
-	totalAccounts := 0
-	for _, page := range accounts {
-		totalAccounts += len(page.AccountList)
+// ssoProfileNamer returns the namer for generated profiles, from the name
+// flags or else the sso.name_* settings.
+func ssoProfileNamer() (*aws.ProfileNamer, error) {
+	nameTemplate := generateNameTemplate
+	if nameTemplate == "" {
+		nameTemplate = awsmConfig.GetSSONameTemplate()
 	}
-	util.SuccessColor.Printf("✔ Found %d accounts.\n", totalAccounts)
+	maxLength := generateNameMaxLength
+	if maxLength == 0 {
+		maxLength = awsmConfig.GetSSONameMaxLength()
+	}
+	return aws.NewProfileNamer(nameTemplate, generateNameKeepCase || awsmConfig.GetSSONameKeepCase(), maxLength)
+}
 
-	// Read existing config
+// addProfileNameFlags registers the flags that control generated profile names.
+func addProfileNameFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&generateNameTemplate, "name-template", "", "Go template for profile names (default \"{{.AccountName}}-{{.RoleName}}\")")
+	cmd.Flags().BoolVar(&generateNameKeepCase, "keep-case", false, "Keep the case of account and role names instead of lowercasing them")
+	cmd.Flags().IntVar(&generateNameMaxLength, "name-max-length", 0, "Shorten profile names to this many characters (0 = no limit)")
+}
+
+// applySSOPlan checks the plan against the awsm policy and writes the result to
+// the config file. The plan is applied to the file as it is once locked, so
+// changes another awsm process made since it was read are kept.
+func applySSOPlan(plan *awsmConfig.Plan, outputFile string) error {
+	var policyProfiles []policy.Profile
+	for _, c := range plan.Changes {
+		if c.Action == awsmConfig.PlanPrune {
+			continue
+		}
+		policyProfiles = append(policyProfiles, policy.Profile{
+			Name:      c.Profile,
+			Region:    c.New["region"],
+			AccountID: c.New["sso_account_id"],
+			RoleName:  c.New["sso_role_name"],
+		})
+	}
+	if err := policy.Enforce(policyProfiles...); err != nil {
+		return err
+	}
+	unlock, err := aws.LockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
 	existingConfig, err := awsmConfig.ReadConfigFile(outputFile)
 	if err != nil {
-		// If file doesn't exist, we'll start with empty config
-		existingConfig = ""
+		return fmt.Errorf("failed to read %s: %w", outputFile, err)
+	}
+	content, err := plan.Apply(existingConfig)
+	if err != nil {
+		return err
 	}
 
-	// Parse existing profile names and their content
-	existingProfiles, existingProfileContent := awsmConfig.ParseExistingProfiles(existingConfig)
-
-	// Build new profiles
-	var newProfilesBuilder strings.Builder
-	cleaner := regexp.MustCompile(`[^a-zA-Z0-9-]`)
-	profileCount := 0
-
-	util.InfoColor.Println("Generating profiles...")
-	for _, page := range accounts {
-		for _, acc := range page.AccountList {
-			util.InfoColor.Fprintf(os.Stderr, "  -> Processing account: %s (%s)\n", *acc.AccountName, *acc.AccountId)
+	// Write the complete config file
+	if err := awsmConfig.WriteConfigFile(outputFile, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	aws.InvalidateProfileCache()
+	return nil
+}
 
-			rolesPaginator := sso.NewListAccountRolesPaginator(ssoClient, &sso.ListAccountRolesInput{
-				AccessToken: &accessToken,
-				AccountId:   acc.AccountId,
-			})
-			for rolesPaginator.HasMorePages() {
-				rolesPage, err := rolesPaginator.NextPage(context.TODO())
-				if err != nil {
-					util.ErrorColor.Fprintf(os.Stderr, "    Could not list roles for account %s: %v\n", *acc.AccountId, err)
-					continue
-				}
-				for _, role := range rolesPage.RoleList {
-					// Sanitize names for the profile
-					cleanAccountName := strings.ToLower(*acc.AccountName)
-					cleanAccountName = cleaner.ReplaceAllString(cleanAccountName, "-")
-					cleanRoleName := strings.ToLower(*role.RoleName)
-					cleanRoleName = cleaner.ReplaceAllString(cleanRoleName, "-")
-
-					profileName := fmt.Sprintf("%s-%s", cleanAccountName, cleanRoleName)
-
-					// Generate the new profile content
-					newProfileContent := fmt.Sprintf("[profile %s]\nsso_session = %s\nsso_account_id = %s\nsso_role_name = %s\nregion = %s\n\n",
-						profileName, ssoSession, *acc.AccountId, *role.RoleName, awsRegion)
-
-					if existingProfiles[profileName] {
-						// Check if the profile content is different
-						if existingContent, exists := existingProfileContent[profileName]; exists {
-							// Extract just the configuration lines for comparison
-							existingLines := awsmConfig.ExtractProfileConfig(existingContent)
-							newLines := awsmConfig.ExtractProfileConfig(newProfileContent)
-
-							if existingLines == newLines {
-								// Profile is identical, skip it
-								util.InfoColor.Fprintf(os.Stderr, "    Profile '%s' is up to date, skipping\n", profileName)
-								continue
-							} else {
-								// Profile content is different, update it
-								util.InfoColor.Fprintf(os.Stderr, "    Updating profile '%s' with new configuration\n", profileName)
-								// Remove the old profile from existing config
-								existingConfig = awsmConfig.RemoveProfileFromConfig(existingConfig, profileName)
-							}
-						} else {
-							// Profile exists but we couldn't find its content, update it anyway
-							util.InfoColor.Fprintf(os.Stderr, "    Updating profile '%s'\n", profileName)
-							existingConfig = awsmConfig.RemoveProfileFromConfig(existingConfig, profileName)
-						}
-					}
-
-					newProfilesBuilder.WriteString(newProfileContent)
-					profileCount++
-				}
+// listSSOAccounts pages through all accounts visible to the access token.
+// Progress is reported after every page so very large organizations don't look hung,
+// and listing stops once maxAccounts accounts have been collected (0 means no limit).
+// truncated reports whether accounts were left out because of maxAccounts.
+func listSSOAccounts(client sso.ListAccountsAPIClient, accessToken string, maxAccounts int) (accounts []ssoTypes.AccountInfo, truncated bool, err error) {
+	accountsPaginator := sso.NewListAccountsPaginator(client, &sso.ListAccountsInput{
+		AccessToken: &accessToken,
+	})
+	for accountsPaginator.HasMorePages() {
+		page, err := accountsPaginator.NextPage(context.TODO())
+		if err != nil {
+			if strings.Contains(err.Error(), "UnauthorizedException") || strings.Contains(err.Error(), "401") {
+				return nil, false, fmt.Errorf("failed to list accounts: Session token not found or invalid.\n\nThis usually happens when:\n1. The SSO session has expired\n2. The cached token is stale\n3. There's a region mismatch\n\nTry running the command again, or clear your SSO cache with: rm -rf ~/.aws/sso/cache/*")
+			}
+			if len(accounts) > 0 {
+				return nil, false, fmt.Errorf("failed to list accounts after fetching %d: %w", len(accounts), err)
 			}
+			return nil, false, fmt.Errorf("failed to list accounts: %w", err)
 		}
-	}
+		accounts = append(accounts, page.AccountList...)
 
-	// Write the updated config
-	if newProfilesBuilder.Len() > 0 {
-		// Combine existing config (with removed profiles if updating) and new profiles
-		finalConfig := existingConfig
-		newContent := newProfilesBuilder.String()
-		if len(finalConfig) > 0 {
-			if !strings.HasSuffix(finalConfig, "\n") {
-				finalConfig += "\n"
+		if maxAccounts > 0 && len(accounts) >= maxAccounts {
+			truncated = len(accounts) > maxAccounts || accountsPaginator.HasMorePages()
+			if truncated {
+				util.WarnColor.Fprintf(os.Stderr, "Stopping after %d accounts (--max-accounts). Some accounts were not processed.\n", maxAccounts)
 			}
-			finalConfig += "\n" + newContent
-		} else {
-			finalConfig = newContent
+			return accounts[:maxAccounts], truncated, nil
 		}
-
-		// Write the complete config file
-		if err := awsmConfig.WriteConfigFile(outputFile, finalConfig); err != nil {
-			return fmt.Errorf("failed to write %s: %w", outputFile, err)
+		if accountsPaginator.HasMorePages() {
+			util.InfoColor.Fprintf(os.Stderr, "  ... %d accounts fetched so far\n", len(accounts))
 		}
+	}
 
-		util.SuccessColor.Printf("\n✔ Done! %d profiles updated/added to %s\n", profileCount, util.BoldColor.Sprint(outputFile))
-	} else {
-		util.InfoColor.Println("All profiles are up to date.")
+	return accounts, false, nil
+}
+
+// ssoRoleWorkers is how many accounts have their roles listed at once. The
+// SSO client's adaptive retryer slows all of them down when AWS throttles.
+const ssoRoleWorkers = 8
+
+// accountRoles are the roles listed for one account.
+type accountRoles struct {
+	Account ssoTypes.AccountInfo
+	Roles   []ssoTypes.RoleInfo
+	Err     error
+}
+
+// listAllSSOAccountRoles lists the roles of all accounts with a bounded pool
+// of workers sharing one client, calling done after each account. Results
+// keep the order of accounts.
+func listAllSSOAccountRoles(client sso.ListAccountRolesAPIClient, accessToken string, accounts []ssoTypes.AccountInfo, workers int, done func()) []accountRoles {
+	results := make([]accountRoles, len(accounts))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(accounts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				roles, err := listSSOAccountRoles(client, accessToken, *accounts[i].AccountId)
+				results[i] = accountRoles{Account: accounts[i], Roles: roles, Err: err}
+				done()
+			}
+		}()
 	}
+	for i := range accounts {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
 
-	util.InfoColor.Println("You can now use the new profiles from your ~/.aws/config.")
-	return nil
+// listSSOAccountRoles returns every role of an account. If a page fails, the
+// roles fetched so far are returned together with the error and the remaining
+// pages are skipped: the caller reports the account and moves on, so a single
+// throttled or restricted account doesn't abort generation for all the others.
+func listSSOAccountRoles(client sso.ListAccountRolesAPIClient, accessToken, accountID string) ([]ssoTypes.RoleInfo, error) {
+	var roles []ssoTypes.RoleInfo
+	rolesPaginator := sso.NewListAccountRolesPaginator(client, &sso.ListAccountRolesInput{
+		AccessToken: &accessToken,
+		AccountId:   &accountID,
+	})
+	for rolesPaginator.HasMorePages() {
+		page, err := rolesPaginator.NextPage(context.TODO())
+		if err != nil {
+			return roles, err
+		}
+		roles = append(roles, page.RoleList...)
+	}
+	return roles, nil
 }
 
 func getSSORegionForSession(ssoSession string) (string, error) {
@@ -213,72 +433,11 @@ func getSSORegionForSession(ssoSession string) (string, error) {
 	return "", fmt.Errorf("SSO session '%s' not found in config", ssoSession)
 }
 
-func findLatestSsoToken(cacheDir string) (string, error) {
-	files, err := os.ReadDir(cacheDir)
-	if err != nil {
-		return "", fmt.Errorf("could not read SSO cache directory at %s: %w", cacheDir, err)
-	}
-
-	var latestFile os.FileInfo
-	var latestTime time.Time
-	var validToken string
-
-	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
-			continue
-		}
-		info, err := file.Info()
-		if err != nil {
-			continue
-		}
-
-		// Read and parse each file to find valid access tokens
-		fullPath := filepath.Join(cacheDir, file.Name())
-		data, err := os.ReadFile(fullPath)
-		if err != nil {
-			continue
-		}
-
-		// Try different token formats
-		var tokenData map[string]interface{}
-		if err := json.Unmarshal(data, &tokenData); err != nil {
-			continue
-		}
-
-		// Look for accessToken in various formats
-		var accessToken string
-		if token, ok := tokenData["accessToken"].(string); ok && token != "" {
-			accessToken = token
-		} else if token, ok := tokenData["access_token"].(string); ok && token != "" {
-			accessToken = token
-		}
-
-		// Check if token has expiration and if it's still valid
-		if accessToken != "" {
-			isValid := true
-			if expiresAt, ok := tokenData["expiresAt"].(string); ok {
-				if expTime, err := time.Parse(time.RFC3339, expiresAt); err == nil {
-					if time.Now().After(expTime) {
-						isValid = false
-					}
-				}
-			}
-
-			if isValid && info.ModTime().After(latestTime) {
-				latestTime = info.ModTime()
-				latestFile = info
-				validToken = accessToken
-			}
-		}
-	}
-
-	if latestFile == nil || validToken == "" {
-		return "", fmt.Errorf("no valid SSO token cache file found in %s.\n\nThis could mean:\n1. No SSO login has been performed\n2. All cached tokens have expired\n3. The cache directory is empty\n\nTry running 'aws sso login --sso-session <session-name>' first", cacheDir)
-	}
-
-	return validToken, nil
-}
-
 func init() {
+	generateCmd.Flags().IntVar(&generateMaxAccounts, "max-accounts", 0, "Stop after processing this many accounts (0 = no limit)")
+	generateCmd.Flags().BoolVar(&generateVerify, "verify", false, "Verify every generated profile by resolving its credentials and calling STS")
+	generateCmd.Flags().IntVar(&generateVerifySample, "verify-sample", 0, "Verify this many generated profiles, picked at random")
+	addProfileNameFlags(generateCmd)
+	generateCmd.Flags().BoolVar(&generateDryRun, "dry-run", false, "Show the changes as a diff of the config file without writing it")
 	ssoCmd.AddCommand(generateCmd)
 }
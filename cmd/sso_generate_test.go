@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+	ssoTypes "github.com/aws/aws-sdk-go-v2/service/sso/types"
+)
+
+// stubSSOClient serves accounts and roles in pages of pageSize.
+type stubSSOClient struct {
+	accounts  int
+	pageSize  int
+	roles     map[string][]string
+	rolesFail map[string]int // page index at which ListAccountRoles fails
+}
+
+func pageToken(token *string) int {
+	if token == nil {
+		return 0
+	}
+	n, _ := strconv.Atoi(*token)
+	return n
+}
+
+func (c *stubSSOClient) ListAccounts(ctx context.Context, in *sso.ListAccountsInput, _ ...func(*sso.Options)) (*sso.ListAccountsOutput, error) {
+	start := pageToken(in.NextToken)
+	out := &sso.ListAccountsOutput{}
+	for i := start; i < start+c.pageSize && i < c.accounts; i++ {
+		out.AccountList = append(out.AccountList, ssoTypes.AccountInfo{
+			AccountId:   aws.String(fmt.Sprintf("%012d", i)),
+			AccountName: aws.String(fmt.Sprintf("account-%d", i)),
+		})
+	}
+	if start+c.pageSize < c.accounts {
+		out.NextToken = aws.String(strconv.Itoa(start + c.pageSize))
+	}
+	return out, nil
+}
+
+func (c *stubSSOClient) ListAccountRoles(ctx context.Context, in *sso.ListAccountRolesInput, _ ...func(*sso.Options)) (*sso.ListAccountRolesOutput, error) {
+	start := pageToken(in.NextToken)
+	if failAt, ok := c.rolesFail[*in.AccountId]; ok && start/c.pageSize == failAt {
+		return nil, errors.New("TooManyRequestsException")
+	}
+	roles := c.roles[*in.AccountId]
+	out := &sso.ListAccountRolesOutput{}
+	for i := start; i < start+c.pageSize && i < len(roles); i++ {
+		out.RoleList = append(out.RoleList, ssoTypes.RoleInfo{AccountId: in.AccountId, RoleName: aws.String(roles[i])})
+	}
+	if start+c.pageSize < len(roles) {
+		out.NextToken = aws.String(strconv.Itoa(start + c.pageSize))
+	}
+	return out, nil
+}
+
+func TestListSSOAccounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		accounts    int
+		maxAccounts int
+		expected    int
+		truncated   bool
+	}{
+		{"No limit", 7, 0, 7, false},
+		{"Limit inside a page", 7, 4, 4, true},
+		{"Limit on a page boundary", 7, 6, 6, true},
+		{"Limit equals total", 6, 6, 6, false},
+		{"Limit above total", 7, 10, 7, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &stubSSOClient{accounts: tt.accounts, pageSize: 3}
+			accounts, truncated, err := listSSOAccounts(client, "token", tt.maxAccounts)
+			if err != nil {
+				t.Fatalf("listSSOAccounts failed: %v", err)
+			}
+			if len(accounts) != tt.expected {
+				t.Errorf("Expected %d accounts, got %d", tt.expected, len(accounts))
+			}
+			if truncated != tt.truncated {
+				t.Errorf("Expected truncated %v, got %v", tt.truncated, truncated)
+			}
+		})
+	}
+}
+
+func TestListSSOAccountRoles(t *testing.T) {
+	client := &stubSSOClient{
+		pageSize:  2,
+		roles:     map[string][]string{"1": {"a", "b", "c"}, "2": {"a", "b", "c"}},
+		rolesFail: map[string]int{"2": 1},
+	}
+
+	roles, err := listSSOAccountRoles(client, "token", "1")
+	if err != nil || len(roles) != 3 {
+		t.Errorf("Expected 3 roles without error, got %d (%v)", len(roles), err)
+	}
+
+	// A failing page stops listing the account and keeps the roles fetched so far
+	roles, err = listSSOAccountRoles(client, "token", "2")
+	if err == nil {
+		t.Error("Expected an error for the failing page")
+	}
+	if len(roles) != 2 {
+		t.Errorf("Expected the 2 roles of the first page, got %d", len(roles))
+	}
+}
+
+func TestListAllSSOAccountRoles(t *testing.T) {
+	client := &stubSSOClient{pageSize: 2, roles: map[string][]string{}, rolesFail: map[string]int{}}
+	var accounts []ssoTypes.AccountInfo
+	for i := range 25 {
+		id := fmt.Sprintf("%012d", i)
+		accounts = append(accounts, ssoTypes.AccountInfo{AccountId: aws.String(id), AccountName: aws.String(fmt.Sprintf("account-%d", i))})
+		client.roles[id] = []string{"Admin", "ReadOnly", "Billing"}
+	}
+	client.rolesFail[fmt.Sprintf("%012d", 7)] = 1
+
+	var mu sync.Mutex
+	done := 0
+	listings := listAllSSOAccountRoles(client, "token", accounts, 4, func() {
+		mu.Lock()
+		done++
+		mu.Unlock()
+	})
+
+	if done != len(accounts) || len(listings) != len(accounts) {
+		t.Fatalf("Expected progress and a listing for all %d accounts, got %d and %d", len(accounts), done, len(listings))
+	}
+	for i, l := range listings {
+		if *l.Account.AccountId != *accounts[i].AccountId {
+			t.Errorf("Listing %d is for account %s, want %s", i, *l.Account.AccountId, *accounts[i].AccountId)
+		}
+		if i == 7 {
+			if l.Err == nil || len(l.Roles) != 2 {
+				t.Errorf("Expected account 7 to fail after its first page, got %d roles (%v)", len(l.Roles), l.Err)
+			}
+			continue
+		}
+		if l.Err != nil || len(l.Roles) != 3 {
+			t.Errorf("Expected 3 roles for account %d, got %d (%v)", i, len(l.Roles), l.Err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/tui"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	ssoImportURLName   string
+	ssoImportURLRegion string
+	ssoImportURLYes    bool
+)
+
+var ssoImportURLCmd = &cobra.Command{
+	Use:   "import-url <start-url>",
+	Short: "Set up an SSO session from just its start URL",
+	Long: `Creates an SSO session from the start URL in an IAM Identity Center
+invite email, collapsing the usual onboarding steps into one command:
+
+  1. The region of the Identity Center instance is detected by asking each
+     region for a device authorization (use --region to skip this).
+  2. A session name is proposed from the URL (use --name to set it).
+  3. The sso-session is added to ~/.aws/config.
+  4. Optionally, you log in and profiles are generated for every account and role.
+
+Example:
+  awsm sso import-url https://mycorp.awsapps.com/start
+  awsm sso import-url d-1234567a10.awsapps.com --name corp --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		startURL, err := aws.NormalizeSSOStartURL(args[0])
+		if err != nil {
+			return err
+		}
+
+		sessions, err := aws.ListSSOSessions()
+		if err != nil {
+			return fmt.Errorf("failed to list SSO sessions: %w", err)
+		}
+		for _, s := range sessions {
+			if strings.TrimSuffix(s.StartURL, "/") == startURL {
+				return fmt.Errorf("SSO session '%s' already uses %s", s.Name, startURL)
+			}
+		}
+
+		region := ssoImportURLRegion
+		if region == "" {
+			err = tui.ShowSpinner(context.Background(), "Detecting the region of "+startURL, func() error {
+				var detectErr error
+				region, detectErr = aws.DetectSSORegion(startURL)
+				return detectErr
+			})
+			if err != nil {
+				return err
+			}
+		} else if !aws.IsValidRegion(region) {
+			return fmt.Errorf("invalid region: %s", region)
+		}
+		util.InfoColor.Printf("Identity Center region: %s\n", util.BoldColor.Sprint(region))
+
+		name := ssoImportURLName
+		if name == "" {
+			name = aws.SuggestSSOSessionName(startURL)
+			if !ssoImportURLYes {
+				answer, err := util.PromptForInput(fmt.Sprintf("Session name [%s]: ", name))
+				if err != nil {
+					return err
+				}
+				if answer != "" {
+					name = answer
+				}
+			}
+		}
+		for _, s := range sessions {
+			if s.Name == name {
+				return fmt.Errorf("SSO session '%s' already exists, choose another name with --name", name)
+			}
+		}
+
+		if err := aws.AddSSOSession(name, startURL, region); err != nil {
+			return fmt.Errorf("failed to add SSO session: %w", err)
+		}
+		util.SuccessColor.Printf("✔ SSO session '%s' added to ~/.aws/config\n", name)
+
+		if !ssoImportURLYes {
+			answer, err := util.PromptForInput("Log in and generate profiles now? [Y/n]: ")
+			if err != nil {
+				return err
+			}
+			if answer != "" && !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+				util.InfoColor.Printf("Generate profiles later with 'awsm sso generate %s'.\n", name)
+				return nil
+			}
+		}
+		util.InfoColor.Println("\nGenerating profiles for SSO session...")
+		return runSSOGenerate(name)
+	},
+}
+
+func init() {
+	ssoImportURLCmd.Flags().StringVar(&ssoImportURLName, "name", "", "Name of the SSO session (proposed from the URL by default)")
+	ssoImportURLCmd.Flags().StringVar(&ssoImportURLRegion, "region", "", "Region of the Identity Center instance (detected by default)")
+	ssoImportURLCmd.Flags().BoolVarP(&ssoImportURLYes, "yes", "y", false, "Accept the proposed name and generate profiles without asking")
+	ssoCmd.AddCommand(ssoImportURLCmd)
+}
@@ -2,11 +2,12 @@ package cmd
 
 import (
 	"awsm/internal/aws"
+	"awsm/internal/output"
 	"awsm/internal/util"
-	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
@@ -17,8 +18,17 @@ var (
 	ssoNameFilter   string
 	ssoSortBy       string
 	ssoOutputJSON   bool
+	ssoDetailed     bool
 )
 
+// ssoSessionDetails extends a session with the profiles using it and its token state.
+type ssoSessionDetails struct {
+	aws.SSOSessionInfo
+	Profiles       []string
+	TokenExpiresAt *time.Time `json:",omitempty"`
+	NeedsLogin     bool
+}
+
 var ssoListCmd = &cobra.Command{
 	Use:     "list",
 	Short:   "List all SSO sessions",
@@ -29,13 +39,9 @@ var ssoListCmd = &cobra.Command{
 			return err
 		}
 
+		format := output.Current(ssoOutputJSON)
 		if len(sessions) == 0 {
-			if !ssoOutputJSON {
-				util.WarnColor.Println("No SSO sessions found.")
-			} else {
-				fmt.Println("[]")
-			}
-			return nil
+			return renderNoSSOSessions(format, "No SSO sessions found.")
 		}
 
 		// Apply filters
@@ -51,12 +57,7 @@ var ssoListCmd = &cobra.Command{
 		}
 
 		if len(filtered) == 0 {
-			if !ssoOutputJSON {
-				util.WarnColor.Println("No SSO sessions match the specified filters.")
-			} else {
-				fmt.Println("[]")
-			}
-			return nil
+			return renderNoSSOSessions(format, "No SSO sessions match the specified filters.")
 		}
 
 		// Sort sessions
@@ -71,21 +72,46 @@ var ssoListCmd = &cobra.Command{
 			})
 		}
 
-		if ssoOutputJSON {
-			return outputSSOSessionsJSON(filtered)
+		raw := func(w io.Writer) error {
+			for _, s := range filtered {
+				fmt.Fprintln(w, s.Name)
+			}
+			return nil
+		}
+		if ssoDetailed {
+			details := collectSSOSessionDetails(filtered)
+			return output.Render(format, output.Result{
+				Data: details,
+				Table: func() error {
+					printSSOSessionDetails(details)
+					return nil
+				},
+				Raw: raw,
+			})
 		}
 
-		// Print detailed sessions
-		printDetailedSSOSessions(filtered)
-
-		return nil
+		return output.Render(format, output.Result{
+			Data: filtered,
+			Table: func() error {
+				printDetailedSSOSessions(filtered)
+				return nil
+			},
+			Raw: raw,
+		})
 	},
 }
 
-func outputSSOSessionsJSON(sessions []aws.SSOSessionInfo) error {
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(sessions)
+// renderNoSSOSessions reports an empty listing: a warning in the table, an
+// empty list in the other formats.
+func renderNoSSOSessions(format output.Format, message string) error {
+	return output.Render(format, output.Result{
+		Data: []aws.SSOSessionInfo{},
+		Table: func() error {
+			util.WarnColor.Println(message)
+			return nil
+		},
+		Raw: func(io.Writer) error { return nil },
+	})
 }
 
 func printDetailedSSOSessions(sessions []aws.SSOSessionInfo) {
@@ -109,10 +135,69 @@ func printDetailedSSOSessions(sessions []aws.SSOSessionInfo) {
 	fmt.Println()
 }
 
+// collectSSOSessionDetails looks up linked profiles and cached token state for each session.
+func collectSSOSessionDetails(sessions []aws.SSOSessionInfo) []ssoSessionDetails {
+	details := make([]ssoSessionDetails, 0, len(sessions))
+	for _, s := range sessions {
+		d := ssoSessionDetails{SSOSessionInfo: s, Profiles: []string{}, NeedsLogin: true}
+		if profiles, err := aws.GetProfilesBySSO(s.Name); err == nil && profiles != nil {
+			d.Profiles = profiles
+		}
+		if status, err := aws.GetSSOTokenStatus(s.Name); err == nil {
+			d.NeedsLogin = status.NeedsLogin
+			if !status.ExpiresAt.IsZero() {
+				expires := status.ExpiresAt
+				d.TokenExpiresAt = &expires
+			}
+		}
+		details = append(details, d)
+	}
+	return details
+}
+
+func printSSOSessionDetails(details []ssoSessionDetails) {
+	headerStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#00D9FF")).
+		Bold(true)
+
+	fmt.Println(headerStyle.Render("🔐 SSO Sessions"))
+	fmt.Println(headerStyle.Render("═══════════════════════"))
+	fmt.Println()
+
+	for i, d := range details {
+		util.SuccessColor.Printf("● Session: %s\n", d.Name)
+		fmt.Printf("  ├── Start URL: %s\n", d.StartURL)
+		fmt.Printf("  ├── Region: %s\n", d.Region)
+		fmt.Printf("  ├── Scopes: %s\n", d.Scopes)
+		fmt.Printf("  ├── Profiles: %d\n", len(d.Profiles))
+		fmt.Printf("  └── Token: %s\n", formatTokenState(d))
+		if i < len(details)-1 {
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+}
+
+// formatTokenState describes a session's cached token for humans.
+func formatTokenState(d ssoSessionDetails) string {
+	switch {
+	case d.TokenExpiresAt == nil:
+		return util.WarnColor.Sprint("not logged in")
+	case d.NeedsLogin:
+		return util.ErrorColor.Sprintf("expired at %s", d.TokenExpiresAt.Local().Format("2006-01-02 15:04"))
+	case time.Now().After(*d.TokenExpiresAt):
+		return util.InfoColor.Sprint("expired, will be refreshed automatically")
+	default:
+		return util.SuccessColor.Sprintf("valid for %s", time.Until(*d.TokenExpiresAt).Round(time.Minute))
+	}
+}
+
 func init() {
 	ssoListCmd.Flags().StringVarP(&ssoFilterRegion, "region", "r", "", "Filter by region")
 	ssoListCmd.Flags().StringVarP(&ssoNameFilter, "name", "n", "", "Filter by session name (case-insensitive)")
 	ssoListCmd.Flags().StringVarP(&ssoSortBy, "sort", "s", "name", "Sort by field (name, region)")
-	ssoListCmd.Flags().BoolVarP(&ssoOutputJSON, "json", "j", false, "Output sessions in JSON format")
+	ssoListCmd.Flags().BoolVarP(&ssoOutputJSON, "json", "j", false, "Output sessions in JSON format (same as --output json)")
+	ssoListCmd.Flags().BoolVarP(&ssoDetailed, "detailed", "d", false, "Include linked profiles and cached token state")
+	ssoListCmd.RegisterFlagCompletionFunc("sort", completeValues("name", "region"))
 	ssoCmd.AddCommand(ssoListCmd)
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var ssoLogoutAll bool
+
+var ssoLogoutCmd = &cobra.Command{
+	Use:   "logout [sso-session]",
+	Short: "Log out of an SSO session and clear its cached credentials",
+	Long: `Revokes the cached access token of an SSO session with AWS where possible,
+deletes its token cache file and the awsm credential cache of its profiles,
+and clears the default credentials if they came from one of its profiles.
+
+Examples:
+  awsm sso logout my-session
+  awsm sso logout --all`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if ssoLogoutAll && len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a session name")
+		}
+		if !ssoLogoutAll && len(args) != 1 {
+			return fmt.Errorf("specify an SSO session or --all")
+		}
+		return nil
+	},
+	ValidArgsFunction: completeArgs(completeSSOSessions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var sessions []string
+		if ssoLogoutAll {
+			infos, err := aws.ListSSOSessions()
+			if err != nil {
+				return err
+			}
+			for _, s := range infos {
+				sessions = append(sessions, s.Name)
+			}
+			if len(sessions) == 0 {
+				util.InfoColor.Println("No SSO sessions configured")
+				return nil
+			}
+		} else {
+			sessions = args
+		}
+
+		for _, session := range sessions {
+			result, err := aws.LogoutSSOSession(session)
+			if err != nil {
+				return fmt.Errorf("failed to log out of '%s': %w", session, err)
+			}
+			printSSOLogoutResult(result)
+		}
+		return nil
+	},
+}
+
+func printSSOLogoutResult(result *aws.SSOLogoutResult) {
+	util.BoldColor.Printf("%s\n", result.Session)
+	switch {
+	case result.RemoteLogout:
+		util.SuccessColor.Println("  ✔ Access token revoked")
+	case result.RemoteError != nil:
+		util.WarnColor.Printf("  Access token not revoked: %v\n", result.RemoteError)
+	}
+	if result.TokenRemoved {
+		util.SuccessColor.Println("  ✔ Token cache removed")
+	} else {
+		util.InfoColor.Println("  No cached token")
+	}
+	if len(result.CachedProfiles) > 0 {
+		util.SuccessColor.Printf("  ✔ Cached credentials removed: %s\n", strings.Join(result.CachedProfiles, ", "))
+	}
+	if result.DefaultCleared != "" {
+		util.SuccessColor.Printf("  ✔ Default credentials of '%s' cleared\n", result.DefaultCleared)
+	}
+}
+
+func init() {
+	ssoLogoutCmd.Flags().BoolVar(&ssoLogoutAll, "all", false, "Log out of every configured SSO session")
+	ssoCmd.AddCommand(ssoLogoutCmd)
+}
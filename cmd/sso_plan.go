@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	planOutput string
+	planPrune  bool
+	applyForce bool
+)
+
+var ssoPlanCmd = &cobra.Command{
+	Use:   "plan <sso-session-name>",
+	Short: "Show which profiles 'sso generate' would add, update or prune",
+	Long: `Discovers all accounts and roles of an SSO session like 'awsm sso generate',
+but instead of writing ~/.aws/config it prints the changes and saves them to a
+plan file. The plan file is plain JSON so it can be reviewed (or code-reviewed)
+and then applied with 'awsm sso apply'.
+
+With --prune, generated profiles of the session whose account or role is no
+longer accessible are scheduled for removal. Only profiles carrying the
+'# Managed by awsm sso generate' comment are pruned, never hand-written ones.
+Pruning is refused together with --max-accounts, and profiles of accounts whose
+roles failed to list are kept.
+
+Examples:
+  awsm sso plan company
+  awsm sso plan company --prune -o company.plan.json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeSSOSessions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ssoSession := args[0]
+
+		discovery, err := discoverSSOProfiles(ssoSession)
+		if err != nil {
+			return err
+		}
+		if planPrune && discovery.Truncated {
+			return fmt.Errorf("refusing to prune: --max-accounts stopped discovery before all accounts were seen, so their profiles would be removed")
+		}
+
+		configPath, err := aws.GeneratedProfilesPath()
+		if err != nil {
+			return err
+		}
+		existingConfig, err := awsmConfig.ReadConfigFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+
+		pruneSession := ""
+		if planPrune {
+			pruneSession = ssoSession
+		}
+		plan := awsmConfig.BuildPlan(existingConfig, discovery.Profiles, pruneSession)
+		plan.SSOSession = ssoSession
+		if kept := plan.KeepAccounts(discovery.FailedAccounts...); len(kept) > 0 {
+			util.WarnColor.Printf("Not pruning %d profiles of accounts whose roles could not be listed: %s\n", len(kept), strings.Join(kept, ", "))
+		}
+
+		fmt.Println()
+		printSSOPlan(plan)
+
+		if len(plan.Changes) == 0 {
+			return nil
+		}
+		output := planOutput
+		if output == "" {
+			output = fmt.Sprintf("awsm-sso-%s.plan.json", ssoSession)
+		}
+		if err := awsmConfig.SavePlan(output, plan); err != nil {
+			return err
+		}
+		util.InfoColor.Printf("\nPlan saved to %s. Apply it with: awsm sso apply %s\n", util.BoldColor.Sprint(output), output)
+		return nil
+	},
+}
+
+var ssoApplyCmd = &cobra.Command{
+	Use:   "apply <plan-file>",
+	Short: "Apply a plan created by 'awsm sso plan'",
+	Long: `Applies the changes saved by 'awsm sso plan' to ~/.aws/config (or the
+generated profiles fragment in ~/.aws/config.d) without
+contacting AWS. The plan is refused if the config file changed since it was
+created, unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plan, err := awsmConfig.LoadPlan(args[0])
+		if err != nil {
+			return err
+		}
+
+		configPath, err := aws.GeneratedProfilesPath()
+		if err != nil {
+			return err
+		}
+		existingConfig, err := awsmConfig.ReadConfigFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+		if awsmConfig.ContentHash(existingConfig) != plan.ConfigHash && !applyForce {
+			return fmt.Errorf("%s changed since the plan was created, run 'awsm sso plan %s' again or use --force", configPath, plan.SSOSession)
+		}
+
+		printSSOPlan(plan)
+		if len(plan.Changes) == 0 {
+			return nil
+		}
+		if err := applySSOPlan(plan, configPath); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("\n✔ Plan applied to %s\n", util.BoldColor.Sprint(configPath))
+		return nil
+	},
+}
+
+// printSSOPlan prints each change of a plan followed by a summary line.
+func printSSOPlan(plan *awsmConfig.Plan) {
+	for _, c := range plan.Changes {
+		switch c.Action {
+		case awsmConfig.PlanAdd:
+			util.SuccessColor.Printf("  + %s\n", c.Profile)
+		case awsmConfig.PlanUpdate:
+			util.WarnColor.Printf("  ~ %s\n", c.Profile)
+			printProfileDiff(c)
+		case awsmConfig.PlanPrune:
+			util.ErrorColor.Printf("  - %s\n", c.Profile)
+		}
+	}
+	fmt.Printf("Plan: %d to add, %d to update, %d to prune, %d unchanged.\n",
+		plan.Count(awsmConfig.PlanAdd), plan.Count(awsmConfig.PlanUpdate), plan.Count(awsmConfig.PlanPrune), plan.Unchanged)
+}
+
+// printProfileDiff prints the keys an update changes, old values in red and new ones in green.
+// Updates that only rewrite the section's formatting have no key changes.
+func printProfileDiff(c awsmConfig.PlanChange) {
+	diff := c.Diff
+	if diff == nil {
+		diff = awsmConfig.DiffKeys(c.Old, c.New)
+	}
+	if len(diff) == 0 {
+		fmt.Println("      (formatting only)")
+		return
+	}
+	for _, d := range diff {
+		if d.Old != "" {
+			util.ErrorColor.Printf("      - %s = %s\n", d.Key, d.Old)
+		}
+		if d.New != "" {
+			util.SuccessColor.Printf("      + %s = %s\n", d.Key, d.New)
+		}
+	}
+}
+
+func init() {
+	ssoPlanCmd.Flags().StringVarP(&planOutput, "output", "o", "", "Plan file to write (default awsm-sso-<session>.plan.json)")
+	ssoPlanCmd.Flags().BoolVar(&planPrune, "prune", false, "Remove generated profiles that are no longer accessible")
+	ssoPlanCmd.Flags().IntVar(&generateMaxAccounts, "max-accounts", 0, "Stop after processing this many accounts (0 = no limit)")
+	addProfileNameFlags(ssoPlanCmd)
+	ssoApplyCmd.Flags().BoolVarP(&applyForce, "force", "f", false, "Apply even if the config file changed since the plan was created")
+	ssoCmd.AddCommand(ssoPlanCmd)
+	ssoCmd.AddCommand(ssoApplyCmd)
+}
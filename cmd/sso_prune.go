@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+)
+
+var ssoPruneYes bool
+
+var ssoPruneCmd = &cobra.Command{
+	Use:   "prune <sso-session-name>",
+	Short: "Delete generated profiles whose account or role is no longer accessible",
+	Long: `Lists the accounts and roles of an SSO session like 'awsm sso generate' and
+deletes the generated profiles of that session that no longer match any of
+them, for example after a permission set was revoked. Only profiles carrying
+the '# Managed by awsm sso generate' comment are considered, never hand-written
+ones, and profiles of accounts whose roles failed to list are kept.
+
+Nothing is added or updated; use 'awsm sso generate' for that.
+
+Examples:
+  awsm sso prune company
+  awsm sso prune company --yes`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArgs(completeSSOSessions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ssoSession := args[0]
+
+		discovery, err := discoverSSOProfiles(ssoSession)
+		if err != nil {
+			return err
+		}
+		configPath, err := aws.GeneratedProfilesPath()
+		if err != nil {
+			return err
+		}
+		existingConfig, err := awsmConfig.ReadConfigFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", configPath, err)
+		}
+
+		plan := awsmConfig.FindOrphanedProfiles(existingConfig, discovery.Profiles, ssoSession)
+		if kept := plan.KeepAccounts(discovery.FailedAccounts...); len(kept) > 0 {
+			util.WarnColor.Printf("Keeping %d profiles of accounts whose roles could not be listed: %s\n", len(kept), strings.Join(kept, ", "))
+		}
+		if len(plan.Changes) == 0 {
+			util.SuccessColor.Printf("✔ All generated profiles of %s are still accessible\n", ssoSession)
+			return nil
+		}
+
+		fmt.Printf("\n%d profiles are no longer accessible:\n", len(plan.Changes))
+		for _, c := range plan.Changes {
+			util.ErrorColor.Printf("  - %s", c.Profile)
+			fmt.Printf(" (%s, %s)\n", c.Old["sso_account_id"], c.Old["sso_role_name"])
+		}
+
+		if !ssoPruneYes {
+			if !isatty.IsTerminal(os.Stdin.Fd()) {
+				return fmt.Errorf("not deleting without confirmation, pass --yes to delete them")
+			}
+			confirm, err := util.PromptForInput(fmt.Sprintf("Delete these %d profiles? (y/N): ", len(plan.Changes)))
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(strings.TrimSpace(confirm), "y") {
+				util.InfoColor.Println("Nothing deleted")
+				return nil
+			}
+		}
+
+		if err := applySSOPlan(plan, configPath); err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ Deleted %d profiles from %s\n", len(plan.Changes), util.BoldColor.Sprint(configPath))
+		return nil
+	},
+}
+
+func init() {
+	ssoPruneCmd.Flags().BoolVarP(&ssoPruneYes, "yes", "y", false, "Delete without asking for confirmation")
+	ssoCmd.AddCommand(ssoPruneCmd)
+}
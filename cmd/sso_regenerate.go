@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	regenerateUpgradeFormat bool
+	regenerateAdopt         bool
+)
+
+var regenerateCmd = &cobra.Command{
+	Use:   "regenerate [sso-session-name]",
+	Short: "Regenerate SSO profiles or upgrade them to the current generated format",
+	Long: `Without flags, this is equivalent to 'awsm sso generate' for the given session.
+
+With --upgrade-format, profiles previously created by 'awsm sso generate' are
+rewritten offline to the format used by this version of awsm: missing keys are
+added and awsm's keys are put back in their canonical order. Keys you added to
+those profiles yourself are preserved. If no session is given, generated
+profiles of all sessions are upgraded. Generated profiles are recognized by the
+'# Managed by awsm sso generate' comment, which versions of awsm before it
+didn't write. Add --adopt to upgrade those too: the SSO profiles of the session
+without the comment are listed and, once confirmed, get it.
+
+Examples:
+  awsm sso regenerate company
+  awsm sso regenerate --upgrade-format
+  awsm sso regenerate company --upgrade-format
+  awsm sso regenerate company --upgrade-format --adopt`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeSSOSessions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var ssoSession string
+		if len(args) > 0 {
+			ssoSession = args[0]
+		}
+
+		if regenerateAdopt && !regenerateUpgradeFormat {
+			return fmt.Errorf("--adopt only works with --upgrade-format")
+		}
+		if !regenerateUpgradeFormat {
+			if ssoSession == "" {
+				return fmt.Errorf("an SSO session name is required unless --upgrade-format is used")
+			}
+			return runSSOGenerate(ssoSession)
+		}
+
+		if regenerateAdopt {
+			if ssoSession == "" {
+				return fmt.Errorf("--adopt needs the SSO session whose profiles to adopt")
+			}
+			if err := adoptGeneratedProfiles(ssoSession); err != nil {
+				return err
+			}
+		}
+
+		upgraded, err := aws.UpgradeGeneratedProfiles(ssoSession)
+		if err != nil {
+			return fmt.Errorf("failed to upgrade generated profiles: %w", err)
+		}
+		if len(upgraded) == 0 {
+			util.InfoColor.Println("All generated profiles already use the current format.")
+			return nil
+		}
+		for _, name := range upgraded {
+			fmt.Printf("  - %s\n", name)
+		}
+		util.SuccessColor.Printf("✔ Upgraded %d profile(s) to the current format\n", len(upgraded))
+		return nil
+	},
+}
+
+// adoptGeneratedProfiles marks the unmarked SSO profiles of ssoSession as
+// generated, after the user confirms the list.
+func adoptGeneratedProfiles(ssoSession string) error {
+	names, err := aws.AdoptableProfiles(ssoSession)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		util.InfoColor.Printf("No unmarked profiles of SSO session '%s' to adopt.\n", ssoSession)
+		return nil
+	}
+	fmt.Printf("SSO profiles of '%s' without the generated profile marker:\n", ssoSession)
+	for _, name := range names {
+		fmt.Printf("  - %s\n", name)
+	}
+	answer, err := util.PromptForInput(fmt.Sprintf("Treat these %d profile(s) as generated by awsm? (y/N): ", len(names)))
+	if err != nil {
+		return fmt.Errorf("failed to read answer: %w", err)
+	}
+	if !strings.EqualFold(answer, "y") && !strings.EqualFold(answer, "yes") {
+		util.WarnColor.Println("Not adopting any profiles.")
+		return nil
+	}
+	if err := aws.AdoptGeneratedProfiles(ssoSession, names); err != nil {
+		return fmt.Errorf("failed to adopt profiles: %w", err)
+	}
+	util.SuccessColor.Printf("✔ Adopted %d profile(s)\n", len(names))
+	return nil
+}
+
+func init() {
+	regenerateCmd.Flags().BoolVar(&regenerateUpgradeFormat, "upgrade-format", false, "Rewrite existing generated profiles to the current format without contacting AWS")
+	regenerateCmd.Flags().BoolVar(&regenerateAdopt, "adopt", false, "With --upgrade-format, also upgrade the session's SSO profiles without the generated marker, after confirmation")
+	ssoCmd.AddCommand(regenerateCmd)
+}
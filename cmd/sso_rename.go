@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"awsm/internal/aws"
+	"awsm/internal/util"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var ssoRenameCmd = &cobra.Command{
+	Use:   "rename-session <old-name> <new-name>",
+	Short: "Rename an SSO session and update all profiles that use it",
+	Long: `Renames an sso-session section in ~/.aws/config and rewrites the
+'sso_session' key of every profile that references it, so no profile is left
+pointing at a session that no longer exists.
+
+Example:
+  awsm sso rename-session company company-prod`,
+	Aliases:           []string{"rename"},
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeArgs(completeSSOSessions),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldName := args[0]
+		newName := args[1]
+
+		if oldName == newName {
+			return fmt.Errorf("new session name must be different from the current one")
+		}
+
+		index, err := aws.GetProfileIndex()
+		if err != nil {
+			return err
+		}
+		affected := index.ProfilesUsingSSO(oldName)
+
+		updated, err := aws.RenameSSOSession(oldName, newName)
+		if err != nil {
+			return fmt.Errorf("failed to rename SSO session: %w", err)
+		}
+
+		util.SuccessColor.Printf("✔ SSO session '%s' renamed to '%s'\n", oldName, newName)
+		util.InfoColor.Printf("%d profile(s) updated to use the new session name\n", updated)
+		if len(affected) > 0 {
+			util.WarnColor.Printf("Cached SSO tokens are stored per session name, run 'awsm sso login %s' before using these profiles:\n", newName)
+			for _, profile := range affected {
+				fmt.Printf("  - %s\n", profile)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	ssoCmd.AddCommand(ssoRenameCmd)
+}
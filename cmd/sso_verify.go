@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+
+	"awsm/internal/aws"
+	"awsm/internal/util"
+)
+
+// verifyWorkers bounds how many profiles are verified at once, to stay clear
+// of SSO and STS throttling in large organizations.
+const verifyWorkers = 5
+
+var (
+	generateVerify       bool
+	generateVerifySample int
+)
+
+// profileVerification is the outcome of verifying one generated profile.
+type profileVerification struct {
+	Profile  string
+	Identity *aws.Identity
+	Err      error
+}
+
+// verifyGeneratedProfile resolves a profile's credentials and asks STS who they belong to.
+func verifyGeneratedProfile(profileName string) (*aws.Identity, error) {
+	creds, _, err := aws.GetCredentialsForProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	region, _ := aws.GetProfileRegion(profileName)
+	return aws.GetIdentity(profileName, creds, region)
+}
+
+// verifyProfiles runs verify for every profile, a few at a time, and returns
+// the results in the order of profiles.
+func verifyProfiles(profiles []string, verify func(string) (*aws.Identity, error)) []profileVerification {
+	results := make([]profileVerification, len(profiles))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(verifyWorkers, len(profiles)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				identity, err := verify(profiles[i])
+				results[i] = profileVerification{Profile: profiles[i], Identity: identity, Err: err}
+			}
+		}()
+	}
+	for i := range profiles {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// sampleProfiles picks n profiles at random, keeping their order. n <= 0 or
+// n >= len(profiles) returns all of them.
+func sampleProfiles(profiles []string, n int) []string {
+	if n <= 0 || n >= len(profiles) {
+		return profiles
+	}
+	picked := rand.Perm(len(profiles))[:n]
+	chosen := make(map[int]bool, n)
+	for _, i := range picked {
+		chosen[i] = true
+	}
+	var sample []string
+	for i, p := range profiles {
+		if chosen[i] {
+			sample = append(sample, p)
+		}
+	}
+	return sample
+}
+
+// runGeneratedProfileVerification verifies the generated profiles requested
+// by --verify or --verify-sample and reports which ones work. It fails when
+// any profile does, so scripts notice broken profiles.
+func runGeneratedProfileVerification(profiles []string) error {
+	if !generateVerify && generateVerifySample <= 0 {
+		return nil
+	}
+	sample := profiles
+	if !generateVerify {
+		sample = sampleProfiles(profiles, generateVerifySample)
+	}
+	if len(sample) == 0 {
+		return nil
+	}
+
+	util.InfoColor.Fprintf(os.Stderr, "\nVerifying %d of %d generated profiles...\n", len(sample), len(profiles))
+	results := verifyProfiles(sample, verifyGeneratedProfile)
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			util.ErrorColor.Printf("  ✗ %s: %v\n", r.Profile, r.Err)
+			continue
+		}
+		util.SuccessColor.Printf("  ✔ %s", r.Profile)
+		fmt.Printf(" (%s)\n", r.Identity.Arn)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d verified profiles do not work; they were written anyway, fix or remove them", failed, len(results))
+	}
+	util.SuccessColor.Printf("✔ All %d verified profiles work\n", len(results))
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"awsm/internal/aws"
+)
+
+func TestVerifyProfiles(t *testing.T) {
+	var profiles []string
+	for i := range 12 {
+		profiles = append(profiles, fmt.Sprintf("account-%d-admin", i))
+	}
+	verify := func(profile string) (*aws.Identity, error) {
+		if profile == "account-7-admin" {
+			return nil, errors.New("AccessDenied")
+		}
+		return &aws.Identity{Arn: "arn:aws:sts::111111111111:assumed-role/" + profile + "/awsm"}, nil
+	}
+
+	results := verifyProfiles(profiles, verify)
+	if len(results) != len(profiles) {
+		t.Fatalf("expected %d results, got %d", len(profiles), len(results))
+	}
+	for i, r := range results {
+		if r.Profile != profiles[i] {
+			t.Errorf("result %d is for %s, want %s", i, r.Profile, profiles[i])
+		}
+		if (r.Err != nil) != (r.Profile == "account-7-admin") {
+			t.Errorf("unexpected outcome for %s: %v", r.Profile, r.Err)
+		}
+	}
+}
+
+func TestSampleProfiles(t *testing.T) {
+	profiles := []string{"a", "b", "c", "d", "e"}
+
+	sample := sampleProfiles(profiles, 3)
+	if len(sample) != 3 {
+		t.Fatalf("expected 3 profiles, got %v", sample)
+	}
+	if !slices.IsSorted(sample) {
+		t.Errorf("expected the sample to keep the profile order, got %v", sample)
+	}
+	for _, n := range []int{0, 5, 9} {
+		if got := sampleProfiles(profiles, n); len(got) != len(profiles) {
+			t.Errorf("sampleProfiles(%d) = %v, want all profiles", n, got)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/checks"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusNetwork bool
+	statusFix     bool
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status [check...]",
+	Short: "Show the active profile and check the local setup",
+	Long: `Shows the active profile and runs awsm's environment checks: AWS CLI,
+config file syntax, file permissions, SSO token expiry, region and completion
+scripts. Checks that call AWS, like the clock skew check, only run with
+--network or when named; 'awsm doctor' runs them all.
+
+With --fix, problems that can be fixed automatically (e.g. file permissions)
+are fixed without asking.
+
+The command exits with a non-zero status when a check reports an error.
+
+Examples:
+  awsm status
+  awsm status --network
+  awsm status sso-tokens region`,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return checks.Names(), cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		selected, err := checks.Select(args, statusNetwork)
+		if err != nil {
+			return err
+		}
+
+		profile := aws.GetCurrentProfileName()
+		expires, known := aws.DefaultCredentialsExpiry()
+		format := output.Selected
+		reports := checks.Run(context.Background(), selected)
+
+		if format.Structured() {
+			status := statusOutput{ActiveProfile: profile, Checks: make([]checkOutput, len(reports))}
+			if profile != "" && known && !expires.IsZero() {
+				status.CredentialsExpire = &expires
+			}
+			for i, r := range reports {
+				status.Checks[i] = checkReportOutput(r, statusFix)
+			}
+			if err := output.Render(format, output.Result{Data: status}); err != nil {
+				return err
+			}
+		} else {
+			if profile != "" {
+				fmt.Printf("Active profile: %s", util.BoldColor.Sprint(profile))
+				if reason, valid := stillValid(expires, known, 0, time.Now()); valid {
+					fmt.Printf(" (credentials %s)", reason)
+				} else if known {
+					util.WarnColor.Print(" (credentials expired)")
+				}
+				fmt.Println()
+			} else {
+				fmt.Println("Active profile: none")
+			}
+			fmt.Println()
+
+			for _, r := range reports {
+				printCheckReport(r)
+				if statusFix && r.Result.Fix != nil {
+					if err := r.Result.Fix.Apply(); err != nil {
+						util.ErrorColor.Printf("  Fix failed: %v\n", err)
+					} else {
+						util.SuccessColor.Printf("  ✔ Fixed: %s\n", r.Result.Fix.Description)
+					}
+				}
+			}
+		}
+
+		if checks.Worst(reports) == checks.Error {
+			cmd.SilenceErrors = format.Structured()
+			return fmt.Errorf("some checks failed")
+		}
+		return nil
+	},
+}
+
+// statusOutput is what 'awsm status' prints with --output json or yaml.
+type statusOutput struct {
+	ActiveProfile     string        `json:"active_profile"`
+	CredentialsExpire *time.Time    `json:"credentials_expire,omitempty"`
+	Checks            []checkOutput `json:"checks"`
+}
+
+// checkOutput is a check report as --output json and yaml print it.
+type checkOutput struct {
+	Name     string `json:"name"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Hint     string `json:"hint,omitempty"`
+	Fix      string `json:"fix,omitempty"`
+	Fixed    bool   `json:"fixed,omitempty"`
+	FixError string `json:"fix_error,omitempty"`
+}
+
+// checkReportOutput converts a report, applying its fix first when fix is set.
+func checkReportOutput(r checks.Report, fix bool) checkOutput {
+	out := checkOutput{
+		Name:     r.Check.Name,
+		Title:    r.Check.Title,
+		Severity: r.Result.Severity.String(),
+		Message:  r.Result.Message,
+		Hint:     r.Result.Hint,
+	}
+	if r.Result.Fix != nil {
+		out.Fix = r.Result.Fix.Description
+		if fix {
+			if err := r.Result.Fix.Apply(); err != nil {
+				out.FixError = err.Error()
+			} else {
+				out.Fixed = true
+			}
+		}
+	}
+	return out
+}
+
+// printCheckReport prints one check result with its hint.
+func printCheckReport(r checks.Report) {
+	lines := strings.Split(r.Result.Message, "\n")
+	switch r.Result.Severity {
+	case checks.OK:
+		util.SuccessColor.Printf("✔ %s: ", r.Check.Title)
+	case checks.Skipped:
+		util.InfoColor.Printf("- %s: ", r.Check.Title)
+	case checks.Warning:
+		util.WarnColor.Printf("⚠ %s: ", r.Check.Title)
+	default:
+		util.ErrorColor.Printf("✘ %s: ", r.Check.Title)
+	}
+	fmt.Println(lines[0])
+	for _, line := range lines[1:] {
+		fmt.Printf("  %s\n", line)
+	}
+	if r.Result.Severity >= checks.Warning && r.Result.Hint != "" {
+		hint := strings.Split(r.Result.Hint, "\n")
+		fmt.Printf("  → %s\n", hint[0])
+		for _, line := range hint[1:] {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
+
+// preflight runs environment checks before a command touches credentials. Errors
+// stop the command, warnings are printed.
+func preflight(names ...string) error {
+	return checks.Preflight(context.Background(), func(r checks.Report) {
+		util.Warn(util.WarnPreflight, "%s: %s", r.Check.Title, r.Result.Message)
+	}, names...)
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusNetwork, "network", false, "Also run checks that call AWS, like clock skew")
+	statusCmd.Flags().BoolVar(&statusFix, "fix", false, "Apply automatic fixes without asking")
+	rootCmd.AddCommand(statusCmd)
+}
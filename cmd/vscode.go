@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"awsm/internal/aws"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	vscodeDir  string
+	vscodeSave bool
+	vscodeOpen bool
+)
+
+var vscodeCmd = &cobra.Command{
+	Use:   "vscode",
+	Short: "Integrate awsm profiles with VS Code and the AWS Toolkit",
+}
+
+var vscodeSetupCmd = &cobra.Command{
+	Use:   "setup [profile]",
+	Short: "Wire a profile into the AWS Toolkit for a workspace",
+	Long: `Prepares a profile for the AWS Toolkit for VS Code and selects it for a workspace.
+
+SSO profiles are used by the Toolkit directly; their SSO session gets the
+sso_registration_scopes the Toolkit needs. Any other profile gets a companion
+profile 'awsm-<profile>' whose credential_process runs awsm, so MFA prompts,
+role chaining and caching keep working the way they do in awsm.
+
+The chosen profile is written to .vscode/settings.json (aws.profile) of the
+workspace. Without an argument the profile comes from the closest ` + awsmConfig.WorkspaceProfileFile + `
+file of the workspace, or the active profile. --save records it in ` + awsmConfig.WorkspaceProfileFile + `,
+--open launches VS Code on the workspace with AWS_PROFILE set (only effective
+when VS Code isn't already running).
+
+Examples:
+  awsm vscode setup prod-admin --save
+  awsm vscode setup --dir ~/src/api --open`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := filepath.Abs(vscodeDir)
+		if err != nil {
+			return fmt.Errorf("failed to resolve workspace directory: %w", err)
+		}
+
+		profileName, err := vscodeProfileForWorkspace(dir, args)
+		if err != nil {
+			return err
+		}
+		profile, err := findProfileInfo(profileName)
+		if err != nil {
+			return err
+		}
+
+		toolkitProfile := profile.Name
+		switch profile.Type {
+		case aws.ProfileTypeSSO:
+			if profile.SSOSession == "" {
+				break
+			}
+			changed, err := aws.EnsureSSOSessionScopes(profile.SSOSession)
+			if err != nil {
+				return fmt.Errorf("failed to prepare SSO session '%s': %w", profile.SSOSession, err)
+			}
+			if changed {
+				util.SuccessColor.Printf("✔ Added sso_registration_scopes to SSO session '%s'\n", profile.SSOSession)
+			}
+		case aws.ProfileTypeProcess:
+			// Already resolved by an external process the Toolkit can run itself
+		default:
+			toolkitProfile = "awsm-" + profile.Name
+			command, err := credentialProcessCommand(profile.Name)
+			if err != nil {
+				return err
+			}
+			if err := aws.AddCredentialProcessProfile(toolkitProfile, command, profile.Region); err != nil {
+				return fmt.Errorf("failed to write profile '%s': %w", toolkitProfile, err)
+			}
+			util.SuccessColor.Printf("✔ Profile '%s' gets its credentials from awsm\n", toolkitProfile)
+		}
+
+		settingsPath, err := writeVSCodeProfileSetting(dir, toolkitProfile)
+		if err != nil {
+			return err
+		}
+		util.SuccessColor.Printf("✔ %s uses profile '%s'\n", settingsPath, toolkitProfile)
+
+		if vscodeSave {
+			path, err := awsmConfig.SaveWorkspaceProfile(dir, profile.Name)
+			if err != nil {
+				return err
+			}
+			util.SuccessColor.Printf("✔ Saved workspace profile to %s\n", path)
+		}
+
+		if vscodeOpen {
+			code, err := exec.LookPath("code")
+			if err != nil {
+				return fmt.Errorf("the 'code' command was not found, install it from VS Code with 'Shell Command: Install code command in PATH'")
+			}
+			launch := exec.Command(code, dir)
+			launch.Env = append(os.Environ(), "AWS_PROFILE="+toolkitProfile)
+			if err := launch.Start(); err != nil {
+				return fmt.Errorf("failed to launch VS Code: %w", err)
+			}
+			util.InfoColor.Printf("Opening %s with AWS_PROFILE=%s\n", dir, toolkitProfile)
+		}
+		return nil
+	},
+}
+
+// vscodeProfileForWorkspace picks the profile to set up: the argument, the
+// workspace profile file or the active profile, in that order.
+func vscodeProfileForWorkspace(dir string, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	profile, path, err := awsmConfig.FindWorkspaceProfile(dir)
+	if err != nil {
+		return "", err
+	}
+	if profile != "" {
+		util.InfoColor.Printf("Using profile '%s' from %s\n", profile, path)
+		return profile, nil
+	}
+	if profile = aws.GetCurrentProfileName(); profile != "" {
+		return profile, nil
+	}
+	return "", fmt.Errorf("no profile given, no %s found and no active profile", awsmConfig.WorkspaceProfileFile)
+}
+
+// credentialProcessCommand returns the credential_process value that runs this
+// awsm binary for a profile, quoting the path when it contains spaces.
+func credentialProcessCommand(profileName string) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the awsm executable: %w", err)
+	}
+	if strings.ContainsAny(executable, " \t") {
+		executable = `"` + executable + `"`
+	}
+	return fmt.Sprintf("%s credential-process %s", executable, profileName), nil
+}
+
+// writeVSCodeProfileSetting sets aws.profile in the workspace settings, keeping
+// the other settings. Settings files with comments can't be merged safely and
+// are left alone.
+func writeVSCodeProfileSetting(dir, profile string) (string, error) {
+	path := filepath.Join(dir, ".vscode", "settings.json")
+	settings := make(map[string]any)
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &settings); err != nil {
+			return "", fmt.Errorf("could not parse %s (%v), add \"aws.profile\": \"profile:%s\" to it yourself", path, err, profile)
+		}
+	case !os.IsNotExist(err):
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	settings["aws.profile"] = "profile:" + profile
+	data, err = json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
+
+func init() {
+	vscodeSetupCmd.Flags().StringVarP(&vscodeDir, "dir", "d", ".", "Workspace directory")
+	vscodeSetupCmd.Flags().BoolVar(&vscodeSave, "save", false, "Record the profile in the workspace's "+awsmConfig.WorkspaceProfileFile)
+	vscodeSetupCmd.Flags().BoolVar(&vscodeOpen, "open", false, "Launch VS Code on the workspace with AWS_PROFILE set")
+	vscodeCmd.AddCommand(vscodeSetupCmd)
+	rootCmd.AddCommand(vscodeCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"awsm/internal/aws"
+)
+
+func TestWriteVSCodeProfileSetting(t *testing.T) {
+	dir := t.TempDir()
+	settingsPath := filepath.Join(dir, ".vscode", "settings.json")
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"editor.tabSize": 2}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writeVSCodeProfileSetting(dir, "awsm-prod"); err != nil {
+		t.Fatalf("writeVSCodeProfileSetting failed: %v", err)
+	}
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["aws.profile"] != "profile:awsm-prod" || settings["editor.tabSize"] != float64(2) {
+		t.Errorf("Unexpected settings: %v", settings)
+	}
+
+	// Settings with comments are not rewritten
+	commented := "{\n  // tabs\n  \"editor.tabSize\": 2\n}\n"
+	if err := os.WriteFile(settingsPath, []byte(commented), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeVSCodeProfileSetting(dir, "awsm-prod"); err == nil || !strings.Contains(err.Error(), "aws.profile") {
+		t.Errorf("Expected an error explaining the setting to add, got %v", err)
+	}
+	if data, _ := os.ReadFile(settingsPath); string(data) != commented {
+		t.Error("Expected the commented settings file to be left alone")
+	}
+}
+
+func TestFormatCredentialProcess(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	output, err := formatCredentialProcess(&aws.TempCredentials{AccessKeyId: "ASIA123", SecretAccessKey: "secret", SessionToken: "token", Expires: expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"Version":1,"AccessKeyId":"ASIA123","SecretAccessKey":"secret","SessionToken":"token","Expiration":"2026-01-02T03:04:05Z"}`
+	if string(output) != expected {
+		t.Errorf("Expected %s, got %s", expected, output)
+	}
+
+	output, err = formatCredentialProcess(&aws.TempCredentials{AccessKeyId: "AKIA123", SecretAccessKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(output), "Expiration") || strings.Contains(string(output), "SessionToken") {
+		t.Errorf("Expected static credentials without expiration or token, got %s", output)
+	}
+}
+
+func TestIsSelfCredentialProcess(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"awsm credential-process prod", true},
+		{`/usr/local/bin/awsm credential-process "prod"`, true},
+		{"awsm credential-process prod-admin", false},
+		{"aws-vault exec prod --json", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isSelfCredentialProcess(tt.command, "prod"); got != tt.want {
+			t.Errorf("isSelfCredentialProcess(%q) = %v, expected %v", tt.command, got, tt.want)
+		}
+	}
+}
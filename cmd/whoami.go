@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"awsm/internal/agent"
+	"awsm/internal/aws"
+	"awsm/internal/output"
+	"awsm/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+var whoamiJSON bool
+
+// whoamiInfo is what 'awsm whoami' reports about a profile's credentials.
+type whoamiInfo struct {
+	Profile        string     `json:"profile"`
+	Account        string     `json:"account"`
+	AccountAlias   string     `json:"account_alias,omitempty"`
+	Arn            string     `json:"arn"`
+	Principal      string     `json:"principal"`
+	Region         string     `json:"region,omitempty"`
+	CredentialType string     `json:"credential_type,omitempty"`
+	Expiration     *time.Time `json:"expiration,omitempty"`
+	LongTerm       bool       `json:"long_term"`
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami [profile]",
+	Short: "Show who the current credentials belong to",
+	Long: `Calls sts:GetCallerIdentity and prints the account ID, account alias, ARN,
+profile, region, credential type and expiration of the credentials.
+
+Without a profile the credentials activated with 'awsm profile set' are
+checked, or the profile in AWS_PROFILE when it is set. The account alias needs
+iam:ListAccountAliases and is left out when it can't be read.
+
+Examples:
+  awsm whoami
+  awsm whoami prod --output json`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeArgs(completeProfiles),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := whoami(args)
+		if err != nil {
+			return err
+		}
+		return output.Render(output.Current(whoamiJSON), output.Result{
+			Data: info,
+			Table: func() error {
+				printWhoami(info, time.Now())
+				return nil
+			},
+			Raw: func(w io.Writer) error {
+				// Like the text output of 'aws sts get-caller-identity'
+				_, err := fmt.Fprintf(w, "%s\t%s\t%s\n", info.Account, info.Arn, info.Profile)
+				return err
+			},
+		})
+	},
+}
+
+// whoami resolves the credentials to look at and asks STS about them.
+func whoami(args []string) (*whoamiInfo, error) {
+	info := &whoamiInfo{}
+	var creds *aws.TempCredentials
+	stsProfile := ""
+
+	switch {
+	case len(args) > 0:
+		info.Profile = args[0]
+	case os.Getenv("AWS_PROFILE") != "":
+		info.Profile = os.Getenv("AWS_PROFILE")
+	default:
+		// The credentials activated with 'awsm profile set' live in the default section
+		info.Profile = aws.GetCurrentProfileName()
+		if info.Profile == "" {
+			return nil, fmt.Errorf("no AWS profile set. Please specify a profile or run 'awsm profile set <profile-name>' first")
+		}
+		stsProfile = "default"
+		if expires, known := aws.DefaultCredentialsExpiry(); known {
+			info.setExpiration(expires)
+		}
+	}
+
+	if stsProfile == "" {
+		var ok bool
+		if creds, ok = credentialsFromAgent(info.Profile, agent.MinValidity); !ok {
+			var err error
+			if creds, err = getCredentialsWithLogin(info.Profile); err != nil {
+				return nil, err
+			}
+		}
+		stsProfile = info.Profile
+		info.setExpiration(creds.Expires)
+	}
+
+	info.Region = os.Getenv("AWS_REGION")
+	if info.Region == "" {
+		info.Region, _ = aws.GetProfileRegion(info.Profile)
+	}
+	if profiles, err := aws.ListProfilesDetailed(); err == nil {
+		for _, p := range profiles {
+			if p.Name == info.Profile {
+				info.CredentialType = string(p.Type)
+				break
+			}
+		}
+	}
+
+	identity, err := aws.GetIdentity(stsProfile, creds, info.Region)
+	if err != nil {
+		return nil, err
+	}
+	info.Account = identity.Account
+	info.AccountAlias = identity.AccountAlias
+	info.Arn = identity.Arn
+	info.Principal = identity.Principal
+	return info, nil
+}
+
+// setExpiration records when credentials expire; a zero time means long-term keys.
+func (w *whoamiInfo) setExpiration(expires time.Time) {
+	if expires.IsZero() {
+		w.LongTerm = true
+		return
+	}
+	w.Expiration = &expires
+}
+
+func printWhoami(info *whoamiInfo, now time.Time) {
+	account := info.Account
+	if info.AccountAlias != "" {
+		account = fmt.Sprintf("%s (%s)", info.Account, info.AccountAlias)
+	}
+	fmt.Printf("%-12s %s\n", "Account:", util.BoldColor.Sprint(account))
+	fmt.Printf("%-12s %s\n", "ARN:", info.Arn)
+	fmt.Printf("%-12s %s\n", "Principal:", info.Principal)
+	fmt.Printf("%-12s %s\n", "Profile:", info.Profile)
+	if info.Region != "" {
+		fmt.Printf("%-12s %s\n", "Region:", info.Region)
+	}
+	if info.CredentialType != "" {
+		fmt.Printf("%-12s %s\n", "Type:", info.CredentialType)
+	}
+	switch {
+	case info.LongTerm:
+		fmt.Printf("%-12s %s\n", "Expires:", "never (long-term keys)")
+	case info.Expiration != nil:
+		remaining := info.Expiration.Sub(now)
+		expires := info.Expiration.Local().Format("2006-01-02 15:04:05")
+		if remaining > 0 {
+			fmt.Printf("%-12s %s (in %s)\n", "Expires:", expires, remaining.Round(time.Minute))
+		} else {
+			fmt.Printf("%-12s %s\n", "Expires:", util.WarnColor.Sprintf("%s (expired)", expires))
+		}
+	}
+}
+
+func init() {
+	whoamiCmd.Flags().BoolVarP(&whoamiJSON, "json", "j", false, "Output the identity in JSON format (same as --output json)")
+	rootCmd.AddCommand(whoamiCmd)
+}
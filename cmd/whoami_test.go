@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWhoamiInfoJSON(t *testing.T) {
+	expires := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	info := &whoamiInfo{Profile: "prod", Account: "123456789012", Arn: "arn:aws:sts::123456789012:assumed-role/Admin/awsm", Principal: "Admin"}
+	info.setExpiration(expires)
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"account":"123456789012"`, `"expiration":"2030-01-01T12:00:00Z"`, `"long_term":false`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s does not contain %s", data, want)
+		}
+	}
+	if strings.Contains(string(data), "account_alias") {
+		t.Errorf("empty account alias should be left out: %s", data)
+	}
+
+	longTerm := &whoamiInfo{}
+	longTerm.setExpiration(time.Time{})
+	if !longTerm.LongTerm || longTerm.Expiration != nil {
+		t.Errorf("zero expiry should mean long-term keys, got %+v", longTerm)
+	}
+}
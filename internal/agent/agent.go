@@ -0,0 +1,370 @@
+// Package agent keeps temporary credentials in memory in a background process
+// and hands them out over a local unix socket, so commands run in quick
+// succession don't repeat STS or SSO calls and MFA prompts.
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+)
+
+// MinValidity is how long credentials must stay valid to be handed out from
+// memory when a request doesn't ask for longer. Credentials expiring sooner
+// are fetched again.
+const MinValidity = 5 * time.Minute
+
+// requestTimeout is how long a client has to send its request, so
+// connections that never do don't stay open.
+var requestTimeout = 10 * time.Second
+
+// ErrNotRunning is returned by the client functions when no agent listens.
+var ErrNotRunning = errors.New("awsm agent is not running")
+
+// MFARequiredError is returned when the agent needs an MFA code to get new
+// credentials. The client asks the user and repeats the request with it.
+type MFARequiredError struct {
+	Serial string
+}
+
+func (e *MFARequiredError) Error() string {
+	return fmt.Sprintf("MFA code required for %s", e.Serial)
+}
+
+// SocketPath returns the path of the agent's unix socket.
+func SocketPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "agent.sock"), nil
+}
+
+// request is a single line sent to the agent.
+type request struct {
+	Op          string        `json:"op"`
+	Profile     string        `json:"profile,omitempty"`
+	MFAToken    string        `json:"mfa_token,omitempty"`
+	MinValidity time.Duration `json:"min_validity,omitempty"`
+}
+
+// response is the agent's answer to a request.
+type response struct {
+	Credentials *aws.TempCredentials `json:"credentials,omitempty"`
+	Entries     []Entry              `json:"entries,omitempty"`
+	MFASerial   string               `json:"mfa_serial,omitempty"`
+	Error       string               `json:"error,omitempty"`
+}
+
+// Entry describes credentials held by the agent.
+type Entry struct {
+	Profile string    `json:"profile"`
+	Expires time.Time `json:"expires"`
+}
+
+// Fetcher gets new credentials for a profile. It returns a
+// *MFARequiredError when the profile needs an MFA code that wasn't given.
+type Fetcher func(profile, mfaToken string) (*aws.TempCredentials, error)
+
+// FetchCredentials is the Fetcher used by the agent: it resolves credentials
+// like 'awsm profile set', but never prompts.
+func FetchCredentials(profile, mfaToken string) (*aws.TempCredentials, error) {
+	if mfaToken == "" {
+		if needsMFA, serial, err := aws.ProfileNeedsMFA(profile); err == nil && needsMFA && !aws.HasValidCachedCredentials(profile) {
+			return nil, &MFARequiredError{Serial: serial}
+		}
+	}
+	creds, _, err := aws.GetCredentialsForProfile(profile, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if creds == nil || creds.AccessKeyId == "" {
+		return nil, fmt.Errorf("no credentials available for profile '%s'", profile)
+	}
+	return creds, nil
+}
+
+// Agent holds credentials per profile until shortly before they expire, or
+// until the profile's config changes.
+type Agent struct {
+	fetch       Fetcher
+	fingerprint func(profile string) (string, error)
+	now         func() time.Time
+
+	mu    sync.Mutex
+	creds map[string]heldCredentials
+	locks map[string]*sync.Mutex
+	stop  chan struct{}
+}
+
+// heldCredentials are credentials with the fingerprint of the profile config
+// they were fetched for.
+type heldCredentials struct {
+	creds       *aws.TempCredentials
+	fingerprint string
+}
+
+// New returns an agent getting credentials with fetch.
+func New(fetch Fetcher) *Agent {
+	return &Agent{
+		fetch:       fetch,
+		fingerprint: aws.ProfileFingerprint,
+		now:         time.Now,
+		creds:       make(map[string]heldCredentials),
+		locks:       make(map[string]*sync.Mutex),
+		stop:        make(chan struct{}),
+	}
+}
+
+// Get returns credentials for a profile from memory, or fetches new ones when
+// none are held or they expire within minValidity (at least MinValidity).
+// Concurrent requests for the same profile wait for a single fetch.
+func (a *Agent) Get(profile, mfaToken string, minValidity time.Duration) (*aws.TempCredentials, error) {
+	minValidity = max(minValidity, MinValidity)
+
+	a.mu.Lock()
+	lock, ok := a.locks[profile]
+	if !ok {
+		lock = &sync.Mutex{}
+		a.locks[profile] = lock
+	}
+	a.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	fingerprint := a.profileFingerprint(profile)
+	if creds := a.held(profile, minValidity, fingerprint); creds != nil {
+		return creds, nil
+	}
+	creds, err := a.fetch(profile, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	// Long-term keys are read from disk cheaply and are not worth holding
+	if !creds.Expires.IsZero() {
+		a.mu.Lock()
+		a.creds[profile] = heldCredentials{creds: creds, fingerprint: fingerprint}
+		a.mu.Unlock()
+	}
+	return creds, nil
+}
+
+// profileFingerprint returns the fingerprint of a profile's config. A config
+// that can't be read has an empty one, fetching reports the actual problem.
+func (a *Agent) profileFingerprint(profile string) string {
+	fingerprint, err := a.fingerprint(profile)
+	if err != nil {
+		return ""
+	}
+	return fingerprint
+}
+
+// held returns the credentials of a profile if they stay valid for
+// minValidity and were fetched for the profile config with fingerprint.
+// Credentials expiring within MinValidity are dropped, and so are ones of a
+// changed config, together with the copy awsm cached on disk.
+func (a *Agent) held(profile string, minValidity time.Duration, fingerprint string) *aws.TempCredentials {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	held, ok := a.creds[profile]
+	if !ok {
+		return nil
+	}
+	if held.fingerprint != fingerprint {
+		delete(a.creds, profile)
+		aws.InvalidateCachedCredentials(profile)
+		return nil
+	}
+	remaining := held.creds.Expires.Sub(a.now())
+	if remaining < MinValidity {
+		delete(a.creds, profile)
+	}
+	if remaining < minValidity {
+		return nil
+	}
+	return held.creds
+}
+
+// Entries lists the credentials held, dropping the ones about to expire.
+func (a *Agent) Entries() []Entry {
+	a.mu.Lock()
+	profiles := make([]string, 0, len(a.creds))
+	for profile := range a.creds {
+		profiles = append(profiles, profile)
+	}
+	a.mu.Unlock()
+
+	var entries []Entry
+	for _, profile := range profiles {
+		if creds := a.held(profile, MinValidity, a.profileFingerprint(profile)); creds != nil {
+			entries = append(entries, Entry{Profile: profile, Expires: creds.Expires})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Profile < entries[j].Profile })
+	return entries
+}
+
+// Serve answers requests on listener until a stop request arrives or the
+// listener fails.
+func (a *Agent) Serve(listener net.Listener) error {
+	go func() {
+		<-a.stop
+		listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-a.stop:
+				return nil
+			default:
+				return err
+			}
+		}
+		go a.handle(conn)
+	}
+}
+
+func (a *Agent) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(requestTimeout))
+	var req request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		return
+	}
+	// Fetching may take a while, the client waits for it
+	conn.SetDeadline(time.Time{})
+
+	var resp response
+	switch req.Op {
+	case "get":
+		creds, err := a.Get(req.Profile, req.MFAToken, req.MinValidity)
+		var mfaErr *MFARequiredError
+		switch {
+		case errors.As(err, &mfaErr):
+			resp.MFASerial = mfaErr.Serial
+			resp.Error = err.Error()
+		case err != nil:
+			resp.Error = err.Error()
+		default:
+			resp.Credentials = creds
+		}
+	case "status":
+		resp.Entries = a.Entries()
+	case "stop":
+		defer a.Stop()
+	default:
+		resp.Error = fmt.Sprintf("unknown request '%s'", req.Op)
+	}
+	conn.SetWriteDeadline(time.Now().Add(requestTimeout))
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// Stop makes Serve return. It is safe to call more than once.
+func (a *Agent) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	select {
+	case <-a.stop:
+	default:
+		close(a.stop)
+	}
+}
+
+// Listen opens the agent's socket, readable only by the user. A socket left
+// behind by an agent that died is replaced; a running agent is an error.
+func Listen() (net.Listener, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create awsm state directory: %w", err)
+	}
+	if Running() {
+		return nil, fmt.Errorf("awsm agent is already running on %s", path)
+	}
+	os.Remove(path)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict %s: %w", path, err)
+	}
+	return listener, nil
+}
+
+// Running reports whether an agent answers on the socket.
+func Running() bool {
+	_, err := call(request{Op: "status"}, time.Second)
+	return err == nil
+}
+
+// GetCredentials asks the agent for credentials of a profile that stay valid
+// for minValidity. It returns ErrNotRunning without an agent and
+// *MFARequiredError when the agent needs an MFA code, to be passed as
+// mfaToken in the next request.
+func GetCredentials(profile, mfaToken string, minValidity time.Duration) (*aws.TempCredentials, error) {
+	resp, err := call(request{Op: "get", Profile: profile, MFAToken: mfaToken, MinValidity: minValidity}, 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if resp.MFASerial != "" {
+		return nil, &MFARequiredError{Serial: resp.MFASerial}
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Credentials, nil
+}
+
+// Status lists the credentials the agent holds.
+func Status() ([]Entry, error) {
+	resp, err := call(request{Op: "status"}, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
+
+// StopAgent asks the agent to exit.
+func StopAgent() error {
+	_, err := call(request{Op: "stop"}, 5*time.Second)
+	return err
+}
+
+// call sends one request to the agent and waits up to timeout for the answer.
+func call(req request, timeout time.Duration) (*response, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to talk to awsm agent: %w", err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read the awsm agent's answer: %w", err)
+	}
+	return &resp, nil
+}
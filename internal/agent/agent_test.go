@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+)
+
+func TestAgentHoldsCredentialsUntilExpiry(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	fetches := 0
+	a := New(func(profile, mfaToken string) (*aws.TempCredentials, error) {
+		fetches++
+		return &aws.TempCredentials{AccessKeyId: "AKIA", Expires: now.Add(time.Hour)}, nil
+	})
+	a.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := a.Get("prod", "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("Expected a single fetch, got %d", fetches)
+	}
+
+	// A caller needing a longer validity gets new credentials
+	if _, err := a.Get("prod", "", 2*time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected a fetch for a longer validity, got %d fetches", fetches)
+	}
+
+	// Credentials about to expire are dropped
+	now = now.Add(58 * time.Minute)
+	if entries := a.Entries(); len(entries) != 0 {
+		t.Errorf("Expected expiring credentials to be dropped, got %v", entries)
+	}
+	if _, err := a.Get("prod", "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 3 {
+		t.Errorf("Expected a fetch after expiry, got %d fetches", fetches)
+	}
+}
+
+func TestAgentServesOverSocket(t *testing.T) {
+	// A short path, unix socket paths are limited to about 100 bytes
+	dir, err := os.MkdirTemp("", "awsm-agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv(config.HomeEnv, dir)
+
+	if _, err := GetCredentials("prod", "", 0); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Expected ErrNotRunning without an agent, got %v", err)
+	}
+
+	a := New(func(profile, mfaToken string) (*aws.TempCredentials, error) {
+		if mfaToken == "" {
+			return nil, &MFARequiredError{Serial: "arn:aws:iam::111111111111:mfa/alice"}
+		}
+		return &aws.TempCredentials{AccessKeyId: "AKIA" + mfaToken, Expires: time.Now().Add(time.Hour)}, nil
+	})
+	listener, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- a.Serve(listener) }()
+
+	if _, err := Listen(); err == nil {
+		t.Error("Expected an error when an agent is already running")
+	}
+
+	var mfaErr *MFARequiredError
+	if _, err := GetCredentials("prod", "", 0); !errors.As(err, &mfaErr) || mfaErr.Serial != "arn:aws:iam::111111111111:mfa/alice" {
+		t.Fatalf("Expected an MFA request, got %v", err)
+	}
+	creds, err := GetCredentials("prod", "123456", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyId != "AKIA123456" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+	// Held credentials need no MFA code
+	if _, err := GetCredentials("prod", "", 0); err != nil {
+		t.Errorf("Expected held credentials, got %v", err)
+	}
+
+	entries, err := Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Profile != "prod" {
+		t.Errorf("Unexpected entries %v", entries)
+	}
+
+	if err := StopAgent(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Agent did not stop")
+	}
+}
+
+func TestAgentDropsCredentialsOnConfigChange(t *testing.T) {
+	t.Setenv(config.HomeEnv, t.TempDir())
+	fetches := 0
+	a := New(func(profile, mfaToken string) (*aws.TempCredentials, error) {
+		fetches++
+		return &aws.TempCredentials{AccessKeyId: "AKIA", Expires: time.Now().Add(time.Hour)}, nil
+	})
+	roleARN := "arn:aws:iam::111111111111:role/Admin"
+	a.fingerprint = func(profile string) (string, error) { return roleARN, nil }
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Get("prod", "", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fetches != 1 {
+		t.Fatalf("Expected a single fetch, got %d", fetches)
+	}
+
+	roleARN = "arn:aws:iam::222222222222:role/Admin"
+	if entries := a.Entries(); len(entries) != 0 {
+		t.Errorf("Expected credentials of the old config to be dropped, got %v", entries)
+	}
+	if _, err := a.Get("prod", "", 0); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("Expected a fetch after the config changed, got %d fetches", fetches)
+	}
+}
+
+func TestAgentClosesIdleConnections(t *testing.T) {
+	defer func(timeout time.Duration) { requestTimeout = timeout }(requestTimeout)
+	requestTimeout = 50 * time.Millisecond
+
+	a := New(func(profile, mfaToken string) (*aws.TempCredentials, error) {
+		return nil, errors.New("not called")
+	})
+	server, client := net.Pipe()
+	defer client.Close()
+	done := make(chan struct{})
+	go func() {
+		a.handle(server)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a connection without a request to be closed")
+	}
+}
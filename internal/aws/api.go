@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+var apiHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// apiRequest sends a SigV4 signed request to an AWS API and returns the
+// response body. awsm only needs a call or two of some services, so it signs
+// them itself rather than pulling in their SDKs. Errors carry the error type
+// and message the service returns.
+func apiRequest(creds *TempCredentials, method, url, service, region string, header http.Header, body []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	payloadHash := sha256.Sum256(body)
+	signerCreds := aws.Credentials{AccessKeyID: creds.AccessKeyId, SecretAccessKey: creds.SecretAccessKey, SessionToken: creds.SessionToken}
+	if err := v4.NewSigner().SignHTTP(ctx, signerCreds, req, hex.EncodeToString(payloadHash[:]), service, region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := apiHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp, data)
+	}
+	return data, nil
+}
+
+// apiError turns an error response of a REST or JSON protocol API into an
+// error like "ResourceNotFoundException: No cluster found".
+func apiError(resp *http.Response, data []byte) error {
+	var body struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	json.Unmarshal(data, &body)
+	message := body.Message
+	if message == "" {
+		message = body.MessageUpper
+	}
+	if message == "" {
+		message = resp.Status
+	}
+
+	// Types come as "Name:extra" in the header and "namespace#Name" in the body
+	errorType := resp.Header.Get("X-Amzn-Errortype")
+	if errorType == "" {
+		errorType = body.Type
+	}
+	errorType, _, _ = strings.Cut(errorType, ":")
+	if i := strings.LastIndex(errorType, "#"); i >= 0 {
+		errorType = errorType[i+1:]
+	}
+	if errorType == "" {
+		return fmt.Errorf("%s", message)
+	}
+	return fmt.Errorf("%s: %s", errorType, message)
+}
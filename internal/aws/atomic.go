@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"io"
+	"os"
+
+	awsmConfig "awsm/internal/config"
+
+	"gopkg.in/ini.v1"
+)
+
+// writeFileAtomic replaces path with data in one step, see
+// awsmConfig.WriteFileAtomic. Every write of the AWS config and credentials
+// files and of cached credentials goes through it or saveIniAtomic.
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	return awsmConfig.WriteFileAtomic(path, mode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// saveIniAtomic writes an ini file like writeFileAtomic, holding the lock on
+// the AWS config files. Comments loaded with the file are written back, and a
+// new file is readable only by the user.
+func saveIniAtomic(cfg *ini.File, path string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return awsmConfig.WriteFileAtomic(path, 0600, func(w io.Writer) error {
+		_, err := cfg.WriteTo(w)
+		return err
+	})
+}
+
+// updateIniAtomic loads path, or starts an empty file when it doesn't exist,
+// lets update change it and saves it with saveIniAtomic. The lock on the AWS
+// config files is held throughout, so no other awsm process changes the file
+// in between. Nothing is written when update fails.
+func updateIniAtomic(path string, update func(cfg *ini.File) error) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	cfg, err := loadOrCreateIni(path)
+	if err != nil {
+		return err
+	}
+	if err := update(cfg); err != nil {
+		return err
+	}
+	return saveIniAtomic(cfg, path)
+}
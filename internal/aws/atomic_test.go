@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestSaveIniAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+
+	cfg := ini.Empty()
+	cfg.Section("default").Key("aws_access_key_id").SetValue("AKIAOLD")
+	if err := saveIniAtomic(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a new file to be 0600, got %v", info.Mode().Perm())
+	}
+
+	// An existing file keeps its permissions
+	if err := os.Chmod(path, 0640); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Section("default").Key("aws_access_key_id").SetValue("AKIANEW")
+	if err := saveIniAtomic(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected permissions 0640 to be kept, got %v", info.Mode().Perm())
+	}
+
+	loaded, err := ini.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Section("default").Key("aws_access_key_id").String(); got != "AKIANEW" {
+		t.Errorf("Expected AKIANEW, got %s", got)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files left, got %v", entries)
+	}
+}
+
+func TestUpdateIniAtomicKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := "# Work accounts\n[profile dev]\n; the dev account\nregion = eu-west-1\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := updateIniAtomic(path, func(cfg *ini.File) error {
+		cfg.Section("profile dev").Key("region").SetValue("us-east-1")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"# Work accounts", "; the dev account", "us-east-1"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected %q in the saved file, got:\n%s", want, data)
+		}
+	}
+
+	// A failing update writes nothing
+	err = updateIniAtomic(path, func(cfg *ini.File) error {
+		cfg.Section("profile dev").Key("region").SetValue("ap-south-1")
+		return errors.New("invalid")
+	})
+	if err == nil {
+		t.Fatal("Expected the update error to be returned")
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(data) {
+		t.Errorf("Expected a failed update to leave the file alone, got:\n%s", after)
+	}
+}
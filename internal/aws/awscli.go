@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"awsm/internal/util"
+)
+
+// awsCLIInstallHelp explains how to get AWS CLI v2, which is needed for `aws sso login`.
+const awsCLIInstallHelp = `Install AWS CLI v2 (v1 does not support 'aws sso login'):
+  macOS:   brew install awscli   (or the pkg installer from https://awscli.amazonaws.com/AWSCLIV2.pkg)
+  Linux:   curl "https://awscli.amazonaws.com/awscli-exe-linux-$(uname -m).zip" -o awscliv2.zip && unzip awscliv2.zip && sudo ./aws/install
+  Windows: msiexec.exe /i https://awscli.amazonaws.com/AWSCLIV2.msi
+If v1 was installed with pip, remove it first with 'pip uninstall awscli'.
+See https://docs.aws.amazon.com/cli/latest/userguide/getting-started-install.html`
+
+// ErrAWSCLINotFound is returned when the aws executable is not on the PATH.
+var ErrAWSCLINotFound = errors.New("AWS CLI not found in PATH")
+
+var awsCLIVersionRegex = regexp.MustCompile(`aws-cli/(\d+)\.(\d+)\.(\d+)`)
+
+// AWSCLIVersion is the version of the installed AWS CLI.
+type AWSCLIVersion struct {
+	Major, Minor, Patch int
+	Path                string
+}
+
+func (v AWSCLIVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+var (
+	awsCLIVersionOnce sync.Once
+	awsCLIVersion     *AWSCLIVersion
+	awsCLIVersionErr  error
+)
+
+// ParseAWSCLIVersion parses the output of `aws --version`,
+// e.g. "aws-cli/2.15.30 Python/3.11.8 Darwin/23.4.0 exe/x86_64".
+func ParseAWSCLIVersion(output string) (*AWSCLIVersion, error) {
+	match := awsCLIVersionRegex.FindStringSubmatch(output)
+	if match == nil {
+		return nil, fmt.Errorf("unrecognized AWS CLI version output: %q", output)
+	}
+	v := &AWSCLIVersion{}
+	v.Major, _ = strconv.Atoi(match[1])
+	v.Minor, _ = strconv.Atoi(match[2])
+	v.Patch, _ = strconv.Atoi(match[3])
+	return v, nil
+}
+
+// DetectAWSCLIVersion runs `aws --version` once and caches the result.
+func DetectAWSCLIVersion() (*AWSCLIVersion, error) {
+	awsCLIVersionOnce.Do(func() {
+		path, err := exec.LookPath("aws")
+		if err != nil {
+			awsCLIVersionErr = ErrAWSCLINotFound
+			return
+		}
+		// AWS CLI v1 on Python 2 prints its version to stderr
+		output, err := exec.Command(path, "--version").CombinedOutput()
+		if err != nil {
+			awsCLIVersionErr = fmt.Errorf("failed to run '%s --version': %w", path, err)
+			return
+		}
+		awsCLIVersion, awsCLIVersionErr = ParseAWSCLIVersion(string(output))
+		if awsCLIVersion != nil {
+			awsCLIVersion.Path = path
+		}
+	})
+	return awsCLIVersion, awsCLIVersionErr
+}
+
+// requireAWSCLIv2 checks that the AWS CLI on the PATH supports SSO login and
+// returns an error with installation instructions otherwise. When the version
+// cannot be determined it only warns and lets the login try anyway.
+func requireAWSCLIv2() error {
+	warning, err := checkAWSCLIv2(DetectAWSCLIVersion())
+	if warning != "" {
+		util.Warn(util.WarnAWSCLIVersion, "%s", warning)
+	}
+	return err
+}
+
+// CheckAWSCLI detects the installed AWS CLI and reports whether it can be used
+// for SSO login, with the same warning and error requireAWSCLIv2 would give.
+func CheckAWSCLI() (*AWSCLIVersion, string, error) {
+	version, detectErr := DetectAWSCLIVersion()
+	warning, err := checkAWSCLIv2(version, detectErr)
+	return version, warning, err
+}
+
+// checkAWSCLIv2 decides whether a detected AWS CLI can be used for SSO login.
+// Only a missing binary or a positively detected v1 are errors; anything else
+// that keeps the version unknown is returned as a warning.
+func checkAWSCLIv2(version *AWSCLIVersion, detectErr error) (string, error) {
+	if errors.Is(detectErr, ErrAWSCLINotFound) {
+		return "", fmt.Errorf("%w, it is needed for SSO login.\n\n%s", detectErr, awsCLIInstallHelp)
+	}
+	if detectErr != nil {
+		return fmt.Sprintf("could not determine the AWS CLI version (%v), SSO login needs AWS CLI v2", detectErr), nil
+	}
+	if version.Major < 2 {
+		return "", fmt.Errorf("AWS CLI %s at %s does not support SSO login.\n\n%s", version, version.Path, awsCLIInstallHelp)
+	}
+	return "", nil
+}
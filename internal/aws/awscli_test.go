@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAWSCLIVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		major   int
+		version string
+		wantErr bool
+	}{
+		{"aws-cli/2.15.30 Python/3.11.8 Darwin/23.4.0 exe/x86_64 prompt/off\n", 2, "2.15.30", false},
+		{"aws-cli/1.18.69 Python/2.7.18 Linux/5.4.0 botocore/1.16.19\n", 1, "1.18.69", false},
+		{"command not found", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		v, err := ParseAWSCLIVersion(tt.output)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for %q", tt.output)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseAWSCLIVersion(%q) failed: %v", tt.output, err)
+		}
+		if v.Major != tt.major || v.String() != tt.version {
+			t.Errorf("Expected %s (major %d), got %s", tt.version, tt.major, v)
+		}
+	}
+}
+
+func TestCheckAWSCLIv2(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     *AWSCLIVersion
+		err         error
+		wantWarning bool
+		wantErr     bool
+	}{
+		{"v2", &AWSCLIVersion{Major: 2, Minor: 15}, nil, false, false},
+		{"v1", &AWSCLIVersion{Major: 1, Minor: 18, Path: "/usr/bin/aws"}, nil, false, true},
+		{"Not installed", nil, ErrAWSCLINotFound, false, true},
+		{"Unparseable output", nil, errors.New("unrecognized AWS CLI version output"), true, false},
+		{"Non-zero exit", nil, errors.New("failed to run 'aws --version': exit status 255"), true, false},
+	}
+	for _, tt := range tests {
+		warning, err := checkAWSCLIv2(tt.version, tt.err)
+		if (warning != "") != tt.wantWarning || (err != nil) != tt.wantErr {
+			t.Errorf("%s: got warning %q, error %v", tt.name, warning, err)
+		}
+	}
+}
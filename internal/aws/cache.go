@@ -0,0 +1,329 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+)
+
+// Kinds of data awsm keeps in its state directory, as named by 'awsm cache'.
+const (
+	CacheKindCredentials     = "credentials"
+	CacheKindPolicyOverrides = "policy-overrides"
+	CacheKindSessionStash    = "session-stash"
+)
+
+// CacheKinds lists every kind of cached data, in display order.
+var CacheKinds = []string{CacheKindCredentials, CacheKindPolicyOverrides, CacheKindSessionStash}
+
+// CacheUsage describes how much of one kind of data awsm keeps.
+type CacheUsage struct {
+	Kind    string `json:"kind"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+	// Expired counts cached credentials that can no longer be used.
+	Expired int `json:"expired"`
+}
+
+// CompactResult is what a compaction removed.
+type CompactResult struct {
+	Removed    int
+	FreedBytes int64
+}
+
+// credsCacheDir returns the directory holding cached credentials,
+// cache/credentials in the state directory. Credentials cached by older
+// versions directly in cache/ are moved there.
+func credsCacheDir() (string, error) {
+	dir, err := awsmConfig.StateDir()
+	if err != nil {
+		return "", err
+	}
+	cacheDir := filepath.Join(dir, "cache", "credentials")
+	migrateCredsCache(filepath.Join(dir, "cache"), cacheDir)
+	return cacheDir, nil
+}
+
+// migrateCredsCache moves the credential files of oldDir into newDir. Files
+// that can't be moved stay behind and are simply not used any more.
+func migrateCredsCache(oldDir, newDir string) {
+	entries, err := os.ReadDir(oldDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		if err := os.MkdirAll(newDir, 0700); err != nil {
+			return
+		}
+		os.Rename(filepath.Join(oldDir, entry.Name()), filepath.Join(newDir, entry.Name()))
+	}
+}
+
+// cachedCredentials is the file format of the credential cache: the
+// credentials with the profile they belong to and when they were cached.
+type cachedCredentials struct {
+	Profile  string    `json:"profile"`
+	CachedAt time.Time `json:"cached_at"`
+	TempCredentials
+}
+
+// CachedCredentialsEntry describes the cached credentials of a profile,
+// without the secrets.
+type CachedCredentialsEntry struct {
+	Profile     string    `json:"profile"`
+	AccessKeyId string    `json:"access_key_id,omitempty"`
+	CachedAt    time.Time `json:"cached_at,omitempty"`
+	Expires     time.Time `json:"expires,omitempty"`
+	// Unreadable is set for files that are not valid cache entries.
+	Unreadable bool `json:"unreadable,omitempty"`
+}
+
+// Expired reports whether the credentials can no longer be used at now.
+func (e CachedCredentialsEntry) Expired(now time.Time) bool {
+	return !e.Expires.After(now)
+}
+
+// cachedCredsFile is a file of the credential cache.
+type cachedCredsFile struct {
+	path     string
+	size     int64
+	modified time.Time
+	// entry has a zero expiry when the file can't be read.
+	entry CachedCredentialsEntry
+}
+
+func listCachedCredsFiles() ([]cachedCredsFile, error) {
+	dir, err := credsCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var files []cachedCredsFile
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		file := cachedCredsFile{path: filepath.Join(dir, entry.Name()), size: info.Size(), modified: info.ModTime()}
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+		file.entry = CachedCredentialsEntry{Profile: name, Unreadable: true}
+		if data, err := os.ReadFile(file.path); err == nil {
+			var cached cachedCredentials
+			if json.Unmarshal(data, &cached) == nil {
+				if cached.Profile != "" {
+					file.entry.Profile = cached.Profile
+				}
+				file.entry.AccessKeyId = cached.AccessKeyId
+				file.entry.CachedAt = cached.CachedAt
+				file.entry.Expires = cached.Expires
+				file.entry.Unreadable = false
+			}
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// ListCachedCredentials returns the profiles with cached credentials, sorted
+// by profile name.
+func ListCachedCredentials() ([]CachedCredentialsEntry, error) {
+	files, err := listCachedCredsFiles()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CachedCredentialsEntry, len(files))
+	for i, f := range files {
+		entries[i] = f.entry
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Profile < entries[j].Profile })
+	return entries, nil
+}
+
+// ClearCachedCredentials removes the cached credentials of the given profiles
+// and returns how many of them had any.
+func ClearCachedCredentials(profiles ...string) (int, error) {
+	removed := 0
+	for _, profile := range profiles {
+		path, err := credsCachePath(profile)
+		if err != nil {
+			return removed, err
+		}
+		err = os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func stashUsage() (entries int, size int64, err error) {
+	path, err := stashPath()
+	if err != nil {
+		return 0, 0, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	sessions, err := StashedSessions()
+	return len(sessions), info.Size(), err
+}
+
+// CacheUsages reports how much data of each kind awsm keeps.
+func CacheUsages(now time.Time) ([]CacheUsage, error) {
+	creds := CacheUsage{Kind: CacheKindCredentials}
+	files, err := listCachedCredsFiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		creds.Entries++
+		creds.Bytes += f.size
+		if f.entry.Expired(now) {
+			creds.Expired++
+		}
+	}
+
+	overrides := CacheUsage{Kind: CacheKindPolicyOverrides}
+	if overrides.Entries, overrides.Bytes, err = policy.OverrideLogUsage(); err != nil {
+		return nil, err
+	}
+	stash := CacheUsage{Kind: CacheKindSessionStash}
+	if stash.Entries, stash.Bytes, err = stashUsage(); err != nil {
+		return nil, err
+	}
+	return []CacheUsage{creds, overrides, stash}, nil
+}
+
+func totalCacheBytes(usages []CacheUsage) int64 {
+	var total int64
+	for _, u := range usages {
+		total += u.Bytes
+	}
+	return total
+}
+
+// CompactCache removes expired credentials and everything older than maxAge,
+// then trims the oldest policy override log entries until the state
+// directory fits in maxSize bytes.
+func CompactCache(maxAge time.Duration, maxSize int64, now time.Time) (CompactResult, error) {
+	var result CompactResult
+	before, err := CacheUsages(now)
+	if err != nil {
+		return result, err
+	}
+	cutoff := now.Add(-maxAge)
+
+	files, err := listCachedCredsFiles()
+	if err != nil {
+		return result, err
+	}
+	for _, f := range files {
+		if !f.entry.Expired(now) && f.modified.After(cutoff) {
+			continue
+		}
+		if err := os.Remove(f.path); err == nil {
+			result.Removed++
+		}
+	}
+
+	sessions, err := StashedSessions()
+	if err != nil {
+		return result, err
+	}
+	var kept []StashedSession
+	for _, s := range sessions {
+		if s.StashedAt.After(cutoff) {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) != len(sessions) {
+		if err := saveStashedSessions(kept); err != nil {
+			return result, err
+		}
+		result.Removed += len(sessions) - len(kept)
+	}
+
+	// The override log is the only thing that grows without bound, so it
+	// gets whatever room the rest leaves
+	usages, err := CacheUsages(now)
+	if err != nil {
+		return result, err
+	}
+	budget := maxSize - (totalCacheBytes(usages) - usages[1].Bytes)
+	if budget < 1 {
+		budget = 1
+	}
+	trimmed, err := policy.TrimOverrideLog(cutoff, budget)
+	if err != nil {
+		return result, fmt.Errorf("failed to compact the policy override log: %w", err)
+	}
+	result.Removed += trimmed
+
+	after, err := CacheUsages(now)
+	if err != nil {
+		return result, err
+	}
+	result.FreedBytes = totalCacheBytes(before) - totalCacheBytes(after)
+	return result, nil
+}
+
+// ClearCache removes all data of one kind and returns how many entries were removed.
+func ClearCache(kind string) (int, error) {
+	switch kind {
+	case CacheKindCredentials:
+		files, err := listCachedCredsFiles()
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range files {
+			if err := os.Remove(f.path); err != nil {
+				return 0, fmt.Errorf("failed to remove %s: %w", f.path, err)
+			}
+		}
+		return len(files), nil
+	case CacheKindPolicyOverrides:
+		// Every entry is newer than the zero time, so a 1-byte limit drops them all
+		return policy.TrimOverrideLog(time.Time{}, 1)
+	case CacheKindSessionStash:
+		entries, _, err := stashUsage()
+		if err != nil {
+			return 0, err
+		}
+		return entries, saveStashedSessions(nil)
+	default:
+		return 0, fmt.Errorf("unknown cache '%s' (known: %s)", kind, strings.Join(CacheKinds, ", "))
+	}
+}
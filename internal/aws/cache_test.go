@@ -0,0 +1,183 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+)
+
+func TestCompactCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(awsmConfig.HomeEnv, dir)
+	t.Setenv("HOME", dir)
+	now := time.Now()
+
+	CacheCredentials("valid", &TempCredentials{AccessKeyId: "ASIAVALID", Expires: now.Add(time.Hour)})
+	CacheCredentials("expired", &TempCredentials{AccessKeyId: "ASIAEXPIRED", Expires: now.Add(-time.Hour)})
+	if err := os.WriteFile(filepath.Join(dir, "cache", "credentials", "broken.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveStashedSessions([]StashedSession{
+		{Profile: "old", StashedAt: now.Add(-60 * 24 * time.Hour)},
+		{Profile: "recent", StashedAt: now.Add(-time.Hour)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var log strings.Builder
+	for i := 0; i < 50; i++ {
+		log.WriteString(`{"time":"` + now.Add(-time.Duration(50-i)*time.Minute).UTC().Format(time.RFC3339) + `","command":"awsm profile add","violations":["region"]}` + "\n")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "policy-overrides.log"), []byte(log.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	usages, err := CacheUsages(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usages[0].Entries != 3 || usages[0].Expired != 2 {
+		t.Errorf("Expected 3 cached credentials with 2 unusable, got %+v", usages[0])
+	}
+	if usages[1].Entries != 50 || usages[2].Entries != 2 {
+		t.Errorf("Expected 50 overrides and 2 stashed sessions, got %+v", usages)
+	}
+
+	maxSize := totalCacheBytes(usages) - usages[1].Bytes/2
+	result, err := CompactCache(30*24*time.Hour, maxSize, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FreedBytes <= 0 {
+		t.Errorf("Expected compaction to free space, got %+v", result)
+	}
+
+	if GetCachedCredentials("valid", 0) == nil {
+		t.Error("Expected valid cached credentials to be kept")
+	}
+	usages, err = CacheUsages(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usages[0].Entries != 1 {
+		t.Errorf("Expected only the valid credentials to remain, got %+v", usages[0])
+	}
+	if sessions, _ := StashedSessions(); len(sessions) != 1 || sessions[0].Profile != "recent" {
+		t.Errorf("Expected only the recent stashed session to remain, got %+v", sessions)
+	}
+	if total := totalCacheBytes(usages); total > maxSize {
+		t.Errorf("Expected at most %d bytes after compaction, got %d", maxSize, total)
+	}
+	recent, err := policy.RecentOverrides(100)
+	if err != nil || len(recent) == 0 || len(recent) >= 50 {
+		t.Errorf("Expected the oldest overrides to be trimmed, got %d, %v", len(recent), err)
+	}
+
+	for _, kind := range CacheKinds {
+		if _, err := ClearCache(kind); err != nil {
+			t.Fatalf("ClearCache(%s): %v", kind, err)
+		}
+	}
+	usages, err = CacheUsages(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := totalCacheBytes(usages); total != 0 {
+		t.Errorf("Expected nothing left after clearing, got %+v", usages)
+	}
+	if _, err := ClearCache("bogus"); err == nil {
+		t.Error("Expected an error for an unknown cache")
+	}
+}
+
+func TestListCachedCredentials(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(awsmConfig.HomeEnv, dir)
+	t.Setenv("HOME", dir)
+	now := time.Now()
+
+	// Files of older versions sit directly in cache/ and carry no metadata
+	if err := os.MkdirAll(filepath.Join(dir, "cache"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	legacy := `{"access_key_id":"ASIALEGACY","expires":"` + now.Add(-time.Hour).UTC().Format(time.RFC3339) + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "cache", "legacy.json"), []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	CacheCredentials("prod", &TempCredentials{AccessKeyId: "ASIAPROD", SecretAccessKey: "secret", Expires: now.Add(time.Hour)})
+
+	path := filepath.Join(dir, "cache", "credentials", "prod.json")
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the cache file to be 0600, got %v", info.Mode().Perm())
+	}
+
+	entries, err := ListCachedCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Profile != "legacy" || entries[1].Profile != "prod" {
+		t.Fatalf("Expected the legacy and prod entries, got %+v", entries)
+	}
+	if !entries[0].Expired(now) || !entries[0].CachedAt.IsZero() {
+		t.Errorf("Expected the legacy entry to be expired without a cache time, got %+v", entries[0])
+	}
+	if entries[1].Expired(now) || entries[1].AccessKeyId != "ASIAPROD" || entries[1].CachedAt.IsZero() {
+		t.Errorf("Expected a valid prod entry with its cache time, got %+v", entries[1])
+	}
+	if creds := GetCachedCredentials("prod", time.Minute); creds == nil || creds.SecretAccessKey != "secret" {
+		t.Errorf("Expected the cached prod credentials, got %+v", creds)
+	}
+
+	removed, err := ClearCachedCredentials("prod", "missing")
+	if err != nil || removed != 1 {
+		t.Errorf("ClearCachedCredentials() = %d, %v, want 1 removed", removed, err)
+	}
+	if GetCachedCredentials("prod", 0) != nil {
+		t.Error("Expected the prod credentials to be gone")
+	}
+}
+
+func TestCachedCredentialsWithSlashInName(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(awsmConfig.HomeEnv, dir)
+	t.Setenv("HOME", dir)
+	now := time.Now()
+
+	for _, profile := range []string{"acme/Admin", "../escape"} {
+		CacheCredentials(profile, &TempCredentials{AccessKeyId: "ASIA", SecretAccessKey: "secret", Expires: now.Add(time.Hour)})
+	}
+	files, err := os.ReadDir(filepath.Join(dir, "cache", "credentials"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			t.Errorf("Expected a single file per profile, got directory %s", f.Name())
+		}
+	}
+	if len(files) != 2 {
+		t.Errorf("Expected 2 files in the cache directory, got %d", len(files))
+	}
+
+	entries, err := ListCachedCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Profile != "../escape" || entries[1].Profile != "acme/Admin" {
+		t.Fatalf("Expected both profiles listed by their real names, got %+v", entries)
+	}
+	if creds := GetCachedCredentials("acme/Admin", time.Minute); creds == nil {
+		t.Error("Expected the cached acme/Admin credentials")
+	}
+	if removed, err := ClearCachedCredentials("acme/Admin", "../escape"); err != nil || removed != 2 {
+		t.Errorf("ClearCachedCredentials() = %d, %v, want 2 removed", removed, err)
+	}
+}
@@ -0,0 +1,136 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ini "gopkg.in/ini.v1"
+)
+
+// maxRoleChainHops limits how many source_profile links are followed, well
+// above what real bastion setups use.
+const maxRoleChainHops = 10
+
+// RoleChain returns the profiles whose credentials are needed to resolve
+// profileName, starting with the profile itself and ending with the profile
+// that holds the base credentials (SSO, static keys, credential_process or a
+// role sourcing itself). It fails on source_profile loops.
+func RoleChain(profileName string) ([]string, error) {
+	chain := []string{profileName}
+	seen := map[string]bool{profileName: true}
+	current := profileName
+	for {
+		pConfig, profileType, err := inspectProfile(current)
+		if err != nil {
+			return nil, err
+		}
+		if profileType != "iam" || pConfig.SourceProfile == "" || pConfig.SourceProfile == current {
+			return chain, nil
+		}
+		next := pConfig.SourceProfile
+		chain = append(chain, next)
+		if seen[next] {
+			return nil, fmt.Errorf("source_profile loop: %s", strings.Join(chain, " -> "))
+		}
+		if len(chain) > maxRoleChainHops+1 {
+			return nil, fmt.Errorf("source_profile chain of '%s' is longer than %d hops", profileName, maxRoleChainHops)
+		}
+		seen[next] = true
+		current = next
+	}
+}
+
+// ProfileFingerprint returns a hash of the config sections credentials of
+// profileName are resolved from: the profile, every profile of its role chain
+// and their SSO sessions. It changes whenever e.g. a role_arn or
+// source_profile along the chain is edited.
+func ProfileFingerprint(profileName string) (string, error) {
+	chain, err := RoleChain(profileName)
+	if err != nil {
+		return "", err
+	}
+	cfg, err := loadMergedConfig()
+	if err != nil {
+		return "", fmt.Errorf("failed to read AWS config file: %w", err)
+	}
+
+	h := sha256.New()
+	writeSection := func(section *ini.Section) {
+		fmt.Fprintf(h, "[%s]\n", section.Name())
+		keys := section.KeyStrings()
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(h, "%s=%s\n", key, section.Key(key).String())
+		}
+	}
+	for _, name := range chain {
+		section, err := getProfileSection(cfg, name)
+		if err != nil {
+			// Profiles only in the credentials file hold long-term keys
+			continue
+		}
+		writeSection(section)
+		if session := section.Key("sso_session").String(); session != "" {
+			if sessionSection, err := cfg.GetSection("sso-session " + session); err == nil {
+				writeSection(sessionSection)
+			}
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// chainedSourceConfig returns the AWS config for assuming the role of
+// profileName when its source profile is itself an assumed role (or needs an
+// MFA session token) or an SSO profile. awsm resolves that hop recursively, so
+// every hop can prompt for MFA and intermediate credentials are cached like
+// any other profile's. SSO credentials are passed to STS as they are instead
+// of leaving the SSO hop to the shared config loader. ok is false when the SDK
+// can resolve the source profile itself.
+func chainedSourceConfig(profileName, sourceProfile string) (cfg aws.Config, ok bool, err error) {
+	if sourceProfile == profileName {
+		return aws.Config{}, false, nil
+	}
+	_, sourceType, err := inspectProfile(sourceProfile)
+	if err != nil || (sourceType != "iam" && sourceType != "sso") {
+		return aws.Config{}, false, nil
+	}
+	if _, err := RoleChain(profileName); err != nil {
+		return aws.Config{}, false, err
+	}
+
+	creds, _, err := GetCredentialsForProfile(sourceProfile)
+	if errors.Is(err, ErrSsoSessionExpired) {
+		// Wrapped, so commands that log in on expired sessions also do it for
+		// the session of the source profile
+		return aws.Config{}, false, fmt.Errorf("SSO session of source profile '%s' has expired, run: %s: %w", sourceProfile, ssoLoginHint(sourceProfile), err)
+	}
+	if err != nil {
+		return aws.Config{}, false, fmt.Errorf("failed to get credentials of source profile '%s': %w", sourceProfile, err)
+	}
+	cfg, err = config.LoadDefaultConfig(context.TODO(),
+		config.WithSharedConfigProfile(sourceProfile),
+		withConfigFragments(),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKeyId, creds.SecretAccessKey, creds.SessionToken)),
+	)
+	if err != nil {
+		return aws.Config{}, false, fmt.Errorf("failed to load AWS config for source profile '%s': %w", sourceProfile, err)
+	}
+	return cfg, true, nil
+}
+
+// ssoLoginHint returns the command that logs in to the SSO session of a profile.
+func ssoLoginHint(profileName string) string {
+	if ssoSession, err := GetSsoSessionForProfile(profileName); err == nil && ssoSession != "" {
+		return "awsm sso login " + ssoSession
+	}
+	// Legacy profiles configure sso_start_url without an sso-session
+	return "aws sso login --profile " + profileName
+}
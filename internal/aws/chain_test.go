@@ -0,0 +1,174 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func writeChainConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(dir, "awsm"))
+	InvalidateProfileCache()
+	t.Cleanup(InvalidateProfileCache)
+
+	config := `[profile workload]
+role_arn = arn:aws:iam::333333333333:role/Deploy
+source_profile = bastion
+region = eu-west-1
+
+[profile bastion]
+role_arn = arn:aws:iam::222222222222:role/Bastion
+source_profile = base
+
+[profile base]
+region = us-east-1
+
+[profile self]
+role_arn = arn:aws:iam::111111111111:role/Self
+source_profile = self
+
+[profile loop-a]
+role_arn = arn:aws:iam::111111111111:role/A
+source_profile = loop-b
+
+[profile loop-b]
+role_arn = arn:aws:iam::111111111111:role/B
+source_profile = loop-a
+`
+	credentials := `[base]
+aws_access_key_id = AKIABASE
+aws_secret_access_key = secret
+
+[self]
+aws_access_key_id = AKIASELF
+aws_secret_access_key = secret
+`
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoleChain(t *testing.T) {
+	writeChainConfig(t)
+
+	tests := []struct {
+		profile string
+		want    string
+		wantErr string
+	}{
+		{"workload", "workload -> bastion -> base", ""},
+		{"bastion", "bastion -> base", ""},
+		{"base", "base", ""},
+		{"self", "self", ""},
+		{"loop-a", "", "loop-a -> loop-b -> loop-a"},
+	}
+	for _, tt := range tests {
+		chain, err := RoleChain(tt.profile)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("RoleChain(%s) error = %v, want %q", tt.profile, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("RoleChain(%s) error = %v", tt.profile, err)
+			continue
+		}
+		if got := strings.Join(chain, " -> "); got != tt.want {
+			t.Errorf("RoleChain(%s) = %s, want %s", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestChainedSourceConfig(t *testing.T) {
+	writeChainConfig(t)
+
+	// Base credentials and self-sourced roles are left to the SDK
+	if _, ok, err := chainedSourceConfig("bastion", "base"); ok || err != nil {
+		t.Errorf("chainedSourceConfig(bastion, base) = %v, %v, want SDK resolution", ok, err)
+	}
+	if _, ok, err := chainedSourceConfig("self", "self"); ok || err != nil {
+		t.Errorf("chainedSourceConfig(self, self) = %v, %v, want SDK resolution", ok, err)
+	}
+	if _, _, err := chainedSourceConfig("loop-a", "loop-b"); err == nil {
+		t.Error("expected an error for a source_profile loop")
+	}
+
+	// The intermediate role's cached credentials are used for the next hop
+	cached := &TempCredentials{AccessKeyId: "ASIABASTION", SecretAccessKey: "secret", SessionToken: "token", Expires: time.Now().Add(time.Hour)}
+	CacheCredentials("bastion", cached)
+	cfg, ok, err := chainedSourceConfig("workload", "bastion")
+	if err != nil || !ok {
+		t.Fatalf("chainedSourceConfig(workload, bastion) = %v, %v", ok, err)
+	}
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIABASTION" || creds.SessionToken != "token" {
+		t.Errorf("expected the cached bastion credentials, got %s", creds.AccessKeyID)
+	}
+}
+
+func TestChainedSourceConfigSSOSource(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(dir, "awsm"))
+	InvalidateProfileCache()
+	t.Cleanup(InvalidateProfileCache)
+
+	config := `[profile partner]
+role_arn = arn:aws:iam::444444444444:role/Partner
+source_profile = sso-admin
+
+[profile sso-admin]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+region = eu-west-1
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-west-1
+`
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tokenPath, err := SSOTokenCachePath("corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	token := `{"accessToken": "expired", "expiresAt": "2020-01-01T00:00:00Z", "region": "eu-west-1", "startUrl": "https://corp.awsapps.com/start"}`
+	if err := os.WriteFile(tokenPath, []byte(token), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The SSO hop is resolved by awsm, so an expired session surfaces as
+	// ErrSsoSessionExpired that names the session to log in to
+	_, ok, err := chainedSourceConfig("partner", "sso-admin")
+	if ok || !errors.Is(err, ErrSsoSessionExpired) {
+		t.Fatalf("chainedSourceConfig(partner, sso-admin) = %v, %v, want ErrSsoSessionExpired", ok, err)
+	}
+	if !strings.Contains(err.Error(), "awsm sso login corp") {
+		t.Errorf("expected a login hint for session corp, got: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"awsm/internal/util"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+// minClockSkew is the smallest difference to the server clock that is reported.
+// Date headers only have second precision and requests take time, so smaller
+// differences can't be told apart from latency.
+const minClockSkew = time.Minute
+
+// clockSkewErrorCodes are the error codes AWS returns when a request is signed
+// with a time too far from its own clock.
+var clockSkewErrorCodes = map[string]bool{
+	"SignatureDoesNotMatch":     true,
+	"InvalidSignatureException": true,
+	"RequestExpired":            true,
+	"RequestInTheFuture":        true,
+	"RequestTimeTooSkewed":      true,
+}
+
+// detectClockSkew reports how far the server clock is ahead of now when err is
+// a signature error and the response's Date header shows a real difference.
+func detectClockSkew(err error, now time.Time) (time.Duration, bool) {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || !clockSkewErrorCodes[apiErr.ErrorCode()] {
+		return 0, false
+	}
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.Response == nil {
+		return 0, false
+	}
+	serverTime, parseErr := http.ParseTime(respErr.Response.Header.Get("Date"))
+	if parseErr != nil {
+		return 0, false
+	}
+	skew := serverTime.Sub(now)
+	if skew.Abs() < minClockSkew {
+		return 0, false
+	}
+	return skew, true
+}
+
+// skewedSigner signs requests as if the local clock were offset by skew.
+type skewedSigner struct {
+	signer sts.HTTPSignerV4
+	skew   time.Duration
+}
+
+func (s *skewedSigner) SignHTTP(ctx context.Context, credentials aws.Credentials, r *http.Request, payloadHash string, service string, region string, signingTime time.Time, optFns ...func(*v4.SignerOptions)) error {
+	return s.signer.SignHTTP(ctx, credentials, r, payloadHash, service, region, signingTime.Add(s.skew), optFns...)
+}
+
+// withClockOffset makes a new STS client sign requests with the local time
+// shifted by skew. The signer is bound when the client is created, so it has no
+// effect as a per-operation option.
+func withClockOffset(skew time.Duration) func(*sts.Options) {
+	return func(o *sts.Options) {
+		o.HTTPSignerV4 = &skewedSigner{signer: v4.NewSigner(), skew: skew}
+	}
+}
+
+// retryOnClockSkew runs an STS call and, when it failed because the local clock
+// is off, reports the difference and runs it once more signed with the server's
+// time. call must pass the options on to the STS client it creates.
+func retryOnClockSkew[T any](call func(optFns ...func(*sts.Options)) (T, error)) (T, error) {
+	result, err := call()
+	skew, ok := detectClockSkew(err, time.Now())
+	if !ok {
+		return result, err
+	}
+	direction := "behind"
+	if skew < 0 {
+		direction = "ahead of"
+	}
+	util.Warn(util.WarnClockSkew, "The local clock is %s %s AWS, retrying with the server time. Sync your system clock (e.g. enable NTP) to avoid this.",
+		skew.Abs().Round(time.Second), direction)
+	return call(withClockOffset(skew))
+}
+
+// MeasureClockSkew compares the local clock with the Date header of an STS
+// response and returns how far the server clock is ahead. The request is not
+// signed, so it works without credentials.
+func MeasureClockSkew(ctx context.Context) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://sts.amazonaws.com/", nil)
+	if err != nil {
+		return 0, err
+	}
+	sent := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach STS: %w", err)
+	}
+	resp.Body.Close()
+	received := time.Now()
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("STS response has no usable Date header: %w", err)
+	}
+	// The server stamped the response somewhere between sending and receiving
+	return serverTime.Sub(sent.Add(received.Sub(sent) / 2)), nil
+}
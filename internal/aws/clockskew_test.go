@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+func TestRetryOnClockSkew(t *testing.T) {
+	serverOffset := 2 * time.Hour
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		serverTime := time.Now().Add(serverOffset)
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "text/xml")
+		signed, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+		if err != nil || signed.Sub(serverTime).Abs() > 5*time.Minute {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse><Error><Type>Sender</Type><Code>SignatureDoesNotMatch</Code><Message>Signature expired</Message></Error><RequestId>1</RequestId></ErrorResponse>`)
+			return
+		}
+		fmt.Fprint(w, `<GetCallerIdentityResponse><GetCallerIdentityResult><Arn>arn:aws:sts::123456789012:assumed-role/Admin/awsm</Arn><UserId>AROA:awsm</UserId><Account>123456789012</Account></GetCallerIdentityResult><ResponseMetadata><RequestId>2</RequestId></ResponseMetadata></GetCallerIdentityResponse>`)
+	}))
+	defer server.Close()
+
+	getCallerIdentity := func(optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+		client := sts.New(sts.Options{
+			Region:           "us-east-1",
+			BaseEndpoint:     aws.String(server.URL),
+			Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			RetryMaxAttempts: 1,
+		}, optFns...)
+		return client.GetCallerIdentity(context.Background(), &sts.GetCallerIdentityInput{})
+	}
+	out, err := retryOnClockSkew(getCallerIdentity)
+	if err != nil {
+		t.Fatalf("Expected the retry with the server time to succeed, got %v", err)
+	}
+	if aws.ToString(out.Account) != "123456789012" || attempts != 2 {
+		t.Errorf("Expected account 123456789012 after 2 attempts, got %q after %d", aws.ToString(out.Account), attempts)
+	}
+
+	serverOffset = 0
+	attempts = 0
+	if _, err := retryOnClockSkew(getCallerIdentity); err != nil || attempts != 1 {
+		t.Errorf("Expected a single attempt without skew, got %d (%v)", attempts, err)
+	}
+}
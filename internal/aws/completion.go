@@ -84,6 +84,7 @@ func InvalidateProfileCache() {
 	defer profileCacheMutex.Unlock()
 	profileCacheValid = false
 	profileCache = nil
+	invalidateProfileIndex()
 }
 
 func CompleteProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
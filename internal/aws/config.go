@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/policy"
+
 	"gopkg.in/ini.v1"
 )
 
@@ -22,12 +26,23 @@ func GetAWSConfigPath() (string, error) {
 	return filepath.Join(home, ".aws", "config"), nil
 }
 
+// loadIni loads AWS config or credentials files while holding the lock on
+// them shared, so another awsm process can't be halfway through a change.
+func loadIni(source any, others ...any) (*ini.File, error) {
+	unlock, err := rlockAWSFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	return ini.Load(source, others...)
+}
+
 // loadOrCreateIni loads an ini file or creates an empty one if it doesn't exist.
 func loadOrCreateIni(path string) (*ini.File, error) {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return ini.Empty(), nil
 	}
-	cfg, err := ini.Load(path)
+	cfg, err := loadIni(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load %s: %w", path, err)
 	}
@@ -50,24 +65,21 @@ func getProfileSection(cfg *ini.File, profileName string) (*ini.Section, error)
 func ListProfiles() ([]string, error) {
 	profilesMap := make(map[string]bool)
 
-	// Load config file
-	configPath, err := GetAWSConfigPath()
-	if err == nil {
-		if cfg, err := ini.Load(configPath); err == nil {
-			for _, section := range cfg.Sections() {
-				name := section.Name()
-				if name == "DEFAULT" || strings.HasPrefix(name, "sso-session ") {
-					continue
-				}
-				profilesMap[strings.TrimPrefix(name, "profile ")] = true
+	// Load config file and its fragments
+	if cfg, err := loadMergedConfig(); err == nil {
+		for _, section := range cfg.Sections() {
+			name := section.Name()
+			if name == "DEFAULT" || strings.HasPrefix(name, "sso-session ") {
+				continue
 			}
+			profilesMap[strings.TrimPrefix(name, "profile ")] = true
 		}
 	}
 
 	// Load credentials file
 	credentialsPath, err := GetAWSCredentialsPath()
 	if err == nil {
-		if cfg, err := ini.Load(credentialsPath); err == nil {
+		if cfg, err := loadIni(credentialsPath); err == nil {
 			for _, section := range cfg.Sections() {
 				name := section.Name()
 				if name == "DEFAULT" {
@@ -111,11 +123,7 @@ func getSsoSessionRecursive(profileName string, visited map[string]bool) (string
 	}
 	visited[profileName] = true
 
-	configPath, err := GetAWSConfigPath()
-	if err != nil {
-		return "", err
-	}
-	cfgFile, err := ini.Load(configPath)
+	cfgFile, err := loadMergedConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to read AWS config file: %w", err)
 	}
@@ -173,6 +181,29 @@ type ProfileInfo struct {
 	SecretKey     string `json:"secret_key,omitempty"`
 }
 
+// AccountID returns the account the profile's credentials belong to, from the
+// SSO account or the role ARN. It is empty for profiles that don't name one.
+func (p ProfileInfo) AccountID() string {
+	if p.SSOAccountID != "" {
+		return p.SSOAccountID
+	}
+	if p.RoleARN != "" {
+		return policy.AccountIDFromARN(p.RoleARN)
+	}
+	return ""
+}
+
+// RoleName returns the role the profile assumes, from the SSO role or the role ARN.
+func (p ProfileInfo) RoleName() string {
+	if p.SSORoleName != "" {
+		return p.SSORoleName
+	}
+	if p.RoleARN != "" {
+		return policy.RoleNameFromARN(p.RoleARN)
+	}
+	return ""
+}
+
 // GetProfileType determines the type of AWS profile based on its configuration
 func getProfileType(section *ini.Section) ProfileType {
 	if section.HasKey("sso_session") || section.HasKey("sso_start_url") {
@@ -181,6 +212,9 @@ func getProfileType(section *ini.Section) ProfileType {
 	if section.HasKey("role_arn") {
 		return ProfileTypeIAM
 	}
+	if section.Key(keychainMarker).MustBool(false) {
+		return ProfileTypeKey
+	}
 	if section.HasKey("credential_process") {
 		return ProfileTypeProcess
 	}
@@ -194,7 +228,7 @@ func ListProfilesDetailed() ([]ProfileInfo, error) {
 		return nil, err
 	}
 
-	cfg, err := ini.Load(configPath)
+	cfg, err := loadMergedConfig()
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read AWS config file at %s: %w", configPath, err)
 	}
@@ -204,7 +238,7 @@ func ListProfilesDetailed() ([]ProfileInfo, error) {
 		return nil, err
 	}
 
-	credCfg, err := ini.Load(credentialsPath)
+	credCfg, err := loadIni(credentialsPath)
 	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read AWS credentials file at %s: %w", credentialsPath, err)
 	}
@@ -281,14 +315,69 @@ func ListProfilesDetailed() ([]ProfileInfo, error) {
 	return profiles, nil
 }
 
-// GetProfileRegion gets the region for a specific profile
-func GetProfileRegion(profileName string) (string, error) {
-	configPath, err := GetAWSConfigPath()
+// ProfileCounts summarizes profiles by type and region.
+type ProfileCounts struct {
+	Total    int
+	ByType   map[ProfileType]int
+	ByRegion map[string]int
+}
+
+// Add counts one profile. Profiles without a region are not counted by region.
+func (c *ProfileCounts) Add(profileType ProfileType, region string) {
+	if c.ByType == nil {
+		c.ByType = make(map[ProfileType]int)
+		c.ByRegion = make(map[string]int)
+	}
+	c.Total++
+	c.ByType[profileType]++
+	if region != "" {
+		c.ByRegion[region]++
+	}
+}
+
+// CountProfiles counts the profiles like ListProfilesDetailed would list them,
+// but only reads the keys it needs, which keeps it fast on configs with
+// hundreds of profiles.
+func CountProfiles() (ProfileCounts, error) {
+	var counts ProfileCounts
+	inConfig := make(map[string]bool)
+
+	cfg, err := loadMergedConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return counts, fmt.Errorf("failed to read AWS config file: %w", err)
+	}
+	if cfg != nil {
+		for _, section := range cfg.Sections() {
+			name := section.Name()
+			if name == "DEFAULT" || strings.HasPrefix(name, "sso-session ") {
+				continue
+			}
+			inConfig[strings.TrimPrefix(name, "profile ")] = true
+			counts.Add(getProfileType(section), section.Key("region").String())
+		}
+	}
+
+	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
-		return "", err
+		return counts, err
+	}
+	credCfg, err := loadIni(credentialsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return counts, fmt.Errorf("failed to read AWS credentials file at %s: %w", credentialsPath, err)
 	}
+	if credCfg != nil {
+		for _, section := range credCfg.Sections() {
+			if name := section.Name(); name != "DEFAULT" && !inConfig[name] {
+				counts.Add(ProfileTypeKey, "")
+			}
+		}
+	}
+	return counts, nil
+}
 
-	cfgFile, err := ini.Load(configPath)
+// GetProfileRegion gets the region for a specific profile
+func GetProfileRegion(profileName string) (string, error) {
+	cfgFile, err := loadMergedConfig()
 	if err != nil {
 		return "", fmt.Errorf("failed to read AWS config file: %w", err)
 	}
@@ -308,6 +397,12 @@ func GetProfileRegion(profileName string) (string, error) {
 
 // AddSSOSession adds a new SSO session to the AWS config file
 func AddSSOSession(sessionName, startURL, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	configPath, err := GetAWSConfigPath()
 	if err != nil {
 		return err
@@ -336,19 +431,95 @@ func AddSSOSession(sessionName, startURL, region string) error {
 	section.Key("sso_region").SetValue(region)
 	section.Key("sso_registration_scopes").SetValue("sso:account:access")
 
-	return cfg.SaveTo(configPath)
+	return saveIniAtomic(cfg, configPath)
+}
+
+// EnsureSSOSessionScopes sets sso_registration_scopes on an SSO session that has
+// none, as tools like the AWS Toolkit need it to request a refreshable token.
+// It reports whether the session was changed.
+func EnsureSSOSessionScopes(sessionName string) (bool, error) {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	sectionName := "sso-session " + sessionName
+	configPath, cfg, err := loadConfigFileDefining(sectionName)
+	if err != nil {
+		return false, err
+	}
+	section, err := cfg.GetSection(sectionName)
+	if err != nil {
+		return false, fmt.Errorf("SSO session '%s' not found in config", sessionName)
+	}
+	if section.Key("sso_registration_scopes").String() != "" {
+		return false, nil
+	}
+	section.Key("sso_registration_scopes").SetValue("sso:account:access")
+	return true, saveIniAtomic(cfg, configPath)
+}
+
+// AddCredentialProcessProfile writes a profile that gets its credentials by running
+// command, replacing a previous definition of the profile.
+func AddCredentialProcessProfile(profileName, command, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region}); err != nil {
+		return err
+	}
+
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return err
+	}
+
+	// Create .aws directory if it doesn't exist
+	awsDir := filepath.Dir(configPath)
+	if err := os.MkdirAll(awsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create AWS directory: %w", err)
+	}
+
+	cfg, err := loadOrCreateIni(configPath)
+	if err != nil {
+		return err
+	}
+
+	sectionName := fmt.Sprintf("profile %s", profileName)
+	cfg.DeleteSection(sectionName)
+	section, err := cfg.NewSection(sectionName)
+	if err != nil {
+		return fmt.Errorf("failed to create profile section: %w", err)
+	}
+
+	section.Key("credential_process").SetValue(command)
+	if region != "" {
+		section.Key("region").SetValue(region)
+	}
+
+	InvalidateProfileCache()
+	return saveIniAtomic(cfg, configPath)
 }
 
 // ChangeProfileRegion changes the region for a specific profile
 func ChangeProfileRegion(profileName, region string) error {
-	configPath, err := GetAWSConfigPath()
+	unlock, err := lockAWSFiles()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region}); err != nil {
+		return err
+	}
 
-	cfg, err := ini.Load(configPath)
+	configPath, cfg, err := loadConfigFileDefining("profile "+profileName, profileName)
 	if err != nil {
-		return fmt.Errorf("failed to load config file: %w", err)
+		return err
 	}
 
 	section, err := getProfileSection(cfg, profileName)
@@ -359,7 +530,7 @@ func ChangeProfileRegion(profileName, region string) error {
 	// Update the region
 	section.Key("region").SetValue(region)
 
-	return cfg.SaveTo(configPath)
+	return saveIniAtomic(cfg, configPath)
 }
 
 // SSOSessionInfo contains information about an SSO session
@@ -372,12 +543,7 @@ type SSOSessionInfo struct {
 
 // ListSSOSessions returns all SSO sessions from the AWS config
 func ListSSOSessions() ([]SSOSessionInfo, error) {
-	configPath, err := GetAWSConfigPath()
-	if err != nil {
-		return nil, err
-	}
-
-	cfg, err := ini.Load(configPath)
+	cfg, err := loadMergedConfig()
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []SSOSessionInfo{}, nil
@@ -405,6 +571,16 @@ func ListSSOSessions() ([]SSOSessionInfo, error) {
 
 // AddIAMUserProfile adds a new IAM user profile with static credentials
 func AddIAMUserProfile(profileName, accessKey, secretKey, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region}); err != nil {
+		return err
+	}
+
 	// Add credentials to credentials file
 	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
@@ -457,7 +633,7 @@ func AddIAMUserProfile(profileName, accessKey, secretKey, region string) error {
 
 	configSection.Key("region").SetValue(region)
 
-	if err := configCfg.SaveTo(configPath); err != nil {
+	if err := saveIniAtomic(configCfg, configPath); err != nil {
 		return fmt.Errorf("failed to save config file: %w", err)
 	}
 
@@ -468,6 +644,16 @@ func AddIAMUserProfile(profileName, accessKey, secretKey, region string) error {
 
 // AddIAMRoleProfile adds a new IAM role profile
 func AddIAMRoleProfile(profileName, roleArn, sourceProfile, mfaSerial, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region, AccountID: policy.AccountIDFromARN(roleArn), RoleName: policy.RoleNameFromARN(roleArn)}); err != nil {
+		return err
+	}
+
 	configPath, err := GetAWSConfigPath()
 	if err != nil {
 		return err
@@ -502,17 +688,31 @@ func AddIAMRoleProfile(profileName, roleArn, sourceProfile, mfaSerial, region st
 		section.Key("region").SetValue(region)
 	}
 
-	return cfg.SaveTo(configPath)
+	if err := saveIniAtomic(cfg, configPath); err != nil {
+		return err
+	}
+	InvalidateProfileCache()
+	return nil
 }
 
 // UpdateIAMRoleProfile updates an existing IAM role profile in place
 func UpdateIAMRoleProfile(profileName, roleArn, sourceProfile, mfaSerial, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region, AccountID: policy.AccountIDFromARN(roleArn), RoleName: policy.RoleNameFromARN(roleArn)}); err != nil {
+		return err
+	}
+
 	configPath, err := GetAWSConfigPath()
 	if err != nil {
 		return err
 	}
 
-	cfg, err := ini.Load(configPath)
+	cfg, err := loadIni(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to load config file: %w", err)
 	}
@@ -539,35 +739,40 @@ func UpdateIAMRoleProfile(profileName, roleArn, sourceProfile, mfaSerial, region
 		section.DeleteKey("region")
 	}
 
-	return cfg.SaveTo(configPath)
+	if err := saveIniAtomic(cfg, configPath); err != nil {
+		return err
+	}
+	InvalidateProfileCache()
+	return nil
 }
 
 // DeleteProfile removes a profile from both config and credentials files
 func DeleteProfile(profileName string) error {
-	// Delete from config file
-	configPath, err := GetAWSConfigPath()
+	unlock, err := lockAWSFiles()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
-		cfg, err := ini.Load(configPath)
-		if err != nil {
-			return fmt.Errorf("failed to load config file: %w", err)
+	if cfg, err := loadMergedConfig(); err == nil {
+		if err := deleteKeychainKeys(cfg, profileName); err != nil {
+			return fmt.Errorf("failed to delete the keys of profile '%s' from the OS keychain: %w", profileName, err)
 		}
-
+	}
+	// Delete from the config file and every fragment defining it
+	err = updateConfigFiles(func(_ string, cfg *ini.File) (bool, error) {
 		// Try both profile formats
 		sectionNames := []string{fmt.Sprintf("profile %s", profileName), profileName}
 		for _, sectionName := range sectionNames {
 			if cfg.HasSection(sectionName) {
 				cfg.DeleteSection(sectionName)
-				break
+				return true, nil
 			}
 		}
-
-		if err := cfg.SaveTo(configPath); err != nil {
-			return fmt.Errorf("failed to save config file: %w", err)
-		}
+		return false, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Delete from credentials file
@@ -577,7 +782,7 @@ func DeleteProfile(profileName string) error {
 	}
 
 	if _, err := os.Stat(credentialsPath); !os.IsNotExist(err) {
-		cfg, err := ini.Load(credentialsPath)
+		cfg, err := loadIni(credentialsPath)
 		if err != nil {
 			return fmt.Errorf("failed to load credentials file: %w", err)
 		}
@@ -597,61 +802,114 @@ func DeleteProfile(profileName string) error {
 
 // DeleteSSOSession removes an SSO session from config file
 func DeleteSSOSession(sessionName string) error {
-	configPath, err := GetAWSConfigPath()
+	unlock, err := lockAWSFiles()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	cfg, err := ini.Load(configPath)
+	sectionName := fmt.Sprintf("sso-session %s", sessionName)
+	return updateConfigFiles(func(_ string, cfg *ini.File) (bool, error) {
+		if !cfg.HasSection(sectionName) {
+			return false, nil
+		}
+		cfg.DeleteSection(sectionName)
+		return true, nil
+	})
+}
+
+// RenameSSOSession renames an SSO session and rewrites the sso_session key of every
+// profile that references it, in the config file and its fragments. Each file is
+// written in a single save, so a file never holds profiles pointing at a session
+// it renamed away.
+// It returns the number of profiles that were updated.
+func RenameSSOSession(oldName, newName string) (int, error) {
+	unlock, err := lockAWSFiles()
 	if err != nil {
-		return fmt.Errorf("failed to load config file: %w", err)
+		return 0, err
 	}
+	defer unlock()
 
-	sectionName := fmt.Sprintf("sso-session %s", sessionName)
-	if cfg.HasSection(sectionName) {
-		cfg.DeleteSection(sectionName)
+	merged, err := loadMergedConfig()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load config file: %w", err)
+	}
+	if !merged.HasSection("sso-session " + oldName) {
+		return 0, fmt.Errorf("SSO session '%s' not found in config", oldName)
+	}
+	if merged.HasSection("sso-session " + newName) {
+		return 0, fmt.Errorf("SSO session '%s' already exists", newName)
 	}
 
-	return cfg.SaveTo(configPath)
+	updated := 0
+	err = updateConfigFiles(func(_ string, cfg *ini.File) (bool, error) {
+		changed := false
+		if oldSection, err := cfg.GetSection("sso-session " + oldName); err == nil {
+			newSection, err := cfg.NewSection("sso-session " + newName)
+			if err != nil {
+				return false, fmt.Errorf("failed to create SSO session section: %w", err)
+			}
+			newSection.Comment = oldSection.Comment
+			for _, key := range oldSection.Keys() {
+				newSection.Key(key.Name()).SetValue(key.Value())
+			}
+			cfg.DeleteSection(oldSection.Name())
+			changed = true
+		}
+
+		for _, section := range cfg.Sections() {
+			if strings.HasPrefix(section.Name(), "sso-session ") {
+				continue
+			}
+			if section.HasKey("sso_session") && section.Key("sso_session").String() == oldName {
+				section.Key("sso_session").SetValue(newName)
+				updated++
+				changed = true
+			}
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	InvalidateProfileCache()
+	return updated, nil
 }
 
 // GetProfilesBySSO returns all profiles that use a specific SSO session
 func GetProfilesBySSO(ssoSession string) ([]string, error) {
-	profiles, err := ListProfilesDetailed()
+	index, err := GetProfileIndex()
 	if err != nil {
 		return nil, err
 	}
-
-	var ssoProfiles []string
-	for _, profile := range profiles {
-		if profile.Type == ProfileTypeSSO && profile.SSOSession == ssoSession {
-			ssoProfiles = append(ssoProfiles, profile.Name)
-		}
-	}
-
-	return ssoProfiles, nil
+	return index.ProfilesBySSO(ssoSession), nil
 }
 
 // UpdateProfileRegion updates the region for a profile
 func UpdateProfileRegion(profileName, region string) error {
-	configPath, err := GetAWSConfigPath()
+	unlock, err := lockAWSFiles()
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region}); err != nil {
+		return err
+	}
 
-	cfg, err := ini.Load(configPath)
+	configPath, cfg, err := loadConfigFileDefining("profile "+profileName, profileName)
 	if err != nil {
-		return fmt.Errorf("failed to load config file: %w", err)
+		return err
 	}
 
-	sectionName := fmt.Sprintf("profile %s", profileName)
-	section := cfg.Section(sectionName)
-	if section == nil {
-		section = cfg.Section(profileName)
+	section, err := getProfileSection(cfg, profileName)
+	if err != nil {
+		section = cfg.Section("profile " + profileName)
 	}
 
 	section.Key("region").SetValue(region)
-	return cfg.SaveTo(configPath)
+	return saveIniAtomic(cfg, configPath)
 }
 
 // ImportSSOSession imports an SSO session
@@ -661,6 +919,16 @@ func ImportSSOSession(session SSOSessionInfo) error {
 
 // AddSSOProfile adds a new SSO profile
 func AddSSOProfile(profileName, ssoSession, ssoAccountID, ssoRoleName, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region, AccountID: ssoAccountID, RoleName: ssoRoleName}); err != nil {
+		return err
+	}
+
 	configPath, err := GetAWSConfigPath()
 	if err != nil {
 		return err
@@ -691,7 +959,25 @@ func AddSSOProfile(profileName, ssoSession, ssoAccountID, ssoRoleName, region st
 		section.Key("region").SetValue(region)
 	}
 
-	return cfg.SaveTo(configPath)
+	if err := saveIniAtomic(cfg, configPath); err != nil {
+		return err
+	}
+	InvalidateProfileCache()
+	return nil
+}
+
+// PolicyProfile returns the fields of a profile the policy checks.
+func (p ProfileInfo) PolicyProfile() policy.Profile {
+	profile := policy.Profile{Name: p.Name, Region: p.Region}
+	switch p.Type {
+	case ProfileTypeIAM:
+		profile.AccountID = policy.AccountIDFromARN(p.RoleARN)
+		profile.RoleName = policy.RoleNameFromARN(p.RoleARN)
+	case ProfileTypeSSO:
+		profile.AccountID = p.SSOAccountID
+		profile.RoleName = p.SSORoleName
+	}
+	return profile
 }
 
 // ImportProfile imports a profile based on its type
@@ -709,26 +995,18 @@ func ImportProfile(profile ProfileInfo) error {
 	}
 }
 
-// saveCredentialsWithDefaultLast ensures default profile is always last
+// saveCredentialsWithDefaultLast ensures default profile is always last. The
+// file is written once and swapped in atomically, so the default credentials
+// never disappear from it, not even briefly.
 func saveCredentialsWithDefaultLast(cfg *ini.File, credentialsPath string) error {
-	// Get current source profile to preserve it
+	// Get current source profile and expiry to preserve them
 	currentSourceProfile := GetCurrentProfileName()
+	currentExpiresAt := readDefaultSectionComment(credentialsPath, "# expires_at")
 
-	// Get default section if it exists
-	var defaultSection *ini.Section
 	if cfg.HasSection("default") {
-		defaultSection = cfg.Section("default")
-		// Remove it temporarily
+		// Re-adding the default section moves it to the end
+		defaultSection := cfg.Section("default")
 		cfg.DeleteSection("default")
-	}
-
-	// Save file without default
-	if err := cfg.SaveTo(credentialsPath); err != nil {
-		return err
-	}
-
-	// Add default section back if it existed
-	if defaultSection != nil {
 		newDefault, err := cfg.NewSection("default")
 		if err != nil {
 			return err
@@ -741,14 +1019,22 @@ func saveCredentialsWithDefaultLast(cfg *ini.File, credentialsPath string) error
 		if currentSourceProfile != "" && !newDefault.HasKey("# source_profile") {
 			newDefault.Key("# source_profile").SetValue(currentSourceProfile)
 		}
-		return cfg.SaveTo(credentialsPath)
+		if currentExpiresAt != "" && !newDefault.HasKey("# expires_at") {
+			newDefault.Key("# expires_at").SetValue(currentExpiresAt)
+		}
 	}
 
-	return nil
+	return saveIniAtomic(cfg, credentialsPath)
 }
 
 // RestoreConfigFiles restores the AWS config and credentials files from raw content
 func RestoreConfigFiles(configContent, credentialsContent string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// 1. Get paths
 	configPath, err := GetAWSConfigPath()
 	if err != nil {
@@ -781,12 +1067,12 @@ func RestoreConfigFiles(configContent, credentialsContent string) error {
 
 	// 4. Write new content
 	if configContent != "" {
-		if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		if err := writeFileAtomic(configPath, []byte(configContent), 0600); err != nil {
 			return fmt.Errorf("failed to write config file: %w", err)
 		}
 	}
 	if credentialsContent != "" {
-		if err := os.WriteFile(credentialsPath, []byte(credentialsContent), 0600); err != nil {
+		if err := writeFileAtomic(credentialsPath, []byte(credentialsContent), 0600); err != nil {
 			return fmt.Errorf("failed to write credentials file: %w", err)
 		}
 	}
@@ -794,3 +1080,173 @@ func RestoreConfigFiles(configContent, credentialsContent string) error {
 	InvalidateProfileCache()
 	return nil
 }
+
+// GeneratedProfileKeys lists the keys 'awsm sso generate' writes for each profile,
+// in the order they are written.
+var GeneratedProfileKeys = []string{"sso_session", "sso_account_id", "sso_role_name", "region"}
+
+// FormatGeneratedProfile renders a profile section in the format written by 'awsm sso generate'.
+func FormatGeneratedProfile(profileName, ssoSession, accountID, roleName, region string) string {
+	return fmt.Sprintf("[profile %s]\n%s\nsso_session = %s\nsso_account_id = %s\nsso_role_name = %s\nregion = %s\n\n",
+		profileName, awsmConfig.GeneratedProfileMarker, ssoSession, accountID, roleName, region)
+}
+
+// isGeneratedProfile reports whether a section was created by 'awsm sso generate',
+// i.e. carries the generated profile marker. The ini parser attaches the marker
+// to the section or to the key that follows it.
+func isGeneratedProfile(section *ini.Section) bool {
+	marker := strings.TrimPrefix(awsmConfig.GeneratedProfileMarker, "# ")
+	if strings.Contains(section.Comment, marker) {
+		return true
+	}
+	for _, key := range section.Keys() {
+		if strings.Contains(key.Comment, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdoptableProfile reports whether a section looks like a profile generated
+// for ssoSession by a version of awsm that didn't mark them yet: a plain SSO
+// profile of the session, without a marker.
+func isAdoptableProfile(section *ini.Section, ssoSession string) bool {
+	return strings.HasPrefix(section.Name(), "profile ") &&
+		!isGeneratedProfile(section) &&
+		section.Key("sso_session").String() == ssoSession &&
+		section.Key("sso_account_id").String() != "" &&
+		section.Key("sso_role_name").String() != "" &&
+		!section.HasKey("role_arn") && !section.HasKey("source_profile") && !section.HasKey("credential_process")
+}
+
+// AdoptableProfiles returns the SSO profiles of ssoSession without the
+// generated profile marker, e.g. generated by older versions of awsm, which
+// AdoptGeneratedProfiles can mark.
+func AdoptableProfiles(ssoSession string) ([]string, error) {
+	cfg, err := loadMergedConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file: %w", err)
+	}
+	var names []string
+	for _, section := range cfg.Sections() {
+		if isAdoptableProfile(section, ssoSession) {
+			names = append(names, strings.TrimPrefix(section.Name(), "profile "))
+		}
+	}
+	return names, nil
+}
+
+// AdoptGeneratedProfiles adds the generated profile marker to the given
+// profiles of ssoSession, so later runs of 'awsm sso generate' and
+// UpgradeGeneratedProfiles treat them as generated. Profiles that don't
+// qualify, see AdoptableProfiles, are left alone.
+func AdoptGeneratedProfiles(ssoSession string, names []string) error {
+	err := updateConfigFiles(func(_ string, cfg *ini.File) (bool, error) {
+		changed := false
+		for _, name := range names {
+			section, err := cfg.GetSection("profile " + name)
+			if err != nil || !isAdoptableProfile(section, ssoSession) || len(section.Keys()) == 0 {
+				continue
+			}
+			// The marker goes right under the section header, like generated profiles have it
+			first := section.Keys()[0]
+			first.Comment = strings.TrimSpace(awsmConfig.GeneratedProfileMarker + "\n" + first.Comment)
+			changed = true
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return err
+	}
+	InvalidateProfileCache()
+	return nil
+}
+
+// UpgradeGeneratedProfiles rewrites awsm-generated SSO profiles to the current
+// generated format: missing keys are filled in and awsm's keys are put back in
+// their canonical order, while keys the user added are kept after them.
+// If ssoSession is non-empty only profiles using that session are touched.
+// It returns the names of the profiles that were changed.
+func UpgradeGeneratedProfiles(ssoSession string) ([]string, error) {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	merged, err := loadMergedConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file: %w", err)
+	}
+
+	sessionRegions := make(map[string]string)
+	for _, section := range merged.Sections() {
+		if name, ok := strings.CutPrefix(section.Name(), "sso-session "); ok {
+			sessionRegions[name] = section.Key("sso_region").String()
+		}
+	}
+
+	var upgraded []string
+	err = updateConfigFiles(func(_ string, cfg *ini.File) (bool, error) {
+		changed := false
+		for _, section := range cfg.Sections() {
+			if !strings.HasPrefix(section.Name(), "profile ") || !isGeneratedProfile(section) {
+				continue
+			}
+			session := section.Key("sso_session").String()
+			if ssoSession != "" && session != ssoSession {
+				continue
+			}
+
+			// Remember every key with its comment so the section can be rebuilt in order
+			type entry struct{ name, value, comment string }
+			var canonical, extra []entry
+			current := make([]string, 0, len(section.Keys()))
+			for _, key := range section.Keys() {
+				current = append(current, key.Name())
+				if !slices.Contains(GeneratedProfileKeys, key.Name()) {
+					extra = append(extra, entry{key.Name(), key.Value(), key.Comment})
+				}
+			}
+			for _, name := range GeneratedProfileKeys {
+				if section.HasKey(name) {
+					key := section.Key(name)
+					canonical = append(canonical, entry{name, key.Value(), key.Comment})
+				} else if name == "region" && sessionRegions[session] != "" {
+					canonical = append(canonical, entry{name, sessionRegions[session], ""})
+				}
+			}
+
+			entries := append(canonical, extra...)
+			wanted := make([]string, 0, len(entries))
+			for _, e := range entries {
+				wanted = append(wanted, e.name)
+			}
+			if slices.Equal(current, wanted) {
+				continue
+			}
+
+			for _, name := range current {
+				section.DeleteKey(name)
+			}
+			for _, e := range entries {
+				key, err := section.NewKey(e.name, e.value)
+				if err != nil {
+					return false, fmt.Errorf("failed to rewrite key '%s' in '%s': %w", e.name, section.Name(), err)
+				}
+				key.Comment = e.comment
+			}
+			upgraded = append(upgraded, strings.TrimPrefix(section.Name(), "profile "))
+			changed = true
+		}
+		return changed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(upgraded) > 0 {
+		InvalidateProfileCache()
+	}
+	return upgraded, nil
+}
@@ -3,7 +3,10 @@ package aws
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"gopkg.in/ini.v1"
 )
 
 func TestGetAWSConfigPath(t *testing.T) {
@@ -74,6 +77,17 @@ func TestProfileInfo(t *testing.T) {
 	}
 }
 
+func TestProfileInfoPolicyProfile(t *testing.T) {
+	iamRole := ProfileInfo{Name: "prod", Type: ProfileTypeIAM, Region: "eu-west-1", RoleARN: "arn:aws:iam::123456789012:role/ops/Admin"}
+	if got := iamRole.PolicyProfile(); got.AccountID != "123456789012" || got.RoleName != "Admin" || got.Region != "eu-west-1" {
+		t.Errorf("Unexpected policy profile for IAM role: %+v", got)
+	}
+	sso := ProfileInfo{Name: "dev", Type: ProfileTypeSSO, SSOAccountID: "111111111111", SSORoleName: "ReadOnly"}
+	if got := sso.PolicyProfile(); got.AccountID != "111111111111" || got.RoleName != "ReadOnly" {
+		t.Errorf("Unexpected policy profile for SSO: %+v", got)
+	}
+}
+
 func TestGetSsoSessionForProfile_Chained(t *testing.T) {
 	// Create a temporary config file
 	content := `
@@ -140,3 +154,278 @@ sso_registration_scopes = sso:account:access
 		t.Errorf("Expected session 'my-session' for leaf-profile, got '%s'", session)
 	}
 }
+
+func TestRenameSSOSession(t *testing.T) {
+	content := `[sso-session old]
+sso_start_url = https://example.awsapps.com/start
+sso_region = eu-west-1
+sso_registration_scopes = sso:account:access
+
+[profile a]
+sso_session = old
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile b]
+sso_session = old
+sso_account_id = 222222222222
+sso_role_name = ReadOnly
+
+[profile c]
+sso_session = other
+sso_account_id = 333333333333
+sso_role_name = Admin
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpDir, "credentials"))
+
+	updated, err := RenameSSOSession("old", "new")
+	if err != nil {
+		t.Fatalf("RenameSSOSession failed: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("Expected 2 updated profiles, got %d", updated)
+	}
+
+	sessions, err := ListSSOSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].Name != "new" || sessions[0].Region != "eu-west-1" {
+		t.Errorf("Expected a single renamed session 'new', got %+v", sessions)
+	}
+
+	profiles, err := GetProfilesBySSO("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profiles) != 2 {
+		t.Errorf("Expected 2 profiles on the renamed session, got %v", profiles)
+	}
+
+	if _, err := RenameSSOSession("missing", "whatever"); err == nil {
+		t.Error("Expected an error when renaming a missing session")
+	}
+}
+
+func TestFormatGeneratedProfileMarker(t *testing.T) {
+	cfg, err := ini.Load([]byte(FormatGeneratedProfile("dev", "corp", "111111111111", "Admin", "eu-west-1")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isGeneratedProfile(cfg.Section("profile dev")) {
+		t.Error("Expected a formatted profile to be recognized as generated")
+	}
+}
+
+func TestUpgradeGeneratedProfiles(t *testing.T) {
+	content := `[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+sso_region = eu-west-1
+
+[profile dev-admin]
+# Managed by awsm sso generate
+sso_role_name = Admin
+output = json
+sso_account_id = 111111111111
+sso_session = corp
+
+[profile prod-admin]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+region = us-east-1
+
+[profile handwritten]
+sso_role_name = Admin
+sso_account_id = 333333333333
+sso_session = corp
+
+[profile manual]
+region = us-west-2
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpDir, "credentials"))
+
+	upgraded, err := UpgradeGeneratedProfiles("")
+	if err != nil {
+		t.Fatalf("UpgradeGeneratedProfiles failed: %v", err)
+	}
+	if len(upgraded) != 1 || upgraded[0] != "dev-admin" {
+		t.Fatalf("Expected only dev-admin to be upgraded, got %v", upgraded)
+	}
+
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := cfg.Section("profile dev-admin").KeyStrings()
+	expected := []string{"sso_session", "sso_account_id", "sso_role_name", "region", "output"}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+	if region := cfg.Section("profile dev-admin").Key("region").String(); region != "eu-west-1" {
+		t.Errorf("Expected region from SSO session, got %q", region)
+	}
+	if !isGeneratedProfile(cfg.Section("profile dev-admin")) {
+		t.Error("Expected the generated profile marker to survive the upgrade")
+	}
+	if keys := cfg.Section("profile handwritten").KeyStrings(); keys[0] != "sso_role_name" {
+		t.Errorf("Expected the hand-written profile to be left alone, got %v", keys)
+	}
+
+	upgraded, err = UpgradeGeneratedProfiles("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upgraded) != 0 {
+		t.Errorf("Expected no changes on second run, got %v", upgraded)
+	}
+}
+
+func TestCountProfiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := `[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+sso_region = eu-west-1
+
+[profile dev]
+sso_session = corp
+region = eu-west-1
+
+[profile prod]
+sso_session = corp
+region = us-east-1
+
+[profile admin]
+role_arn = arn:aws:iam::111111111111:role/Admin
+source_profile = keys
+region = us-east-1
+
+[profile keys]
+region = us-east-1
+`
+	credentials := `[keys]
+aws_access_key_id = AKIA
+aws_secret_access_key = secret
+
+[legacy]
+aws_access_key_id = AKIA
+aws_secret_access_key = secret
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(tmpDir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpDir, "credentials"))
+
+	counts, err := CountProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Total != 5 {
+		t.Errorf("Expected 5 profiles, got %d", counts.Total)
+	}
+	if counts.ByType[ProfileTypeSSO] != 2 || counts.ByType[ProfileTypeIAM] != 1 || counts.ByType[ProfileTypeKey] != 2 {
+		t.Errorf("Unexpected counts by type %v", counts.ByType)
+	}
+	if counts.ByRegion["us-east-1"] != 3 || counts.ByRegion["eu-west-1"] != 1 {
+		t.Errorf("Unexpected counts by region %v", counts.ByRegion)
+	}
+
+	detailed, err := ListProfilesDetailed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detailed) != counts.Total {
+		t.Errorf("Expected CountProfiles to match ListProfilesDetailed, got %d and %d", counts.Total, len(detailed))
+	}
+}
+
+func TestAdoptGeneratedProfiles(t *testing.T) {
+	content := `[sso-session corp]
+sso_start_url = https://example.awsapps.com/start
+sso_region = eu-west-1
+
+[profile old-admin]
+sso_role_name = Admin
+sso_account_id = 111111111111
+sso_session = corp
+
+[profile marked]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+region = eu-west-1
+
+[profile chained]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+role_arn = arn:aws:iam::111111111111:role/Deploy
+
+[profile other]
+sso_session = lab
+sso_account_id = 333333333333
+sso_role_name = Admin
+`
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config")
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(tmpDir, "credentials"))
+
+	names, err := AdoptableProfiles("corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "old-admin" {
+		t.Fatalf("Expected only old-admin to be adoptable, got %v", names)
+	}
+	if err := AdoptGeneratedProfiles("corp", names); err != nil {
+		t.Fatal(err)
+	}
+	upgraded, err := UpgradeGeneratedProfiles("corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upgraded) != 1 || upgraded[0] != "old-admin" {
+		t.Fatalf("Expected the adopted profile to be upgraded, got %v", upgraded)
+	}
+
+	cfg, err := ini.Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	section := cfg.Section("profile old-admin")
+	if !isGeneratedProfile(section) {
+		t.Error("Expected the adopted profile to carry the marker")
+	}
+	expected := []string{"sso_session", "sso_account_id", "sso_role_name", "region"}
+	if keys := section.KeyStrings(); strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+	if isGeneratedProfile(cfg.Section("profile chained")) {
+		t.Error("Expected the chained profile to be left alone")
+	}
+	if names, _ := AdoptableProfiles("corp"); len(names) != 0 {
+		t.Errorf("Expected nothing left to adopt, got %v", names)
+	}
+}
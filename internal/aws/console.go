@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ConsoleEndpoints holds the sign-in and console URLs for a region.
+type ConsoleEndpoints struct {
+	// FederationURL is the federation endpoint that issues sign-in tokens.
+	FederationURL string
+	// Destination is the console home page the sign-in lands on.
+	Destination string
+	// SignoutURL ends the console session of the browser context opening it.
+	SignoutURL string
+}
+
+// GetConsoleEndpoints returns the federation endpoint and console home page for
+// region. Commercial regions use their regional console domain
+// (<region>.console.aws.amazon.com); the China and GovCloud partitions have
+// their own sign-in and console domains.
+func GetConsoleEndpoints(region string) ConsoleEndpoints {
+	signinURL := "https://signin.aws.amazon.com"
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		signinURL = "https://signin.amazonaws.cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		signinURL = "https://signin.amazonaws-us-gov.com"
+	}
+	return ConsoleEndpoints{
+		FederationURL: signinURL + "/federation",
+		Destination:   consoleBaseURL(region) + "/console/home?region=" + url.QueryEscape(region),
+		SignoutURL:    signinURL + "/oauth?Action=logout",
+	}
+}
+
+// consoleBaseURL returns the console domain serving region.
+func consoleBaseURL(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "https://console.amazonaws.cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "https://console.amazonaws-us-gov.com"
+	default:
+		return fmt.Sprintf("https://%s.console.aws.amazon.com", region)
+	}
+}
+
+// consoleServicePaths maps service names to their console landing pages.
+// %s is replaced by the region.
+var consoleServicePaths = map[string]string{
+	"billing":        "/billing/home#/",
+	"cloudformation": "/cloudformation/home?region=%s#/stacks",
+	"cloudtrail":     "/cloudtrail/home?region=%s#/events",
+	"cloudwatch":     "/cloudwatch/home?region=%s",
+	"dynamodb":       "/dynamodbv2/home?region=%s#tables",
+	"ec2":            "/ec2/home?region=%s#Instances:",
+	"ecs":            "/ecs/v2/clusters?region=%s",
+	"eks":            "/eks/home?region=%s#/clusters",
+	"iam":            "/iam/home#/home",
+	"lambda":         "/lambda/home?region=%s#/functions",
+	"logs":           "/cloudwatch/home?region=%s#logsV2:log-groups",
+	"rds":            "/rds/home?region=%s#databases:",
+	"route53":        "/route53/v2/home#Dashboard",
+	"s3":             "/s3/buckets?region=%s",
+	"secretsmanager": "/secretsmanager/listsecrets?region=%s",
+	"sns":            "/sns/v3/home?region=%s#/topics",
+	"sqs":            "/sqs/v3/home?region=%s#/queues",
+	"ssm":            "/systems-manager/home?region=%s",
+	"vpc":            "/vpcconsole/home?region=%s#vpcs:",
+}
+
+// ConsoleServices lists the service names with a known console page, followed
+// by the other services of the standard partition in the metadata.
+func ConsoleServices() []string {
+	services := make([]string, 0, len(consoleServicePaths))
+	for service := range consoleServicePaths {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range GetMetadata().Partition("aws").Services {
+		if _, ok := consoleServicePaths[service]; !ok {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// ConsoleServiceURL returns the console page of a service in region, to land
+// on instead of the console home. service is one of ConsoleServices, a
+// service with a page inside it ("lambda/functions/my-fn" opens
+// /lambda/home?region=...#/functions/my-fn), any other service name, or a raw
+// path starting with "/".
+func ConsoleServiceURL(region, service string) string {
+	base := consoleBaseURL(region)
+	query := "region=" + url.QueryEscape(region)
+
+	if strings.HasPrefix(service, "/") {
+		if strings.Contains(service, "region=") {
+			return base + service
+		}
+		path, fragment, hasFragment := strings.Cut(service, "#")
+		separator := "?"
+		if strings.Contains(path, "?") {
+			separator = "&"
+		}
+		if hasFragment {
+			return base + path + separator + query + "#" + fragment
+		}
+		return base + path + separator + query
+	}
+
+	name, page, hasPage := strings.Cut(strings.Trim(service, "/"), "/")
+	name = strings.ToLower(name)
+	if !hasPage {
+		if path, ok := consoleServicePaths[name]; ok {
+			if strings.Contains(path, "%s") {
+				path = fmt.Sprintf(path, url.QueryEscape(region))
+			}
+			return base + path
+		}
+	}
+	target := base + "/" + url.PathEscape(name) + "/home?" + query
+	if hasPage {
+		target += "#/" + page
+	}
+	return target
+}
+
+// IsValidConsoleRegion reports whether region can be used for the console,
+// which also covers the China and GovCloud partitions.
+func IsValidConsoleRegion(region string) bool {
+	p := GetMetadata().PartitionForRegion(region)
+	return p != nil && slices.Contains(consolePartitions, p.ID)
+}
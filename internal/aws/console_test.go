@@ -0,0 +1,54 @@
+package aws
+
+import "testing"
+
+func TestGetConsoleEndpoints(t *testing.T) {
+	tests := []struct {
+		region     string
+		federation string
+		dest       string
+		signout    string
+	}{
+		{"eu-west-1", "https://signin.aws.amazon.com/federation", "https://eu-west-1.console.aws.amazon.com/console/home?region=eu-west-1", "https://signin.aws.amazon.com/oauth?Action=logout"},
+		{"cn-north-1", "https://signin.amazonaws.cn/federation", "https://console.amazonaws.cn/console/home?region=cn-north-1", "https://signin.amazonaws.cn/oauth?Action=logout"},
+		{"us-gov-west-1", "https://signin.amazonaws-us-gov.com/federation", "https://console.amazonaws-us-gov.com/console/home?region=us-gov-west-1", "https://signin.amazonaws-us-gov.com/oauth?Action=logout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.region, func(t *testing.T) {
+			got := GetConsoleEndpoints(tt.region)
+			if got.FederationURL != tt.federation {
+				t.Errorf("Expected federation URL %s, got %s", tt.federation, got.FederationURL)
+			}
+			if got.Destination != tt.dest {
+				t.Errorf("Expected destination %s, got %s", tt.dest, got.Destination)
+			}
+			if got.SignoutURL != tt.signout {
+				t.Errorf("Expected sign-out URL %s, got %s", tt.signout, got.SignoutURL)
+			}
+		})
+	}
+}
+
+func TestConsoleServiceURL(t *testing.T) {
+	tests := []struct {
+		region  string
+		service string
+		want    string
+	}{
+		{"eu-west-1", "s3", "https://eu-west-1.console.aws.amazon.com/s3/buckets?region=eu-west-1"},
+		{"eu-west-1", "EC2", "https://eu-west-1.console.aws.amazon.com/ec2/home?region=eu-west-1#Instances:"},
+		{"eu-west-1", "lambda/functions/my-fn", "https://eu-west-1.console.aws.amazon.com/lambda/home?region=eu-west-1#/functions/my-fn"},
+		{"eu-west-1", "glue", "https://eu-west-1.console.aws.amazon.com/glue/home?region=eu-west-1"},
+		{"eu-west-1", "/codesuite/codepipeline/pipelines", "https://eu-west-1.console.aws.amazon.com/codesuite/codepipeline/pipelines?region=eu-west-1"},
+		{"eu-west-1", "/ec2/home#Volumes:", "https://eu-west-1.console.aws.amazon.com/ec2/home?region=eu-west-1#Volumes:"},
+		{"eu-west-1", "/ec2/home?region=us-east-1", "https://eu-west-1.console.aws.amazon.com/ec2/home?region=us-east-1"},
+		{"cn-north-1", "s3", "https://console.amazonaws.cn/s3/buckets?region=cn-north-1"},
+		{"us-gov-west-1", "iam", "https://console.amazonaws-us-gov.com/iam/home#/home"},
+	}
+	for _, tt := range tests {
+		if got := ConsoleServiceURL(tt.region, tt.service); got != tt.want {
+			t.Errorf("ConsoleServiceURL(%s, %s) = %s, want %s", tt.region, tt.service, got, tt.want)
+		}
+	}
+}
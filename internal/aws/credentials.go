@@ -6,12 +6,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"awsm/internal/browser"
+	awsmConfig "awsm/internal/config"
 	"awsm/internal/util"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -32,17 +37,30 @@ type TempCredentials struct {
 	Expires         time.Time `json:"expires"`
 }
 
-// credsCachePath returns the path for a profile's cached credentials.
+// credsCachePath returns the path for a profile's cached credentials. The
+// profile name is escaped into a single file name, as generated names may
+// contain '/'; the file itself records the real name.
 func credsCachePath(profileName string) (string, error) {
-	home, err := os.UserHomeDir()
+	dir, err := credsCacheDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, ".awsm", "cache", profileName+".json"), nil
+	return filepath.Join(dir, url.PathEscape(profileName)+".json"), nil
 }
 
+// renewableMinValidity is how long cached credentials of profiles that renew
+// without user interaction, like SSO profiles, must stay valid to be reused.
+const renewableMinValidity = 5 * time.Minute
+
 // getCachedCreds reads cached credentials for a profile if they exist and are still valid.
 func getCachedCreds(profileName string) *TempCredentials {
+	// Require at least 60 seconds remaining
+	return GetCachedCredentials(profileName, 60*time.Second)
+}
+
+// GetCachedCredentials returns the cached credentials of a profile when they
+// stay valid for at least minValidity, nil otherwise.
+func GetCachedCredentials(profileName string, minValidity time.Duration) *TempCredentials {
 	path, err := credsCachePath(profileName)
 	if err != nil {
 		return nil
@@ -51,19 +69,23 @@ func getCachedCreds(profileName string) *TempCredentials {
 	if err != nil {
 		return nil
 	}
-	var creds TempCredentials
-	if err := json.Unmarshal(data, &creds); err != nil {
+	var cached cachedCredentials
+	if err := json.Unmarshal(data, &cached); err != nil {
 		return nil
 	}
-	// Require at least 60 seconds remaining
-	if time.Until(creds.Expires) < 60*time.Second {
+	if time.Until(cached.Expires) < minValidity {
 		return nil
 	}
-	return &creds
+	return &cached.TempCredentials
 }
 
-// setCachedCreds writes credentials to the cache.
-func setCachedCreds(profileName string, creds *TempCredentials) {
+// CacheCredentials stores temporary credentials of a profile in awsm's cache,
+// readable only by the user. Credentials without an expiry are long-term keys
+// and never cached.
+func CacheCredentials(profileName string, creds *TempCredentials) {
+	if creds.Expires.IsZero() {
+		return
+	}
 	path, err := credsCachePath(profileName)
 	if err != nil {
 		return
@@ -71,11 +93,11 @@ func setCachedCreds(profileName string, creds *TempCredentials) {
 	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return
 	}
-	data, err := json.Marshal(creds)
+	data, err := json.Marshal(cachedCredentials{Profile: profileName, CachedAt: time.Now().UTC(), TempCredentials: *creds})
 	if err != nil {
 		return
 	}
-	_ = os.WriteFile(path, data, 0600)
+	_ = writeFileAtomic(path, data, 0600)
 }
 
 // HasValidCachedCredentials checks if valid cached credentials exist for a profile.
@@ -85,18 +107,29 @@ func HasValidCachedCredentials(profileName string) bool {
 
 // profileConfig holds the relevant configuration details extracted from a profile.
 type profileConfig struct {
-	MfaSerial     string
-	RoleArn       string
-	SourceProfile string
+	MfaSerial            string
+	MfaProcess           string
+	RoleArn              string
+	SourceProfile        string
+	CredentialSource     string
+	Region               string
+	STSRegionalEndpoints string
+	DurationSeconds      string
+	RoleSessionName      string
+	ExternalID           string
+	// Keychain is set for profiles whose static keys live in the OS keychain.
+	Keychain bool
 }
 
-// ProfileNeedsMFA checks if a profile requires MFA and returns the MFA serial.
+// ProfileNeedsMFA checks if a profile requires the user to enter an MFA code
+// and returns the MFA serial. Profiles with an mfa_process or a TOTP secret in
+// the keychain get their codes without asking.
 func ProfileNeedsMFA(profileName string) (bool, string, error) {
 	pConfig, profileType, err := inspectProfile(profileName)
 	if err != nil {
 		return false, "", err
 	}
-	if profileType == "iam" && pConfig.MfaSerial != "" {
+	if profileType == "iam" && pConfig.MfaSerial != "" && !hasAutomaticMFA(pConfig) {
 		return true, pConfig.MfaSerial, nil
 	}
 	return false, "", nil
@@ -118,9 +151,12 @@ func GetCredentialsForProfile(profileName string, mfaToken ...string) (creds *Te
 
 	switch profileType {
 	case "iam":
-		// Check credential cache before prompting for MFA
-		if cached := getCachedCreds(profileName); cached != nil {
-			return cached, false, nil
+		// Check credential cache before prompting for MFA. An explicit
+		// --duration asks for a new session of that length.
+		if RoleDuration == 0 {
+			if cached := getCachedCreds(profileName); cached != nil {
+				return cached, false, nil
+			}
 		}
 		tempCreds, err := handleIamProfile(profileName, pConfig, token)
 		if err != nil {
@@ -132,11 +168,16 @@ func GetCredentialsForProfile(profileName string, mfaToken ...string) (creds *Te
 			SessionToken:    *tempCreds.SessionToken,
 			Expires:         *tempCreds.Expiration,
 		}
-		setCachedCreds(profileName, result)
+		CacheCredentials(profileName, result)
 		return result, false, nil
 
 	case "sso", "credential-process":
-		awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName))
+		// The cache spares a call to SSO or the process for every command.
+		// Renewing needs no MFA code, so nearly expired ones are renewed early.
+		if cached := GetCachedCredentials(profileName, renewableMinValidity); cached != nil {
+			return cached, false, nil
+		}
+		awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName), withConfigFragments())
 		if err != nil {
 			return nil, false, fmt.Errorf("failed to load AWS config for profile: %w", err)
 		}
@@ -145,23 +186,29 @@ func GetCredentialsForProfile(profileName string, mfaToken ...string) (creds *Te
 			if strings.Contains(err.Error(), "token has expired") || strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "InvalidGrantException") {
 				return nil, false, ErrSsoSessionExpired // Return our special error.
 			}
-			return nil, false, err // Return the original error for other issues.
+			return nil, false, WrapProfileError("get credentials", profileName, err)
 		}
-		return &TempCredentials{
+		result := &TempCredentials{
 			AccessKeyId:     sdkCreds.AccessKeyID,
 			SecretAccessKey: sdkCreds.SecretAccessKey,
 			SessionToken:    sdkCreds.SessionToken,
 			Expires:         sdkCreds.Expires,
-		}, false, nil
+		}
+		CacheCredentials(profileName, result)
+		return result, false, nil
+
+	case "keychain":
+		creds, err := staticKeysFromKeychain(profileName)
+		return creds, true, err
 
 	case "iam-user", "static":
-		awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName))
+		awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName), withConfigFragments())
 		if err != nil {
 			return nil, true, fmt.Errorf("failed to load AWS config for static profile: %w", err)
 		}
 		sdkCreds, err := awsCfg.Credentials.Retrieve(context.TODO())
 		if err != nil {
-			return nil, true, fmt.Errorf("failed to retrieve static credentials: %w", err)
+			return nil, true, WrapProfileError("retrieve static credentials", profileName, err)
 		}
 		return &TempCredentials{
 			AccessKeyId:     sdkCreds.AccessKeyID,
@@ -177,11 +224,7 @@ func GetCredentialsForProfile(profileName string, mfaToken ...string) (creds *Te
 
 // inspectProfile reads the config file to determine the profile type.
 func inspectProfile(profileName string) (*profileConfig, string, error) {
-	configPath, err := GetAWSConfigPath()
-	if err != nil {
-		return nil, "", err
-	}
-	cfgFile, err := ini.Load(configPath)
+	cfgFile, err := loadMergedConfig()
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to read AWS config file: %w", err)
 	}
@@ -193,7 +236,7 @@ func inspectProfile(profileName string) (*profileConfig, string, error) {
 		if credErr != nil {
 			return nil, "", fmt.Errorf("could not find profile section for '%s'", profileName)
 		}
-		credFile, credErr := ini.Load(credentialsPath)
+		credFile, credErr := loadIni(credentialsPath)
 		if credErr != nil {
 			return nil, "", fmt.Errorf("could not find profile section for '%s'", profileName)
 		}
@@ -209,17 +252,29 @@ func inspectProfile(profileName string) (*profileConfig, string, error) {
 	}
 
 	pConfig := &profileConfig{
-		MfaSerial:     section.Key("mfa_serial").String(),
-		RoleArn:       section.Key("role_arn").String(),
-		SourceProfile: section.Key("source_profile").String(),
+		MfaSerial:            section.Key("mfa_serial").String(),
+		MfaProcess:           section.Key("mfa_process").String(),
+		RoleArn:              section.Key("role_arn").String(),
+		SourceProfile:        section.Key("source_profile").String(),
+		CredentialSource:     section.Key("credential_source").String(),
+		Region:               section.Key("region").String(),
+		STSRegionalEndpoints: section.Key("sts_regional_endpoints").String(),
+		DurationSeconds:      section.Key("duration_seconds").String(),
+		RoleSessionName:      section.Key("role_session_name").String(),
+		ExternalID:           section.Key("external_id").String(),
+		Keychain:             section.Key(keychainMarker).MustBool(false),
 	}
 
 	if pConfig.RoleArn != "" || pConfig.MfaSerial != "" {
 		return pConfig, "iam", nil
 	}
-	if section.HasKey("sso_session") {
+	if section.HasKey("sso_session") || section.HasKey("sso_start_url") {
 		return pConfig, "sso", nil
 	}
+	// Checked before credential_process, which only points other tools at awsm
+	if pConfig.Keychain {
+		return pConfig, "keychain", nil
+	}
 	if section.HasKey("credential_process") {
 		return pConfig, "credential-process", nil
 	}
@@ -230,7 +285,7 @@ func inspectProfile(profileName string) (*profileConfig, string, error) {
 	// Profile found in config but no special keys, check credentials file for static keys
 	credentialsPath, credErr := GetAWSCredentialsPath()
 	if credErr == nil {
-		credFile, credErr := ini.Load(credentialsPath)
+		credFile, credErr := loadIni(credentialsPath)
 		if credErr == nil {
 			credSection, credErr := credFile.GetSection(profileName)
 			if credErr == nil && credSection.HasKey("aws_access_key_id") && credSection.HasKey("aws_secret_access_key") {
@@ -250,6 +305,24 @@ func handleIamProfile(profileName string, pConfig *profileConfig, mfaToken strin
 	return getSessionToken(profileName, pConfig, mfaToken)
 }
 
+// defaultRoleSessionName names role sessions of profiles without role_session_name.
+const defaultRoleSessionName = "awsm-session"
+
+// roleSessionNamePattern is what STS accepts as RoleSessionName.
+var roleSessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// roleSessionName reads a profile's role_session_name value.
+func roleSessionName(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return defaultRoleSessionName, nil
+	}
+	if !roleSessionNamePattern.MatchString(value) {
+		return "", fmt.Errorf("invalid role_session_name '%s': use 2-64 letters, digits or +=,.@_- characters", value)
+	}
+	return value, nil
+}
+
 // assumeRole handles the specific logic for calling sts:AssumeRole.
 func assumeRole(profileName string, pConfig *profileConfig, mfaToken string) (*types.Credentials, error) {
 	util.InfoColor.Fprintf(os.Stderr, "Assuming role %s...\n", util.BoldColor.Sprint(pConfig.RoleArn))
@@ -257,45 +330,61 @@ func assumeRole(profileName string, pConfig *profileConfig, mfaToken string) (*t
 	stsClientProfile := profileName
 	if pConfig.SourceProfile != "" {
 		stsClientProfile = pConfig.SourceProfile
-
-		// Check if source profile is SSO and ensure it's logged in
-		if ssoSession, err := GetSsoSessionForProfile(stsClientProfile); err == nil {
-			// It's an SSO profile, check if login is needed
-			if needsLogin, checkErr := checkSSOLoginNeeded(stsClientProfile); checkErr != nil {
-				// If there's an error checking SSO status, it's likely expired or has connectivity issues
-				if strings.Contains(checkErr.Error(), "certificate") || strings.Contains(checkErr.Error(), "SSL") {
-					return nil, fmt.Errorf("SSL certificate issue with SSO session for source profile '%s'. Please check your network configuration or run: aws sso login --sso-session %s", stsClientProfile, ssoSession)
-				}
-				return nil, fmt.Errorf("SSO session for source profile '%s' has expired or is invalid. Please run: aws sso login --sso-session %s", stsClientProfile, ssoSession)
-			} else if needsLogin {
-				return nil, fmt.Errorf("SSO session for source profile '%s' has expired. Please run: aws sso login --sso-session %s", stsClientProfile, ssoSession)
-			}
-		}
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(stsClientProfile))
-	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config for source profile '%s': %w", stsClientProfile, err)
+	var awsCfg aws.Config
+	var chained bool
+	var err error
+	if pConfig.SourceProfile == "" && pConfig.CredentialSource != "" {
+		// Base credentials come from the environment/instance rather than another profile
+		awsCfg, err = loadCredentialSourceConfig(pConfig.CredentialSource, pConfig.Region)
+		if err != nil {
+			return nil, err
+		}
+	} else if pConfig.SourceProfile != "" && IsKeychainProfile(stsClientProfile) {
+		if awsCfg, err = keychainSourceConfig(stsClientProfile); err != nil {
+			return nil, err
+		}
+	} else if awsCfg, chained, err = chainedSourceConfig(profileName, stsClientProfile); err != nil {
+		return nil, err
+	} else if !chained {
+		awsCfg, err = config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(stsClientProfile), withConfigFragments())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config for source profile '%s': %w", stsClientProfile, err)
+		}
 	}
 
 	var tokenCode *string
 	if pConfig.MfaSerial != "" {
-		code := mfaToken
-		if code == "" {
-			prompt := fmt.Sprintf("Enter MFA token for %s: ", util.BoldColor.Sprint(pConfig.MfaSerial))
-			var err error
-			code, err = util.PromptForInput(prompt)
-			if err != nil {
-				return nil, fmt.Errorf("failed to read MFA token: %w", err)
-			}
+		code, err := resolveMFACode(pConfig, mfaToken)
+		if err != nil {
+			return nil, err
 		}
 		tokenCode = aws.String(code)
 	}
 
+	duration, err := roleDurationSeconds(pConfig.DurationSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	sessionName, err := roleSessionName(pConfig.RoleSessionName)
+	if err != nil {
+		return nil, fmt.Errorf("profile '%s': %w", profileName, err)
+	}
+	// AWS caps sessions of a role assumed with role credentials at one hour
+	if chained && duration > defaultRoleDuration {
+		duration = defaultRoleDuration
+	}
 	input := &sts.AssumeRoleInput{
 		RoleArn:         aws.String(pConfig.RoleArn),
-		RoleSessionName: aws.String("awsm-session"),
-		DurationSeconds: aws.Int32(3600),
+		RoleSessionName: aws.String(sessionName),
+		DurationSeconds: aws.Int32(duration),
+	}
+	if pConfig.ExternalID != "" {
+		input.ExternalId = aws.String(pConfig.ExternalID)
+	}
+	if input.Tags, err = profileSessionTags(profileName); err != nil {
+		return nil, err
 	}
 
 	if pConfig.MfaSerial != "" {
@@ -303,10 +392,30 @@ func assumeRole(profileName string, pConfig *profileConfig, mfaToken string) (*t
 		input.TokenCode = tokenCode
 	}
 
-	stsClient := sts.NewFromConfig(awsCfg)
-	result, err := stsClient.AssumeRole(context.TODO(), input)
+	stsOpts, err := newSTSClientOptions(pConfig)
+	if err != nil {
+		return nil, err
+	}
+	assumeRole := func(optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+		return sts.NewFromConfig(awsCfg, append(stsOpts, optFns...)...).AssumeRole(context.TODO(), input)
+	}
+	result, err := retryOnClockSkew(assumeRole)
+	if err != nil && duration > defaultRoleDuration && isDurationTooLongError(err) {
+		clamped := clampRoleDuration(awsCfg, pConfig.RoleArn, duration, err)
+		setting := fmt.Sprintf("duration_seconds of profile '%s'", profileName)
+		if RoleDuration != 0 {
+			setting = "--duration"
+		}
+		util.Warn(util.WarnDurationCapped, "%s does not allow %s sessions, using %s instead. Lower %s to avoid this.",
+			pConfig.RoleArn, time.Duration(duration)*time.Second, time.Duration(clamped)*time.Second, setting)
+		input.DurationSeconds = aws.Int32(clamped)
+		result, err = retryOnClockSkew(assumeRole)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to assume role: %w", err)
+		if len(input.Tags) > 0 && strings.Contains(err.Error(), "AccessDenied") {
+			err = fmt.Errorf("%w (the profile passes session tags, which the role's trust policy must allow with sts:TagSession)", err)
+		}
+		return nil, WrapProfileError("assume role", profileName, err)
 	}
 	return result.Credentials, nil
 }
@@ -315,18 +424,20 @@ func assumeRole(profileName string, pConfig *profileConfig, mfaToken string) (*t
 func getSessionToken(profileName string, pConfig *profileConfig, mfaToken string) (*types.Credentials, error) {
 	util.InfoColor.Fprintf(os.Stderr, "Getting session token for profile %s...\n", util.BoldColor.Sprint(profileName))
 
-	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName))
+	var awsCfg aws.Config
+	var err error
+	if pConfig.Keychain {
+		awsCfg, err = keychainSourceConfig(profileName)
+	} else if awsCfg, err = config.LoadDefaultConfig(context.TODO(), config.WithSharedConfigProfile(profileName), withConfigFragments()); err != nil {
+		err = fmt.Errorf("failed to load AWS config for profile '%s': %w", profileName, err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config for profile '%s': %w", profileName, err)
+		return nil, err
 	}
 
-	code := mfaToken
-	if code == "" {
-		prompt := fmt.Sprintf("Enter MFA token for %s: ", util.BoldColor.Sprint(pConfig.MfaSerial))
-		code, err = util.PromptForInput(prompt)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read MFA token: %w", err)
-		}
+	code, err := resolveMFACode(pConfig, mfaToken)
+	if err != nil {
+		return nil, err
 	}
 
 	input := &sts.GetSessionTokenInput{
@@ -335,10 +446,15 @@ func getSessionToken(profileName string, pConfig *profileConfig, mfaToken string
 		TokenCode:       aws.String(code),
 	}
 
-	stsClient := sts.NewFromConfig(awsCfg)
-	result, err := stsClient.GetSessionToken(context.TODO(), input)
+	stsOpts, err := newSTSClientOptions(pConfig)
+	if err != nil {
+		return nil, err
+	}
+	result, err := retryOnClockSkew(func(optFns ...func(*sts.Options)) (*sts.GetSessionTokenOutput, error) {
+		return sts.NewFromConfig(awsCfg, append(stsOpts, optFns...)...).GetSessionToken(context.TODO(), input)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get session token: %w", err)
+		return nil, WrapProfileError("get session token", profileName, err)
 	}
 	return result.Credentials, nil
 }
@@ -356,8 +472,10 @@ func GetAWSCredentialsPath() (string, error) {
 	return filepath.Join(home, ".aws", "credentials"), nil
 }
 
-// UpdateCredentialsFile updates the default profile in the AWS credentials file
-func UpdateCredentialsFile(creds *TempCredentials, region, profileName string) error {
+// updateDefaultCredentials changes the default section of the credentials
+// file, creating the file and the section when missing. The file is replaced
+// in one step, so concurrent SDK reads never see partial credentials.
+func updateDefaultCredentials(update func(cfg *ini.File, section *ini.Section) error) error {
 	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
 		return err
@@ -369,36 +487,44 @@ func UpdateCredentialsFile(creds *TempCredentials, region, profileName string) e
 		return fmt.Errorf("failed to create AWS directory: %w", err)
 	}
 
-	// Load or create credentials file
-	cfg, err := loadOrCreateIni(credentialsPath)
-	if err != nil {
-		return err
-	}
-
-	// Get or create default section
-	section, err := cfg.GetSection("default")
-	if err != nil {
-		section, err = cfg.NewSection("default")
+	return updateIniAtomic(credentialsPath, func(cfg *ini.File) error {
+		section, err := cfg.GetSection("default")
 		if err != nil {
-			return fmt.Errorf("failed to create default section: %w", err)
+			section, err = cfg.NewSection("default")
+			if err != nil {
+				return fmt.Errorf("failed to create default section: %w", err)
+			}
 		}
-	}
-
-	// Update credentials
-	section.Key("aws_access_key_id").SetValue(creds.AccessKeyId)
-	section.Key("aws_secret_access_key").SetValue(creds.SecretAccessKey)
-	section.Key("aws_session_token").SetValue(creds.SessionToken)
+		return update(cfg, section)
+	})
+}
 
-	// Update region if provided
-	if region != "" {
-		section.Key("region").SetValue(region)
-	}
+// UpdateCredentialsFile updates the default profile in the AWS credentials file
+func UpdateCredentialsFile(creds *TempCredentials, region, profileName string) error {
+	return updateDefaultCredentials(func(_ *ini.File, section *ini.Section) error {
+		// Update credentials
+		section.Key("aws_access_key_id").SetValue(creds.AccessKeyId)
+		section.Key("aws_secret_access_key").SetValue(creds.SecretAccessKey)
+		if creds.SessionToken != "" {
+			section.Key("aws_session_token").SetValue(creds.SessionToken)
+		} else {
+			section.DeleteKey("aws_session_token")
+		}
 
-	// Track the source profile name
-	section.Key("# source_profile").SetValue(profileName)
+		// Update region if provided
+		if region != "" {
+			section.Key("region").SetValue(region)
+		}
 
-	// Save the file
-	return cfg.SaveTo(credentialsPath)
+		// Track the source profile name and when the credentials expire
+		section.Key("# source_profile").SetValue(profileName)
+		if !creds.Expires.IsZero() {
+			section.Key("# expires_at").SetValue(creds.Expires.UTC().Format(time.RFC3339))
+		} else {
+			section.DeleteKey("# expires_at")
+		}
+		return nil
+	})
 }
 
 // GetCurrentProfileName returns the name of the profile currently set in default
@@ -409,7 +535,7 @@ func GetCurrentProfileName() string {
 	}
 
 	// Try to load with ini first (faster)
-	cfg, err := ini.Load(credentialsPath)
+	cfg, err := loadIni(credentialsPath)
 	if err == nil {
 		section, err := cfg.GetSection("default")
 		if err == nil {
@@ -420,6 +546,18 @@ func GetCurrentProfileName() string {
 	}
 
 	// Fallback to manual parsing for edge cases
+	return readDefaultSectionComment(credentialsPath, "# source_profile")
+}
+
+// readDefaultSectionComment reads a "# key = value" line awsm keeps in the
+// default section, which ini parsers may treat as a comment.
+func readDefaultSectionComment(credentialsPath, key string) string {
+	unlock, err := rlockAWSFiles()
+	if err != nil {
+		return ""
+	}
+	defer unlock()
+
 	file, err := os.Open(credentialsPath)
 	if err != nil {
 		return ""
@@ -442,9 +580,9 @@ func GetCurrentProfileName() string {
 			continue
 		}
 
-		if inDefaultSection && strings.HasPrefix(line, "# source_profile") {
+		if inDefaultSection && strings.HasPrefix(line, key) {
 			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
+			if len(parts) == 2 && strings.TrimSpace(parts[0]) == key {
 				return strings.TrimSpace(parts[1])
 			}
 		}
@@ -453,136 +591,146 @@ func GetCurrentProfileName() string {
 	return ""
 }
 
-// UpdateStaticProfile updates the default profile to use a static profile's credentials
-func UpdateStaticProfile(profileName string) error {
-	configPath, err := GetAWSConfigPath()
+// DefaultCredentialsExpiry returns when the credentials in the default profile
+// expire, as recorded when they were set. Long-term keys return a zero time.
+// ok is false when the expiry is unknown, e.g. for temporary credentials set
+// by another tool.
+func DefaultCredentialsExpiry() (expires time.Time, ok bool) {
+	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
-		return err
+		return time.Time{}, false
+	}
+	if value := readDefaultSectionComment(credentialsPath, "# expires_at"); value != "" {
+		expires, err := time.Parse(time.RFC3339, value)
+		return expires, err == nil
 	}
 
-	credentialsPath, err := GetAWSCredentialsPath()
+	cfg, err := loadIni(credentialsPath)
 	if err != nil {
-		return err
+		return time.Time{}, false
+	}
+	section, err := cfg.GetSection("default")
+	if err != nil || section.Key("aws_access_key_id").String() == "" {
+		return time.Time{}, false
+	}
+	// Without a session token the default profile holds long-term keys
+	return time.Time{}, section.Key("aws_session_token").String() == ""
+}
+
+// InvalidateCachedCredentials drops the cached credentials of a profile, so
+// the next call gets new ones.
+func InvalidateCachedCredentials(profileName string) {
+	if path, err := credsCachePath(profileName); err == nil {
+		os.Remove(path)
 	}
+}
 
+// UpdateStaticProfile updates the default profile to use a static profile's credentials
+func UpdateStaticProfile(profileName string) error {
 	// Load config file to get region (optional)
 	var region string
-	cfgFile, err := ini.Load(configPath)
+	cfgFile, err := loadMergedConfig()
 	if err == nil {
 		if configSection, err := getProfileSection(cfgFile, profileName); err == nil {
 			region = configSection.Key("region").String()
 		}
 	}
 
-	// Load credentials file to get static credentials and region if needed
-	credFile, err := ini.Load(credentialsPath)
-	if err != nil {
-		return fmt.Errorf("failed to read AWS credentials file: %w", err)
-	}
-
-	// If no region in config, check credentials file
-	if region == "" {
-		if credSection, err := credFile.GetSection(profileName); err == nil {
-			region = credSection.Key("region").String()
+	// Keys kept in the keychain are copied into the default section, which
+	// tools without credential_process support read
+	if IsKeychainProfile(profileName) {
+		creds, err := staticKeysFromKeychain(profileName)
+		if err != nil {
+			return err
 		}
+		return UpdateCredentialsFile(creds, region, profileName)
 	}
 
-	sourceSection, err := credFile.GetSection(profileName)
-	if err != nil {
-		return fmt.Errorf("could not find credentials for profile '%s'", profileName)
-	}
+	return updateDefaultCredentials(func(credFile *ini.File, defaultSection *ini.Section) error {
+		sourceSection, err := credFile.GetSection(profileName)
+		if err != nil {
+			return fmt.Errorf("could not find credentials for profile '%s'", profileName)
+		}
 
-	accessKey := sourceSection.Key("aws_access_key_id").String()
-	secretKey := sourceSection.Key("aws_secret_access_key").String()
+		// If no region in config, check credentials file
+		if region == "" {
+			region = sourceSection.Key("region").String()
+		}
 
-	if accessKey == "" || secretKey == "" {
-		return fmt.Errorf("profile '%s' does not have static credentials", profileName)
-	}
+		accessKey := sourceSection.Key("aws_access_key_id").String()
+		secretKey := sourceSection.Key("aws_secret_access_key").String()
 
-	// Update default section
-	defaultSection, err := credFile.GetSection("default")
-	if err != nil {
-		defaultSection, err = credFile.NewSection("default")
-		if err != nil {
-			return fmt.Errorf("failed to create default section: %w", err)
+		if accessKey == "" || secretKey == "" {
+			return fmt.Errorf("profile '%s' does not have static credentials", profileName)
 		}
-	}
 
-	defaultSection.Key("aws_access_key_id").SetValue(accessKey)
-	defaultSection.Key("aws_secret_access_key").SetValue(secretKey)
+		defaultSection.Key("aws_access_key_id").SetValue(accessKey)
+		defaultSection.Key("aws_secret_access_key").SetValue(secretKey)
 
-	// Check if source profile has session token and copy it
-	if sourceSection.HasKey("aws_session_token") {
-		sessionToken := sourceSection.Key("aws_session_token").String()
-		if sessionToken != "" {
+		// Check if source profile has session token and copy it
+		if sessionToken := sourceSection.Key("aws_session_token").String(); sessionToken != "" {
 			defaultSection.Key("aws_session_token").SetValue(sessionToken)
 		} else {
-			defaultSection.DeleteKey("aws_session_token")
+			defaultSection.DeleteKey("aws_session_token") // Remove session token if not present in source
 		}
-	} else {
-		defaultSection.DeleteKey("aws_session_token") // Remove session token if not present in source
-	}
 
-	if region != "" {
-		defaultSection.Key("region").SetValue(region)
-	}
-
-	// Track the source profile name
-	defaultSection.Key("# source_profile").SetValue(profileName)
+		if region != "" {
+			defaultSection.Key("region").SetValue(region)
+		}
 
-	return credFile.SaveTo(credentialsPath)
+		// Track the source profile name; the expiry of static keys is unknown
+		defaultSection.Key("# source_profile").SetValue(profileName)
+		defaultSection.DeleteKey("# expires_at")
+		return nil
+	})
 }
 
 // SetRegion updates the region in the default profile
 func SetRegion(region string) error {
-	credentialsPath, err := GetAWSCredentialsPath()
+	unlock, err := lockAWSFiles()
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	// Get current source profile name to preserve it
-	currentSourceProfile := GetCurrentProfileName()
-
-	// Create .aws directory if it doesn't exist
-	awsDir := filepath.Dir(credentialsPath)
-	if err := os.MkdirAll(awsDir, 0755); err != nil {
-		return fmt.Errorf("failed to create AWS directory: %w", err)
-	}
-
-	cfg, err := loadOrCreateIni(credentialsPath)
+	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
 		return err
 	}
 
-	// Get or create default section
-	section, err := cfg.GetSection("default")
-	if err != nil {
-		section, err = cfg.NewSection("default")
-		if err != nil {
-			return fmt.Errorf("failed to create default section: %w", err)
-		}
-	}
-
-	// Update region
-	section.Key("region").SetValue(region)
+	// Get current source profile name and expiry to preserve them
+	currentSourceProfile := GetCurrentProfileName()
+	currentExpiresAt := readDefaultSectionComment(credentialsPath, "# expires_at")
 
-	// Preserve the source profile comment if it exists
-	if currentSourceProfile != "" {
-		section.Key("# source_profile").SetValue(currentSourceProfile)
-	}
+	return updateDefaultCredentials(func(_ *ini.File, section *ini.Section) error {
+		// Update region
+		section.Key("region").SetValue(region)
 
-	// Save the file
-	return cfg.SaveTo(credentialsPath)
+		// Preserve the source profile comment if it exists
+		if currentSourceProfile != "" {
+			section.Key("# source_profile").SetValue(currentSourceProfile)
+		}
+		if currentExpiresAt != "" {
+			section.Key("# expires_at").SetValue(currentExpiresAt)
+		}
+		return nil
+	})
 }
 
 // ClearDefaultProfile removes all credentials and region from the default profile
 func ClearDefaultProfile() error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	credentialsPath, err := GetAWSCredentialsPath()
 	if err != nil {
 		return err
 	}
 
-	cfg, err := ini.Load(credentialsPath)
+	cfg, err := loadIni(credentialsPath)
 	if err != nil {
 		return fmt.Errorf("failed to load credentials file: %w", err)
 	}
@@ -598,33 +746,68 @@ func ClearDefaultProfile() error {
 	section.DeleteKey("aws_session_token")
 	section.DeleteKey("region")
 	section.DeleteKey("# source_profile")
+	section.DeleteKey("# expires_at")
 
-	return cfg.SaveTo(credentialsPath)
+	return saveIniAtomic(cfg, credentialsPath)
 }
 
-// checkSSOLoginNeeded checks if an SSO profile needs login
-func checkSSOLoginNeeded(profileName string) (bool, error) {
-	// Try to get credentials to see if SSO session is valid
-	_, _, err := GetCredentialsForProfile(profileName)
-	if err != nil && errors.Is(err, ErrSsoSessionExpired) {
-		return true, nil
+// PerformSSOLogin renews the token of an SSO session, silently with the cached
+// refresh token when possible and with a full login otherwise.
+func PerformSSOLogin(ssoSession string) error {
+	err := RefreshSSOToken(ssoSession)
+	if err == nil {
+		InvalidateSSOTokenStatus(ssoSession)
+		util.SuccessColor.Fprintf(os.Stderr, "✔ SSO token of session %s refreshed without a new login.\n", util.BoldColor.Sprint(ssoSession))
+		return nil
 	}
-	return false, err
+	if !errors.Is(err, ErrSSORefreshUnavailable) {
+		util.Warn(util.WarnSSORefreshFailed, "Could not refresh the SSO token silently (%v), starting a new login.", err)
+	}
+	return PerformFullSSOLogin(ssoSession)
 }
 
-// PerformSSOLogin runs `aws sso login` for the given SSO session.
-func PerformSSOLogin(ssoSession string) error {
+// PerformFullSSOLogin runs `aws sso login` for the given SSO session. The login
+// page opens in the browser chosen in the awsm config, or is only printed
+// when SSONoBrowser or the sso.no_browser setting is on. Without AWS CLI v2,
+// or with the sso.native_login setting, awsm logs in by itself.
+func PerformFullSSOLogin(ssoSession string) error {
+	if awsmConfig.GetSSONativeLogin() {
+		return PerformNativeSSOLogin(ssoSession)
+	}
+	if _, err := checkAWSCLIv2(DetectAWSCLIVersion()); err != nil {
+		util.InfoColor.Fprintln(os.Stderr, "AWS CLI v2 not available, logging in with awsm's built-in SSO login.")
+		return PerformNativeSSOLogin(ssoSession)
+	}
+	if err := requireAWSCLIv2(); err != nil {
+		return err
+	}
+
+	noBrowser := SSONoBrowser || awsmConfig.GetSSONoBrowser()
+	chromeProfile := awsmConfig.GetSSOChromeProfile()
+
 	util.InfoColor.Fprintf(os.Stderr, "SSO session expired. Attempting login for session: %s\n", util.BoldColor.Sprint(ssoSession))
-	util.InfoColor.Fprintln(os.Stderr, "Your browser should open. Please follow the instructions.")
+	var stdout io.Writer = os.Stderr
+	switch {
+	case noBrowser:
+		util.InfoColor.Fprintln(os.Stderr, "Open the URL below in a browser on any device and confirm the code.")
+	case chromeProfile != "":
+		util.InfoColor.Fprintf(os.Stderr, "Opening the login page in Chrome profile '%s'. Please follow the instructions.\n", chromeProfile)
+		stdout = newSSOLoginURLOpener(os.Stderr, func(loginURL string) error {
+			return browser.OpenURL(loginURL, chromeProfile, "", "")
+		})
+	default:
+		util.InfoColor.Fprintln(os.Stderr, "Your browser should open. Please follow the instructions.")
+	}
 
-	cmd := exec.Command("aws", "sso", "login", "--sso-session", ssoSession)
+	cmd := exec.Command("aws", ssoLoginArgs(ssoSession, noBrowser || chromeProfile != "")...)
 	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stderr
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("aws sso login failed: %w", err)
 	}
+	InvalidateSSOTokenStatus(ssoSession)
 	util.SuccessColor.Fprintln(os.Stderr, "✔ SSO login successful.")
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGetAWSCredentialsPath(t *testing.T) {
@@ -69,3 +70,63 @@ func TestProfileConfig(t *testing.T) {
 		t.Errorf("Unexpected SourceProfile: %s", config.SourceProfile)
 	}
 }
+
+func TestGetCachedCredentials(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AWSM_HOME", "")
+
+	CacheCredentials("static", &TempCredentials{AccessKeyId: "AKIA"})
+	if GetCachedCredentials("static", 0) != nil {
+		t.Error("Expected credentials without expiry not to be cached")
+	}
+
+	CacheCredentials("dev", &TempCredentials{AccessKeyId: "ASIA", Expires: time.Now().Add(10 * time.Minute)})
+	if GetCachedCredentials("dev", 5*time.Minute) == nil {
+		t.Error("Expected cached credentials valid for 10 minutes to be returned for a 5 minute window")
+	}
+	if GetCachedCredentials("dev", 15*time.Minute) != nil {
+		t.Error("Expected credentials expiring within the refresh window to be refreshed")
+	}
+}
+
+func TestDefaultCredentialsExpiry(t *testing.T) {
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+
+	if _, ok := DefaultCredentialsExpiry(); ok {
+		t.Error("Expected an unknown expiry without a credentials file")
+	}
+
+	expires := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	creds := &TempCredentials{AccessKeyId: "AKIA", SecretAccessKey: "secret", SessionToken: "token", Expires: expires}
+	if err := UpdateCredentialsFile(creds, "eu-west-1", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	// Rewriting the default section keeps the recorded expiry
+	if err := SetRegion("us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := DefaultCredentialsExpiry(); !ok || !got.Equal(expires) {
+		t.Errorf("Expected expiry %v, got %v (known %v)", expires, got, ok)
+	}
+	if got := GetCurrentProfileName(); got != "prod" {
+		t.Errorf("Expected source profile prod, got %s", got)
+	}
+
+	if err := ClearDefaultProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := DefaultCredentialsExpiry(); ok {
+		t.Error("Expected an unknown expiry after clearing the default profile")
+	}
+
+	// Long-term keys never expire
+	if err := os.WriteFile(credentialsPath, []byte("[default]\naws_access_key_id = AKIA\naws_secret_access_key = secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := DefaultCredentialsExpiry(); !ok || !got.IsZero() {
+		t.Errorf("Expected long-term keys to never expire, got %v (known %v)", got, ok)
+	}
+}
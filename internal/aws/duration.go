@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"awsm/internal/policy"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// defaultRoleDuration is the session duration requested when a profile doesn't set duration_seconds.
+	// Every role allows at least one hour, which is also the limit for role chaining.
+	defaultRoleDuration int32 = 3600
+	// minRoleDuration and maxRoleDuration are the bounds STS accepts for AssumeRole.
+	minRoleDuration int32 = 900
+	maxRoleDuration int32 = 43200
+)
+
+// RoleDuration overrides duration_seconds of the role profiles assumed by this
+// run, set by the --duration flags. Zero means the profile decides.
+var RoleDuration time.Duration
+
+// roleDurationSeconds picks the session duration to request for a role
+// profile: the --duration override when given, duration_seconds otherwise.
+func roleDurationSeconds(value string) (int32, error) {
+	if RoleDuration == 0 {
+		return parseDurationSeconds(value)
+	}
+	seconds := int64(RoleDuration / time.Second)
+	if seconds < int64(minRoleDuration) || seconds > int64(maxRoleDuration) {
+		return 0, fmt.Errorf("--duration must be between %s and %s, got %s",
+			time.Duration(minRoleDuration)*time.Second, time.Duration(maxRoleDuration)*time.Second, RoleDuration)
+	}
+	return int32(seconds), nil
+}
+
+// parseDurationSeconds reads a profile's duration_seconds value.
+func parseDurationSeconds(value string) (int32, error) {
+	if value == "" {
+		return defaultRoleDuration, nil
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration_seconds '%s': %w", value, err)
+	}
+	if int32(seconds) < minRoleDuration || int32(seconds) > maxRoleDuration {
+		return 0, fmt.Errorf("duration_seconds must be between %d and %d, got %d", minRoleDuration, maxRoleDuration, seconds)
+	}
+	return int32(seconds), nil
+}
+
+// isDurationTooLongError reports whether STS rejected AssumeRole because the
+// requested duration exceeds the role's MaxSessionDuration or the role chaining limit.
+func isDurationTooLongError(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationError" &&
+		strings.Contains(apiErr.ErrorMessage(), "DurationSeconds exceeds")
+}
+
+// roleMaxSessionDuration asks IAM for the MaxSessionDuration of a role. It only
+// works when the caller may call iam:GetRole in the role's account.
+func roleMaxSessionDuration(awsCfg aws.Config, roleArn string) (int32, error) {
+	roleName := policy.RoleNameFromARN(roleArn)
+	if roleName == "" {
+		return 0, fmt.Errorf("invalid role ARN '%s'", roleArn)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := iam.NewFromConfig(awsCfg).GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		return 0, err
+	}
+	if out.Role == nil || out.Role.MaxSessionDuration == nil {
+		return 0, fmt.Errorf("role '%s' has no MaxSessionDuration", roleName)
+	}
+	return *out.Role.MaxSessionDuration, nil
+}
+
+// clampRoleDuration picks the longest duration STS will accept after it
+// rejected the requested one: the role's MaxSessionDuration when IAM tells us,
+// and one hour, the minimum any role allows, otherwise.
+func clampRoleDuration(awsCfg aws.Config, roleArn string, requested int32, stsErr error) int32 {
+	var apiErr smithy.APIError
+	if errors.As(stsErr, &apiErr) && strings.Contains(apiErr.ErrorMessage(), "role chaining") {
+		return defaultRoleDuration
+	}
+	if maxDuration, err := roleMaxSessionDuration(awsCfg, roleArn); err == nil && maxDuration < requested {
+		return maxDuration
+	}
+	return defaultRoleDuration
+}
@@ -0,0 +1,108 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestParseDurationSeconds(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int32
+		wantErr  bool
+	}{
+		{"", defaultRoleDuration, false},
+		{"900", 900, false},
+		{" 7200 ", 7200, false},
+		{"43200", 43200, false},
+		{"899", 0, true},
+		{"43201", 0, true},
+		{"1h", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDurationSeconds(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Expected error for %q, got %d", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("parseDurationSeconds(%q) = %d, %v; expected %d", tt.value, got, err, tt.expected)
+		}
+	}
+}
+
+func TestIsDurationTooLongError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Max session duration", &smithy.GenericAPIError{Code: "ValidationError", Message: "The requested DurationSeconds exceeds the MaxSessionDuration set for this role."}, true},
+		{"Role chaining", &smithy.GenericAPIError{Code: "ValidationError", Message: "The requested DurationSeconds exceeds the 1 hour session limit for roles assumed by role chaining."}, true},
+		{"Wrapped", fmt.Errorf("operation error STS: AssumeRole: %w", &smithy.GenericAPIError{Code: "ValidationError", Message: "The requested DurationSeconds exceeds the MaxSessionDuration set for this role."}), true},
+		{"Other validation error", &smithy.GenericAPIError{Code: "ValidationError", Message: "1 validation error detected: Value at 'roleArn' failed"}, false},
+		{"Access denied", &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"}, false},
+		{"Plain error", errors.New("DurationSeconds exceeds"), false},
+	}
+
+	for _, tt := range tests {
+		if got := isDurationTooLongError(tt.err); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRoleDurationSeconds(t *testing.T) {
+	t.Cleanup(func() { RoleDuration = 0 })
+
+	RoleDuration = 0
+	if got, err := roleDurationSeconds("7200"); err != nil || got != 7200 {
+		t.Errorf("Expected duration_seconds without an override, got %d, %v", got, err)
+	}
+
+	RoleDuration = 2 * time.Hour
+	if got, err := roleDurationSeconds("900"); err != nil || got != 7200 {
+		t.Errorf("Expected --duration to win over duration_seconds, got %d, %v", got, err)
+	}
+	// The override replaces an invalid profile value
+	if got, err := roleDurationSeconds("bogus"); err != nil || got != 7200 {
+		t.Errorf("Expected --duration to ignore duration_seconds, got %d, %v", got, err)
+	}
+
+	for _, d := range []time.Duration{10 * time.Minute, 13 * time.Hour} {
+		RoleDuration = d
+		if _, err := roleDurationSeconds(""); err == nil {
+			t.Errorf("Expected an error for --duration %s", d)
+		}
+	}
+}
+
+func TestRoleSessionName(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{"", defaultRoleSessionName, false},
+		{"jane.doe@example.com", "jane.doe@example.com", false},
+		{" ci-deploy ", "ci-deploy", false},
+		{"a", "", true},
+		{"has space", "", true},
+		{strings.Repeat("x", 65), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := roleSessionName(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("roleSessionName(%q) = %q, %v; expected %q", tt.value, got, err, tt.expected)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -24,6 +25,7 @@ type EC2Instance struct {
 func ListRunningInstances(profile, region string) ([]EC2Instance, error) {
 	opts := []func(*config.LoadOptions) error{
 		config.WithSharedConfigProfile(profile),
+		withConfigFragments(),
 	}
 	if region != "" {
 		opts = append(opts, config.WithRegion(region))
@@ -51,7 +53,7 @@ func ListRunningInstances(profile, region string) ([]EC2Instance, error) {
 	for paginator.HasMorePages() {
 		page, err := paginator.NextPage(context.TODO())
 		if err != nil {
-			return nil, fmt.Errorf("failed to describe instances: %w", err)
+			return nil, WrapProfileError("describe instances", profile, err)
 		}
 
 		for _, reservation := range page.Reservations {
@@ -85,3 +87,21 @@ func ListRunningInstances(profile, region string) ([]EC2Instance, error) {
 
 	return instances, nil
 }
+
+// MatchInstances returns the instances a user-supplied instance ID or Name tag
+// refers to. Exact ID or name matches win; otherwise names are fuzzy matched.
+func MatchInstances(instances []EC2Instance, query string) []EC2Instance {
+	var exact, fuzzy []EC2Instance
+	for _, instance := range instances {
+		switch {
+		case instance.InstanceID == query || strings.EqualFold(instance.Name, query):
+			exact = append(exact, instance)
+		case instance.Name != "" && FuzzyMatch(instance.Name, query):
+			fuzzy = append(fuzzy, instance)
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return fuzzy
+}
@@ -0,0 +1,42 @@
+package aws
+
+import "testing"
+
+func TestMatchInstances(t *testing.T) {
+	instances := []EC2Instance{
+		{InstanceID: "i-1", Name: "web-server"},
+		{InstanceID: "i-2", Name: "web-server-canary"},
+		{InstanceID: "i-3", Name: "database"},
+		{InstanceID: "i-4"},
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"web-server", []string{"i-1"}},
+		{"WEB-SERVER", []string{"i-1"}},
+		{"i-4", []string{"i-4"}},
+		{"web", []string{"i-1", "i-2"}},
+		{"dbase", []string{"i-3"}},
+		{"cache", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			matches := MatchInstances(instances, tt.query)
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.InstanceID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
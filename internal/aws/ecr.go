@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ECRPublicRegistry is the registry of ECR Public.
+const ECRPublicRegistry = "public.ecr.aws"
+
+// ecrPublicRegion is the only region that issues ECR Public tokens.
+const ecrPublicRegion = "us-east-1"
+
+// ECRLogin holds the docker credentials of an ECR registry.
+type ECRLogin struct {
+	// Registry is the host name to log in to, e.g. 123456789012.dkr.ecr.eu-west-1.amazonaws.com.
+	Registry  string
+	Username  string
+	Password  string
+	ExpiresAt time.Time
+}
+
+// ecrEndpoint returns the endpoint of the ECR API, or of ECR Public when
+// service is ecr-public; tests point it elsewhere.
+var ecrEndpoint = func(service, region string) string {
+	suffix := dnsSuffix(region)
+	if service == "ecr-public" {
+		return fmt.Sprintf("https://api.ecr-public.%s.%s", region, suffix)
+	}
+	return fmt.Sprintf("https://api.ecr.%s.%s", region, suffix)
+}
+
+type ecrAuthorizationData struct {
+	AuthorizationToken string  `json:"authorizationToken"`
+	ExpiresAt          float64 `json:"expiresAt"`
+	ProxyEndpoint      string  `json:"proxyEndpoint"`
+}
+
+// GetECRLogins returns docker credentials for the ECR registries of region:
+// the registry of the credentials' account, or those of registryIDs.
+func GetECRLogins(creds *TempCredentials, region string, registryIDs []string) ([]ECRLogin, error) {
+	input := struct {
+		RegistryIDs []string `json:"registryIds,omitempty"`
+	}{registryIDs}
+	var out struct {
+		AuthorizationData []ecrAuthorizationData `json:"authorizationData"`
+	}
+	if err := ecrRequest(creds, "ecr", region, "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken", input, &out); err != nil {
+		return nil, fmt.Errorf("failed to get ECR authorization token: %w", err)
+	}
+
+	logins := make([]ECRLogin, 0, len(out.AuthorizationData))
+	for _, data := range out.AuthorizationData {
+		login, err := parseECRAuthorization(data)
+		if err != nil {
+			return nil, err
+		}
+		login.Registry = strings.TrimPrefix(data.ProxyEndpoint, "https://")
+		logins = append(logins, login)
+	}
+	if len(logins) == 0 {
+		return nil, fmt.Errorf("ECR returned no authorization token")
+	}
+	return logins, nil
+}
+
+// GetECRPublicLogin returns docker credentials for ECR Public, which are
+// always issued in us-east-1.
+func GetECRPublicLogin(creds *TempCredentials) (*ECRLogin, error) {
+	var out struct {
+		AuthorizationData ecrAuthorizationData `json:"authorizationData"`
+	}
+	if err := ecrRequest(creds, "ecr-public", ecrPublicRegion, "SpencerFrontendService.GetAuthorizationToken", struct{}{}, &out); err != nil {
+		return nil, fmt.Errorf("failed to get ECR Public authorization token: %w", err)
+	}
+	login, err := parseECRAuthorization(out.AuthorizationData)
+	if err != nil {
+		return nil, err
+	}
+	login.Registry = ECRPublicRegistry
+	return &login, nil
+}
+
+// parseECRAuthorization decodes an authorization token, the base64 encoded
+// "user:password" docker logs in with.
+func parseECRAuthorization(data ecrAuthorizationData) (ECRLogin, error) {
+	decoded, err := base64.StdEncoding.DecodeString(data.AuthorizationToken)
+	if err != nil {
+		return ECRLogin{}, fmt.Errorf("failed to decode ECR authorization token: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return ECRLogin{}, fmt.Errorf("ECR authorization token is not in the user:password form")
+	}
+	login := ECRLogin{Username: username, Password: password}
+	if data.ExpiresAt > 0 {
+		login.ExpiresAt = time.Unix(0, int64(data.ExpiresAt*float64(time.Second)))
+	}
+	return login, nil
+}
+
+// ecrRequest calls an action of the ECR or ECR Public JSON API.
+func ecrRequest(creds *TempCredentials, service, region, target string, input, out any) error {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return err
+	}
+	header := http.Header{
+		"Content-Type": {"application/x-amz-json-1.1"},
+		"X-Amz-Target": {target},
+	}
+	data, err := apiRequest(creds, http.MethodPost, ecrEndpoint(service, region)+"/", service, region, header, body)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse %s response: %w", service, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGetECRLogins(t *testing.T) {
+	token := base64.StdEncoding.EncodeToString([]byte("AWS:secret-password"))
+	var targets, signedFor []string
+	var registryIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targets = append(targets, r.Header.Get("X-Amz-Target"))
+		signedFor = append(signedFor, r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		var input struct {
+			RegistryIDs []string `json:"registryIds"`
+		}
+		json.Unmarshal(body, &input)
+		registryIDs = input.RegistryIDs
+
+		switch {
+		case strings.HasPrefix(r.Header.Get("X-Amz-Target"), "SpencerFrontendService."):
+			w.Write([]byte(`{"authorizationData":{"authorizationToken":"` + token + `","expiresAt":1.7e9}}`))
+		case slices.Contains(input.RegistryIDs, "999999999999"):
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazonaws.ecr#InvalidParameterException","message":"Invalid registry"}`))
+		default:
+			w.Write([]byte(`{"authorizationData":[
+				{"authorizationToken":"` + token + `","expiresAt":1.7e9,"proxyEndpoint":"https://111111111111.dkr.ecr.eu-west-1.amazonaws.com"},
+				{"authorizationToken":"` + token + `","expiresAt":1.7e9,"proxyEndpoint":"https://222222222222.dkr.ecr.eu-west-1.amazonaws.com"}]}`))
+		}
+	}))
+	defer server.Close()
+	old := ecrEndpoint
+	t.Cleanup(func() { ecrEndpoint = old })
+	ecrEndpoint = func(string, string) string { return server.URL }
+
+	logins, err := GetECRLogins(eksTestCreds, "eu-west-1", []string{"111111111111", "222222222222"})
+	if err != nil {
+		t.Fatalf("GetECRLogins failed: %v", err)
+	}
+	if !slices.Equal(registryIDs, []string{"111111111111", "222222222222"}) {
+		t.Errorf("Expected the registry IDs to be sent, got %v", registryIDs)
+	}
+	if len(logins) != 2 || logins[1].Registry != "222222222222.dkr.ecr.eu-west-1.amazonaws.com" {
+		t.Fatalf("Unexpected logins: %+v", logins)
+	}
+	if logins[0].Username != "AWS" || logins[0].Password != "secret-password" || logins[0].ExpiresAt.Unix() != 1700000000 {
+		t.Errorf("Expected the token to be decoded, got %+v", logins[0])
+	}
+	if targets[0] != "AmazonEC2ContainerRegistry_V20150921.GetAuthorizationToken" || !strings.Contains(signedFor[0], "/eu-west-1/ecr/aws4_request") {
+		t.Errorf("Unexpected ECR request: %s %s", targets[0], signedFor[0])
+	}
+
+	public, err := GetECRPublicLogin(eksTestCreds)
+	if err != nil {
+		t.Fatalf("GetECRPublicLogin failed: %v", err)
+	}
+	if public.Registry != ECRPublicRegistry || public.Password != "secret-password" {
+		t.Errorf("Unexpected public login: %+v", public)
+	}
+	if !strings.Contains(signedFor[1], "/us-east-1/ecr-public/aws4_request") {
+		t.Errorf("Expected ECR Public to be signed for us-east-1, got %s", signedFor[1])
+	}
+
+	_, err = GetECRLogins(eksTestCreds, "eu-west-1", []string{"999999999999"})
+	if err == nil || !strings.Contains(err.Error(), "InvalidParameterException: Invalid registry") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
+
+func TestParseECRAuthorizationRejectsMalformedTokens(t *testing.T) {
+	if _, err := parseECRAuthorization(ecrAuthorizationData{AuthorizationToken: "not base64!"}); err == nil {
+		t.Error("Expected an error for a token that isn't base64")
+	}
+	if _, err := parseECRAuthorization(ecrAuthorizationData{AuthorizationToken: base64.StdEncoding.EncodeToString([]byte("nocolon"))}); err == nil {
+		t.Error("Expected an error for a token without a password")
+	}
+}
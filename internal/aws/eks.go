@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// eksTokenPrefix starts the bearer tokens the EKS authenticator accepts.
+const eksTokenPrefix = "k8s-aws-v1."
+
+// eksTokenLifetime is how long EKS accepts a token. kubectl asks for a new
+// one shortly before, so the expiry reported to it is a little earlier.
+const eksTokenLifetime = 15 * time.Minute
+
+// EKSCluster is what a kubeconfig needs to reach an EKS cluster.
+type EKSCluster struct {
+	Name     string
+	Arn      string
+	Endpoint string
+	// CertificateAuthority is the base64 encoded CA bundle of the API server.
+	CertificateAuthority string
+	Status               string
+}
+
+// EKSToken is a bearer token for the Kubernetes API server of an EKS cluster.
+type EKSToken struct {
+	Token      string
+	Expiration time.Time
+}
+
+// eksEndpoint returns the EKS API endpoint of a region; tests point it elsewhere.
+var eksEndpoint = func(region string) string {
+	return fmt.Sprintf("https://eks.%s.%s", region, dnsSuffix(region))
+}
+
+// ListEKSClusters returns the names of the EKS clusters the credentials can see in region.
+func ListEKSClusters(creds *TempCredentials, region string) ([]string, error) {
+	var clusters []string
+	nextToken := ""
+	for {
+		query := url.Values{"maxResults": {"100"}}
+		if nextToken != "" {
+			query.Set("nextToken", nextToken)
+		}
+		var page struct {
+			Clusters  []string `json:"clusters"`
+			NextToken string   `json:"nextToken"`
+		}
+		if err := eksRequest(creds, region, "/clusters?"+query.Encode(), &page); err != nil {
+			return nil, fmt.Errorf("failed to list EKS clusters: %w", err)
+		}
+		clusters = append(clusters, page.Clusters...)
+		if page.NextToken == "" {
+			break
+		}
+		nextToken = page.NextToken
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
+
+// DescribeEKSCluster looks up the endpoint and CA of an EKS cluster.
+func DescribeEKSCluster(creds *TempCredentials, region, name string) (*EKSCluster, error) {
+	var out struct {
+		Cluster struct {
+			Name                 string `json:"name"`
+			Arn                  string `json:"arn"`
+			Endpoint             string `json:"endpoint"`
+			Status               string `json:"status"`
+			CertificateAuthority struct {
+				Data string `json:"data"`
+			} `json:"certificateAuthority"`
+		} `json:"cluster"`
+	}
+	if err := eksRequest(creds, region, "/clusters/"+url.PathEscape(name), &out); err != nil {
+		return nil, fmt.Errorf("failed to describe EKS cluster '%s': %w", name, err)
+	}
+	return &EKSCluster{
+		Name:                 out.Cluster.Name,
+		Arn:                  out.Cluster.Arn,
+		Endpoint:             out.Cluster.Endpoint,
+		CertificateAuthority: out.Cluster.CertificateAuthority.Data,
+		Status:               out.Cluster.Status,
+	}, nil
+}
+
+// eksRequest sends a signed GET to the EKS API and decodes the JSON answer into out.
+func eksRequest(creds *TempCredentials, region, path string, out any) error {
+	data, err := apiRequest(creds, http.MethodGet, eksEndpoint(region)+path, "eks", region, nil, nil)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse EKS response: %w", err)
+	}
+	return nil
+}
+
+// GetEKSToken creates a bearer token for an EKS cluster from credentials, as
+// 'aws eks get-token' does: a presigned STS GetCallerIdentity request naming
+// the cluster, which EKS verifies.
+func GetEKSToken(creds *TempCredentials, region, clusterName string) (*EKSToken, error) {
+	client := sts.New(sts.Options{
+		Region:      region,
+		Credentials: credentials.NewStaticCredentialsProvider(creds.AccessKeyId, creds.SecretAccessKey, creds.SessionToken),
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	presigned, err := sts.NewPresignClient(client).PresignGetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(o *sts.PresignOptions) {
+		o.ClientOptions = append(o.ClientOptions, func(opts *sts.Options) {
+			opts.APIOptions = append(opts.APIOptions,
+				smithyhttp.SetHeaderValue("x-k8s-aws-id", clusterName),
+				smithyhttp.SetHeaderValue("X-Amz-Expires", "60"))
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign token request: %w", err)
+	}
+
+	expiration := time.Now().Add(eksTokenLifetime - time.Minute)
+	if !creds.Expires.IsZero() && creds.Expires.Before(expiration) {
+		expiration = creds.Expires
+	}
+	return &EKSToken{
+		Token:      eksTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presigned.URL)),
+		Expiration: expiration.UTC(),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var eksTestCreds = &TempCredentials{AccessKeyId: "ASIAEKS", SecretAccessKey: "secret", SessionToken: "token", Expires: time.Now().Add(time.Hour)}
+
+func TestGetEKSToken(t *testing.T) {
+	token, err := GetEKSToken(eksTestCreds, "eu-west-1", "prod")
+	if err != nil {
+		t.Fatalf("GetEKSToken failed: %v", err)
+	}
+	if !strings.HasPrefix(token.Token, eksTokenPrefix) {
+		t.Fatalf("Expected the token to start with %s, got %s", eksTokenPrefix, token.Token)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token.Token, eksTokenPrefix))
+	if err != nil {
+		t.Fatalf("Token is not unpadded base64url: %v", err)
+	}
+	presigned, err := url.Parse(string(raw))
+	if err != nil {
+		t.Fatalf("Token does not hold a URL: %v", err)
+	}
+	query := presigned.Query()
+	if presigned.Host != "sts.eu-west-1.amazonaws.com" || query.Get("Action") != "GetCallerIdentity" {
+		t.Errorf("Expected a regional GetCallerIdentity URL, got %s", presigned)
+	}
+	if !slices.Contains(strings.Split(query.Get("X-Amz-SignedHeaders"), ";"), "x-k8s-aws-id") {
+		t.Errorf("Expected the cluster header to be signed, got %s", query.Get("X-Amz-SignedHeaders"))
+	}
+	if query.Get("X-Amz-Expires") != "60" || query.Get("X-Amz-Security-Token") != "token" {
+		t.Errorf("Unexpected presign parameters: %v", query)
+	}
+	if until := time.Until(token.Expiration); until <= 0 || until > eksTokenLifetime {
+		t.Errorf("Expected the token to expire within %v, got %v", eksTokenLifetime, until)
+	}
+
+	// The token can't outlive the credentials it was made from
+	short := *eksTestCreds
+	short.Expires = time.Now().Add(5 * time.Minute)
+	token, err = GetEKSToken(&short, "eu-west-1", "prod")
+	if err != nil {
+		t.Fatalf("GetEKSToken failed: %v", err)
+	}
+	if !token.Expiration.Equal(short.Expires.UTC()) {
+		t.Errorf("Expected the token to expire with the credentials at %v, got %v", short.Expires, token.Expiration)
+	}
+}
+
+func TestEKSClusters(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		switch {
+		case r.URL.Path == "/clusters" && r.URL.Query().Get("nextToken") == "":
+			w.Write([]byte(`{"clusters":["staging","prod"],"nextToken":"page2"}`))
+		case r.URL.Path == "/clusters":
+			w.Write([]byte(`{"clusters":["dev"]}`))
+		case r.URL.Path == "/clusters/prod":
+			w.Write([]byte(`{"cluster":{"name":"prod","arn":"arn:aws:eks:eu-west-1:111111111111:cluster/prod","endpoint":"https://ABC.gr7.eu-west-1.eks.amazonaws.com","status":"ACTIVE","certificateAuthority":{"data":"Q0E="}}}`))
+		default:
+			w.Header().Set("X-Amzn-Errortype", "ResourceNotFoundException")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"No cluster found for name: missing."}`))
+		}
+	}))
+	defer server.Close()
+	old := eksEndpoint
+	t.Cleanup(func() { eksEndpoint = old })
+	eksEndpoint = func(string) string { return server.URL }
+
+	clusters, err := ListEKSClusters(eksTestCreds, "eu-west-1")
+	if err != nil {
+		t.Fatalf("ListEKSClusters failed: %v", err)
+	}
+	if want := []string{"dev", "prod", "staging"}; !slices.Equal(clusters, want) {
+		t.Errorf("Expected %v across pages, got %v", want, clusters)
+	}
+	auth := requests[0].Header.Get("Authorization")
+	if !strings.Contains(auth, "Credential=ASIAEKS/") || !strings.Contains(auth, "/eu-west-1/eks/aws4_request") {
+		t.Errorf("Expected a SigV4 signature for eks, got %q", auth)
+	}
+	if requests[0].Header.Get("X-Amz-Security-Token") != "token" {
+		t.Error("Expected the session token to be sent")
+	}
+
+	cluster, err := DescribeEKSCluster(eksTestCreds, "eu-west-1", "prod")
+	if err != nil {
+		t.Fatalf("DescribeEKSCluster failed: %v", err)
+	}
+	if cluster.Arn != "arn:aws:eks:eu-west-1:111111111111:cluster/prod" || cluster.CertificateAuthority != "Q0E=" || cluster.Endpoint == "" {
+		t.Errorf("Unexpected cluster: %+v", cluster)
+	}
+
+	_, err = DescribeEKSCluster(eksTestCreds, "eu-west-1", "missing")
+	if err == nil || !strings.Contains(err.Error(), "ResourceNotFoundException: No cluster found") {
+		t.Errorf("Expected the API error to be reported, got %v", err)
+	}
+}
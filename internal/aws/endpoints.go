@@ -0,0 +1,66 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Endpoint is an AWS endpoint awsm has to reach to work.
+type Endpoint struct {
+	Name string
+	URL  string
+}
+
+// dnsSuffix returns the DNS suffix of the partition of region, e.g.
+// amazonaws.com.cn for the China regions.
+func dnsSuffix(region string) string {
+	if p := GetMetadata().PartitionForRegion(region); p != nil && p.DNSSuffix != "" {
+		return p.DNSSuffix
+	}
+	return "amazonaws.com"
+}
+
+// RequiredEndpoints returns the endpoints awsm calls for credentials: STS,
+// globally and in region, and the OIDC and portal endpoints of every SSO
+// session's region. An empty region leaves the regional STS endpoint out.
+func RequiredEndpoints(region string, sessions []SSOSessionInfo) []Endpoint {
+	endpoints := []Endpoint{{Name: "STS", URL: "https://sts.amazonaws.com"}}
+	if region != "" {
+		endpoints = append(endpoints, Endpoint{Name: "STS " + region, URL: fmt.Sprintf("https://sts.%s.%s", region, dnsSuffix(region))})
+	}
+
+	seen := make(map[string]bool)
+	var ssoRegions []string
+	for _, s := range sessions {
+		if s.Region != "" && !seen[s.Region] {
+			seen[s.Region] = true
+			ssoRegions = append(ssoRegions, s.Region)
+		}
+	}
+	sort.Strings(ssoRegions)
+	for _, r := range ssoRegions {
+		endpoints = append(endpoints,
+			Endpoint{Name: "SSO OIDC " + r, URL: fmt.Sprintf("https://oidc.%s.%s", r, dnsSuffix(r))},
+			Endpoint{Name: "SSO portal " + r, URL: fmt.Sprintf("https://portal.sso.%s.%s", r, dnsSuffix(r))},
+		)
+	}
+	return endpoints
+}
+
+// ProbeEndpoint checks that an endpoint answers over HTTPS. Any HTTP answer
+// counts, error statuses included: only DNS, connection and TLS failures mean
+// it can't be reached.
+func ProbeEndpoint(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
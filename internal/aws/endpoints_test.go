@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequiredEndpoints(t *testing.T) {
+	endpoints := RequiredEndpoints("cn-north-1", []SSOSessionInfo{
+		{Name: "corp", Region: "eu-west-1"},
+		{Name: "corp-eu", Region: "eu-west-1"},
+		{Name: "legacy"},
+	})
+	want := []Endpoint{
+		{Name: "STS", URL: "https://sts.amazonaws.com"},
+		{Name: "STS cn-north-1", URL: "https://sts.cn-north-1.amazonaws.com.cn"},
+		{Name: "SSO OIDC eu-west-1", URL: "https://oidc.eu-west-1.amazonaws.com"},
+		{Name: "SSO portal eu-west-1", URL: "https://portal.sso.eu-west-1.amazonaws.com"},
+	}
+	if len(endpoints) != len(want) {
+		t.Fatalf("RequiredEndpoints returned %v, want %v", endpoints, want)
+	}
+	for i := range want {
+		if endpoints[i] != want[i] {
+			t.Errorf("endpoint %d = %v, want %v", i, endpoints[i], want[i])
+		}
+	}
+
+	if endpoints := RequiredEndpoints("", nil); len(endpoints) != 1 {
+		t.Errorf("without region and sessions only global STS should be probed, got %v", endpoints)
+	}
+}
+
+func TestProbeEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	if err := ProbeEndpoint(context.Background(), server.URL); err != nil {
+		t.Errorf("an endpoint answering 403 is reachable, got %v", err)
+	}
+	server.Close()
+	if err := ProbeEndpoint(context.Background(), server.URL); err == nil {
+		t.Error("expected an error for a closed endpoint")
+	}
+}
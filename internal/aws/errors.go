@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+
+	"awsm/internal/policy"
+)
+
+// ProfileError is a failure while using a profile, annotated with the account
+// and role the profile points at so multi-account users can tell at a glance
+// which account it relates to.
+type ProfileError struct {
+	// Op describes what failed, e.g. "assume role" or "describe instances".
+	Op        string
+	Profile   string
+	AccountID string
+	RoleName  string
+	Err       error
+}
+
+func (e *ProfileError) Error() string {
+	msg := "failed to " + e.Op
+	if e.AccountID != "" {
+		msg += " in account " + e.AccountID
+	}
+	context := "profile " + e.Profile
+	if e.RoleName != "" {
+		context += ", role " + e.RoleName
+	}
+	return fmt.Sprintf("%s (%s): %v", msg, context, e.Err)
+}
+
+func (e *ProfileError) Unwrap() error {
+	return e.Err
+}
+
+// WrapProfileError annotates err with the account and role of a profile. Errors
+// that already carry profile context are returned unchanged, so the innermost,
+// most precise context wins when calls are nested.
+func WrapProfileError(op, profileName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var profileErr *ProfileError
+	if errors.As(err, &profileErr) {
+		return err
+	}
+	accountID, roleName := profileAccountContext(profileName)
+	return &ProfileError{Op: op, Profile: profileName, AccountID: accountID, RoleName: roleName, Err: err}
+}
+
+// profileAccountContext returns the account and role a profile uses, from
+// role_arn or the SSO account settings. Both are empty when unknown.
+func profileAccountContext(profileName string) (string, string) {
+	cfg, err := loadMergedConfig()
+	if err != nil {
+		return "", ""
+	}
+	section, err := getProfileSection(cfg, profileName)
+	if err != nil {
+		return "", ""
+	}
+	if roleArn := section.Key("role_arn").String(); roleArn != "" {
+		return policy.AccountIDFromARN(roleArn), policy.RoleNameFromARN(roleArn)
+	}
+	return section.Key("sso_account_id").String(), section.Key("sso_role_name").String()
+}
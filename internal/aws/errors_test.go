@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrapProfileError(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	config := "[profile prod-admin]\nrole_arn = arn:aws:iam::123456789012:role/Admin\nsource_profile = base\n\n" +
+		"[profile dev]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = ReadOnly\n\n[profile base]\nregion = eu-west-1\n"
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cause := errors.New("AccessDenied")
+	tests := []struct {
+		op       string
+		profile  string
+		expected string
+	}{
+		{"assume role", "prod-admin", "failed to assume role in account 123456789012 (profile prod-admin, role Admin): AccessDenied"},
+		{"describe instances", "dev", "failed to describe instances in account 111111111111 (profile dev, role ReadOnly): AccessDenied"},
+		{"get session token", "base", "failed to get session token (profile base): AccessDenied"},
+		{"get credentials", "missing", "failed to get credentials (profile missing): AccessDenied"},
+	}
+	for _, tt := range tests {
+		err := WrapProfileError(tt.op, tt.profile, cause)
+		if err.Error() != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, err.Error())
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("Expected %q to wrap the cause", err)
+		}
+	}
+
+	inner := WrapProfileError("assume role", "prod-admin", cause)
+	outer := WrapProfileError("retrieve credentials", "prod-admin", fmt.Errorf("wrapped: %w", inner))
+	if outer.Error() != "wrapped: "+inner.Error() {
+		t.Errorf("Expected nested profile context to be kept once, got %q", outer)
+	}
+	if WrapProfileError("assume role", "prod-admin", nil) != nil {
+		t.Error("Expected nil for a nil error")
+	}
+}
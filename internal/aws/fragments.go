@@ -0,0 +1,289 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"gopkg.in/ini.v1"
+)
+
+// GeneratedFragmentName is the fragment awsm writes generated SSO profiles to
+// when the config fragments directory exists.
+const GeneratedFragmentName = "awsm-generated.conf"
+
+// ConfigFragmentsDir returns the directory of config fragments, config.d next to the AWS config file.
+func ConfigFragmentsDir() (string, error) {
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "config.d"), nil
+}
+
+// ConfigFragmentsEnabled reports whether the config fragments directory exists.
+func ConfigFragmentsEnabled() bool {
+	dir, err := ConfigFragmentsDir()
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(dir)
+	return err == nil && info.IsDir()
+}
+
+// ListConfigFragments returns the *.conf files of the fragments directory in the
+// order they are merged. A missing directory yields no fragments.
+func ListConfigFragments() ([]string, error) {
+	dir, err := ConfigFragmentsDir()
+	if err != nil {
+		return nil, err
+	}
+	fragments, err := filepath.Glob(filepath.Join(dir, "*.conf"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list config fragments: %w", err)
+	}
+	sort.Strings(fragments)
+	return fragments, nil
+}
+
+// GeneratedProfilesPath returns the file 'awsm sso generate' writes to: the
+// dedicated fragment when fragments are enabled, the AWS config file otherwise.
+func GeneratedProfilesPath() (string, error) {
+	if ConfigFragmentsEnabled() {
+		dir, err := ConfigFragmentsDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, GeneratedFragmentName), nil
+	}
+	return GetAWSConfigPath()
+}
+
+// configFiles returns the AWS config file followed by its fragments.
+func configFiles() ([]string, error) {
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	fragments, err := ListConfigFragments()
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{configPath}, fragments...), nil
+}
+
+// loadMergedConfig loads the AWS config file merged with its fragments, for reading only.
+// Sections defined in several files are merged, with later files winning.
+func loadMergedConfig() (*ini.File, error) {
+	files, err := configFiles()
+	if err != nil {
+		return nil, err
+	}
+	var sources []any
+	for _, file := range files {
+		if _, err := os.Stat(file); err == nil {
+			sources = append(sources, file)
+		}
+	}
+	if len(sources) == 0 {
+		// Let ini report the missing config file like a plain load would
+		return loadIni(files[0])
+	}
+	return loadIni(sources[0], sources[1:]...)
+}
+
+// loadConfigFileDefining loads the config file that defines one of the given
+// sections, searching fragments first since they win when merged. When no file
+// defines them it loads the AWS config file, so callers can create the section there.
+func loadConfigFileDefining(sectionNames ...string) (string, *ini.File, error) {
+	files, err := configFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	for i := len(files) - 1; i > 0; i-- {
+		if _, err := os.Stat(files[i]); err != nil {
+			continue
+		}
+		cfg, err := loadIni(files[i])
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to load %s: %w", files[i], err)
+		}
+		for _, name := range sectionNames {
+			if cfg.HasSection(name) {
+				return files[i], cfg, nil
+			}
+		}
+	}
+	cfg, err := loadIni(files[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load config file: %w", err)
+	}
+	return files[0], cfg, nil
+}
+
+// updateConfigFiles calls update for the AWS config file and every fragment
+// that exists, and saves the files it reports as changed.
+func updateConfigFiles(update func(path string, cfg *ini.File) (bool, error)) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	files, err := configFiles()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			continue
+		}
+		cfg, err := loadIni(file)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", file, err)
+		}
+		changed, err := update(file, cfg)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		if err := saveIniAtomic(cfg, file); err != nil {
+			return fmt.Errorf("failed to save %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+// withConfigFragments makes the SDK read the config fragments together with the AWS config file.
+func withConfigFragments() config.LoadOptionsFunc {
+	return func(o *config.LoadOptions) error {
+		files, err := configFiles()
+		if err != nil || len(files) == 1 {
+			return nil
+		}
+		o.SharedConfigFiles = files
+		return nil
+	}
+}
+
+var fragmentSectionRegex = regexp.MustCompile(`(?m)^\[([^\]]+)\]`)
+
+// MaterializeConfig flattens the AWS config file and its fragments into a
+// single config for tools that only read one file. A section redefined in a
+// fragment is merged the way the SDK merges it: keys from the later file win,
+// and keys only set in the earlier file are carried over.
+func MaterializeConfig() (string, error) {
+	files, err := configFiles()
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		content := strings.TrimRight(string(data), "\n")
+
+		for _, match := range fragmentSectionRegex.FindAllStringSubmatch(content, -1) {
+			// Walk back from the latest file so the nearest definition of a key wins
+			var inherited []string
+			for j := len(parts) - 1; j >= 0; j-- {
+				var body string
+				parts[j], body = removeConfigSection(parts[j], match[1])
+				inherited = append(inherited, configSectionLines(body)...)
+			}
+			content = mergeConfigSection(content, match[1], inherited)
+		}
+		if i > 0 {
+			content = fmt.Sprintf("# Materialized from %s\n%s", file, content)
+		}
+		parts = append(parts, content)
+	}
+
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.Trim(part, "\n"); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return "", nil
+	}
+	return strings.Join(nonEmpty, "\n\n") + "\n", nil
+}
+
+// findConfigSection returns the byte range of a section of any kind, from its
+// header up to the next section header, and the offset where its body starts.
+func findConfigSection(content, header string) (start, body, end int, ok bool) {
+	headerRegex := regexp.MustCompile(`(?m)^\[` + regexp.QuoteMeta(header) + `\][^\n]*\n?`)
+	match := headerRegex.FindStringIndex(content)
+	if match == nil {
+		return 0, 0, 0, false
+	}
+	end = len(content)
+	if next := fragmentSectionRegex.FindStringIndex(content[match[1]:]); next != nil {
+		end = match[1] + next[0]
+	}
+	return match[0], match[1], end, true
+}
+
+// removeConfigSection removes a section and returns the remaining content and the removed section body.
+func removeConfigSection(content, header string) (string, string) {
+	start, body, end, ok := findConfigSection(content, header)
+	if !ok {
+		return content, ""
+	}
+	return content[:start] + content[end:], content[body:end]
+}
+
+// configSectionLines returns the key lines of a section body, without comments and blank lines.
+func configSectionLines(body string) []string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+	return lines
+}
+
+// mergeConfigSection adds the inherited key lines whose key the section doesn't set itself.
+func mergeConfigSection(content, header string, inherited []string) string {
+	_, body, end, ok := findConfigSection(content, header)
+	if !ok || len(inherited) == 0 {
+		return content
+	}
+	own := make(map[string]bool)
+	for _, line := range configSectionLines(content[body:end]) {
+		key, _, _ := strings.Cut(line, "=")
+		own[strings.TrimSpace(key)] = true
+	}
+	var missing []string
+	for _, line := range inherited {
+		key, _, _ := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); !own[key] {
+			own[key] = true
+			missing = append(missing, line+"\n")
+		}
+	}
+	if len(missing) == 0 {
+		return content
+	}
+	if body > 0 && content[body-1] != '\n' {
+		missing[0] = "\n" + missing[0]
+	}
+	return content[:body] + strings.Join(missing, "") + content[body:]
+}
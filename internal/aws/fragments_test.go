@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConfigFragments(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	base := "[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = us-east-1\n\n[profile dev]\nregion = us-east-1\noutput = json\n\n[profile keep]\nregion = eu-west-1\n"
+	if err := os.WriteFile(configPath, []byte(base), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := GeneratedProfilesPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated != configPath {
+		t.Errorf("Expected generated profiles in %s without config.d, got %s", configPath, generated)
+	}
+
+	fragmentsDir := filepath.Join(dir, "config.d")
+	if err := os.MkdirAll(fragmentsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	fragment := "[profile dev]\nsso_session = corp\nsso_account_id = 123456789012\nsso_role_name = Admin\nregion = eu-west-1\n"
+	if err := os.WriteFile(filepath.Join(fragmentsDir, GeneratedFragmentName), []byte(fragment), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, _ = GeneratedProfilesPath()
+	if generated != filepath.Join(fragmentsDir, GeneratedFragmentName) {
+		t.Errorf("Expected generated profiles in the fragment, got %s", generated)
+	}
+
+	session, err := GetSsoSessionForProfile("dev")
+	if err != nil || session != "corp" {
+		t.Errorf("Expected profile from fragment to use session corp, got %q (%v)", session, err)
+	}
+	if region, _ := GetProfileRegion("dev"); region != "eu-west-1" {
+		t.Errorf("Expected fragment to override region, got %s", region)
+	}
+
+	content, err := MaterializeConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(content, "[profile dev]") != 1 {
+		t.Errorf("Expected a single [profile dev] section, got:\n%s", content)
+	}
+	if !strings.Contains(content, "[profile keep]\nregion = eu-west-1") || !strings.Contains(content, "sso_account_id = 123456789012") {
+		t.Errorf("Materialized config is missing sections:\n%s", content)
+	}
+	if strings.Contains(content, "\nregion = us-east-1") || !strings.Contains(content, "[profile dev]\noutput = json\nsso_session = corp") {
+		t.Errorf("Expected the base [profile dev] to be merged into the fragment's:\n%s", content)
+	}
+}
+
+func TestConfigMutatorsInFragments(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	base := "[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = eu-west-1\n\n[profile manual]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = ReadOnly\nregion = eu-west-1\n"
+	if err := os.WriteFile(configPath, []byte(base), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fragmentsDir := filepath.Join(dir, "config.d")
+	if err := os.MkdirAll(fragmentsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	fragmentPath := filepath.Join(fragmentsDir, "team.conf")
+	fragment := "[profile dev]\n# Managed by awsm sso generate\nsso_role_name = Admin\nsso_account_id = 222222222222\nsso_session = corp\n\n[profile gone]\nregion = us-east-1\n"
+	if err := os.WriteFile(fragmentPath, []byte(fragment), 0600); err != nil {
+		t.Fatal(err)
+	}
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if err := ChangeProfileRegion("dev", "us-west-2"); err != nil {
+		t.Fatalf("ChangeProfileRegion failed: %v", err)
+	}
+	if err := UpdateProfileRegion("manual", "eu-central-1"); err != nil {
+		t.Fatalf("UpdateProfileRegion failed: %v", err)
+	}
+	if strings.Contains(read(configPath), "[profile dev]") || !strings.Contains(read(fragmentPath), "region         = us-west-2") {
+		t.Errorf("Expected the region of dev to change in its fragment:\n%s\n%s", read(configPath), read(fragmentPath))
+	}
+	if !strings.Contains(read(configPath), "eu-central-1") {
+		t.Errorf("Expected the region of manual to change in the config file:\n%s", read(configPath))
+	}
+
+	upgraded, err := UpgradeGeneratedProfiles("corp")
+	if err != nil {
+		t.Fatalf("UpgradeGeneratedProfiles failed: %v", err)
+	}
+	if !slices.Contains(upgraded, "dev") {
+		t.Errorf("Expected dev in the fragment to be upgraded, got %v", upgraded)
+	}
+
+	updated, err := RenameSSOSession("corp", "company")
+	if err != nil {
+		t.Fatalf("RenameSSOSession failed: %v", err)
+	}
+	if updated != 2 || strings.Contains(read(fragmentPath), "= corp") || !strings.Contains(read(configPath), "[sso-session company]") {
+		t.Errorf("Expected profiles in both files to follow the rename, got %d:\n%s\n%s", updated, read(configPath), read(fragmentPath))
+	}
+
+	if err := DeleteProfile("gone"); err != nil {
+		t.Fatalf("DeleteProfile failed: %v", err)
+	}
+	if strings.Contains(read(fragmentPath), "[profile gone]") {
+		t.Errorf("Expected gone to be deleted from its fragment:\n%s", read(fragmentPath))
+	}
+
+	if err := os.WriteFile(filepath.Join(fragmentsDir, "sessions.conf"), []byte("[sso-session extra]\nsso_region = us-east-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := DeleteSSOSession("extra"); err != nil {
+		t.Fatalf("DeleteSSOSession failed: %v", err)
+	}
+	if strings.Contains(read(filepath.Join(fragmentsDir, "sessions.conf")), "extra") {
+		t.Error("Expected the session to be deleted from its fragment")
+	}
+}
@@ -0,0 +1,219 @@
+package aws
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// GraphNodeKind tells what a node of an AccessGraph stands for.
+type GraphNodeKind string
+
+const (
+	GraphNodeSSOSession GraphNodeKind = "sso-session"
+	GraphNodeProfile    GraphNodeKind = "profile"
+	GraphNodeAccount    GraphNodeKind = "account"
+	// GraphNodeMissing is a source_profile that no profile defines.
+	GraphNodeMissing GraphNodeKind = "missing"
+)
+
+// GraphNode is an SSO session, profile or account of an AccessGraph.
+type GraphNode struct {
+	ID    string
+	Kind  GraphNodeKind
+	Label string
+}
+
+// GraphEdge says that From gives access to To, e.g. a source profile whose
+// credentials assume the role of a profile. Label names the role or mechanism.
+type GraphEdge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// AccessGraph shows which accounts can be reached from which SSO sessions and
+// profiles, following sso_session, source_profile and role_arn.
+type AccessGraph struct {
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// BuildAccessGraph links SSO sessions to their profiles, source profiles to
+// the role profiles using them and profiles to the accounts their
+// credentials belong to. Nodes and edges are sorted, so the output is stable.
+func BuildAccessGraph(profiles []ProfileInfo, sessions []SSOSessionInfo) *AccessGraph {
+	g := &AccessGraph{}
+	nodes := make(map[string]GraphNode)
+	addNode := func(id string, kind GraphNodeKind, label string) {
+		if _, ok := nodes[id]; !ok {
+			nodes[id] = GraphNode{ID: id, Kind: kind, Label: label}
+		}
+	}
+
+	for _, s := range sessions {
+		addNode("sso-session:"+s.Name, GraphNodeSSOSession, "sso-session "+s.Name)
+	}
+	defined := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		defined[p.Name] = true
+	}
+
+	for _, p := range profiles {
+		profileID := "profile:" + p.Name
+		addNode(profileID, GraphNodeProfile, p.Name)
+
+		switch {
+		case p.SSOSession != "":
+			addNode("sso-session:"+p.SSOSession, GraphNodeSSOSession, "sso-session "+p.SSOSession)
+			g.Edges = append(g.Edges, GraphEdge{From: "sso-session:" + p.SSOSession, To: profileID, Label: "sso"})
+		case p.SSOStartURL != "":
+			// Legacy SSO profiles carry the start URL themselves
+			addNode("sso-url:"+p.SSOStartURL, GraphNodeSSOSession, p.SSOStartURL)
+			g.Edges = append(g.Edges, GraphEdge{From: "sso-url:" + p.SSOStartURL, To: profileID, Label: "sso"})
+		}
+
+		if p.SourceProfile != "" && p.SourceProfile != p.Name {
+			sourceID := "profile:" + p.SourceProfile
+			if !defined[p.SourceProfile] {
+				addNode(sourceID, GraphNodeMissing, p.SourceProfile+" (missing)")
+			}
+			g.Edges = append(g.Edges, GraphEdge{From: sourceID, To: profileID, Label: "source_profile"})
+		}
+
+		if account := p.AccountID(); account != "" {
+			addNode("account:"+account, GraphNodeAccount, account)
+			g.Edges = append(g.Edges, GraphEdge{From: profileID, To: "account:" + account, Label: p.RoleName()})
+		}
+	}
+
+	for _, n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	slices.SortFunc(g.Nodes, func(a, b GraphNode) int { return strings.Compare(a.ID, b.ID) })
+	slices.SortFunc(g.Edges, func(a, b GraphEdge) int {
+		if c := strings.Compare(a.From, b.From); c != 0 {
+			return c
+		}
+		return strings.Compare(a.To, b.To)
+	})
+	return g
+}
+
+// Reaching returns the part of the graph that leads to the given account: the
+// account, every profile and session with a path to it, and the edges between
+// them. It returns nil when no profile reaches the account.
+func (g *AccessGraph) Reaching(accountID string) *AccessGraph {
+	target := "account:" + accountID
+	incoming := make(map[string][]GraphEdge)
+	for _, e := range g.Edges {
+		incoming[e.To] = append(incoming[e.To], e)
+	}
+	if len(incoming[target]) == 0 {
+		return nil
+	}
+
+	keep := map[string]bool{target: true}
+	queue := []string{target}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, e := range incoming[current] {
+			if !keep[e.From] {
+				keep[e.From] = true
+				queue = append(queue, e.From)
+			}
+		}
+	}
+
+	sub := &AccessGraph{}
+	for _, n := range g.Nodes {
+		if keep[n.ID] {
+			sub.Nodes = append(sub.Nodes, n)
+		}
+	}
+	for _, e := range g.Edges {
+		// Edges out of kept profiles to other accounts are not part of the path
+		if keep[e.From] && keep[e.To] {
+			sub.Edges = append(sub.Edges, e)
+		}
+	}
+	return sub
+}
+
+// DOT renders the graph in the Graphviz DOT language.
+func (g *AccessGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph awsm {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, %s];\n", dotQuote(n.ID), dotQuote(n.Label), dotNodeStyle(n.Kind))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Label != "" {
+			fmt.Fprintf(&b, " [label=%s]", dotQuote(e.Label))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func dotNodeStyle(kind GraphNodeKind) string {
+	switch kind {
+	case GraphNodeSSOSession:
+		return "shape=ellipse, style=filled, fillcolor=\"#dbeafe\""
+	case GraphNodeAccount:
+		return "shape=box3d, style=filled, fillcolor=\"#fef3c7\""
+	case GraphNodeMissing:
+		return "shape=box, style=dashed, color=red"
+	default:
+		return "shape=box"
+	}
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// Mermaid renders the graph as a Mermaid flowchart, which GitHub and many
+// wikis display inline.
+func (g *AccessGraph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, n := range g.Nodes {
+		label := mermaidQuote(n.Label)
+		switch n.Kind {
+		case GraphNodeSSOSession:
+			fmt.Fprintf(&b, "  %s([%s])\n", ids[n.ID], label)
+		case GraphNodeAccount:
+			fmt.Fprintf(&b, "  %s[(%s)]\n", ids[n.ID], label)
+		default:
+			fmt.Fprintf(&b, "  %s[%s]\n", ids[n.ID], label)
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Label != "" {
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.From], mermaidQuote(e.Label), ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.Kind == GraphNodeMissing {
+			fmt.Fprintf(&b, "  style %s stroke:#f00,stroke-dasharray:4\n", ids[n.ID])
+		}
+	}
+	return b.String()
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func graphTestProfiles() ([]ProfileInfo, []SSOSessionInfo) {
+	profiles := []ProfileInfo{
+		{Name: "corp-admin", Type: ProfileTypeSSO, SSOSession: "corp", SSOAccountID: "111111111111", SSORoleName: "Admin"},
+		{Name: "prod", Type: ProfileTypeIAM, SourceProfile: "corp-admin", RoleARN: "arn:aws:iam::222222222222:role/Deploy"},
+		{Name: "audit", Type: ProfileTypeIAM, SourceProfile: "prod", RoleARN: "arn:aws:iam::333333333333:role/Audit"},
+		{Name: "orphan", Type: ProfileTypeIAM, SourceProfile: "gone", RoleARN: "arn:aws:iam::222222222222:role/ReadOnly"},
+		{Name: "keys", Type: ProfileTypeKey},
+	}
+	sessions := []SSOSessionInfo{{Name: "corp"}, {Name: "unused"}}
+	return profiles, sessions
+}
+
+func TestBuildAccessGraph(t *testing.T) {
+	g := BuildAccessGraph(graphTestProfiles())
+
+	kinds := make(map[string]GraphNodeKind)
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+	for id, kind := range map[string]GraphNodeKind{
+		"sso-session:corp":     GraphNodeSSOSession,
+		"sso-session:unused":   GraphNodeSSOSession,
+		"profile:keys":         GraphNodeProfile,
+		"profile:gone":         GraphNodeMissing,
+		"account:222222222222": GraphNodeAccount,
+	} {
+		if kinds[id] != kind {
+			t.Errorf("node %s: expected kind %s, got %q", id, kind, kinds[id])
+		}
+	}
+
+	edges := make(map[string]string)
+	for _, e := range g.Edges {
+		edges[e.From+" -> "+e.To] = e.Label
+	}
+	for edge, label := range map[string]string{
+		"sso-session:corp -> profile:corp-admin":     "sso",
+		"profile:corp-admin -> profile:prod":         "source_profile",
+		"profile:prod -> account:222222222222":       "Deploy",
+		"profile:gone -> profile:orphan":             "source_profile",
+		"profile:corp-admin -> account:111111111111": "Admin",
+	} {
+		if got, ok := edges[edge]; !ok || got != label {
+			t.Errorf("edge %s: expected label %q, got %q (present: %v)", edge, label, got, ok)
+		}
+	}
+}
+
+func TestAccessGraphReaching(t *testing.T) {
+	g := BuildAccessGraph(graphTestProfiles()).Reaching("333333333333")
+	if g == nil {
+		t.Fatal("expected a path into 333333333333")
+	}
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	want := "account:333333333333 profile:audit profile:corp-admin profile:prod sso-session:corp"
+	if got := strings.Join(ids, " "); got != want {
+		t.Errorf("nodes = %s, want %s", got, want)
+	}
+	for _, e := range g.Edges {
+		if e.To == "account:111111111111" || e.To == "account:222222222222" {
+			t.Errorf("edge to another account kept: %+v", e)
+		}
+	}
+
+	if BuildAccessGraph(graphTestProfiles()).Reaching("999999999999") != nil {
+		t.Error("expected no path into an unknown account")
+	}
+}
+
+func TestAccessGraphRendering(t *testing.T) {
+	g := BuildAccessGraph(graphTestProfiles())
+
+	dot := g.DOT()
+	for _, want := range []string{
+		"digraph awsm {",
+		`"profile:corp-admin" -> "profile:prod" [label="source_profile"];`,
+		`"profile:gone" [label="gone (missing)", shape=box, style=dashed, color=red];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output lacks %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid := g.Mermaid()
+	if !strings.HasPrefix(mermaid, "flowchart LR\n") {
+		t.Errorf("Mermaid output should start with a flowchart:\n%s", mermaid)
+	}
+	for _, want := range []string{`(["sso-session corp"])`, `[("222222222222")]`, `-->|"Deploy"|`, "stroke-dasharray"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("Mermaid output lacks %q:\n%s", want, mermaid)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Identity is the caller identity behind a set of credentials.
+type Identity struct {
+	Account      string
+	AccountAlias string
+	Arn          string
+	// Principal is the role name for assumed roles and the user name for IAM users.
+	Principal string
+}
+
+// GetIdentity asks STS who the credentials belong to and looks up the account
+// alias. Without temporary credentials the profile's own credentials are used.
+// The alias is best effort, as many roles may not call iam:ListAccountAliases.
+func GetIdentity(profileName string, creds *TempCredentials, region string) (*Identity, error) {
+	if region == "" {
+		region = "us-east-1"
+	}
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+	if creds != nil {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(creds.AccessKeyId, creds.SecretAccessKey, creds.SessionToken)))
+	} else {
+		opts = append(opts, config.WithSharedConfigProfile(profileName), withConfigFragments())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config for profile '%s': %w", profileName, err)
+	}
+
+	out, err := retryOnClockSkew(func(optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+		return sts.NewFromConfig(awsCfg, optFns...).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	})
+	if err != nil {
+		return nil, WrapProfileError("get caller identity", profileName, err)
+	}
+	identity := &Identity{
+		Account:   aws.ToString(out.Account),
+		Arn:       aws.ToString(out.Arn),
+		Principal: principalFromARN(aws.ToString(out.Arn)),
+	}
+
+	if aliases, err := iam.NewFromConfig(awsCfg).ListAccountAliases(ctx, &iam.ListAccountAliasesInput{}); err == nil && len(aliases.AccountAliases) > 0 {
+		identity.AccountAlias = aliases.AccountAliases[0]
+	}
+	return identity, nil
+}
+
+// principalFromARN extracts the role name from an assumed-role ARN
+// (arn:aws:sts::123456789012:assumed-role/Admin/session) or the user name from
+// an IAM user ARN, returning the ARN's resource part for anything else.
+func principalFromARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return arn
+	}
+	resource := parts[5]
+	switch {
+	case strings.HasPrefix(resource, "assumed-role/"):
+		role, _, _ := strings.Cut(strings.TrimPrefix(resource, "assumed-role/"), "/")
+		return role
+	case strings.HasPrefix(resource, "user/"):
+		return resource[strings.LastIndex(resource, "/")+1:]
+	}
+	return resource
+}
@@ -0,0 +1,21 @@
+package aws
+
+import "testing"
+
+func TestPrincipalFromARN(t *testing.T) {
+	tests := []struct {
+		arn      string
+		expected string
+	}{
+		{"arn:aws:sts::123456789012:assumed-role/AWSReservedSSO_Admin_abc/jane@example.com", "AWSReservedSSO_Admin_abc"},
+		{"arn:aws:sts::123456789012:assumed-role/Deploy/awsm-session", "Deploy"},
+		{"arn:aws:iam::123456789012:user/ops/jane", "jane"},
+		{"arn:aws:iam::123456789012:root", "root"},
+		{"not-an-arn", "not-an-arn"},
+	}
+	for _, tt := range tests {
+		if got := principalFromARN(tt.arn); got != tt.expected {
+			t.Errorf("principalFromARN(%q) = %q, expected %q", tt.arn, got, tt.expected)
+		}
+	}
+}
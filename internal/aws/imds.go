@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+const (
+	// imdsTimeout bounds every single request to the instance metadata service.
+	// IMDS answers in milliseconds on EC2, so anything slower means it is unreachable.
+	imdsTimeout = 2 * time.Second
+	// imdsProbeTimeout bounds the whole credential retrieval including retries.
+	imdsProbeTimeout = 5 * time.Second
+	// ecsCredentialsHost serves container credentials for AWS_CONTAINER_CREDENTIALS_RELATIVE_URI.
+	ecsCredentialsHost = "http://169.254.170.2"
+)
+
+// newIMDSv2Client returns an instance metadata client that only uses the
+// token-based (IMDSv2) protocol and gives up quickly when IMDS is not reachable.
+func newIMDSv2Client() *imds.Client {
+	return imds.New(imds.Options{
+		ClientEnableState: imds.ClientEnabled,
+		EnableFallback:    aws.FalseTernary,
+		HTTPClient:        awshttp.NewBuildableClient().WithTimeout(imdsTimeout),
+		Retryer:           retry.AddWithMaxAttempts(retry.NewStandard(), 2),
+	})
+}
+
+// loadCredentialSourceConfig builds an AWS config whose credentials come from the
+// profile's credential_source instead of another profile.
+func loadCredentialSourceConfig(credentialSource, region string) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{}
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	switch credentialSource {
+	case "Ec2InstanceMetadata":
+		client := newIMDSv2Client()
+		provider := aws.NewCredentialsCache(ec2rolecreds.New(func(o *ec2rolecreds.Options) {
+			o.Client = client
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), imdsProbeTimeout)
+		defer cancel()
+		if _, err := provider.Retrieve(ctx); err != nil {
+			return aws.Config{}, fmt.Errorf("could not get credentials from the EC2 instance metadata service (IMDSv2): %w\n\nMake sure awsm is running on an EC2 instance with an instance profile attached,\nand that the metadata hop limit allows token requests (e.g. 2 when running inside containers)", err)
+		}
+
+		opts = append(opts, config.WithCredentialsProvider(provider))
+		if region == "" {
+			opts = append(opts, config.WithEC2IMDSRegion(func(o *config.UseEC2IMDSRegion) {
+				o.Client = client
+			}))
+		}
+	case "Environment":
+		accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+		secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+		if accessKey == "" || secretKey == "" {
+			return aws.Config{}, fmt.Errorf("credential_source is Environment but AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY are not set")
+		}
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"))))
+	case "EcsContainer":
+		provider, err := containerCredentialsProvider()
+		if err != nil {
+			return aws.Config{}, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), imdsProbeTimeout)
+		defer cancel()
+		if _, err := provider.Retrieve(ctx); err != nil {
+			return aws.Config{}, fmt.Errorf("could not get credentials from the container credentials endpoint: %w", err)
+		}
+		opts = append(opts, config.WithCredentialsProvider(provider))
+	default:
+		return aws.Config{}, fmt.Errorf("unsupported credential_source '%s' (supported: Ec2InstanceMetadata, EcsContainer, Environment)", credentialSource)
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(context.TODO(), opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config for credential source '%s': %w", credentialSource, err)
+	}
+	return awsCfg, nil
+}
+
+// containerCredentialsProvider returns a provider for the credentials endpoint
+// that ECS, EKS Pod Identity and similar container runtimes expose through the
+// AWS_CONTAINER_CREDENTIALS_* environment variables.
+func containerCredentialsProvider() (aws.CredentialsProvider, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = ecsCredentialsHost + relative
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("credential_source is EcsContainer but neither AWS_CONTAINER_CREDENTIALS_RELATIVE_URI nor AWS_CONTAINER_CREDENTIALS_FULL_URI is set")
+	}
+
+	provider := endpointcreds.New(endpoint, func(o *endpointcreds.Options) {
+		o.HTTPClient = awshttp.NewBuildableClient().WithTimeout(imdsTimeout)
+		o.AuthorizationToken = os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+		if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+			// Read the file on every retrieval since the runtime rotates the token
+			o.AuthorizationTokenProvider = endpointcreds.TokenProviderFunc(func() (string, error) {
+				data, err := os.ReadFile(tokenFile)
+				if err != nil {
+					return "", fmt.Errorf("failed to read container authorization token: %w", err)
+				}
+				return strings.TrimSpace(string(data)), nil
+			})
+		}
+	})
+	return aws.NewCredentialsCache(provider), nil
+}
@@ -0,0 +1,88 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func isolateSDKConfig(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+}
+
+func TestLoadCredentialSourceConfigEnvironment(t *testing.T) {
+	isolateSDKConfig(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+
+	awsCfg, err := loadCredentialSourceConfig("Environment", "eu-west-1")
+	if err != nil {
+		t.Fatalf("loadCredentialSourceConfig failed: %v", err)
+	}
+	creds, err := awsCfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "AKIAENV" || creds.SessionToken != "token" || awsCfg.Region != "eu-west-1" {
+		t.Errorf("Unexpected config: %+v, region %s", creds, awsCfg.Region)
+	}
+
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	if _, err := loadCredentialSourceConfig("Environment", ""); err == nil {
+		t.Error("Expected an error without AWS_SECRET_ACCESS_KEY")
+	}
+}
+
+func TestLoadCredentialSourceConfigEcsContainer(t *testing.T) {
+	isolateSDKConfig(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("file-token\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "file-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		w.Write([]byte(`{"AccessKeyId":"ASIACONTAINER","SecretAccessKey":"secret","Token":"token","Expiration":"` + expiration + `"}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", "")
+	if _, err := loadCredentialSourceConfig("EcsContainer", "us-east-1"); err == nil || !strings.Contains(err.Error(), "AWS_CONTAINER_CREDENTIALS") {
+		t.Errorf("Expected an error naming the missing variables, got %v", err)
+	}
+
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", server.URL+"/creds")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE", tokenFile)
+	awsCfg, err := loadCredentialSourceConfig("EcsContainer", "us-east-1")
+	if err != nil {
+		t.Fatalf("loadCredentialSourceConfig failed: %v", err)
+	}
+	creds, err := awsCfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "ASIACONTAINER" {
+		t.Errorf("Expected container credentials, got %+v", creds)
+	}
+}
+
+func TestLoadCredentialSourceConfigUnsupported(t *testing.T) {
+	if _, err := loadCredentialSourceConfig("Magic", ""); err == nil || !strings.Contains(err.Error(), "unsupported credential_source") {
+		t.Errorf("Expected an unsupported credential_source error, got %v", err)
+	}
+}
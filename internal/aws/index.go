@@ -0,0 +1,149 @@
+package aws
+
+import (
+	"slices"
+	"sync"
+)
+
+// ProfileIndex maps SSO sessions and source profiles to the profiles that use
+// them, so commands can compute what a change affects without re-reading the
+// config for every profile.
+type ProfileIndex struct {
+	bySession  map[string][]string
+	dependents map[string][]string
+	sso        map[string]bool
+}
+
+var (
+	profileIndex      *ProfileIndex
+	profileIndexMutex sync.Mutex
+)
+
+// GetProfileIndex returns the index of the current config. It is built once and
+// reused until InvalidateProfileCache is called after a config change.
+func GetProfileIndex() (*ProfileIndex, error) {
+	profileIndexMutex.Lock()
+	defer profileIndexMutex.Unlock()
+	if profileIndex != nil {
+		return profileIndex, nil
+	}
+
+	profiles, err := ListProfilesDetailed()
+	if err != nil {
+		return nil, err
+	}
+	profileIndex = buildProfileIndex(profiles)
+	return profileIndex, nil
+}
+
+// invalidateProfileIndex drops the cached index.
+func invalidateProfileIndex() {
+	profileIndexMutex.Lock()
+	defer profileIndexMutex.Unlock()
+	profileIndex = nil
+}
+
+func buildProfileIndex(profiles []ProfileInfo) *ProfileIndex {
+	index := &ProfileIndex{
+		bySession:  make(map[string][]string),
+		dependents: make(map[string][]string),
+		sso:        make(map[string]bool),
+	}
+	for _, p := range profiles {
+		if p.Type == ProfileTypeSSO {
+			index.sso[p.Name] = true
+			if p.SSOSession != "" {
+				index.bySession[p.SSOSession] = append(index.bySession[p.SSOSession], p.Name)
+			}
+		}
+		if p.SourceProfile != "" && p.SourceProfile != p.Name {
+			index.dependents[p.SourceProfile] = append(index.dependents[p.SourceProfile], p.Name)
+		}
+	}
+	for _, names := range index.bySession {
+		slices.Sort(names)
+	}
+	for _, names := range index.dependents {
+		slices.Sort(names)
+	}
+	return index
+}
+
+// ProfilesBySSO returns the SSO profiles that reference the session directly.
+func (i *ProfileIndex) ProfilesBySSO(ssoSession string) []string {
+	return slices.Clone(i.bySession[ssoSession])
+}
+
+// Dependents returns the profiles that name profile as their source_profile.
+func (i *ProfileIndex) Dependents(profile string) []string {
+	return slices.Clone(i.dependents[profile])
+}
+
+// AllDependents returns every profile that chains through profile, directly
+// or via other role profiles, in breadth-first order.
+func (i *ProfileIndex) AllDependents(profile string) []string {
+	seen := map[string]bool{profile: true}
+	var result []string
+	queue := []string{profile}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range i.dependents[current] {
+			if seen[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			result = append(result, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+	return result
+}
+
+// ProfilesUsingSSO returns the SSO profiles of a session followed by every
+// profile whose credentials are derived from one of them through
+// source_profile. Chains stop at profiles with SSO settings of their own, as
+// those don't use the session's credentials.
+func (i *ProfileIndex) ProfilesUsingSSO(ssoSession string) []string {
+	profiles := i.ProfilesBySSO(ssoSession)
+	seen := make(map[string]bool)
+	for _, p := range profiles {
+		seen[p] = true
+	}
+	queue := slices.Clone(profiles)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dependent := range i.dependents[current] {
+			if seen[dependent] || i.sso[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			profiles = append(profiles, dependent)
+			queue = append(queue, dependent)
+		}
+	}
+	return profiles
+}
+
+// BrokenDependents returns the profiles outside removed whose source_profile
+// chain runs through one of the removed profiles, i.e. the profiles that stop
+// working once removed is deleted.
+func (i *ProfileIndex) BrokenDependents(removed []string) []string {
+	skip := make(map[string]bool)
+	for _, p := range removed {
+		skip[p] = true
+	}
+	seen := make(map[string]bool)
+	var result []string
+	for _, p := range removed {
+		for _, dependent := range i.AllDependents(p) {
+			if skip[dependent] || seen[dependent] {
+				continue
+			}
+			seen[dependent] = true
+			result = append(result, dependent)
+		}
+	}
+	return result
+}
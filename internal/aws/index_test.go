@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestProfileIndex(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	config := `[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-1
+
+[profile dev]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile prod]
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+
+[profile deploy]
+role_arn = arn:aws:iam::333333333333:role/Deploy
+source_profile = dev
+
+[profile deploy-audit]
+role_arn = arn:aws:iam::333333333333:role/Audit
+source_profile = deploy
+
+[profile other]
+sso_session = other
+sso_account_id = 444444444444
+sso_role_name = Admin
+source_profile = dev
+
+[profile static]
+region = eu-west-1
+`
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	InvalidateProfileCache()
+
+	index, err := GetProfileIndex()
+	if err != nil {
+		t.Fatalf("GetProfileIndex failed: %v", err)
+	}
+
+	if got := index.ProfilesBySSO("corp"); !slices.Equal(got, []string{"dev", "prod"}) {
+		t.Errorf("Expected corp profiles [dev prod], got %v", got)
+	}
+	if got := index.Dependents("dev"); !slices.Equal(got, []string{"deploy", "other"}) {
+		t.Errorf("Expected dependents [deploy other], got %v", got)
+	}
+	if got := index.AllDependents("dev"); !slices.Equal(got, []string{"deploy", "other", "deploy-audit"}) {
+		t.Errorf("Expected all dependents [deploy other deploy-audit], got %v", got)
+	}
+	if got := index.ProfilesUsingSSO("corp"); !slices.Equal(got, []string{"dev", "prod", "deploy", "deploy-audit"}) {
+		t.Errorf("Expected profiles using corp [dev prod deploy deploy-audit], got %v", got)
+	}
+	if got := index.BrokenDependents([]string{"dev", "deploy"}); !slices.Equal(got, []string{"other", "deploy-audit"}) {
+		t.Errorf("Expected broken dependents [other deploy-audit], got %v", got)
+	}
+	if got := index.BrokenDependents([]string{"static"}); len(got) != 0 {
+		t.Errorf("Expected no broken dependents, got %v", got)
+	}
+
+	if cached, _ := GetProfileIndex(); cached != index {
+		t.Error("Expected the index to be reused")
+	}
+	if err := DeleteProfile("prod"); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := GetProfilesBySSO("corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(profiles, []string{"dev"}) {
+		t.Errorf("Expected the index to be rebuilt after a delete, got %v", profiles)
+	}
+}
@@ -0,0 +1,236 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"awsm/internal/keychain"
+	"awsm/internal/policy"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	ini "gopkg.in/ini.v1"
+)
+
+// keychainMarker marks profiles whose static keys live in the OS keychain
+// instead of the credentials file.
+const keychainMarker = "awsm_keychain"
+
+// keychainKeys is how static keys are stored in the keychain.
+type keychainKeys struct {
+	AccessKeyId     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
+// staticKeysAccount is the keychain account holding a profile's static keys.
+func staticKeysAccount(profileName string) string {
+	return "static:" + profileName
+}
+
+// keychainCredentialProcess is the credential_process written for keychain
+// profiles, so the AWS CLI and SDKs get the keys through awsm.
+func keychainCredentialProcess(profileName string) string {
+	return "awsm credential-process " + profileName
+}
+
+// IsKeychainProfile reports whether a profile keeps its static keys in the OS keychain.
+func IsKeychainProfile(profileName string) bool {
+	cfg, err := loadMergedConfig()
+	if err != nil {
+		return false
+	}
+	section, err := getProfileSection(cfg, profileName)
+	return err == nil && section.Key(keychainMarker).MustBool(false)
+}
+
+// staticKeysFromKeychain reads a keychain profile's static keys.
+func staticKeysFromKeychain(profileName string) (*TempCredentials, error) {
+	secret, err := keychain.Get(staticKeysAccount(profileName))
+	if errors.Is(err, keychain.ErrNotFound) {
+		return nil, fmt.Errorf("the keys of profile '%s' are missing from the OS keychain; add them again with 'awsm profile add iam-user %s --keychain'", profileName, profileName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var keys keychainKeys
+	if err := json.Unmarshal([]byte(secret), &keys); err != nil {
+		return nil, fmt.Errorf("the keychain entry of profile '%s' is corrupt: %w", profileName, err)
+	}
+	return &TempCredentials{AccessKeyId: keys.AccessKeyId, SecretAccessKey: keys.SecretAccessKey}, nil
+}
+
+func storeStaticKeys(profileName, accessKey, secretKey string) error {
+	data, err := json.Marshal(keychainKeys{AccessKeyId: accessKey, SecretAccessKey: secretKey})
+	if err != nil {
+		return err
+	}
+	return keychain.Set(staticKeysAccount(profileName), string(data))
+}
+
+// markKeychainProfile writes the config section of a keychain profile: the
+// marker, a credential_process for other tools and the region.
+func markKeychainProfile(profileName, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create AWS directory: %w", err)
+	}
+	// Update the file defining the profile, which may be a config fragment
+	cfg := ini.Empty()
+	if _, statErr := os.Stat(configPath); statErr == nil {
+		if configPath, cfg, err = loadConfigFileDefining("profile "+profileName, profileName); err != nil {
+			return err
+		}
+	}
+	section, err := getProfileSection(cfg, profileName)
+	if err != nil {
+		if section, err = cfg.NewSection("profile " + profileName); err != nil {
+			return fmt.Errorf("failed to create config profile section: %w", err)
+		}
+	}
+	section.DeleteKey("aws_access_key_id")
+	section.DeleteKey("aws_secret_access_key")
+	section.Key(keychainMarker).SetValue("true")
+	section.Key("credential_process").SetValue(keychainCredentialProcess(profileName))
+	if region != "" {
+		section.Key("region").SetValue(region)
+	}
+	if err := saveIniAtomic(cfg, configPath); err != nil {
+		return fmt.Errorf("failed to save config file: %w", err)
+	}
+	InvalidateProfileCache()
+	return nil
+}
+
+// AddKeychainIAMUserProfile adds an IAM user profile like AddIAMUserProfile,
+// but keeps the keys in the OS keychain. The config file only gets a
+// credential_process pointing at awsm.
+func AddKeychainIAMUserProfile(profileName, accessKey, secretKey, region string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := policy.Enforce(policy.Profile{Name: profileName, Region: region}); err != nil {
+		return err
+	}
+	if err := storeStaticKeys(profileName, accessKey, secretKey); err != nil {
+		return err
+	}
+	return markKeychainProfile(profileName, region)
+}
+
+// SecureProfile moves the plaintext static keys of a profile from the
+// credentials file (or its config section) into the OS keychain.
+func SecureProfile(profileName string) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if IsKeychainProfile(profileName) {
+		return fmt.Errorf("the keys of profile '%s' are already in the OS keychain", profileName)
+	}
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err != nil {
+		return err
+	}
+	credCfg, err := loadOrCreateIni(credentialsPath)
+	if err != nil {
+		return err
+	}
+
+	var accessKey, secretKey, sessionToken string
+	credSection, credErr := credCfg.GetSection(profileName)
+	if credErr == nil {
+		accessKey = credSection.Key("aws_access_key_id").String()
+		secretKey = credSection.Key("aws_secret_access_key").String()
+		sessionToken = credSection.Key("aws_session_token").String()
+	}
+	region := ""
+	if cfg, err := loadMergedConfig(); err == nil {
+		if section, err := getProfileSection(cfg, profileName); err == nil {
+			region = section.Key("region").String()
+			if accessKey == "" {
+				accessKey = section.Key("aws_access_key_id").String()
+				secretKey = section.Key("aws_secret_access_key").String()
+				sessionToken = section.Key("aws_session_token").String()
+			}
+		}
+	}
+	if region == "" && credErr == nil {
+		region = credSection.Key("region").String()
+	}
+	if accessKey == "" || secretKey == "" {
+		return fmt.Errorf("profile '%s' has no static keys to move", profileName)
+	}
+	if sessionToken != "" {
+		return fmt.Errorf("profile '%s' holds temporary credentials, only long-term keys can be moved to the keychain", profileName)
+	}
+
+	if err := storeStaticKeys(profileName, accessKey, secretKey); err != nil {
+		return err
+	}
+	if err := markKeychainProfile(profileName, region); err != nil {
+		return err
+	}
+	// Only remove the plaintext keys once they are safely in the keychain
+	if credErr == nil {
+		credSection.DeleteKey("aws_access_key_id")
+		credSection.DeleteKey("aws_secret_access_key")
+		credSection.DeleteKey("region")
+		if len(credSection.Keys()) == 0 {
+			credCfg.DeleteSection(profileName)
+		}
+		if err := saveCredentialsWithDefaultLast(credCfg, credentialsPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteKeychainKeys removes a keychain profile's keys, if it is one.
+func deleteKeychainKeys(cfg *ini.File, profileName string) error {
+	section, err := getProfileSection(cfg, profileName)
+	if err != nil || !section.Key(keychainMarker).MustBool(false) {
+		return nil
+	}
+	if err := keychain.Delete(staticKeysAccount(profileName)); err != nil && !errors.Is(err, keychain.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// keychainSourceConfig returns the AWS config of a keychain profile with its
+// keys read directly, rather than through the credential_process that would
+// run awsm again.
+func keychainSourceConfig(profileName string) (aws.Config, error) {
+	creds, err := staticKeysFromKeychain(profileName)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithSharedConfigProfile(profileName),
+		withConfigFragments(),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(creds.AccessKeyId, creds.SecretAccessKey, "")),
+	)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config for profile '%s': %w", profileName, err)
+	}
+	return cfg, nil
+}
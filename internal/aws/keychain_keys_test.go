@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/keychain"
+)
+
+func TestSecureProfileMovesKeysToKeychain(t *testing.T) {
+	keychain.MockInit()
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(dir, "awsm"))
+	InvalidateProfileCache()
+
+	if err := os.WriteFile(configPath, []byte("[profile dev]\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsPath, []byte("[dev]\naws_access_key_id = AKIADEV\naws_secret_access_key = dev-secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SecureProfile("dev"); err != nil {
+		t.Fatal(err)
+	}
+	InvalidateProfileCache()
+
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "dev-secret") {
+		t.Errorf("Expected the plaintext keys to be removed, got:\n%s", data)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), keychainCredentialProcess("dev")) {
+		t.Errorf("Expected a credential_process for other tools, got:\n%s", data)
+	}
+
+	if !IsKeychainProfile("dev") {
+		t.Fatal("Expected dev to be a keychain profile")
+	}
+	creds, isStatic, err := GetCredentialsForProfile("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isStatic || creds.AccessKeyId != "AKIADEV" || creds.SecretAccessKey != "dev-secret" {
+		t.Errorf("Expected the keys from the keychain, got %+v (static %v)", creds, isStatic)
+	}
+	if err := SecureProfile("dev"); err == nil {
+		t.Error("Expected securing a keychain profile twice to fail")
+	}
+
+	if err := DeleteProfile("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keychain.Get(staticKeysAccount("dev")); err == nil {
+		t.Error("Expected deleting the profile to remove its keychain entry")
+	}
+}
+
+func TestSecureProfileRejectsSessionCredentials(t *testing.T) {
+	keychain.MockInit()
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	InvalidateProfileCache()
+
+	content := "[temp]\naws_access_key_id = ASIATEMP\naws_secret_access_key = temp-secret\naws_session_token = token\n"
+	if err := os.WriteFile(credentialsPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := SecureProfile("temp"); err == nil {
+		t.Fatal("Expected temporary credentials to be rejected")
+	}
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("Expected the credentials file to be untouched, got:\n%s", data)
+	}
+}
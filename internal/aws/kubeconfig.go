@@ -0,0 +1,199 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExecCredentialAPIVersion is the client authentication API kubectl and the
+// Kubernetes clients speak with exec credential plugins.
+const ExecCredentialAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// KubeconfigEntry is the cluster, user and context awsm writes for an EKS
+// cluster. Like 'aws eks update-kubeconfig', the cluster and user are named
+// after the cluster ARN, and the context too unless it has an alias.
+type KubeconfigEntry struct {
+	ClusterArn           string
+	Alias                string
+	Server               string
+	CertificateAuthority string
+	// Command and Args run the exec credential plugin that prints tokens.
+	Command string
+	Args    []string
+}
+
+// ContextName is the name of the kubeconfig context of the entry.
+func (e KubeconfigEntry) ContextName() string {
+	if e.Alias != "" {
+		return e.Alias
+	}
+	return e.ClusterArn
+}
+
+type kubeCluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+}
+
+type kubeUser struct {
+	Exec kubeExec `yaml:"exec"`
+}
+
+type kubeExec struct {
+	APIVersion      string   `yaml:"apiVersion"`
+	Command         string   `yaml:"command"`
+	Args            []string `yaml:"args"`
+	InteractiveMode string   `yaml:"interactiveMode"`
+}
+
+type kubeContext struct {
+	Cluster string `yaml:"cluster"`
+	User    string `yaml:"user"`
+}
+
+// KubeconfigPath returns the kubeconfig kubectl reads first: the first file
+// in KUBECONFIG, or ~/.kube/config.
+func KubeconfigPath() (string, error) {
+	for _, path := range filepath.SplitList(os.Getenv("KUBECONFIG")) {
+		if path != "" {
+			return path, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// UpdateKubeconfig adds the entry to the kubeconfig at path, replacing
+// entries of the same name and keeping everything else, comments included.
+// The file is created when missing. With setCurrent the entry's context
+// becomes the current one.
+func UpdateKubeconfig(path string, entry KubeconfigEntry, setCurrent bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	var doc yaml.Node
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse kubeconfig %s: %w", path, err)
+		}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("kubeconfig %s is not a YAML mapping", path)
+	}
+
+	if yamlMappingValue(root, "apiVersion") == nil {
+		setYAMLMappingValue(root, "apiVersion", yamlScalar("v1"))
+	}
+	if yamlMappingValue(root, "kind") == nil {
+		setYAMLMappingValue(root, "kind", yamlScalar("Config"))
+	}
+	user := kubeUser{Exec: kubeExec{
+		APIVersion:      ExecCredentialAPIVersion,
+		Command:         entry.Command,
+		Args:            entry.Args,
+		InteractiveMode: "IfAvailable",
+	}}
+	for _, named := range []struct {
+		list, key, name string
+		value           any
+	}{
+		{"clusters", "cluster", entry.ClusterArn, kubeCluster{Server: entry.Server, CertificateAuthorityData: entry.CertificateAuthority}},
+		{"users", "user", entry.ClusterArn, user},
+		{"contexts", "context", entry.ContextName(), kubeContext{Cluster: entry.ClusterArn, User: entry.ClusterArn}},
+	} {
+		if err := upsertKubeconfigItem(root, named.list, named.key, named.name, named.value); err != nil {
+			return fmt.Errorf("failed to update kubeconfig %s: %w", path, err)
+		}
+	}
+	if setCurrent {
+		setYAMLMappingValue(root, "current-context", yamlScalar(entry.ContextName()))
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to render kubeconfig: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to render kubeconfig: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create kubeconfig directory: %w", err)
+	}
+	if err := writeFileAtomic(path, out.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+	return nil
+}
+
+// upsertKubeconfigItem sets the item called name in the list of root, e.g.
+// clusters, to {name: name, key: value}.
+func upsertKubeconfigItem(root *yaml.Node, list, key, name string, value any) error {
+	var body yaml.Node
+	if err := body.Encode(value); err != nil {
+		return err
+	}
+	item := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		yamlScalar("name"), yamlScalar(name),
+		yamlScalar(key), &body,
+	}}
+
+	items := yamlMappingValue(root, list)
+	if items == nil || items.Tag == "!!null" {
+		items = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setYAMLMappingValue(root, list, items)
+	}
+	if items.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s is not a list", list)
+	}
+	// An empty list written as [] would keep the flow style
+	items.Style = 0
+	for i, existing := range items.Content {
+		if n := yamlMappingValue(existing, "name"); n != nil && n.Value == name {
+			items.Content[i] = item
+			return nil
+		}
+	}
+	items.Content = append(items.Content, item)
+	return nil
+}
+
+func yamlScalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// yamlMappingValue returns the value of key in a mapping node, nil when absent.
+func yamlMappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setYAMLMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, yamlScalar(key), value)
+}
@@ -0,0 +1,142 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func testKubeconfigEntry(alias string) KubeconfigEntry {
+	return KubeconfigEntry{
+		ClusterArn:           "arn:aws:eks:eu-west-1:111111111111:cluster/prod",
+		Alias:                alias,
+		Server:               "https://ABC.gr7.eu-west-1.eks.amazonaws.com",
+		CertificateAuthority: "Q0E=",
+		Command:              "/usr/local/bin/awsm",
+		Args:                 []string{"eks", "token", "--cluster", "prod", "--profile", "prod", "--region", "eu-west-1"},
+	}
+}
+
+type testKubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server string `yaml:"server"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token string   `yaml:"token"`
+			Exec  kubeExec `yaml:"exec"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string      `yaml:"name"`
+		Context kubeContext `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+func readTestKubeconfig(t *testing.T, path string) (testKubeconfig, string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read kubeconfig: %v", err)
+	}
+	var cfg testKubeconfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Written kubeconfig is not valid YAML: %v\n%s", err, data)
+	}
+	return cfg, string(data)
+}
+
+func TestUpdateKubeconfigCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kube", "config")
+	if err := UpdateKubeconfig(path, testKubeconfigEntry(""), true); err != nil {
+		t.Fatalf("UpdateKubeconfig failed: %v", err)
+	}
+	cfg, data := readTestKubeconfig(t, path)
+	arn := "arn:aws:eks:eu-west-1:111111111111:cluster/prod"
+	if cfg.CurrentContext != arn || len(cfg.Contexts) != 1 || cfg.Contexts[0].Context.User != arn {
+		t.Errorf("Expected a context named after the cluster ARN, got:\n%s", data)
+	}
+	exec := cfg.Users[0].User.Exec
+	if exec.APIVersion != ExecCredentialAPIVersion || exec.Command != "/usr/local/bin/awsm" || strings.Join(exec.Args, " ") != "eks token --cluster prod --profile prod --region eu-west-1" {
+		t.Errorf("Unexpected exec plugin: %+v", exec)
+	}
+	if !strings.HasPrefix(data, "apiVersion: v1\nkind: Config\n") {
+		t.Errorf("Expected a kubeconfig header, got:\n%s", data)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the kubeconfig to be private, got %v", info.Mode())
+	}
+}
+
+func TestUpdateKubeconfigMerges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	existing := `apiVersion: v1
+kind: Config
+# local development cluster
+clusters:
+  - name: kind-dev
+    cluster:
+      server: https://127.0.0.1:6443
+  - name: arn:aws:eks:eu-west-1:111111111111:cluster/prod
+    cluster:
+      server: https://old.example.com
+users:
+  - name: kind-dev
+    user:
+      token: abc
+contexts: []
+current-context: kind-dev
+`
+	if err := os.WriteFile(path, []byte(existing), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpdateKubeconfig(path, testKubeconfigEntry("prod"), false); err != nil {
+		t.Fatalf("UpdateKubeconfig failed: %v", err)
+	}
+	cfg, data := readTestKubeconfig(t, path)
+	if cfg.CurrentContext != "kind-dev" {
+		t.Errorf("Expected the current context to stay without setCurrent, got %s", cfg.CurrentContext)
+	}
+	if len(cfg.Clusters) != 2 || cfg.Clusters[0].Name != "kind-dev" || cfg.Clusters[1].Cluster.Server != "https://ABC.gr7.eu-west-1.eks.amazonaws.com" {
+		t.Errorf("Expected the stale cluster to be replaced and the other kept, got:\n%s", data)
+	}
+	if len(cfg.Users) != 2 || cfg.Users[0].User.Token != "abc" {
+		t.Errorf("Expected the other user to be kept, got:\n%s", data)
+	}
+	if len(cfg.Contexts) != 1 || cfg.Contexts[0].Name != "prod" {
+		t.Errorf("Expected a context named after the alias, got:\n%s", data)
+	}
+	if !strings.Contains(data, "# local development cluster") {
+		t.Errorf("Expected comments to be kept, got:\n%s", data)
+	}
+
+	// Running it again doesn't duplicate entries
+	if err := UpdateKubeconfig(path, testKubeconfigEntry("prod"), true); err != nil {
+		t.Fatalf("UpdateKubeconfig failed: %v", err)
+	}
+	cfg, data = readTestKubeconfig(t, path)
+	if len(cfg.Clusters) != 2 || len(cfg.Users) != 2 || len(cfg.Contexts) != 1 || cfg.CurrentContext != "prod" {
+		t.Errorf("Expected entries to be updated in place, got:\n%s", data)
+	}
+}
+
+func TestKubeconfigPath(t *testing.T) {
+	t.Setenv("KUBECONFIG", string(os.PathListSeparator)+"/tmp/a"+string(os.PathListSeparator)+"/tmp/b")
+	if path, err := KubeconfigPath(); err != nil || path != "/tmp/a" {
+		t.Errorf("Expected the first KUBECONFIG file, got %q, %v", path, err)
+	}
+	home := t.TempDir()
+	t.Setenv("KUBECONFIG", "")
+	t.Setenv("HOME", home)
+	if path, err := KubeconfigPath(); err != nil || path != filepath.Join(home, ".kube", "config") {
+		t.Errorf("Expected ~/.kube/config, got %q, %v", path, err)
+	}
+}
@@ -0,0 +1,307 @@
+package aws
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"awsm/internal/policy"
+)
+
+// Lint finding severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// LintFinding is a single problem found in an AWS config file.
+type LintFinding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Section  string `json:"section,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s [%s]", f.File, f.Line, f.Severity, f.Message, f.Rule)
+}
+
+// lintKey is a key = value line with its position.
+type lintKey struct {
+	Value string
+	Line  int
+}
+
+// lintSection is a section of a config file with the line numbers of its keys.
+type lintSection struct {
+	Kind string // "profile", "sso-session", "services" or "" for unknown sections
+	Name string
+	File string
+	Line int
+	Keys map[string]lintKey
+}
+
+var (
+	lintSectionRegex = regexp.MustCompile(`^\[\s*([^\]]*?)\s*\]\s*([#;].*)?$`)
+	roleArnRegex     = regexp.MustCompile(`^arn:aws[a-zA-Z-]*:iam::\d{12}:role/.+$`)
+	accountIDRegex   = regexp.MustCompile(`^\d{12}$`)
+)
+
+// LintInput is a config file to lint.
+type LintInput struct {
+	File   string
+	Reader io.Reader
+}
+
+// LintConfig validates the content of an AWS config file. Apart from the
+// organization policy it does not depend on local state, so it can check
+// files that are not the live config. Findings are sorted by line.
+func LintConfig(file string, r io.Reader) ([]LintFinding, error) {
+	return LintConfigFiles(LintInput{File: file, Reader: r})
+}
+
+// LintConfigFiles validates config files that are read together, like the AWS
+// config file and its fragments: references may point to sections in other
+// files, and a section defined in more than one file is reported.
+// Findings are sorted by file, in input order, then by line.
+func LintConfigFiles(inputs ...LintInput) ([]LintFinding, error) {
+	var findings []LintFinding
+	add := func(file string, line int, severity, rule, section, format string, args ...any) {
+		findings = append(findings, LintFinding{File: file, Line: line, Severity: severity, Rule: rule, Section: section, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var sections []*lintSection
+	seen := make(map[string]*lintSection)
+	fileOrder := make(map[string]int)
+
+	for i, input := range inputs {
+		file := input.File
+		fileOrder[file] = i
+		var current *lintSection
+
+		scanner := bufio.NewScanner(input.Reader)
+		lineNo := 0
+		for scanner.Scan() {
+			lineNo++
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+
+			if strings.HasPrefix(line, "[") {
+				match := lintSectionRegex.FindStringSubmatch(line)
+				if match == nil {
+					add(file, lineNo, SeverityError, "syntax", "", "malformed section header %q", line)
+					current = nil
+					continue
+				}
+				current = newLintSection(match[1], file, lineNo)
+				if current.Kind == "" {
+					add(file, lineNo, SeverityWarning, "profile-prefix", match[1], "section [%s] is ignored by the AWS CLI in the config file, use [profile %s]", match[1], match[1])
+				}
+				id := current.Kind + " " + current.Name
+				if prev, ok := seen[id]; ok {
+					if prev.File != file {
+						// A fragment may redefine a section on purpose; the SDK merges the keys with the later file winning
+						add(file, lineNo, SeverityWarning, "section-override", match[1], "section [%s] overrides keys of the one in %s:%d", match[1], prev.File, prev.Line)
+					} else {
+						add(file, lineNo, SeverityError, "duplicate-section", match[1], "section [%s] is already defined on line %d", match[1], prev.Line)
+					}
+				} else {
+					seen[id] = current
+				}
+				sections = append(sections, current)
+				continue
+			}
+
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				add(file, lineNo, SeverityError, "syntax", "", "expected 'key = value', got %q", line)
+				continue
+			}
+			if current == nil {
+				add(file, lineNo, SeverityError, "syntax", "", "key %q is outside of any section", strings.TrimSpace(key))
+				continue
+			}
+			key = strings.TrimSpace(key)
+			if prev, ok := current.Keys[key]; ok {
+				add(file, lineNo, SeverityWarning, "duplicate-key", current.Name, "key '%s' is already set on line %d", key, prev.Line)
+			}
+			current.Keys[key] = lintKey{Value: strings.TrimSpace(value), Line: lineNo}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+
+	profiles := make(map[string]*lintSection)
+	ssoSessions := make(map[string]*lintSection)
+	for _, s := range sections {
+		switch s.Kind {
+		case "profile":
+			if _, ok := profiles[s.Name]; !ok {
+				profiles[s.Name] = s
+			}
+		case "sso-session":
+			if _, ok := ssoSessions[s.Name]; !ok {
+				ssoSessions[s.Name] = s
+			}
+		}
+	}
+
+	pol, err := policy.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range sections {
+		keyLine := func(key string) int {
+			if k, ok := s.Keys[key]; ok {
+				return k.Line
+			}
+			return s.Line
+		}
+		if region, ok := s.Keys["region"]; ok && !IsValidRegion(region.Value) {
+			add(s.File, region.Line, SeverityWarning, "region", s.Name, "unknown region '%s'", region.Value)
+		}
+
+		switch s.Kind {
+		case "sso-session":
+			for _, key := range []string{"sso_start_url", "sso_region"} {
+				if _, ok := s.Keys[key]; !ok {
+					add(s.File, s.Line, SeverityError, "sso-session", s.Name, "sso-session '%s' is missing %s", s.Name, key)
+				}
+			}
+			if region, ok := s.Keys["sso_region"]; ok && !IsValidRegion(region.Value) {
+				add(s.File, region.Line, SeverityWarning, "region", s.Name, "unknown sso_region '%s'", region.Value)
+			}
+
+		case "profile":
+			_, hasSession := s.Keys["sso_session"]
+			_, hasStartURL := s.Keys["sso_start_url"]
+			if session, ok := s.Keys["sso_session"]; ok {
+				if _, exists := ssoSessions[session.Value]; !exists {
+					add(s.File, session.Line, SeverityError, "missing-sso-session", s.Name, "sso_session '%s' is not defined", session.Value)
+				}
+			} else if hasStartURL {
+				add(s.File, keyLine("sso_start_url"), SeverityInfo, "legacy-sso", s.Name, "profile uses the legacy SSO format, consider moving sso_start_url to an sso-session section")
+			}
+			if hasSession || hasStartURL {
+				for _, key := range []string{"sso_account_id", "sso_role_name"} {
+					if _, ok := s.Keys[key]; !ok {
+						add(s.File, s.Line, SeverityError, "sso-profile", s.Name, "SSO profile is missing %s", key)
+					}
+				}
+			}
+			if account, ok := s.Keys["sso_account_id"]; ok && !accountIDRegex.MatchString(account.Value) {
+				add(s.File, account.Line, SeverityError, "account-id", s.Name, "sso_account_id '%s' is not a 12-digit account ID", account.Value)
+			}
+
+			if arn, ok := s.Keys["role_arn"]; ok {
+				if !roleArnRegex.MatchString(arn.Value) {
+					add(s.File, arn.Line, SeverityError, "role-arn", s.Name, "role_arn '%s' is not a valid IAM role ARN", arn.Value)
+				}
+				_, hasSource := s.Keys["source_profile"]
+				_, hasCredSource := s.Keys["credential_source"]
+				if hasSource && hasCredSource {
+					add(s.File, arn.Line, SeverityError, "role-source", s.Name, "source_profile and credential_source are mutually exclusive")
+				} else if !hasSource && !hasCredSource && !hasSession && !hasStartURL {
+					add(s.File, arn.Line, SeverityError, "role-source", s.Name, "role_arn needs a source_profile or credential_source")
+				}
+			}
+			if source, ok := s.Keys["source_profile"]; ok {
+				if _, exists := profiles[source.Value]; !exists && source.Value != "default" {
+					add(s.File, source.Line, SeverityWarning, "missing-source-profile", s.Name, "source_profile '%s' is not defined in this file (it may be in the credentials file)", source.Value)
+				} else if cycle := sourceProfileCycle(s.Name, profiles); cycle != "" {
+					add(s.File, source.Line, SeverityError, "source-profile-cycle", s.Name, "source_profile chain loops: %s", cycle)
+				}
+			}
+			if secret, ok := s.Keys["aws_secret_access_key"]; ok {
+				add(s.File, secret.Line, SeverityWarning, "static-credentials", s.Name, "long-term secret key stored in the config file, keep it in the credentials file or use SSO")
+			}
+
+			accountID := ""
+			if account, ok := s.Keys["sso_account_id"]; ok {
+				accountID = account.Value
+			} else if arn, ok := s.Keys["role_arn"]; ok {
+				accountID = policy.AccountIDFromARN(arn.Value)
+			}
+			roleName := s.Keys["sso_role_name"].Value
+			if arn, ok := s.Keys["role_arn"]; ok && roleName == "" {
+				roleName = policy.RoleNameFromARN(arn.Value)
+			}
+			for _, v := range pol.Check(policy.Profile{Name: s.Name, Region: s.Keys["region"].Value, AccountID: accountID, RoleName: roleName}) {
+				add(s.File, s.Line, SeverityError, "policy", s.Name, "%s", v)
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return fileOrder[findings[i].File] < fileOrder[findings[j].File]
+		}
+		return findings[i].Line < findings[j].Line
+	})
+	return findings, nil
+}
+
+// newLintSection classifies a section header.
+func newLintSection(header, file string, line int) *lintSection {
+	s := &lintSection{Name: header, File: file, Line: line, Keys: make(map[string]lintKey)}
+	switch {
+	case header == "default":
+		s.Kind = "profile"
+	case strings.HasPrefix(header, "profile "):
+		s.Kind = "profile"
+		s.Name = strings.TrimSpace(strings.TrimPrefix(header, "profile "))
+	case strings.HasPrefix(header, "sso-session "):
+		s.Kind = "sso-session"
+		s.Name = strings.TrimSpace(strings.TrimPrefix(header, "sso-session "))
+	case strings.HasPrefix(header, "services "):
+		s.Kind = "services"
+		s.Name = strings.TrimSpace(strings.TrimPrefix(header, "services "))
+	}
+	return s
+}
+
+// sourceProfileCycle follows the source_profile chain starting at name and
+// returns a description of the loop if the chain comes back to a visited profile.
+// A profile that sources itself is valid when it also has static keys.
+func sourceProfileCycle(name string, profiles map[string]*lintSection) string {
+	chain := []string{name}
+	visited := map[string]bool{name: true}
+	current := profiles[name]
+	for current != nil {
+		source, ok := current.Keys["source_profile"]
+		if !ok {
+			return ""
+		}
+		if source.Value == current.Name {
+			if _, hasKey := current.Keys["aws_access_key_id"]; hasKey {
+				return ""
+			}
+		}
+		chain = append(chain, source.Value)
+		if visited[source.Value] {
+			return strings.Join(chain, " -> ")
+		}
+		visited[source.Value] = true
+		current = profiles[source.Value]
+	}
+	return ""
+}
+
+// LintSummary counts findings per severity.
+func LintSummary(findings []LintFinding) map[string]int {
+	counts := map[string]int{SeverityError: 0, SeverityWarning: 0, SeverityInfo: 0}
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
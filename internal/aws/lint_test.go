@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	content := `[default]
+region = us-east-1
+
+[profile dev]
+sso_session = missing
+sso_account_id = 1234
+sso_role_name = Admin
+
+[profile dev]
+region = us-east-1
+
+[profile role]
+role_arn = not-an-arn
+
+[profile a]
+role_arn = arn:aws:iam::123456789012:role/A
+source_profile = b
+
+[profile b]
+role_arn = arn:aws:iam::123456789012:role/B
+source_profile = a
+region = mars-1
+
+[staging]
+region = eu-west-1
+this line is broken
+`
+	findings, err := LintConfig("config", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("LintConfig failed: %v", err)
+	}
+
+	expected := map[string]int{
+		"missing-sso-session":  5,
+		"account-id":           6,
+		"duplicate-section":    9,
+		"role-arn":             13,
+		"role-source":          13,
+		"source-profile-cycle": 17,
+		"region":               22,
+		"profile-prefix":       24,
+		"syntax":               26,
+	}
+	for rule, line := range expected {
+		found := false
+		for _, f := range findings {
+			if f.Rule == rule && f.Line == line {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Expected %s finding on line %d, got %v", rule, line, findings)
+		}
+	}
+
+	clean := `[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-1
+
+[profile dev]
+sso_session = corp
+sso_account_id = 123456789012
+sso_role_name = Admin
+region = eu-west-1
+`
+	findings, err = LintConfig("config", strings.NewReader(clean))
+	if err != nil {
+		t.Fatalf("LintConfig failed: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("Expected no findings, got %v", findings)
+	}
+}
+
+func TestLintConfigFilesOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	base := "[profile dev]\nregion = us-east-1\n"
+	fragment := "[profile dev]\nregion = eu-west-1\n"
+	findings, err := LintConfigFiles(
+		LintInput{File: "config", Reader: strings.NewReader(base)},
+		LintInput{File: "config.d/team.conf", Reader: strings.NewReader(fragment)},
+	)
+	if err != nil {
+		t.Fatalf("LintConfigFiles failed: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "section-override" || findings[0].Severity != SeverityWarning || findings[0].File != "config.d/team.conf" {
+		t.Errorf("Expected a single section-override warning, got %v", findings)
+	}
+}
@@ -0,0 +1,135 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// lockTimeout is how long awsm waits for another awsm process to finish with
+// the AWS config files before giving up.
+var lockTimeout = 10 * time.Second
+
+// errLockHeld is returned by tryLockFile when another process holds the lock.
+var errLockHeld = errors.New("lock held by another process")
+
+// fileLock is an advisory lock on a lock file, shared by all callers in this
+// process. Callers nest freely: the lock is taken by the first one, upgraded
+// when a nested caller needs it exclusively, and released by the last one.
+// It keeps awsm processes from interleaving their changes, e.g. when several
+// terminals refresh credentials at once; goroutines of one process are not
+// serialized against each other.
+type fileLock struct {
+	mu        sync.Mutex
+	file      *os.File
+	holders   int
+	exclusive bool
+}
+
+// awsFilesLock guards the AWS config and credentials files.
+var awsFilesLock fileLock
+
+// awsFilesLockPath returns the lock file guarding the AWS config files, kept
+// next to the config file so every awsm process using it agrees on the lock.
+func awsFilesLockPath() (string, error) {
+	configPath, err := GetAWSConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), ".awsm.lock"), nil
+}
+
+// lockAWSFiles takes the lock on the AWS config files exclusively, for a
+// change that reads and writes them. The returned function releases it.
+func lockAWSFiles() (unlock func(), err error) {
+	return lockAWSFilesMode(true)
+}
+
+// rlockAWSFiles takes the lock on the AWS config files shared, for reading
+// them while no other awsm process is changing them.
+func rlockAWSFiles() (unlock func(), err error) {
+	return lockAWSFilesMode(false)
+}
+
+func lockAWSFilesMode(exclusive bool) (func(), error) {
+	path, err := awsFilesLockPath()
+	if err != nil {
+		return nil, err
+	}
+	return awsFilesLock.acquire(path, exclusive)
+}
+
+// LockAWSFiles takes the lock on the AWS config files for commands that
+// rewrite them outside this package, such as 'awsm sso generate'.
+func LockAWSFiles() (unlock func(), err error) {
+	return lockAWSFiles()
+}
+
+func (l *fileLock) acquire(path string, exclusive bool) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch {
+	case l.holders == 0:
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create AWS directory: %w", err)
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+		}
+		if err := waitForLock(file, exclusive); err != nil {
+			file.Close()
+			return nil, err
+		}
+		l.file, l.exclusive = file, exclusive
+	case exclusive && !l.exclusive:
+		// Re-taking the lock exclusively lets another process in between,
+		// just like converting a flock does
+		unlockFile(l.file)
+		if err := waitForLock(l.file, true); err != nil {
+			if waitForLock(l.file, false) != nil {
+				l.file.Close()
+				l.file, l.holders = nil, 0
+			}
+			return nil, err
+		}
+		l.exclusive = true
+	}
+	l.holders++
+
+	var once sync.Once
+	return func() { once.Do(l.release) }, nil
+}
+
+func (l *fileLock) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.holders == 0 {
+		return
+	}
+	l.holders--
+	if l.holders == 0 {
+		unlockFile(l.file)
+		l.file.Close()
+		l.file, l.exclusive = nil, false
+	}
+}
+
+// waitForLock retries tryLockFile until it succeeds or lockTimeout passes.
+func waitForLock(file *os.File, exclusive bool) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		err := tryLockFile(file, exclusive)
+		if !errors.Is(err, errLockHeld) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("another awsm process is using the AWS config files, gave up waiting for %s after %s", file.Name(), lockTimeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package aws
+
+import "os"
+
+// Other platforms run without locking, as before.
+func tryLockFile(file *os.File, exclusive bool) error { return nil }
+
+func unlockFile(file *os.File) {}
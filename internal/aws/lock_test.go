@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+	default:
+		t.Skipf("no file locking on %s", runtime.GOOS)
+	}
+	old := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = old })
+
+	path := filepath.Join(t.TempDir(), ".awsm.lock")
+	// Two fileLocks open the lock file separately, like two awsm processes
+	var first, second fileLock
+
+	unlockShared, err := first.acquire(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlockOtherShared, err := second.acquire(path, false)
+	if err != nil {
+		t.Fatalf("Expected shared locks not to conflict: %v", err)
+	}
+	unlockOtherShared()
+
+	// Nested callers upgrade the lock of their process
+	unlockExclusive, err := first.acquire(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.holders != 2 || !first.exclusive {
+		t.Errorf("Expected 2 holders of an exclusive lock, got %d (exclusive %v)", first.holders, first.exclusive)
+	}
+	if _, err := second.acquire(path, false); err == nil || !strings.Contains(err.Error(), "another awsm process") {
+		t.Errorf("Expected a timeout while another process holds the lock, got %v", err)
+	}
+
+	unlockExclusive()
+	unlockExclusive() // Releasing twice is harmless
+	if first.holders != 1 {
+		t.Errorf("Expected the outer holder to keep the lock, got %d holders", first.holders)
+	}
+	unlockShared()
+	if first.file != nil {
+		t.Error("Expected the lock file to be closed once the last holder is done")
+	}
+
+	unlockOther, err := second.acquire(path, true)
+	if err != nil {
+		t.Fatalf("Expected the lock to be free again: %v", err)
+	}
+	unlockOther()
+}
@@ -0,0 +1,270 @@
+package aws
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+// DefaultMetadataURL is the upstream endpoints file 'awsm metadata update'
+// downloads. It lists the partitions, their regions and the services
+// available in each, and is what the SDKs generate their endpoint tables from.
+const DefaultMetadataURL = "https://raw.githubusercontent.com/boto/botocore/develop/botocore/data/endpoints.json"
+
+// bundledMetadata is the metadata shipped with awsm, trimmed from the
+// partitions file of the AWS SDK.
+//
+//go:embed metadata.json
+var bundledMetadata []byte
+
+// Metadata describes the AWS partitions, their regions and services.
+type Metadata struct {
+	// Source tells where the metadata came from.
+	Source string `json:"source"`
+	// Updated is when 'awsm metadata update' fetched it, zero for the bundled copy.
+	Updated    time.Time   `json:"updated,omitzero"`
+	Partitions []Partition `json:"partitions"`
+}
+
+// Partition is a group of regions sharing sign-in and endpoints, such as the
+// standard, China and GovCloud partitions.
+type Partition struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DNSSuffix   string `json:"dnsSuffix"`
+	RegionRegex string `json:"regionRegex"`
+	// Regions maps region names to their description.
+	Regions map[string]string `json:"regions"`
+	// Services lists the service endpoint names known in the partition.
+	Services []string `json:"services,omitempty"`
+}
+
+var (
+	metadataOnce   sync.Once
+	loadedMetadata *Metadata
+)
+
+// MetadataPath returns where 'awsm metadata update' stores refreshed metadata.
+func MetadataPath() (string, error) {
+	dir, err := awsmConfig.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "metadata.json"), nil
+}
+
+// GetMetadata returns the refreshed metadata when 'awsm metadata update' has
+// stored a usable copy, and the bundled metadata otherwise.
+func GetMetadata() *Metadata {
+	metadataOnce.Do(func() {
+		if path, err := MetadataPath(); err == nil {
+			if data, err := os.ReadFile(path); err == nil {
+				if m, err := parseMetadata(data); err == nil {
+					loadedMetadata = m
+					return
+				}
+			}
+		}
+		m, err := parseMetadata(bundledMetadata)
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled metadata: %v", err))
+		}
+		loadedMetadata = m
+	})
+	return loadedMetadata
+}
+
+// resetMetadata forgets the loaded metadata, so the next GetMetadata reads it again.
+func resetMetadata() {
+	metadataOnce = sync.Once{}
+	loadedMetadata = nil
+}
+
+// parseMetadata reads metadata as written by SaveMetadata and checks it can
+// stand in for the bundled copy.
+func parseMetadata(data []byte) (*Metadata, error) {
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// validate rejects metadata missing the standard partition, which awsm can't
+// work without.
+func (m *Metadata) validate() error {
+	p := m.Partition("aws")
+	if p == nil {
+		return fmt.Errorf("metadata has no 'aws' partition")
+	}
+	if _, ok := p.Regions["us-east-1"]; !ok {
+		return fmt.Errorf("metadata has no us-east-1 region")
+	}
+	return nil
+}
+
+// Partition returns the partition with the given ID, or nil.
+func (m *Metadata) Partition(id string) *Partition {
+	for i := range m.Partitions {
+		if m.Partitions[i].ID == id {
+			return &m.Partitions[i]
+		}
+	}
+	return nil
+}
+
+// PartitionForRegion returns the partition listing region, or nil.
+func (m *Metadata) PartitionForRegion(region string) *Partition {
+	for i := range m.Partitions {
+		if _, ok := m.Partitions[i].Regions[region]; ok {
+			return &m.Partitions[i]
+		}
+	}
+	return nil
+}
+
+// RegionNames returns the sorted region names of the partition.
+func (p *Partition) RegionNames() []string {
+	names := make([]string, 0, len(p.Regions))
+	for name := range p.Regions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseEndpointsFile converts an upstream endpoints file into metadata. Both
+// the botocore endpoints.json (with services) and the partitions.json of the
+// Go SDK (regions only) are understood. Pseudo regions such as aws-global,
+// which don't match the region pattern of their partition, are left out.
+func ParseEndpointsFile(data []byte) (*Metadata, error) {
+	var file struct {
+		Partitions []struct {
+			// botocore endpoints.json
+			Partition     string                     `json:"partition"`
+			PartitionName string                     `json:"partitionName"`
+			DNSSuffix     string                     `json:"dnsSuffix"`
+			Services      map[string]json.RawMessage `json:"services"`
+			// Go SDK partitions.json
+			ID      string `json:"id"`
+			Outputs struct {
+				DNSSuffix string `json:"dnsSuffix"`
+			} `json:"outputs"`
+
+			RegionRegex string `json:"regionRegex"`
+			Regions     map[string]struct {
+				Description string `json:"description"`
+			} `json:"regions"`
+		} `json:"partitions"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse endpoints file: %w", err)
+	}
+
+	m := &Metadata{}
+	for _, fp := range file.Partitions {
+		p := Partition{
+			ID:          fp.Partition,
+			Name:        fp.PartitionName,
+			DNSSuffix:   fp.DNSSuffix,
+			RegionRegex: fp.RegionRegex,
+			Regions:     map[string]string{},
+		}
+		if p.ID == "" {
+			p.ID = fp.ID
+			p.DNSSuffix = fp.Outputs.DNSSuffix
+		}
+		if p.Name == "" {
+			p.Name = p.ID
+		}
+		regionPattern, err := regexp.Compile(fp.RegionRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid region pattern of partition '%s': %w", p.ID, err)
+		}
+		for name, region := range fp.Regions {
+			if regionPattern.MatchString(name) {
+				p.Regions[name] = region.Description
+			}
+		}
+		for service := range fp.Services {
+			p.Services = append(p.Services, service)
+		}
+		sort.Strings(p.Services)
+		m.Partitions = append(m.Partitions, p)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FetchMetadata downloads and parses the endpoints file at url.
+func FetchMetadata(url string) (*Metadata, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	m, err := ParseEndpointsFile(data)
+	if err != nil {
+		return nil, err
+	}
+	m.Source = url
+	m.Updated = time.Now().UTC().Truncate(time.Second)
+	return m, nil
+}
+
+// SaveMetadata stores refreshed metadata, used from then on instead of the
+// bundled copy.
+func SaveMetadata(m *Metadata) error {
+	path, err := MetadataPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	resetMetadata()
+	return nil
+}
+
+// ResetMetadata removes refreshed metadata, going back to the bundled copy.
+func ResetMetadata() error {
+	path, err := MetadataPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	resetMetadata()
+	return nil
+}
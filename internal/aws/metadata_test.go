@@ -0,0 +1,173 @@
+package aws
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func TestBundledMetadata(t *testing.T) {
+	m, err := parseMetadata(bundledMetadata)
+	if err != nil {
+		t.Fatalf("bundled metadata is invalid: %v", err)
+	}
+	for _, id := range consolePartitions {
+		if m.Partition(id) == nil {
+			t.Errorf("bundled metadata lacks partition %s", id)
+		}
+	}
+	if m.PartitionForRegion("aws-global") != nil {
+		t.Error("pseudo region aws-global should not be bundled")
+	}
+	if got := m.PartitionForRegion("cn-north-1"); got == nil || got.ID != "aws-cn" {
+		t.Errorf("cn-north-1 should be in aws-cn, got %+v", got)
+	}
+}
+
+func TestRegionValidation(t *testing.T) {
+	resetMetadata()
+	t.Cleanup(resetMetadata)
+	t.Setenv(awsmConfig.HomeEnv, t.TempDir())
+
+	tests := []struct {
+		region  string
+		valid   bool
+		console bool
+	}{
+		{"eu-west-1", true, true},
+		{"ap-southeast-6", true, true},
+		{"cn-north-1", false, true},
+		{"us-gov-west-1", false, true},
+		{"us-iso-east-1", false, false},
+		{"aws-global", false, false},
+		{"mars-north-1", false, false},
+	}
+	for _, tt := range tests {
+		if got := IsValidRegion(tt.region); got != tt.valid {
+			t.Errorf("IsValidRegion(%s) = %v, want %v", tt.region, got, tt.valid)
+		}
+		if got := IsValidConsoleRegion(tt.region); got != tt.console {
+			t.Errorf("IsValidConsoleRegion(%s) = %v, want %v", tt.region, got, tt.console)
+		}
+	}
+	if got := RegionDescription("eu-west-1"); got != "Europe (Ireland)" {
+		t.Errorf("RegionDescription(eu-west-1) = %q", got)
+	}
+	if !slices.IsSorted(GetAllRegions()) {
+		t.Error("GetAllRegions should be sorted")
+	}
+}
+
+const botocoreEndpoints = `{
+  "partitions": [{
+    "partition": "aws",
+    "partitionName": "AWS Standard",
+    "dnsSuffix": "amazonaws.com",
+    "regionRegex": "^(us|eu|ap|sa|ca|me|af|il|mx)\\-\\w+\\-\\d+$",
+    "regions": {
+      "us-east-1": {"description": "US East (N. Virginia)"},
+      "eu-west-9": {"description": "Europe (Somewhere)"}
+    },
+    "services": {
+      "ec2": {"endpoints": {"us-east-1": {}}},
+      "athena": {"endpoints": {"us-east-1": {}}}
+    }
+  }, {
+    "partition": "aws-cn",
+    "partitionName": "AWS China",
+    "dnsSuffix": "amazonaws.com.cn",
+    "regionRegex": "^cn\\-\\w+\\-\\d+$",
+    "regions": {"cn-north-1": {"description": "China (Beijing)"}},
+    "services": {}
+  }],
+  "version": 3
+}`
+
+const sdkPartitions = `{
+  "partitions": [{
+    "id": "aws",
+    "outputs": {"dnsSuffix": "amazonaws.com"},
+    "regionRegex": "^(us|eu|ap|sa|ca|me|af|il|mx)\\-\\w+\\-\\d+$",
+    "regions": {
+      "aws-global": {"description": "AWS Standard global region"},
+      "us-east-1": {"description": "US East (N. Virginia)"}
+    }
+  }],
+  "version": "1.1"
+}`
+
+func TestParseEndpointsFile(t *testing.T) {
+	m, err := ParseEndpointsFile([]byte(botocoreEndpoints))
+	if err != nil {
+		t.Fatalf("ParseEndpointsFile: %v", err)
+	}
+	std := m.Partition("aws")
+	if got := std.RegionNames(); !slices.Equal(got, []string{"eu-west-9", "us-east-1"}) {
+		t.Errorf("regions = %v", got)
+	}
+	if !slices.Equal(std.Services, []string{"athena", "ec2"}) {
+		t.Errorf("services = %v", std.Services)
+	}
+	if cn := m.Partition("aws-cn"); cn == nil || cn.DNSSuffix != "amazonaws.com.cn" || cn.Name != "AWS China" {
+		t.Errorf("aws-cn = %+v", cn)
+	}
+
+	m, err = ParseEndpointsFile([]byte(sdkPartitions))
+	if err != nil {
+		t.Fatalf("ParseEndpointsFile(partitions.json): %v", err)
+	}
+	std = m.Partition("aws")
+	if got := std.RegionNames(); !slices.Equal(got, []string{"us-east-1"}) {
+		t.Errorf("regions = %v, pseudo regions should be left out", got)
+	}
+	if std.DNSSuffix != "amazonaws.com" || len(std.Services) != 0 {
+		t.Errorf("aws = %+v", std)
+	}
+
+	if _, err := ParseEndpointsFile([]byte(`{"partitions": []}`)); err == nil {
+		t.Error("an endpoints file without the aws partition should be rejected")
+	}
+}
+
+func TestSaveAndResetMetadata(t *testing.T) {
+	resetMetadata()
+	t.Cleanup(resetMetadata)
+	t.Setenv(awsmConfig.HomeEnv, t.TempDir())
+
+	m, err := ParseEndpointsFile([]byte(botocoreEndpoints))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Source = "test"
+	if err := SaveMetadata(m); err != nil {
+		t.Fatalf("SaveMetadata: %v", err)
+	}
+	if !IsValidRegion("eu-west-9") || IsValidRegion("eu-west-1") {
+		t.Error("refreshed metadata should replace the bundled regions")
+	}
+	if services := ConsoleServices(); !slices.Contains(services, "athena") {
+		t.Errorf("ConsoleServices should include metadata services, got %v", services)
+	}
+
+	// A broken file falls back to the bundled metadata
+	path, _ := MetadataPath()
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resetMetadata()
+	if !IsValidRegion("eu-west-1") {
+		t.Error("a broken metadata file should fall back to the bundled metadata")
+	}
+
+	if err := ResetMetadata(); err != nil {
+		t.Fatalf("ResetMetadata: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("ResetMetadata should remove %s", path)
+	}
+	if GetMetadata().Source == "test" {
+		t.Error("ResetMetadata should go back to the bundled metadata")
+	}
+}
@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"awsm/internal/keychain"
+	"awsm/internal/util"
+)
+
+// mfaCodePattern is what STS accepts as TokenCode.
+var mfaCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// totpAccount is the keychain account holding the TOTP secret of an MFA device.
+// Secrets are stored per device, so profiles sharing a device share it.
+func totpAccount(mfaSerial string) string {
+	return "totp:" + mfaSerial
+}
+
+// ParseTOTPSecret accepts a base32 TOTP secret, as shown when registering a
+// virtual MFA device, or an otpauth:// URI from its QR code, and returns the
+// normalized base32 secret.
+func ParseTOTPSecret(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "otpauth://") {
+		u, err := url.Parse(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid otpauth URI: %w", err)
+		}
+		value = u.Query().Get("secret")
+		if value == "" {
+			return "", fmt.Errorf("otpauth URI has no secret")
+		}
+	}
+	secret := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(value))
+	secret = strings.TrimRight(secret, "=")
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil || secret == "" {
+		return "", fmt.Errorf("TOTP secret is not valid base32")
+	}
+	return secret, nil
+}
+
+// totpCode computes the RFC 6238 code (SHA-1, 30 seconds, 6 digits) AWS
+// virtual MFA devices use.
+func totpCode(secret string, t time.Time) (string, error) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("TOTP secret is not valid base32")
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000), nil
+}
+
+// SetTOTPSecret stores the TOTP secret of an MFA device in the OS keychain.
+func SetTOTPSecret(mfaSerial, secret string) error {
+	return keychain.Set(totpAccount(mfaSerial), secret)
+}
+
+// RemoveTOTPSecret removes the TOTP secret of an MFA device from the OS keychain.
+func RemoveTOTPSecret(mfaSerial string) error {
+	return keychain.Delete(totpAccount(mfaSerial))
+}
+
+// hasTOTPSecret reports whether a TOTP secret is stored for an MFA device.
+func hasTOTPSecret(mfaSerial string) bool {
+	_, err := keychain.Get(totpAccount(mfaSerial))
+	return err == nil
+}
+
+// runMFAProcess runs a profile's mfa_process command and returns the code it
+// prints. The command runs through the shell, like credential_process.
+func runMFAProcess(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("mfa_process '%s' failed: %w", command, err)
+	}
+	code := strings.TrimSpace(string(out))
+	if !mfaCodePattern.MatchString(code) {
+		return "", fmt.Errorf("mfa_process '%s' did not print a 6-digit code", command)
+	}
+	return code, nil
+}
+
+// automaticMFACode gets an MFA code without asking the user, from the
+// profile's mfa_process or a TOTP secret in the keychain. ok is false when the
+// profile has neither.
+func automaticMFACode(pConfig *profileConfig) (code string, ok bool, err error) {
+	if pConfig.MfaProcess != "" {
+		code, err := runMFAProcess(pConfig.MfaProcess)
+		return code, true, err
+	}
+	secret, err := keychain.Get(totpAccount(pConfig.MfaSerial))
+	if err != nil {
+		if errors.Is(err, keychain.ErrNotFound) || errors.Is(err, keychain.ErrUnsupported) {
+			return "", false, nil
+		}
+		return "", true, err
+	}
+	code, err = totpCode(secret, time.Now())
+	return code, true, err
+}
+
+// hasAutomaticMFA reports whether codes for a profile come without a prompt.
+func hasAutomaticMFA(pConfig *profileConfig) bool {
+	return pConfig.MfaProcess != "" || hasTOTPSecret(pConfig.MfaSerial)
+}
+
+// resolveMFACode returns the MFA code to send for a profile: the one given on
+// the command line, one obtained automatically, or one typed at a prompt.
+func resolveMFACode(pConfig *profileConfig, given string) (string, error) {
+	if given != "" {
+		return given, nil
+	}
+	code, ok, err := automaticMFACode(pConfig)
+	if ok {
+		return code, err
+	}
+	prompt := fmt.Sprintf("Enter MFA token for %s: ", util.BoldColor.Sprint(pConfig.MfaSerial))
+	code, err = util.PromptForInput(prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to read MFA token: %w", err)
+	}
+	return code, nil
+}
+
+// MFACode returns the current MFA code of a profile from its mfa_process or
+// TOTP secret, for 'awsm mfa code'.
+func MFACode(profileName string) (string, error) {
+	pConfig, _, err := inspectProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	if pConfig.MfaSerial == "" {
+		return "", fmt.Errorf("profile '%s' has no mfa_serial", profileName)
+	}
+	code, ok, err := automaticMFACode(pConfig)
+	if !ok {
+		return "", fmt.Errorf("profile '%s' has no mfa_process and no TOTP secret is stored for %s", profileName, pConfig.MfaSerial)
+	}
+	return code, err
+}
+
+// ProfileMFASerial returns the mfa_serial of a profile.
+func ProfileMFASerial(profileName string) (string, error) {
+	pConfig, _, err := inspectProfile(profileName)
+	if err != nil {
+		return "", err
+	}
+	if pConfig.MfaSerial == "" {
+		return "", fmt.Errorf("profile '%s' has no mfa_serial", profileName)
+	}
+	return pConfig.MfaSerial, nil
+}
@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 SHA-1 test vectors, truncated to the 6 digits AWS uses
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" // "12345678901234567890"
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(secret, time.Unix(tt.unix, 0))
+		if err != nil || got != tt.expected {
+			t.Errorf("totpCode at %d = %s, %v; expected %s", tt.unix, got, err, tt.expected)
+		}
+	}
+}
+
+func TestParseTOTPSecret(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+		wantErr  bool
+	}{
+		{"GEZDGNBVGY3TQOJQ", "GEZDGNBVGY3TQOJQ", false},
+		{"gezd gnbv gy3t qojq", "GEZDGNBVGY3TQOJQ", false},
+		{"JBSWY3DPEHPK3PXP====", "JBSWY3DPEHPK3PXP", false},
+		{"otpauth://totp/Amazon%20Web%20Services:jane@123456789012?secret=JBSWY3DPEHPK3PXP&issuer=Amazon%20Web%20Services", "JBSWY3DPEHPK3PXP", false},
+		{"otpauth://totp/x?issuer=y", "", true},
+		{"not base32!", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTOTPSecret(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseTOTPSecret(%q) = %q, %v; expected %q", tt.value, got, err, tt.expected)
+		}
+	}
+}
+
+func TestResolveMFACode(t *testing.T) {
+	pConfig := &profileConfig{MfaSerial: "arn:aws:iam::123456789012:mfa/jane", MfaProcess: "echo 123456"}
+
+	if code, err := resolveMFACode(pConfig, "654321"); err != nil || code != "654321" {
+		t.Errorf("Expected the given code to win, got %s, %v", code, err)
+	}
+	if code, err := resolveMFACode(pConfig, ""); err != nil || code != "123456" {
+		t.Errorf("Expected the mfa_process code, got %s, %v", code, err)
+	}
+
+	pConfig.MfaProcess = "echo not-a-code"
+	if _, err := resolveMFACode(pConfig, ""); err == nil {
+		t.Error("Expected an error for mfa_process output that isn't a code")
+	}
+	pConfig.MfaProcess = "exit 3"
+	if _, err := resolveMFACode(pConfig, ""); err == nil {
+		t.Error("Expected an error for a failing mfa_process")
+	}
+}
@@ -0,0 +1,140 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// Opt-in states of a region, as reported by EC2 DescribeRegions.
+const (
+	RegionOptInNotRequired = "opt-in-not-required"
+	RegionOptedIn          = "opted-in"
+	RegionNotOptedIn       = "not-opted-in"
+)
+
+// RegionInfo describes a region for 'awsm regions'.
+type RegionInfo struct {
+	Name        string
+	Description string
+	// OptInStatus is one of the RegionOptIn constants, empty when unknown.
+	OptInStatus string
+	// Latency is the time to open a connection to the regional EC2 endpoint,
+	// zero when not measured or unreachable.
+	Latency time.Duration
+}
+
+// Enabled reports whether the account can use the region. Regions with an
+// unknown status count as enabled.
+func (r RegionInfo) Enabled() bool {
+	return r.OptInStatus != RegionNotOptedIn
+}
+
+// RegionOptInStatus asks EC2 which regions are enabled for the account of a
+// profile. An empty profile uses the default credentials.
+func RegionOptInStatus(profileName string) (map[string]string, error) {
+	region, _ := GetProfileRegion(profileName)
+	if region == "" {
+		region = "us-east-1"
+	}
+	opts := []func(*config.LoadOptions) error{config.WithRegion(region), withConfigFragments()}
+	if profileName != "" {
+		opts = append(opts, config.WithSharedConfigProfile(profileName))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	out, err := ec2.NewFromConfig(awsCfg).DescribeRegions(ctx, &ec2.DescribeRegionsInput{AllRegions: aws.Bool(true)})
+	if err != nil {
+		if profileName == "" {
+			profileName = "default"
+		}
+		return nil, WrapProfileError("describe regions", profileName, err)
+	}
+	status := make(map[string]string, len(out.Regions))
+	for _, r := range out.Regions {
+		status[aws.ToString(r.RegionName)] = aws.ToString(r.OptInStatus)
+	}
+	return status, nil
+}
+
+// ListRegionInfo returns the regions of the standard partition with their
+// location, plus any region in status that the metadata doesn't know yet.
+// status may be nil.
+func ListRegionInfo(status map[string]string) []RegionInfo {
+	names := GetAllRegions()
+	for name := range status {
+		if !IsValidRegion(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	regions := make([]RegionInfo, 0, len(names))
+	for _, name := range names {
+		regions = append(regions, RegionInfo{Name: name, Description: RegionDescription(name), OptInStatus: status[name]})
+	}
+	return regions
+}
+
+// regionEndpointDialer opens the connections MeasureRegionLatency times.
+var regionEndpointDialer = func(ctx context.Context, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", address)
+}
+
+// MeasureRegionLatency times a connection to the EC2 endpoint of every region
+// at once and stores it in Latency. Regions that don't answer within timeout
+// keep a zero Latency.
+func MeasureRegionLatency(regions []RegionInfo, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i := range regions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			regions[i].Latency = regionLatency(regions[i].Name, timeout)
+		}()
+	}
+	wg.Wait()
+}
+
+func regionLatency(region string, timeout time.Duration) time.Duration {
+	suffix := "amazonaws.com"
+	if p := GetMetadata().PartitionForRegion(region); p != nil && p.DNSSuffix != "" {
+		suffix = p.DNSSuffix
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := regionEndpointDialer(ctx, net.JoinHostPort(fmt.Sprintf("ec2.%s.%s", region, suffix), "443"))
+	if err != nil {
+		return 0
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+	return max(elapsed, time.Microsecond)
+}
+
+// SortRegionsByLatency orders regions from the closest to the farthest, with
+// unreachable ones last.
+func SortRegionsByLatency(regions []RegionInfo) {
+	sort.SliceStable(regions, func(i, j int) bool {
+		a, b := regions[i].Latency, regions[j].Latency
+		if a == 0 || b == 0 {
+			return b == 0 && a != 0
+		}
+		return a < b
+	})
+}
@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func TestListRegionInfo(t *testing.T) {
+	resetMetadata()
+	t.Cleanup(resetMetadata)
+	t.Setenv(awsmConfig.HomeEnv, t.TempDir())
+
+	regions := ListRegionInfo(map[string]string{
+		"us-east-1":   RegionOptInNotRequired,
+		"af-south-1":  RegionNotOptedIn,
+		"zz-future-1": RegionOptedIn,
+	})
+	byName := make(map[string]RegionInfo)
+	var names []string
+	for _, r := range regions {
+		byName[r.Name] = r
+		names = append(names, r.Name)
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Expected regions sorted by name, got %v", names)
+	}
+	if r := byName["zz-future-1"]; r.OptInStatus != RegionOptedIn {
+		t.Errorf("Expected regions unknown to the metadata to be listed, got %+v", r)
+	}
+	if r := byName["af-south-1"]; r.Enabled() || r.Description != "Africa (Cape Town)" {
+		t.Errorf("Expected af-south-1 to be disabled and described, got %+v", r)
+	}
+	if r := byName["eu-west-1"]; !r.Enabled() || r.OptInStatus != "" {
+		t.Errorf("Expected a region without status to count as enabled, got %+v", r)
+	}
+}
+
+func TestMeasureRegionLatency(t *testing.T) {
+	old := regionEndpointDialer
+	t.Cleanup(func() { regionEndpointDialer = old })
+	var dialed []string
+	regionEndpointDialer = func(ctx context.Context, address string) (net.Conn, error) {
+		if strings.Contains(address, "cn-north-1") {
+			dialed = append(dialed, address)
+		}
+		if strings.Contains(address, "eu-west-1") {
+			return nil, errors.New("unreachable")
+		}
+		if strings.Contains(address, "us-east-1") {
+			time.Sleep(20 * time.Millisecond)
+		}
+		client, server := net.Pipe()
+		server.Close()
+		return client, nil
+	}
+
+	regions := []RegionInfo{{Name: "us-east-1"}, {Name: "eu-west-1"}, {Name: "eu-central-1"}}
+	MeasureRegionLatency(regions, time.Second)
+	if regions[1].Latency != 0 {
+		t.Errorf("Expected an unreachable region to have no latency, got %v", regions[1].Latency)
+	}
+	if regions[0].Latency < 20*time.Millisecond || regions[2].Latency == 0 {
+		t.Errorf("Unexpected latencies: %+v", regions)
+	}
+
+	SortRegionsByLatency(regions)
+	var order []string
+	for _, r := range regions {
+		order = append(order, r.Name)
+	}
+	if want := []string{"eu-central-1", "us-east-1", "eu-west-1"}; !slices.Equal(order, want) {
+		t.Errorf("Expected %v, got %v", want, order)
+	}
+
+	// China regions are reached through their own DNS suffix
+	MeasureRegionLatency([]RegionInfo{{Name: "cn-north-1"}}, time.Second)
+	if len(dialed) != 1 || dialed[0] != "ec2.cn-north-1.amazonaws.com.cn:443" {
+		t.Errorf("Expected the China endpoint to be dialed, got %v", dialed)
+	}
+}
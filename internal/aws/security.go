@@ -0,0 +1,155 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	awsmConfig "awsm/internal/config"
+)
+
+// SecurityFinding is a single issue found by ReviewSecurity.
+type SecurityFinding struct {
+	Title  string
+	Detail string
+	// FixDescription and Fix are empty when the issue cannot be fixed automatically.
+	FixDescription string
+	Fix            func() error
+	// Destructive fixes delete data and are only applied after an explicit yes.
+	Destructive bool
+}
+
+// backupSuffixes are file name suffixes of common editor and tool backups.
+var backupSuffixes = []string{".bak", ".backup", ".old", ".orig", "~"}
+
+// ReviewSecurity inspects the local AWS and awsm files for common security issues.
+func ReviewSecurity() ([]SecurityFinding, error) {
+	findings, err := FilePermissionFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if staticProfiles := listStaticKeyProfiles(credentialsPath); len(staticProfiles) > 0 {
+		findings = append(findings, SecurityFinding{
+			Title:  fmt.Sprintf("%d profile(s) store long-term access keys in plaintext", len(staticProfiles)),
+			Detail: fmt.Sprintf("%s (in %s). Prefer SSO or role profiles where possible.", strings.Join(staticProfiles, ", "), credentialsPath),
+		})
+	}
+
+	backups := findCredentialBackups(filepath.Dir(credentialsPath))
+	if len(backups) > 0 {
+		findings = append(findings, SecurityFinding{
+			Title:          fmt.Sprintf("%d old backup file(s) may contain credentials", len(backups)),
+			Detail:         strings.Join(backups, "\n"),
+			FixDescription: "Delete the backup files",
+			Destructive:    true,
+			Fix: func() error {
+				for _, path := range backups {
+					if err := os.Remove(path); err != nil {
+						return fmt.Errorf("failed to delete %s: %w", path, err)
+					}
+				}
+				return nil
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+// FilePermissionFindings reports the credentials file and awsm's cache
+// directory when other users can read them.
+func FilePermissionFindings() ([]SecurityFinding, error) {
+	var findings []SecurityFinding
+
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	if f := checkFileMode(credentialsPath, 0600); f != nil {
+		findings = append(findings, *f)
+	}
+
+	stateDir, err := awsmConfig.StateDir()
+	if err != nil {
+		return nil, err
+	}
+	if f := checkFileMode(filepath.Join(stateDir, "cache"), 0700); f != nil {
+		findings = append(findings, *f)
+	}
+	return findings, nil
+}
+
+// checkFileMode reports a finding when path is accessible by group or others.
+// Permissions are not checked on Windows where mode bits are not meaningful.
+func checkFileMode(path string, want os.FileMode) *SecurityFinding {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	mode := info.Mode().Perm()
+	if mode&0077 == 0 {
+		return nil
+	}
+	return &SecurityFinding{
+		Title:          fmt.Sprintf("%s is readable by other users", path),
+		Detail:         fmt.Sprintf("Current permissions are %#o.", mode),
+		FixDescription: fmt.Sprintf("Restrict permissions to %#o", want),
+		Fix: func() error {
+			return os.Chmod(path, want)
+		},
+	}
+}
+
+// listStaticKeyProfiles returns credentials file profiles holding long-term keys,
+// i.e. an access key without a session token.
+func listStaticKeyProfiles(credentialsPath string) []string {
+	credFile, err := loadIni(credentialsPath)
+	if err != nil {
+		return nil
+	}
+	var profiles []string
+	for _, section := range credFile.Sections() {
+		if !section.HasKey("aws_access_key_id") || section.Key("aws_session_token").String() != "" {
+			continue
+		}
+		// The default section is managed by awsm and mirrors another profile
+		if section.Name() == "default" && GetCurrentProfileName() != "" {
+			continue
+		}
+		profiles = append(profiles, section.Name())
+	}
+	return profiles
+}
+
+// findCredentialBackups returns backup copies of the credentials file in dir.
+func findCredentialBackups(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "credentials") {
+			continue
+		}
+		for _, suffix := range backupSuffixes {
+			if strings.HasSuffix(name, suffix) {
+				backups = append(backups, filepath.Join(dir, name))
+				break
+			}
+		}
+	}
+	return backups
+}
@@ -0,0 +1,89 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestReviewSecurity(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	awsDir := filepath.Join(home, ".aws")
+	if err := os.MkdirAll(awsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	credentialsPath := filepath.Join(awsDir, "credentials")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+
+	content := `[static]
+aws_access_key_id = AKIA123
+aws_secret_access_key = secret
+
+[temporary]
+aws_access_key_id = ASIA123
+aws_secret_access_key = secret
+aws_session_token = token
+`
+	if err := os.WriteFile(credentialsPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentialsPath+".bak", []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := ReviewSecurity()
+	if err != nil {
+		t.Fatalf("ReviewSecurity failed: %v", err)
+	}
+	expected := 3
+	if runtime.GOOS == "windows" {
+		expected = 2
+	}
+	if len(findings) != expected {
+		t.Fatalf("Expected %d findings, got %d: %+v", expected, len(findings), findings)
+	}
+
+	for _, f := range findings {
+		if f.Fix != nil {
+			if err := f.Fix(); err != nil {
+				t.Fatalf("Fix %q failed: %v", f.Title, err)
+			}
+		}
+	}
+
+	findings, err = ReviewSecurity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 {
+		t.Errorf("Expected only the static key finding after fixes, got %+v", findings)
+	}
+}
+
+func TestListStaticKeyProfilesDefault(t *testing.T) {
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+
+	static := "[static]\naws_access_key_id = AKIA123\naws_secret_access_key = secret\n\n"
+
+	// A default section written by awsm mirrors another profile and is not reported again
+	managed := static + "[default]\n# source_profile = static\naws_access_key_id = AKIA123\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(credentialsPath, []byte(managed), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if profiles := listStaticKeyProfiles(credentialsPath); len(profiles) != 1 || profiles[0] != "static" {
+		t.Errorf("Expected the awsm-managed default to be skipped, got %v", profiles)
+	}
+
+	// A hand-written default section with long-term keys is reported
+	manual := static + "[default]\naws_access_key_id = AKIA456\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(credentialsPath, []byte(manual), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if profiles := listStaticKeyProfiles(credentialsPath); len(profiles) != 2 {
+		t.Errorf("Expected a hand-written default to be reported, got %v", profiles)
+	}
+}
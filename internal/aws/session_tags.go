@@ -0,0 +1,41 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+
+	awsmConfig "awsm/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts/types"
+)
+
+// profileSessionTags returns the session tags set for a profile with 'awsm
+// profile session-tags', sorted by key, to be passed on every AssumeRole.
+func profileSessionTags(profileName string) ([]types.Tag, error) {
+	all, err := awsmConfig.LoadSessionTags()
+	if err != nil {
+		return nil, err
+	}
+	tags := all[profileName]
+	if len(tags) > awsmConfig.MaxSessionTags {
+		return nil, fmt.Errorf("profile '%s' has %d session tags, STS accepts at most %d", profileName, len(tags), awsmConfig.MaxSessionTags)
+	}
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]types.Tag, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, types.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return result, nil
+}
+
+// IsRoleProfile reports whether a profile assumes a role, the only case where
+// session tags are passed.
+func IsRoleProfile(profileName string) bool {
+	pConfig, _, err := inspectProfile(profileName)
+	return err == nil && pConfig.RoleArn != ""
+}
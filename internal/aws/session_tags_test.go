@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"testing"
+
+	awsmConfig "awsm/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestProfileSessionTags(t *testing.T) {
+	t.Setenv(awsmConfig.HomeEnv, t.TempDir())
+
+	tags := awsmConfig.ProfileTags{}
+	tags.Set("prod-admin", "team", "platform")
+	tags.Set("prod-admin", "cost-center", "1234")
+	if err := awsmConfig.SaveSessionTags(tags); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := profileSessionTags("prod-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || aws.ToString(got[0].Key) != "cost-center" || aws.ToString(got[1].Value) != "platform" {
+		t.Errorf("expected the tags sorted by key, got %+v", got)
+	}
+	if none, err := profileSessionTags("dev"); err != nil || len(none) != 0 {
+		t.Errorf("expected no tags for an untagged profile, got %v, %v", none, err)
+	}
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// secretProfileKeys are never returned by EffectiveProfileSettings.
+var secretProfileKeys = map[string]bool{
+	"aws_secret_access_key": true,
+	"aws_session_token":     true,
+}
+
+// EffectiveProfileSettings returns the settings a profile resolves to: its keys
+// from the config files and the credentials file, plus derived values under
+// names starting with "awsm:" (type, account, role and the SSO session it
+// gets credentials from, even through source_profile). Secrets are left out.
+func EffectiveProfileSettings(profileName string) (map[string]string, error) {
+	settings := make(map[string]string)
+	found := false
+	var profileType ProfileType
+
+	cfg, err := loadMergedConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read AWS config file: %w", err)
+	}
+	if cfg != nil {
+		if section, err := getProfileSection(cfg, profileName); err == nil {
+			found = true
+			addSectionSettings(settings, section)
+			profileType = getProfileType(section)
+		}
+	}
+
+	if credentialsPath, err := GetAWSCredentialsPath(); err == nil {
+		if credCfg, err := loadIni(credentialsPath); err == nil {
+			if section, err := credCfg.GetSection(profileName); err == nil {
+				found = true
+				addSectionSettings(settings, section)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("profile '%s' not found", profileName)
+	}
+
+	if profileType == "" {
+		profileType = ProfileTypeKey
+	}
+	settings["awsm:type"] = string(profileType)
+	accountID, roleName := profileAccountContext(profileName)
+	if accountID != "" {
+		settings["awsm:account"] = accountID
+	}
+	if roleName != "" {
+		settings["awsm:role"] = roleName
+	}
+	if session, err := GetSsoSessionForProfile(profileName); err == nil {
+		settings["awsm:sso_session"] = session
+	}
+	return settings, nil
+}
+
+func addSectionSettings(settings map[string]string, section *ini.Section) {
+	for _, key := range section.Keys() {
+		name := strings.ToLower(key.Name())
+		if secretProfileKeys[name] {
+			continue
+		}
+		settings[name] = key.Value()
+	}
+}
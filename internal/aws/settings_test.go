@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEffectiveProfileSettings(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+
+	config := "[sso-session corp]\nsso_region = us-east-1\n\n[profile base]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = Admin\n\n" +
+		"[profile deploy]\nrole_arn = arn:aws:iam::222222222222:role/Deploy\nsource_profile = base\nduration_seconds = 7200\nregion = eu-west-1\n"
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	credentials := "[static]\naws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = secret\n"
+	if err := os.WriteFile(credentialsPath, []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := EffectiveProfileSettings("deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"role_arn":         "arn:aws:iam::222222222222:role/Deploy",
+		"source_profile":   "base",
+		"duration_seconds": "7200",
+		"region":           "eu-west-1",
+		"awsm:type":        "IAM",
+		"awsm:account":     "222222222222",
+		"awsm:role":        "Deploy",
+		"awsm:sso_session": "corp",
+	}
+	if len(settings) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, settings)
+	}
+	for key, value := range expected {
+		if settings[key] != value {
+			t.Errorf("Expected %s = %q, got %q", key, value, settings[key])
+		}
+	}
+
+	static, err := EffectiveProfileSettings("static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := static["aws_secret_access_key"]; ok || static["aws_access_key_id"] != "AKIAEXAMPLE" || static["awsm:type"] != "Key" {
+		t.Errorf("Unexpected static profile settings %v", static)
+	}
+
+	if _, err := EffectiveProfileSettings("missing"); err == nil {
+		t.Error("Expected an error for a missing profile")
+	}
+}
@@ -0,0 +1,249 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sso"
+)
+
+const (
+	// ssoMaxAttempts is the number of attempts made for a single SSO API call
+	// before a throttling error is surfaced to the caller.
+	ssoMaxAttempts = 10
+	// ssoMaxBackoff caps the delay between two retries of the same call.
+	ssoMaxBackoff = 20 * time.Second
+)
+
+// NewSSOClient creates an SSO portal client for the given region.
+// The client uses the adaptive retry mode so that ListAccounts/ListAccountRoles
+// slow down when Identity Center starts throttling instead of failing outright,
+// which matters for organizations with hundreds of accounts.
+func NewSSOClient(region string) (*sso.Client, error) {
+	cfg, err := config.LoadDefaultConfig(context.TODO(),
+		config.WithRegion(region),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, func(so *retry.StandardOptions) {
+					so.MaxAttempts = ssoMaxAttempts
+					so.MaxBackoff = ssoMaxBackoff
+				})
+			})
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not create basic AWS config: %w", err)
+	}
+	return sso.NewFromConfig(cfg), nil
+}
+
+// ssoTokenStatusTTL is how long a token status is reused before the cache file is read again.
+const ssoTokenStatusTTL = 60 * time.Second
+
+// SSOTokenStatus describes the cached SSO token of an sso-session.
+type SSOTokenStatus struct {
+	Session         string
+	ExpiresAt       time.Time
+	HasRefreshToken bool
+	// NeedsLogin is true when the access token has expired and cannot be refreshed silently.
+	NeedsLogin bool
+}
+
+// ssoTokenFile mirrors the fields of the AWS CLI token cache that awsm cares about.
+type ssoTokenFile struct {
+	AccessToken           string    `json:"accessToken"`
+	ExpiresAt             time.Time `json:"expiresAt"`
+	RefreshToken          string    `json:"refreshToken"`
+	RegistrationExpiresAt time.Time `json:"registrationExpiresAt"`
+	Region                string    `json:"region"`
+}
+
+type cachedTokenStatus struct {
+	status  *SSOTokenStatus
+	checked time.Time
+}
+
+var (
+	ssoTokenStatusMu    sync.Mutex
+	ssoTokenStatusCache = make(map[string]cachedTokenStatus)
+)
+
+// SSOTokenCachePath returns the path of the AWS CLI token cache file for an sso-session,
+// which is named after the SHA-1 of the session name.
+func SSOTokenCachePath(ssoSession string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	sum := sha1.Sum([]byte(ssoSession))
+	return filepath.Join(home, ".aws", "sso", "cache", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// GetSSOTokenStatus inspects the cached token of an sso-session without calling AWS.
+// Results are cached per session for a short time so repeated checks stay cheap.
+func GetSSOTokenStatus(ssoSession string) (*SSOTokenStatus, error) {
+	ssoTokenStatusMu.Lock()
+	defer ssoTokenStatusMu.Unlock()
+
+	if cached, ok := ssoTokenStatusCache[ssoSession]; ok && time.Since(cached.checked) < ssoTokenStatusTTL {
+		return cached.status, nil
+	}
+
+	path, err := SSOTokenCachePath(ssoSession)
+	if err != nil {
+		return nil, err
+	}
+	status := &SSOTokenStatus{Session: ssoSession, NeedsLogin: true}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read SSO token cache: %w", err)
+	}
+	if err == nil {
+		var token ssoTokenFile
+		if err := json.Unmarshal(data, &token); err != nil {
+			return nil, fmt.Errorf("failed to parse SSO token cache %s: %w", path, err)
+		}
+		now := time.Now()
+		status.ExpiresAt = token.ExpiresAt
+		status.HasRefreshToken = token.RefreshToken != ""
+		canRefresh := status.HasRefreshToken && (token.RegistrationExpiresAt.IsZero() || now.Before(token.RegistrationExpiresAt))
+		status.NeedsLogin = token.AccessToken == "" || (!now.Before(token.ExpiresAt) && !canRefresh)
+	}
+
+	ssoTokenStatusCache[ssoSession] = cachedTokenStatus{status: status, checked: time.Now()}
+	return status, nil
+}
+
+// CachedSSOAccessToken returns the cached access token of an sso-session
+// without logging in or refreshing it, for read-only checks. It fails when the
+// token is missing or expired.
+func CachedSSOAccessToken(ssoSession string) (string, error) {
+	path, err := SSOTokenCachePath(ssoSession)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("SSO session '%s' is not logged in", ssoSession)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read SSO token cache: %w", err)
+	}
+	var token ssoTokenFile
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("failed to parse SSO token cache %s: %w", path, err)
+	}
+	if token.AccessToken == "" || !time.Now().Before(token.ExpiresAt) {
+		return "", fmt.Errorf("the token of SSO session '%s' has expired", ssoSession)
+	}
+	return token.AccessToken, nil
+}
+
+// InvalidateSSOTokenStatus drops the cached token status of a session, e.g. after a login.
+func InvalidateSSOTokenStatus(ssoSession string) {
+	ssoTokenStatusMu.Lock()
+	defer ssoTokenStatusMu.Unlock()
+	delete(ssoTokenStatusCache, ssoSession)
+}
+
+// SSOLogoutResult summarizes what 'awsm sso logout' cleared for a session.
+type SSOLogoutResult struct {
+	Session string
+	// RemoteLogout is true when the access token was revoked with AWS.
+	RemoteLogout bool
+	// RemoteError explains why the token could not be revoked, if it was not.
+	RemoteError    error
+	TokenRemoved   bool
+	CachedProfiles []string
+	// DefaultCleared is the profile whose credentials were removed from [default].
+	DefaultCleared string
+}
+
+// LogoutSSOSession revokes the cached access token of an sso-session where
+// possible, deletes the token cache file and the awsm credential cache of the
+// session's profiles, and clears the default credentials if they came from it.
+func LogoutSSOSession(ssoSession string) (*SSOLogoutResult, error) {
+	result := &SSOLogoutResult{Session: ssoSession}
+
+	path, err := SSOTokenCachePath(ssoSession)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read SSO token cache: %w", err)
+	}
+	if err == nil {
+		var token ssoTokenFile
+		if jsonErr := json.Unmarshal(data, &token); jsonErr == nil && token.AccessToken != "" && time.Now().Before(token.ExpiresAt) {
+			result.RemoteError = revokeSSOToken(ssoSession, token)
+			result.RemoteLogout = result.RemoteError == nil
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove SSO token cache %s: %w", path, err)
+		}
+		result.TokenRemoved = true
+	}
+	InvalidateSSOTokenStatus(ssoSession)
+
+	index, err := GetProfileIndex()
+	if err != nil {
+		return nil, err
+	}
+	current := GetCurrentProfileName()
+	for _, profile := range index.ProfilesUsingSSO(ssoSession) {
+		if cachePath, err := credsCachePath(profile); err == nil {
+			if err := os.Remove(cachePath); err == nil {
+				result.CachedProfiles = append(result.CachedProfiles, profile)
+			}
+		}
+		if profile == current {
+			if err := ClearDefaultProfile(); err != nil {
+				return nil, fmt.Errorf("failed to clear default credentials: %w", err)
+			}
+			result.DefaultCleared = profile
+		}
+	}
+	return result, nil
+}
+
+// revokeSSOToken calls the SSO portal Logout API with the session's access token.
+func revokeSSOToken(ssoSession string, token ssoTokenFile) error {
+	region := token.Region
+	if region == "" {
+		sessions, err := ListSSOSessions()
+		if err != nil {
+			return err
+		}
+		for _, s := range sessions {
+			if s.Name == ssoSession {
+				region = s.Region
+			}
+		}
+	}
+	if region == "" {
+		return fmt.Errorf("unknown SSO region for session '%s'", ssoSession)
+	}
+
+	client, err := NewSSOClient(region)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := client.Logout(ctx, &sso.LogoutInput{AccessToken: aws.String(token.AccessToken)}); err != nil {
+		return fmt.Errorf("failed to revoke SSO token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"awsm/internal/browser"
+	awsmConfig "awsm/internal/config"
+	"awsm/internal/util"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// ssoDeviceClient is the part of the SSO OIDC client used by the device flow.
+type ssoDeviceClient interface {
+	ssoTokenRefresher
+	RegisterClient(ctx context.Context, params *ssooidc.RegisterClientInput, optFns ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error)
+	StartDeviceAuthorization(ctx context.Context, params *ssooidc.StartDeviceAuthorizationInput, optFns ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error)
+}
+
+// PerformNativeSSOLogin logs in to an SSO session with the OIDC device
+// authorization flow and writes the token cache the AWS CLI and SDKs read, so
+// no AWS CLI is needed. The verification page opens like with 'aws sso login'.
+func PerformNativeSSOLogin(ssoSession string) error {
+	session, err := findSSOSession(ssoSession)
+	if err != nil {
+		return err
+	}
+	path, err := SSOTokenCachePath(ssoSession)
+	if err != nil {
+		return err
+	}
+
+	noBrowser := SSONoBrowser || awsmConfig.GetSSONoBrowser()
+	chromeProfile := awsmConfig.GetSSOChromeProfile()
+	notify := func(verificationURL, userCode string) {
+		util.InfoColor.Fprintf(os.Stderr, "Logging in to SSO session %s.\n", util.BoldColor.Sprint(ssoSession))
+		fmt.Fprintf(os.Stderr, "\nOpen this URL and confirm the code %s:\n%s\n\n", util.BoldColor.Sprint(userCode), verificationURL)
+		if noBrowser {
+			return
+		}
+		if err := browser.OpenURL(verificationURL, chromeProfile, "", ""); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not open the browser, open the URL above yourself.")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	client := ssooidc.New(ssooidc.Options{Region: session.Region})
+	token, err := deviceLogin(ctx, client, session, notify, time.Now)
+	if err != nil {
+		return err
+	}
+	if err := writeSSOTokenCache(path, token); err != nil {
+		return err
+	}
+	InvalidateSSOTokenStatus(ssoSession)
+	util.SuccessColor.Fprintln(os.Stderr, "✔ SSO login successful.")
+	return nil
+}
+
+// findSSOSession returns the configuration of an sso-session.
+func findSSOSession(ssoSession string) (SSOSessionInfo, error) {
+	sessions, err := ListSSOSessions()
+	if err != nil {
+		return SSOSessionInfo{}, err
+	}
+	for _, s := range sessions {
+		if s.Name == ssoSession {
+			if s.StartURL == "" || s.Region == "" {
+				return SSOSessionInfo{}, fmt.Errorf("SSO session '%s' needs sso_start_url and sso_region", ssoSession)
+			}
+			return s, nil
+		}
+	}
+	return SSOSessionInfo{}, fmt.Errorf("SSO session '%s' not found", ssoSession)
+}
+
+// deviceLogin runs the device authorization flow: register a client, start
+// the authorization, let notify show the page to confirm and poll for the
+// token. It returns the token cache content.
+func deviceLogin(ctx context.Context, client ssoDeviceClient, session SSOSessionInfo, notify func(verificationURL, userCode string), now func() time.Time) (map[string]any, error) {
+	var scopes []string
+	for _, scope := range strings.Split(session.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	registration, err := client.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("awsm"),
+		ClientType: aws.String("public"),
+		Scopes:     scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register SSO client: %w", err)
+	}
+	authorization, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(session.StartURL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start SSO device authorization: %w", err)
+	}
+
+	verificationURL := aws.ToString(authorization.VerificationUriComplete)
+	if verificationURL == "" {
+		verificationURL = aws.ToString(authorization.VerificationUri)
+	}
+	notify(verificationURL, aws.ToString(authorization.UserCode))
+
+	interval := time.Duration(authorization.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		out, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     registration.ClientId,
+			ClientSecret: registration.ClientSecret,
+			DeviceCode:   authorization.DeviceCode,
+			GrantType:    aws.String(deviceCodeGrantType),
+		})
+		if err == nil {
+			issued := now()
+			token := map[string]any{
+				"startUrl":              session.StartURL,
+				"region":                session.Region,
+				"accessToken":           aws.ToString(out.AccessToken),
+				"expiresAt":             issued.Add(time.Duration(out.ExpiresIn) * time.Second).UTC().Format(time.RFC3339),
+				"clientId":              aws.ToString(registration.ClientId),
+				"clientSecret":          aws.ToString(registration.ClientSecret),
+				"registrationExpiresAt": time.Unix(registration.ClientSecretExpiresAt, 0).UTC().Format(time.RFC3339),
+			}
+			if refreshToken := aws.ToString(out.RefreshToken); refreshToken != "" {
+				token["refreshToken"] = refreshToken
+			}
+			return token, nil
+		}
+
+		var pending *types.AuthorizationPendingException
+		var slowDown *types.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+		case errors.As(err, &slowDown):
+			interval += 5 * time.Second
+		default:
+			return nil, fmt.Errorf("SSO login failed: %w", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("SSO login timed out waiting for the code to be confirmed")
+		case <-time.After(interval):
+		}
+	}
+}
+
+// writeSSOTokenCache writes a token cache file readable only by the user.
+func writeSSOTokenCache(path string, token map[string]any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create SSO token cache directory: %w", err)
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write SSO token cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+)
+
+type fakeDeviceClient struct {
+	pending  int
+	scopes   []string
+	startURL string
+	polls    int
+}
+
+func (f *fakeDeviceClient) RegisterClient(ctx context.Context, params *ssooidc.RegisterClientInput, optFns ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error) {
+	f.scopes = params.Scopes
+	return &ssooidc.RegisterClientOutput{
+		ClientId:              aws.String("client"),
+		ClientSecret:          aws.String("secret"),
+		ClientSecretExpiresAt: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC).Unix(),
+	}, nil
+}
+
+func (f *fakeDeviceClient) StartDeviceAuthorization(ctx context.Context, params *ssooidc.StartDeviceAuthorizationInput, optFns ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error) {
+	f.startURL = aws.ToString(params.StartUrl)
+	return &ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode:              aws.String("device"),
+		UserCode:                aws.String("ABCD-EFGH"),
+		VerificationUriComplete: aws.String("https://device.sso.eu-west-1.amazonaws.com/?user_code=ABCD-EFGH"),
+		Interval:                1,
+	}, nil
+}
+
+func (f *fakeDeviceClient) CreateToken(ctx context.Context, params *ssooidc.CreateTokenInput, optFns ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error) {
+	f.polls++
+	if f.polls <= f.pending {
+		return nil, &types.AuthorizationPendingException{}
+	}
+	return &ssooidc.CreateTokenOutput{
+		AccessToken:  aws.String("access"),
+		RefreshToken: aws.String("refresh"),
+		ExpiresIn:    28800,
+	}, nil
+}
+
+func TestDeviceLogin(t *testing.T) {
+	client := &fakeDeviceClient{pending: 1}
+	session := SSOSessionInfo{Name: "corp", StartURL: "https://corp.awsapps.com/start", Region: "eu-west-1", Scopes: "sso:account:access"}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var shownURL, shownCode string
+	notify := func(url, code string) { shownURL, shownCode = url, code }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	token, err := deviceLogin(ctx, client, session, notify, func() time.Time { return now })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if client.startURL != session.StartURL || len(client.scopes) != 1 || client.scopes[0] != "sso:account:access" {
+		t.Errorf("Unexpected registration: start URL %s, scopes %v", client.startURL, client.scopes)
+	}
+	if shownCode != "ABCD-EFGH" || shownURL == "" {
+		t.Errorf("Expected the verification URL and code to be shown, got %q %q", shownURL, shownCode)
+	}
+	if client.polls != 2 {
+		t.Errorf("Expected polling to continue while authorization is pending, got %d polls", client.polls)
+	}
+
+	want := map[string]any{
+		"startUrl":              "https://corp.awsapps.com/start",
+		"region":                "eu-west-1",
+		"accessToken":           "access",
+		"refreshToken":          "refresh",
+		"expiresAt":             "2025-06-01T20:00:00Z",
+		"clientId":              "client",
+		"clientSecret":          "secret",
+		"registrationExpiresAt": "2025-09-01T00:00:00Z",
+	}
+	for key, value := range want {
+		if token[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, token[key])
+		}
+	}
+}
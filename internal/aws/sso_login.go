@@ -0,0 +1,70 @@
+package aws
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// SSONoBrowser makes SSO logins print the verification URL and code instead of
+// opening a browser, for remote or headless sessions. Set by --no-browser.
+var SSONoBrowser bool
+
+// ssoLoginArgs returns the `aws` arguments that log in to an SSO session. With
+// noBrowser the AWS CLI only prints the login URL and code.
+func ssoLoginArgs(ssoSession string, noBrowser bool) []string {
+	args := []string{"sso", "login", "--sso-session", ssoSession}
+	if noBrowser {
+		args = append(args, "--no-browser")
+	}
+	return args
+}
+
+// ssoLoginURLOpener passes the output of `aws sso login --no-browser` through
+// and opens the login page it prints, so awsm decides which browser is used.
+type ssoLoginURLOpener struct {
+	out    io.Writer
+	open   func(string) error
+	mu     sync.Mutex
+	line   []byte
+	opened bool
+}
+
+func newSSOLoginURLOpener(out io.Writer, open func(string) error) *ssoLoginURLOpener {
+	return &ssoLoginURLOpener{out: out, open: open}
+}
+
+func (o *ssoLoginURLOpener) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, err := o.out.Write(p); err != nil {
+		return 0, err
+	}
+	o.line = append(o.line, p...)
+	for {
+		i := bytes.IndexByte(o.line, '\n')
+		if i < 0 {
+			break
+		}
+		o.handleLine(strings.TrimSpace(string(o.line[:i])))
+		o.line = o.line[i+1:]
+	}
+	return len(p), nil
+}
+
+// handleLine opens the first URL that logs in without typing the code: the
+// device flow URL with the user_code filled in, or the authorization URL of
+// the PKCE flow used by newer AWS CLI versions.
+func (o *ssoLoginURLOpener) handleLine(line string) {
+	if o.opened || !strings.HasPrefix(line, "https://") {
+		return
+	}
+	if !strings.Contains(line, "user_code=") && !strings.Contains(line, "/authorize?") {
+		return
+	}
+	o.opened = true
+	if err := o.open(line); err != nil {
+		io.WriteString(o.out, "Could not open the browser, open the URL above yourself.\n")
+	}
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestSSOLoginArgs(t *testing.T) {
+	if args := ssoLoginArgs("corp", false); !slices.Equal(args, []string{"sso", "login", "--sso-session", "corp"}) {
+		t.Errorf("Unexpected args %v", args)
+	}
+	if args := ssoLoginArgs("corp", true); !slices.Contains(args, "--no-browser") {
+		t.Errorf("Expected --no-browser in %v", args)
+	}
+}
+
+func TestSSOLoginURLOpener(t *testing.T) {
+	output := "Browser will not be automatically opened.\nPlease visit the following URL:\n\nhttps://device.sso.us-east-1.amazonaws.com/\n\nThen enter the code:\n\nABCD-EFGH\n\n" +
+		"Alternatively, you may visit the following URL which will autofill the code upon loading:\nhttps://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH\n" +
+		"https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH\n"
+
+	var out bytes.Buffer
+	var opened []string
+	opener := newSSOLoginURLOpener(&out, func(url string) error {
+		opened = append(opened, url)
+		return nil
+	})
+	// Written in chunks that split lines, like a pipe would deliver them
+	for i := 0; i < len(output); i += 7 {
+		end := min(i+7, len(output))
+		if _, err := opener.Write([]byte(output[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if out.String() != output {
+		t.Errorf("Expected output to be passed through unchanged, got:\n%s", out.String())
+	}
+	if !slices.Equal(opened, []string{"https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH"}) {
+		t.Errorf("Expected the autofill URL to be opened once, got %v", opened)
+	}
+}
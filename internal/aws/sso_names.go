@@ -0,0 +1,107 @@
+package aws
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultProfileNameTemplate gives generated profiles their <account>-<role> names.
+const DefaultProfileNameTemplate = "{{.AccountName}}-{{.RoleName}}"
+
+// ProfileNameVars are the variables of a profile name template.
+type ProfileNameVars struct {
+	AccountID   string
+	AccountName string
+	RoleName    string
+	SessionName string
+}
+
+// profileNameValueChars are replaced in variable values, so account and role
+// names with spaces or punctuation still give usable profile names.
+var profileNameValueChars = regexp.MustCompile(`[^a-zA-Z0-9-]`)
+
+// invalidProfileNameChars can't appear in a profile name, whatever the template says.
+var invalidProfileNameChars = regexp.MustCompile(`[\s\[\]#;=]`)
+
+// ProfileNamer names generated profiles from a text/template.
+type ProfileNamer struct {
+	tmpl *template.Template
+	// KeepCase leaves variable values in their original case instead of lowercasing them.
+	KeepCase bool
+	// MaxLength shortens longer names, keeping them unique with a hash suffix; 0 means no limit.
+	MaxLength int
+}
+
+// NewProfileNamer parses a profile name template. An empty template is the
+// default <account>-<role> format. The template is tried once with sample
+// values so mistakes show up before anything is discovered.
+func NewProfileNamer(text string, keepCase bool, maxLength int) (*ProfileNamer, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultProfileNameTemplate
+	}
+	if maxLength != 0 && maxLength < 16 {
+		return nil, fmt.Errorf("profile names can't be shortened below 16 characters, got %d", maxLength)
+	}
+	tmpl, err := template.New("profile-name").Option("missingkey=error").Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trunc": func(n int, s string) string {
+			if len(s) > n {
+				return s[:n]
+			}
+			return s
+		},
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid profile name template: %w", err)
+	}
+	namer := &ProfileNamer{tmpl: tmpl, KeepCase: keepCase, MaxLength: maxLength}
+	if _, err := namer.Name(ProfileNameVars{AccountID: "123456789012", AccountName: "account", RoleName: "role", SessionName: "session"}); err != nil {
+		return nil, err
+	}
+	return namer, nil
+}
+
+// Name renders the profile name for an account and role.
+func (n *ProfileNamer) Name(vars ProfileNameVars) (string, error) {
+	clean := func(value string) string {
+		if !n.KeepCase {
+			value = strings.ToLower(value)
+		}
+		return profileNameValueChars.ReplaceAllString(value, "-")
+	}
+	vars = ProfileNameVars{
+		AccountID:   vars.AccountID,
+		AccountName: clean(vars.AccountName),
+		RoleName:    clean(vars.RoleName),
+		SessionName: clean(vars.SessionName),
+	}
+
+	var b strings.Builder
+	if err := n.tmpl.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("invalid profile name template: %w", err)
+	}
+	name := strings.TrimSpace(b.String())
+	if name == "" {
+		return "", fmt.Errorf("profile name template gives an empty name for account %s and role %s", vars.AccountID, vars.RoleName)
+	}
+	if invalidProfileNameChars.MatchString(name) {
+		return "", fmt.Errorf("profile name template gives '%s', which contains characters not allowed in profile names", name)
+	}
+	if n.MaxLength > 0 && len(name) > n.MaxLength {
+		name = shortenProfileName(name, n.MaxLength)
+	}
+	return name, nil
+}
+
+// shortenProfileName cuts a name to maxLength characters. A hash of the full
+// name replaces the end, so names sharing a long prefix stay distinct.
+func shortenProfileName(name string, maxLength int) string {
+	sum := sha1.Sum([]byte(name))
+	suffix := "-" + hex.EncodeToString(sum[:])[:6]
+	return strings.TrimRight(name[:maxLength-len(suffix)], "-") + suffix
+}
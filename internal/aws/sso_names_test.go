@@ -0,0 +1,64 @@
+package aws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfileNamer(t *testing.T) {
+	vars := ProfileNameVars{AccountID: "123456789012", AccountName: "Payments Prod", RoleName: "AdministratorAccess", SessionName: "corp"}
+
+	tests := []struct {
+		template string
+		keepCase bool
+		want     string
+	}{
+		{"", false, "payments-prod-administratoraccess"},
+		{"{{.AccountName}}/{{.RoleName}}", false, "payments-prod/administratoraccess"},
+		{"{{.SessionName}}-{{.AccountID}}-{{.RoleName}}", true, "corp-123456789012-AdministratorAccess"},
+		{"{{.AccountName | upper}}-{{trunc 5 .RoleName}}", false, "PAYMENTS-PROD-admin"},
+	}
+	for _, tt := range tests {
+		namer, err := NewProfileNamer(tt.template, tt.keepCase, 0)
+		if err != nil {
+			t.Fatalf("NewProfileNamer(%q) error = %v", tt.template, err)
+		}
+		got, err := namer.Name(vars)
+		if err != nil {
+			t.Fatalf("Name() with %q error = %v", tt.template, err)
+		}
+		if got != tt.want {
+			t.Errorf("Name() with %q = %s, want %s", tt.template, got, tt.want)
+		}
+	}
+}
+
+func TestProfileNamerRejectsBadTemplates(t *testing.T) {
+	for _, template := range []string{"{{.Account}}", "{{.AccountName", "{{.AccountName}} {{.RoleName}}", "[{{.RoleName}}]"} {
+		if _, err := NewProfileNamer(template, false, 0); err == nil {
+			t.Errorf("expected NewProfileNamer(%q) to fail", template)
+		}
+	}
+	if _, err := NewProfileNamer("", false, 8); err == nil {
+		t.Error("expected a too short maximum length to be rejected")
+	}
+}
+
+func TestProfileNamerShortens(t *testing.T) {
+	namer, err := NewProfileNamer("", false, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := namer.Name(ProfileNameVars{AccountName: "very-long-account-name-one", RoleName: "Admin"})
+	b, _ := namer.Name(ProfileNameVars{AccountName: "very-long-account-name-two", RoleName: "Admin"})
+	if len(a) > 24 || len(b) > 24 {
+		t.Errorf("expected names of at most 24 characters, got %s and %s", a, b)
+	}
+	if a == b || !strings.HasPrefix(a, "very-long-account") {
+		t.Errorf("expected distinct shortened names keeping the prefix, got %s and %s", a, b)
+	}
+	short, _ := namer.Name(ProfileNameVars{AccountName: "dev", RoleName: "Admin"})
+	if short != "dev-admin" {
+		t.Errorf("expected short names to stay as they are, got %s", short)
+	}
+}
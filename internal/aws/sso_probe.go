@@ -0,0 +1,114 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+// ssoRegionProbe reports whether startURL belongs to an IAM Identity Center
+// instance in region.
+type ssoRegionProbe func(ctx context.Context, region, startURL string) error
+
+// DetectSSORegion finds the region of the IAM Identity Center instance behind a
+// start URL. The start URL doesn't name its region, so a device authorization
+// is started in every region; only the instance's home region accepts it. No
+// login happens and nothing is written.
+func DetectSSORegion(startURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return detectSSORegion(ctx, startURL, GetAllRegions(), probeSSORegion)
+}
+
+// probeSSORegion registers a throwaway public client in region and asks for a
+// device authorization for startURL, which fails outside the home region.
+func probeSSORegion(ctx context.Context, region, startURL string) error {
+	client := ssooidc.New(ssooidc.Options{Region: region})
+	registration, err := client.RegisterClient(ctx, &ssooidc.RegisterClientInput{
+		ClientName: aws.String("awsm-region-probe"),
+		ClientType: aws.String("public"),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     registration.ClientId,
+		ClientSecret: registration.ClientSecret,
+		StartUrl:     aws.String(startURL),
+	})
+	return err
+}
+
+func detectSSORegion(ctx context.Context, startURL string, regions []string, probe ssoRegionProbe) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan string, len(regions))
+	var wg sync.WaitGroup
+	for _, region := range regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			if probe(ctx, region, startURL) == nil {
+				found <- region
+			}
+		}(region)
+	}
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	if region, ok := <-found; ok {
+		return region, nil
+	}
+	if ctx.Err() != nil {
+		return "", fmt.Errorf("timed out detecting the region of %s", startURL)
+	}
+	return "", fmt.Errorf("no region accepted the start URL %s; check the URL or pass --region", startURL)
+}
+
+var sessionNameInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// NormalizeSSOStartURL validates a start URL pasted from an IAM Identity Center
+// invite and returns it in the https://<host>/start form.
+func NormalizeSSOStartURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid start URL: %s", raw)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("start URL must use https: %s", raw)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if path == "" {
+		path = "/start"
+	}
+	return "https://" + u.Host + path, nil
+}
+
+// SuggestSSOSessionName proposes a session name from a start URL: the
+// directory alias of <alias>.awsapps.com, or the host's first label otherwise.
+func SuggestSSOSessionName(startURL string) string {
+	u, err := url.Parse(startURL)
+	if err != nil || u.Hostname() == "" {
+		return "sso"
+	}
+	label := strings.ToLower(strings.SplitN(u.Hostname(), ".", 2)[0])
+	name := strings.Trim(sessionNameInvalidChars.ReplaceAllString(label, "-"), "-")
+	if name == "" {
+		return "sso"
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDetectSSORegion(t *testing.T) {
+	regions := []string{"us-east-1", "eu-west-1", "ap-southeast-2"}
+	probe := func(home string) ssoRegionProbe {
+		return func(ctx context.Context, region, startURL string) error {
+			if region == home {
+				return nil
+			}
+			return errors.New("InvalidRequestException")
+		}
+	}
+
+	region, err := detectSSORegion(context.Background(), "https://corp.awsapps.com/start", regions, probe("eu-west-1"))
+	if err != nil || region != "eu-west-1" {
+		t.Errorf("Expected eu-west-1, got %q (%v)", region, err)
+	}
+
+	if _, err := detectSSORegion(context.Background(), "https://corp.awsapps.com/start", regions, probe("")); err == nil {
+		t.Error("Expected an error when no region accepts the start URL")
+	}
+}
+
+func TestNormalizeSSOStartURL(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"https://corp.awsapps.com/start", "https://corp.awsapps.com/start", false},
+		{"https://corp.awsapps.com/start/", "https://corp.awsapps.com/start", false},
+		{"https://corp.awsapps.com/start/#/", "https://corp.awsapps.com/start", false},
+		{"d-1234567a10.awsapps.com", "https://d-1234567a10.awsapps.com/start", false},
+		{"http://corp.awsapps.com/start", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeSSOStartURL(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSuggestSSOSessionName(t *testing.T) {
+	tests := map[string]string{
+		"https://mycorp.awsapps.com/start":       "mycorp",
+		"https://d-1234567a10.awsapps.com/start": "d-1234567a10",
+		"https://My_Corp.awsapps.com/start":      "my-corp",
+	}
+	for input, want := range tests {
+		if got := SuggestSSOSessionName(input); got != want {
+			t.Errorf("SuggestSSOSessionName(%s) = %s, expected %s", input, got, want)
+		}
+	}
+}
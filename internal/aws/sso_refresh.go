@@ -0,0 +1,112 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+// ErrSSORefreshUnavailable means the cached token of a session can't be
+// renewed silently and a full login is needed.
+var ErrSSORefreshUnavailable = errors.New("no usable refresh token cached for the SSO session")
+
+// ssoTokenRefresher is the part of the SSO OIDC client used to renew tokens.
+type ssoTokenRefresher interface {
+	CreateToken(ctx context.Context, params *ssooidc.CreateTokenInput, optFns ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error)
+}
+
+// RefreshSSOToken renews the cached access token of an sso-session with its
+// refresh token (ssooidc:CreateToken with grant_type refresh_token), so no
+// browser or device flow is needed until the refresh token itself expires.
+func RefreshSSOToken(ssoSession string) error {
+	path, err := SSOTokenCachePath(ssoSession)
+	if err != nil {
+		return err
+	}
+	token, raw, err := readSSOTokenCache(path)
+	if err != nil {
+		return err
+	}
+	region := token.Region
+	if region == "" {
+		if region, err = getSSOSessionRegion(ssoSession); err != nil {
+			return err
+		}
+	}
+	client := ssooidc.New(ssooidc.Options{Region: region})
+	return refreshSSOToken(path, token, raw, client, time.Now())
+}
+
+// ssoRefreshableToken holds the token cache fields needed for a refresh.
+type ssoRefreshableToken struct {
+	ssoTokenFile
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+// readSSOTokenCache reads a token cache file both typed and as raw JSON, so it
+// can be written back without dropping fields awsm doesn't know about.
+func readSSOTokenCache(path string) (*ssoRefreshableToken, map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, ErrSSORefreshUnavailable
+		}
+		return nil, nil, fmt.Errorf("failed to read SSO token cache: %w", err)
+	}
+	var token ssoRefreshableToken
+	var raw map[string]any
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse SSO token cache %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse SSO token cache %s: %w", path, err)
+	}
+	return &token, raw, nil
+}
+
+func refreshSSOToken(path string, token *ssoRefreshableToken, raw map[string]any, client ssoTokenRefresher, now time.Time) error {
+	if token.RefreshToken == "" || token.ClientID == "" || token.ClientSecret == "" {
+		return ErrSSORefreshUnavailable
+	}
+	if !token.RegistrationExpiresAt.IsZero() && !now.Before(token.RegistrationExpiresAt) {
+		return ErrSSORefreshUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+		ClientId:     aws.String(token.ClientID),
+		ClientSecret: aws.String(token.ClientSecret),
+		GrantType:    aws.String("refresh_token"),
+		RefreshToken: aws.String(token.RefreshToken),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh SSO token: %w", err)
+	}
+	if aws.ToString(out.AccessToken) == "" {
+		return fmt.Errorf("failed to refresh SSO token: no access token returned")
+	}
+
+	raw["accessToken"] = aws.ToString(out.AccessToken)
+	raw["expiresAt"] = now.Add(time.Duration(out.ExpiresIn) * time.Second).UTC().Format(time.RFC3339)
+	if refreshToken := aws.ToString(out.RefreshToken); refreshToken != "" {
+		raw["refreshToken"] = refreshToken
+	}
+	return writeSSOTokenCache(path, raw)
+}
+
+// getSSOSessionRegion returns the sso_region of an sso-session.
+func getSSOSessionRegion(ssoSession string) (string, error) {
+	session, err := findSSOSession(ssoSession)
+	if err != nil {
+		return "", err
+	}
+	return session.Region, nil
+}
@@ -0,0 +1,116 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+)
+
+type fakeTokenRefresher struct {
+	input *ssooidc.CreateTokenInput
+	out   *ssooidc.CreateTokenOutput
+	err   error
+}
+
+func (f *fakeTokenRefresher) CreateToken(ctx context.Context, params *ssooidc.CreateTokenInput, optFns ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error) {
+	f.input = params
+	return f.out, f.err
+}
+
+func TestRefreshSSOToken(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := map[string]any{
+		"startUrl":              "https://corp.awsapps.com/start",
+		"region":                "us-east-1",
+		"accessToken":           "old",
+		"expiresAt":             now.Add(-time.Hour).Format(time.RFC3339),
+		"refreshToken":          "refresh-1",
+		"clientId":              "client",
+		"clientSecret":          "secret",
+		"registrationExpiresAt": now.Add(24 * time.Hour).Format(time.RFC3339),
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(map[string]any)
+		wantErr error
+	}{
+		{"Refreshable", func(map[string]any) {}, nil},
+		{"No refresh token", func(c map[string]any) { delete(c, "refreshToken") }, ErrSSORefreshUnavailable},
+		{"No client secret", func(c map[string]any) { delete(c, "clientSecret") }, ErrSSORefreshUnavailable},
+		{"Expired registration", func(c map[string]any) {
+			c["registrationExpiresAt"] = now.Add(-time.Minute).Format(time.RFC3339)
+		}, ErrSSORefreshUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := map[string]any{}
+			for k, v := range cache {
+				content[k] = v
+			}
+			tt.modify(content)
+			path := filepath.Join(t.TempDir(), "token.json")
+			data, _ := json.Marshal(content)
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			token, raw, err := readSSOTokenCache(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &fakeTokenRefresher{out: &ssooidc.CreateTokenOutput{
+				AccessToken:  aws.String("new"),
+				RefreshToken: aws.String("refresh-2"),
+				ExpiresIn:    3600,
+			}}
+			err = refreshSSOToken(path, token, raw, client, now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr != nil {
+				if client.input != nil {
+					t.Error("Expected no CreateToken call")
+				}
+				return
+			}
+
+			if got := aws.ToString(client.input.GrantType); got != "refresh_token" {
+				t.Errorf("Expected grant type refresh_token, got %s", got)
+			}
+			if got := aws.ToString(client.input.RefreshToken); got != "refresh-1" {
+				t.Errorf("Expected refresh token refresh-1, got %s", got)
+			}
+
+			var written map[string]any
+			data, _ = os.ReadFile(path)
+			if err := json.Unmarshal(data, &written); err != nil {
+				t.Fatal(err)
+			}
+			if written["accessToken"] != "new" || written["refreshToken"] != "refresh-2" {
+				t.Errorf("Expected rotated tokens, got %v", written)
+			}
+			if written["expiresAt"] != now.Add(time.Hour).Format(time.RFC3339) {
+				t.Errorf("Expected new expiry, got %v", written["expiresAt"])
+			}
+			if written["startUrl"] != cache["startUrl"] || written["clientSecret"] != "secret" {
+				t.Errorf("Expected other fields to be kept, got %v", written)
+			}
+		})
+	}
+}
+
+func TestReadSSOTokenCacheMissing(t *testing.T) {
+	_, _, err := readSSOTokenCache(filepath.Join(t.TempDir(), "missing.json"))
+	if !errors.Is(err, ErrSSORefreshUnavailable) {
+		t.Errorf("Expected ErrSSORefreshUnavailable, got %v", err)
+	}
+}
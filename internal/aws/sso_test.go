@@ -0,0 +1,141 @@
+package aws
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetSSOTokenStatus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	now := time.Now().UTC()
+	tests := []struct {
+		name       string
+		token      *ssoTokenFile
+		needsLogin bool
+	}{
+		{"No cache file", nil, true},
+		{"Valid token", &ssoTokenFile{AccessToken: "a", ExpiresAt: now.Add(time.Hour)}, false},
+		{"Expired token", &ssoTokenFile{AccessToken: "a", ExpiresAt: now.Add(-time.Hour)}, true},
+		{"Expired but refreshable", &ssoTokenFile{AccessToken: "a", ExpiresAt: now.Add(-time.Hour), RefreshToken: "r", RegistrationExpiresAt: now.Add(24 * time.Hour)}, false},
+		{"Expired registration", &ssoTokenFile{AccessToken: "a", ExpiresAt: now.Add(-time.Hour), RefreshToken: "r", RegistrationExpiresAt: now.Add(-time.Hour)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := tt.name
+			if tt.token != nil {
+				path, err := SSOTokenCachePath(session)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+					t.Fatal(err)
+				}
+				data, _ := json.Marshal(tt.token)
+				if err := os.WriteFile(path, data, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			status, err := GetSSOTokenStatus(session)
+			if err != nil {
+				t.Fatalf("GetSSOTokenStatus failed: %v", err)
+			}
+			if status.NeedsLogin != tt.needsLogin {
+				t.Errorf("Expected NeedsLogin %v, got %v", tt.needsLogin, status.NeedsLogin)
+			}
+		})
+	}
+}
+
+func TestCachedSSOAccessToken(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := CachedSSOAccessToken("corp"); err == nil {
+		t.Error("expected an error without a cached token")
+	}
+
+	path, err := SSOTokenCachePath("corp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		expiresAt time.Time
+		want      string
+	}{
+		{time.Now().Add(time.Hour), "token"},
+		{time.Now().Add(-time.Hour), ""},
+	} {
+		// Refresh tokens are not used, the check must not change the cache
+		data, _ := json.Marshal(ssoTokenFile{AccessToken: "token", ExpiresAt: tt.expiresAt, RefreshToken: "r"})
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := CachedSSOAccessToken("corp")
+		if got != tt.want || (err == nil) != (tt.want != "") {
+			t.Errorf("CachedSSOAccessToken with expiry %v = %q, %v", tt.expiresAt, got, err)
+		}
+	}
+}
+
+func TestLogoutSSOSession(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+
+	awsDir := filepath.Join(home, ".aws")
+	if err := os.MkdirAll(awsDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	config := "[sso-session corp]\nsso_start_url = https://corp.awsapps.com/start\nsso_region = us-east-1\n\n" +
+		"[profile dev]\nsso_session = corp\nsso_account_id = 123456789012\nsso_role_name = Admin\n\n" +
+		"[profile other]\nregion = eu-west-1\n"
+	if err := os.WriteFile(filepath.Join(awsDir, "config"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	credentials := "[default]\n# source_profile = dev\naws_access_key_id = ASIATEST\naws_secret_access_key = secret\naws_session_token = token\n"
+	if err := os.WriteFile(filepath.Join(awsDir, "credentials"), []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// An expired token is removed without contacting AWS
+	tokenPath, _ := SSOTokenCachePath("corp")
+	if err := os.MkdirAll(filepath.Dir(tokenPath), 0700); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(ssoTokenFile{AccessToken: "a", ExpiresAt: time.Now().Add(-time.Hour)})
+	if err := os.WriteFile(tokenPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	CacheCredentials("dev", &TempCredentials{AccessKeyId: "ASIATEST", Expires: time.Now().Add(time.Hour)})
+
+	result, err := LogoutSSOSession("corp")
+	if err != nil {
+		t.Fatalf("LogoutSSOSession failed: %v", err)
+	}
+	if result.RemoteLogout || result.RemoteError != nil {
+		t.Errorf("Expected no remote logout for an expired token, got %v / %v", result.RemoteLogout, result.RemoteError)
+	}
+	if !result.TokenRemoved {
+		t.Error("Expected the token cache to be removed")
+	}
+	if _, err := os.Stat(tokenPath); !os.IsNotExist(err) {
+		t.Error("Token cache file still exists")
+	}
+	if len(result.CachedProfiles) != 1 || result.CachedProfiles[0] != "dev" {
+		t.Errorf("Expected cached credentials of 'dev' to be removed, got %v", result.CachedProfiles)
+	}
+	if result.DefaultCleared != "dev" {
+		t.Errorf("Expected default credentials of 'dev' to be cleared, got %q", result.DefaultCleared)
+	}
+	if HasValidCachedCredentials("dev") {
+		t.Error("Cached credentials for 'dev' still exist")
+	}
+}
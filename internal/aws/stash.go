@@ -0,0 +1,202 @@
+package aws
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+// StashedSession is a snapshot of the default section of the credentials
+// file, taken by 'awsm session stash'.
+type StashedSession struct {
+	// Profile is the active profile at the time, empty when none was set.
+	Profile string `json:"profile,omitempty"`
+	// ExpiresAt is the recorded expiry of the credentials in RFC 3339, if any.
+	ExpiresAt string       `json:"expires_at,omitempty"`
+	Keys      []StashedKey `json:"keys"`
+	StashedAt time.Time    `json:"stashed_at"`
+}
+
+// StashedKey is a key of the default section, kept in file order.
+type StashedKey struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Region returns the region of the stashed session.
+func (s *StashedSession) Region() string {
+	for _, key := range s.Keys {
+		if key.Name == "region" {
+			return key.Value
+		}
+	}
+	return ""
+}
+
+// Expires returns when the stashed credentials expire; ok is false when unknown.
+func (s *StashedSession) Expires() (expires time.Time, ok bool) {
+	if s.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	expires, err := time.Parse(time.RFC3339, s.ExpiresAt)
+	return expires, err == nil
+}
+
+// stashPath returns the file holding the stack of stashed sessions. It holds
+// credentials, so it lives next to the credential cache.
+func stashPath() (string, error) {
+	dir, err := awsmConfig.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session-stash.json"), nil
+}
+
+// StashedSessions returns the stashed sessions, oldest first.
+func StashedSessions() ([]StashedSession, error) {
+	path, err := stashPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var sessions []StashedSession
+	if err := json.Unmarshal(data, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return sessions, nil
+}
+
+func saveStashedSessions(sessions []StashedSession) error {
+	path, err := stashPath()
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create awsm directory: %w", err)
+	}
+	data, err := json.MarshalIndent(sessions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, data, 0600); err != nil {
+		return err
+	}
+	return nil
+}
+
+// StashDefaultSession pushes a snapshot of the default credentials, region
+// and active profile onto the stash.
+func StashDefaultSession() (*StashedSession, error) {
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	session := StashedSession{StashedAt: time.Now().UTC()}
+	if _, statErr := os.Stat(credentialsPath); statErr == nil {
+		cfg, err := loadIni(credentialsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read AWS credentials file: %w", err)
+		}
+		if section, err := cfg.GetSection("default"); err == nil {
+			for _, key := range section.Keys() {
+				session.Keys = append(session.Keys, StashedKey{Name: key.Name(), Value: key.Value()})
+			}
+		}
+		session.Profile = GetCurrentProfileName()
+		session.ExpiresAt = readDefaultSectionComment(credentialsPath, "# expires_at")
+	}
+
+	sessions, err := StashedSessions()
+	if err != nil {
+		return nil, err
+	}
+	if err := saveStashedSessions(append(sessions, session)); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// PopDefaultSession restores the most recently stashed session into the
+// default section and removes it from the stash.
+func PopDefaultSession() (*StashedSession, error) {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	sessions, err := StashedSessions()
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no stashed session, run 'awsm session stash' first")
+	}
+	session := sessions[len(sessions)-1]
+	if err := restoreDefaultSection(&session); err != nil {
+		return nil, err
+	}
+	if err := saveStashedSessions(sessions[:len(sessions)-1]); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// restoreDefaultSection replaces the keys of the default section with the
+// stashed ones, leaving the other profiles in the file untouched.
+func restoreDefaultSection(session *StashedSession) error {
+	unlock, err := lockAWSFiles()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(credentialsPath), 0755); err != nil {
+		return fmt.Errorf("failed to create AWS directory: %w", err)
+	}
+	cfg, err := loadOrCreateIni(credentialsPath)
+	if err != nil {
+		return err
+	}
+	section, err := cfg.GetSection("default")
+	if err != nil {
+		section, err = cfg.NewSection("default")
+		if err != nil {
+			return fmt.Errorf("failed to create default section: %w", err)
+		}
+	}
+	for _, key := range section.KeyStrings() {
+		section.DeleteKey(key)
+	}
+	for _, key := range session.Keys {
+		section.Key(key.Name).SetValue(key.Value)
+	}
+	if session.Profile != "" {
+		section.Key("# source_profile").SetValue(session.Profile)
+	}
+	if session.ExpiresAt != "" {
+		section.Key("# expires_at").SetValue(session.ExpiresAt)
+	}
+	return saveIniAtomic(cfg, credentialsPath)
+}
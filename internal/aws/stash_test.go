@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	awsmConfig "awsm/internal/config"
+)
+
+func TestStashAndPopDefaultSession(t *testing.T) {
+	dir := t.TempDir()
+	credentialsPath := filepath.Join(dir, "credentials")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentialsPath)
+	t.Setenv(awsmConfig.HomeEnv, filepath.Join(dir, "awsm"))
+
+	if err := os.WriteFile(credentialsPath, []byte("[static]\naws_access_key_id = AKIASTATIC\naws_secret_access_key = static\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	work := &TempCredentials{AccessKeyId: "ASIAWORK", SecretAccessKey: "work-secret", SessionToken: "work-token", Expires: expires}
+	if err := UpdateCredentialsFile(work, "eu-west-1", "work"); err != nil {
+		t.Fatal(err)
+	}
+
+	stashed, err := StashDefaultSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stashed.Profile != "work" || stashed.Region() != "eu-west-1" {
+		t.Errorf("Expected the work session to be stashed, got %+v", stashed)
+	}
+
+	other := &TempCredentials{AccessKeyId: "ASIAOTHER", SecretAccessKey: "other-secret", SessionToken: "other-token", Expires: expires.Add(time.Hour)}
+	if err := UpdateCredentialsFile(other, "us-east-1", "other"); err != nil {
+		t.Fatal(err)
+	}
+
+	popped, err := PopDefaultSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if popped.Profile != "work" {
+		t.Errorf("Expected to pop the work session, got %s", popped.Profile)
+	}
+	if got := GetCurrentProfileName(); got != "work" {
+		t.Errorf("Expected the work profile to be active again, got %s", got)
+	}
+	if got, ok := DefaultCredentialsExpiry(); !ok || !got.Equal(expires) {
+		t.Errorf("Expected the work expiry %s, got %s (%v)", expires, got, ok)
+	}
+
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	for _, want := range []string{"ASIAWORK", "work-token", "eu-west-1", "AKIASTATIC"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in the credentials file:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"ASIAOTHER", "us-east-1", "other"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("Expected %q to be gone from the credentials file:\n%s", unwanted, content)
+		}
+	}
+
+	if _, err := PopDefaultSession(); err == nil {
+		t.Error("Expected an error popping an empty stash")
+	}
+	if sessions, err := StashedSessions(); err != nil || len(sessions) != 0 {
+		t.Errorf("Expected an empty stash, got %v, %v", sessions, err)
+	}
+}
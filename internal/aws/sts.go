@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"awsm/internal/config"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	// STSEndpointsRegional sends STS calls to sts.<region>.amazonaws.com.
+	STSEndpointsRegional = "regional"
+	// STSEndpointsLegacy sends STS calls to the global sts.amazonaws.com endpoint.
+	STSEndpointsLegacy = "legacy"
+)
+
+// resolveSTSEndpointsMode picks the STS endpoint mode for a profile. The profile's
+// sts_regional_endpoints key wins, then AWS_STS_REGIONAL_ENDPOINTS, then the
+// awsm config default. An empty result means the SDK default (regional).
+func resolveSTSEndpointsMode(profileValue string) (string, error) {
+	mode := profileValue
+	if mode == "" {
+		mode = os.Getenv("AWS_STS_REGIONAL_ENDPOINTS")
+	}
+	if mode == "" {
+		mode = config.GetSTSRegionalEndpoints()
+	}
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "", STSEndpointsRegional, STSEndpointsLegacy:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid sts_regional_endpoints value '%s' (expected 'regional' or 'legacy')", mode)
+	}
+}
+
+// newSTSClientOptions returns the options that point an STS client at the
+// endpoint selected for the profile.
+func newSTSClientOptions(pConfig *profileConfig) ([]func(*sts.Options), error) {
+	mode, err := resolveSTSEndpointsMode(pConfig.STSRegionalEndpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mode {
+	case STSEndpointsLegacy:
+		return []func(*sts.Options){func(o *sts.Options) {
+			o.Region = "aws-global"
+		}}, nil
+	case STSEndpointsRegional:
+		return []func(*sts.Options){func(o *sts.Options) {
+			// Prefer the region of the profile being resolved over the source profile's
+			if pConfig.Region != "" {
+				o.Region = pConfig.Region
+			}
+		}}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,32 @@
+package aws
+
+import "testing"
+
+func TestResolveSTSEndpointsMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		env      string
+		expected string
+		wantErr  bool
+	}{
+		{"Unset", "", "", "", false},
+		{"Profile value", "regional", "", "regional", false},
+		{"Profile overrides env", "legacy", "regional", "legacy", false},
+		{"Env fallback", "", "Regional", "regional", false},
+		{"Invalid value", "global", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_STS_REGIONAL_ENDPOINTS", tt.env)
+			mode, err := resolveSTSEndpointsMode(tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if mode != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, mode)
+			}
+		})
+	}
+}
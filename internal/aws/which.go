@@ -0,0 +1,178 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// ResolutionStep is one link of the default credential chain used by the AWS CLI and SDKs.
+type ResolutionStep struct {
+	Source string
+	// Detail explains why the step was or wasn't used, and what it points at.
+	Detail string
+	// Selected is true for the step that provides the credentials.
+	Selected bool
+	// Reached is false for steps after the selected one, which are never consulted.
+	Reached bool
+}
+
+// ExplainCredentialResolution walks the default credential chain for the
+// current environment and reports which step provides credentials.
+// explicitProfile is the value of a --profile flag, if the tool was given one.
+func ExplainCredentialResolution(explicitProfile string) []ResolutionStep {
+	var steps []ResolutionStep
+	selected := false
+	add := func(source, detail string, used bool) {
+		step := ResolutionStep{Source: source, Detail: detail, Reached: !selected}
+		if used && !selected {
+			step.Selected = true
+			selected = true
+		}
+		steps = append(steps, step)
+	}
+
+	if explicitProfile != "" {
+		add("--profile flag", describeProfileSource(explicitProfile), true)
+	} else {
+		add("--profile flag", "not given", false)
+	}
+
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	if accessKey != "" && os.Getenv("AWS_SECRET_ACCESS_KEY") != "" {
+		detail := fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", maskAccessKey(accessKey))
+		if os.Getenv("AWS_SESSION_TOKEN") != "" {
+			detail += " with AWS_SESSION_TOKEN (temporary credentials)"
+		}
+		if expiry := os.Getenv("AWS_CREDENTIAL_EXPIRATION"); expiry != "" {
+			detail += ", expires " + expiry
+		}
+		add("Environment variables", detail, true)
+	} else {
+		add("Environment variables", "AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY not set", false)
+	}
+
+	envProfile, envVar := os.Getenv("AWS_PROFILE"), "AWS_PROFILE"
+	if envProfile == "" {
+		// Only honoured by the AWS CLI v1 and older SDKs
+		envProfile, envVar = os.Getenv("AWS_DEFAULT_PROFILE"), "AWS_DEFAULT_PROFILE"
+	}
+	if envProfile != "" {
+		add(envVar, fmt.Sprintf("%s=%s: %s", envVar, envProfile, describeProfileSource(envProfile)), true)
+	} else {
+		add("AWS_PROFILE", "not set", false)
+	}
+
+	if tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); tokenFile != "" && os.Getenv("AWS_ROLE_ARN") != "" {
+		add("Web identity", fmt.Sprintf("assumes %s with token from %s", os.Getenv("AWS_ROLE_ARN"), tokenFile), true)
+	} else {
+		add("Web identity", "AWS_WEB_IDENTITY_TOKEN_FILE/AWS_ROLE_ARN not set", false)
+	}
+
+	if defaultDetail, ok := describeDefaultProfile(); ok {
+		add("Default profile", defaultDetail, true)
+	} else {
+		add("Default profile", defaultDetail, false)
+	}
+
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); uri != "" {
+		add("Container credentials", uri, true)
+	} else if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		add("Container credentials", "http://169.254.170.2"+uri, true)
+	} else {
+		add("Container credentials", "AWS_CONTAINER_CREDENTIALS_*_URI not set", false)
+	}
+
+	add("EC2 instance metadata", "used as the last resort when running on EC2", true)
+
+	return steps
+}
+
+// describeProfileSource explains where a named profile is defined and how it gets credentials.
+func describeProfileSource(profileName string) string {
+	var parts []string
+
+	if configPath, err := GetAWSConfigPath(); err == nil {
+		if cfg, err := loadIni(configPath); err == nil {
+			if section, err := getProfileSection(cfg, profileName); err == nil {
+				parts = append(parts, fmt.Sprintf("[%s] in %s", section.Name(), configPath))
+				parts = append(parts, describeProfileSection(section))
+			}
+		}
+	}
+	if credentialsPath, err := GetAWSCredentialsPath(); err == nil {
+		if credFile, err := loadIni(credentialsPath); err == nil {
+			if section, err := credFile.GetSection(profileName); err == nil && section.HasKey("aws_access_key_id") {
+				parts = append(parts, fmt.Sprintf("keys %s in [%s] of %s", maskAccessKey(section.Key("aws_access_key_id").String()), profileName, credentialsPath))
+			}
+		}
+	}
+
+	if len(parts) == 0 {
+		return fmt.Sprintf("profile '%s' is not defined, so the tool will fail", profileName)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeProfileSection summarizes how a config file profile obtains credentials.
+func describeProfileSection(section *ini.Section) string {
+	switch {
+	case section.HasKey("role_arn"):
+		via := section.Key("source_profile").String()
+		if via == "" {
+			via = section.Key("credential_source").String()
+		}
+		return fmt.Sprintf("assumes %s via %s", section.Key("role_arn").String(), via)
+	case section.HasKey("sso_session") || section.HasKey("sso_start_url"):
+		return fmt.Sprintf("SSO role %s in account %s", section.Key("sso_role_name").String(), section.Key("sso_account_id").String())
+	case section.HasKey("credential_process"):
+		return fmt.Sprintf("runs '%s'", section.Key("credential_process").String())
+	default:
+		return "static keys"
+	}
+}
+
+// describeDefaultProfile explains the [default] profile and whether awsm manages it.
+func describeDefaultProfile() (string, bool) {
+	credentialsPath, err := GetAWSCredentialsPath()
+	if err == nil {
+		if credFile, err := loadIni(credentialsPath); err == nil {
+			if section, err := credFile.GetSection("default"); err == nil && section.Key("aws_access_key_id").String() != "" {
+				detail := fmt.Sprintf("keys %s in [default] of %s", maskAccessKey(section.Key("aws_access_key_id").String()), credentialsPath)
+				if source := GetCurrentProfileName(); source != "" {
+					detail += fmt.Sprintf(", written by awsm for profile '%s'", source)
+				}
+				return detail, true
+			}
+		}
+	}
+
+	if configPath, err := GetAWSConfigPath(); err == nil {
+		if cfg, err := loadIni(configPath); err == nil {
+			if section, err := cfg.GetSection("default"); err == nil && len(section.Keys()) > 0 && !onlyRegionKeys(section) {
+				return fmt.Sprintf("[default] in %s: %s", configPath, describeProfileSection(section)), true
+			}
+		}
+	}
+	return "no credentials in the [default] profile", false
+}
+
+// onlyRegionKeys reports whether a section only sets non-credential settings.
+func onlyRegionKeys(section *ini.Section) bool {
+	for _, key := range section.KeyStrings() {
+		if key != "region" && key != "output" {
+			return false
+		}
+	}
+	return true
+}
+
+// maskAccessKey shortens an access key ID so it can be shown without revealing it fully.
+func maskAccessKey(accessKey string) string {
+	if len(accessKey) <= 8 {
+		return accessKey
+	}
+	return accessKey[:4] + "…" + accessKey[len(accessKey)-4:]
+}
@@ -0,0 +1,220 @@
+package browser
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// App is a browser with Multi-Account Containers: Firefox or Zen.
+type App string
+
+const (
+	Firefox App = "firefox"
+	Zen     App = "zen"
+)
+
+// Container is a contextual identity of a Firefox or Zen profile.
+type Container struct {
+	ID    int    `json:"userContextId"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Icon  string `json:"icon"`
+}
+
+// ContainerStyle is the color and icon a container is created with.
+type ContainerStyle struct {
+	Color string
+	Icon  string
+}
+
+// Names of the containers Firefox ships with, stored as localization IDs
+var defaultContainerNames = map[string]string{
+	"userContextPersonal.label": "Personal",
+	"userContextWork.label":     "Work",
+	"userContextBanking.label":  "Banking",
+	"userContextShopping.label": "Shopping",
+}
+
+// StyleForProfile derives the style of a profile's container: the color
+// from the account, so all profiles of an account share it, and the icon
+// from the profile type. Without an account the profile name picks the color.
+func StyleForProfile(accountID, profileName, profileType string) ContainerStyle {
+	key := accountID
+	if key == "" {
+		key = profileName
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	icon := "circle"
+	switch profileType {
+	case "SSO":
+		icon = "briefcase"
+	case "IAM":
+		icon = "fingerprint"
+	case "Key":
+		icon = "dollar"
+	case "Process":
+		icon = "tree"
+	}
+	return ContainerStyle{Color: firefoxColors[h.Sum32()%uint32(len(firefoxColors))], Icon: icon}
+}
+
+// profilesRoot returns the directory holding the profiles.ini of app.
+func profilesRoot(app App) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		name := "Firefox"
+		if app == Zen {
+			name = "zen"
+		}
+		return filepath.Join(home, "Library", "Application Support", name), nil
+	case "windows":
+		if app == Zen {
+			return filepath.Join(os.Getenv("APPDATA"), "zen"), nil
+		}
+		return filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox"), nil
+	default:
+		if app == Zen {
+			return filepath.Join(home, ".zen"), nil
+		}
+		// Snap and Flatpak installs keep their profiles in their sandbox
+		for _, dir := range []string{
+			filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+			filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+		} {
+			if _, err := os.Stat(filepath.Join(dir, "profiles.ini")); err == nil {
+				return dir, nil
+			}
+		}
+		return filepath.Join(home, ".mozilla", "firefox"), nil
+	}
+}
+
+// defaultProfileDir returns the profile directory app opens links in: the
+// default of an install section, or else the profile marked as default.
+func defaultProfileDir(root string) (string, error) {
+	cfg, err := ini.Load(filepath.Join(root, "profiles.ini"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read browser profiles: %w", err)
+	}
+
+	var path string
+	isRelative := true
+	for _, section := range cfg.Sections() {
+		if strings.HasPrefix(section.Name(), "Install") && section.Key("Default").String() != "" {
+			path = section.Key("Default").String()
+			break
+		}
+	}
+	if path == "" {
+		for _, section := range cfg.Sections() {
+			if !strings.HasPrefix(section.Name(), "Profile") {
+				continue
+			}
+			if path == "" || section.Key("Default").String() == "1" {
+				path = section.Key("Path").String()
+				isRelative = section.Key("IsRelative").MustBool(true)
+			}
+		}
+	}
+	if path == "" {
+		return "", fmt.Errorf("no browser profile found in %s", root)
+	}
+	if isRelative {
+		path = filepath.Join(root, filepath.FromSlash(path))
+	}
+	return path, nil
+}
+
+// ListContainers returns the containers of the default profile of app, as
+// saved in its containers.json. Containers Firefox keeps for itself are left
+// out.
+func ListContainers(app App) ([]Container, error) {
+	root, err := profilesRoot(app)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := defaultProfileDir(root)
+	if err != nil {
+		return nil, err
+	}
+	return readContainers(filepath.Join(dir, "containers.json"))
+}
+
+func readContainers(path string) ([]Container, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read containers: %w", err)
+	}
+
+	var file struct {
+		Identities []struct {
+			Container
+			Public bool   `json:"public"`
+			L10nID string `json:"l10nID"`
+		} `json:"identities"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var containers []Container
+	for _, identity := range file.Identities {
+		if !identity.Public {
+			continue
+		}
+		c := identity.Container
+		if c.Name == "" {
+			c.Name = defaultContainerNames[identity.L10nID]
+		}
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+// FindContainer returns the container of app named name, or nil if there is
+// none.
+func FindContainer(app App, name string) (*Container, error) {
+	containers, err := ListContainers(app)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range containers {
+		if c.Name == name {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+// OpenURLInContainer opens targetURL in the container of app named name.
+// A missing container is created with style by the "Open external links in a
+// container" extension; created reports that. An existing one keeps its own
+// color and icon. When the containers can't be read, style is passed along
+// and the extension creates the container only if it is missing.
+func OpenURLInContainer(app App, targetURL, name string, style ContainerStyle) (created bool, err error) {
+	params := url.Values{}
+	params.Set("name", name)
+	existing, err := FindContainer(app, name)
+	if err != nil || existing == nil {
+		params.Set("color", style.Color)
+		params.Set("icon", style.Icon)
+	}
+	params.Set("url", targetURL)
+	return err == nil && existing == nil, openContainerURL(app, targetURL, "ext+container:"+params.Encode())
+}
@@ -0,0 +1,98 @@
+package browser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDefaultProfileDir(t *testing.T) {
+	root := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "profiles.ini"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`[Profile1]
+Name=default
+IsRelative=1
+Path=Profiles/abc.default
+Default=1
+
+[Profile0]
+Name=default-release
+IsRelative=1
+Path=Profiles/xyz.default-release
+
+[Install4F96D1932A9F858E]
+Default=Profiles/xyz.default-release
+Locked=1
+`)
+	dir, err := defaultProfileDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "Profiles", "xyz.default-release"); dir != want {
+		t.Errorf("the install default should win, got %s, want %s", dir, want)
+	}
+
+	write(`[Profile0]
+Name=other
+IsRelative=1
+Path=other
+
+[Profile1]
+Name=work
+IsRelative=0
+Path=/data/firefox/work
+Default=1
+`)
+	if dir, err := defaultProfileDir(root); err != nil || dir != "/data/firefox/work" {
+		t.Errorf("expected the absolute default profile, got %s (%v)", dir, err)
+	}
+}
+
+func TestReadContainers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "containers.json")
+	if containers, err := readContainers(path); err != nil || containers != nil {
+		t.Errorf("a profile without containers.json has no containers, got %v (%v)", containers, err)
+	}
+
+	content := `{"version":5,"lastUserContextId":6,"identities":[
+{"userContextId":1,"public":true,"icon":"fingerprint","color":"blue","l10nID":"userContextPersonal.label","accessKey":"userContextPersonal.accesskey"},
+{"userContextId":5,"public":false,"icon":"","color":"","name":"userContextIdInternal.thumbnail","accessKey":""},
+{"userContextId":6,"public":true,"icon":"briefcase","color":"red","name":"prod"}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	containers, err := readContainers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Container{
+		{ID: 1, Name: "Personal", Color: "blue", Icon: "fingerprint"},
+		{ID: 6, Name: "prod", Color: "red", Icon: "briefcase"},
+	}
+	if !slices.Equal(containers, want) {
+		t.Errorf("readContainers() = %v, want %v", containers, want)
+	}
+}
+
+func TestStyleForProfile(t *testing.T) {
+	prod := StyleForProfile("111111111111", "prod", "SSO")
+	deploy := StyleForProfile("111111111111", "prod-deploy", "IAM")
+	if prod.Color != deploy.Color {
+		t.Errorf("profiles of one account should share a color, got %s and %s", prod.Color, deploy.Color)
+	}
+	if prod.Icon != "briefcase" || deploy.Icon != "fingerprint" {
+		t.Errorf("unexpected icons %s and %s", prod.Icon, deploy.Icon)
+	}
+	for _, style := range []ContainerStyle{prod, deploy, StyleForProfile("", "legacy", "")} {
+		if !slices.Contains(firefoxColors, style.Color) || !slices.Contains(firefoxIcons, style.Icon) {
+			t.Errorf("%v isn't a valid container style", style)
+		}
+	}
+}
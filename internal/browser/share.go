@@ -0,0 +1,47 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/mattn/go-isatty"
+	"github.com/skip2/go-qrcode"
+)
+
+// CopyToClipboard copies text to the system clipboard. Without a clipboard
+// utility, e.g. over SSH, it asks the terminal to do it with an OSC 52 escape
+// sequence when stderr is a terminal; viaTerminal reports that, as not every
+// terminal supports it.
+func CopyToClipboard(text string) (viaTerminal bool, err error) {
+	err = clipboard.WriteAll(text)
+	if err == nil {
+		return false, nil
+	}
+	if !isatty.IsTerminal(os.Stderr.Fd()) {
+		return false, fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if os.Getenv("STY") != "" {
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		return false, fmt.Errorf("failed to copy to the clipboard: %w", err)
+	}
+	return true, nil
+}
+
+// QRCode renders text as a QR code for the terminal, two modules per
+// character row. Dark modules are left blank, so it reads on a dark
+// background, and the result is as many columns wide as it has modules.
+func QRCode(text string) (code string, width int, err error) {
+	qr, err := qrcode.New(text, qrcode.Low)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create QR code: %w", err)
+	}
+	return qr.ToSmallString(false), len(qr.Bitmap()), nil
+}
@@ -0,0 +1,27 @@
+package browser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQRCode(t *testing.T) {
+	code, width, err := QRCode("https://signin.aws.amazon.com/federation?Action=login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(code, "\n"), "\n")
+	// Two modules per row, rounded up
+	if want := (width + 1) / 2; len(lines) != want {
+		t.Errorf("expected %d rows for %d modules, got %d", want, width, len(lines))
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != width {
+			t.Fatalf("row %d is %d columns wide, want %d", i, n, width)
+		}
+	}
+
+	if _, _, err := QRCode(strings.Repeat("x", 5000)); err == nil {
+		t.Error("expected an error for text longer than a QR code holds")
+	}
+}
@@ -0,0 +1,101 @@
+// Package bugreport builds the archive 'awsm bugreport' writes: text files
+// describing the installation with every configuration value replaced by a
+// short hash, so reports can be attached to public issues.
+package bugreport
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// File is one file of a bug report.
+type File struct {
+	Name    string
+	Content string
+}
+
+// HashValue replaces a value with a short hash, so equal values can still be
+// told apart (e.g. two profiles using the same sso_session) without
+// revealing them.
+func HashValue(value string) string {
+	if value == "" {
+		return `""`
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// RedactINI returns the structure of an AWS config or credentials file:
+// section and key names with every value hashed. A missing file is reported
+// as such rather than as an error.
+func RedactINI(path string) (string, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "(file does not exist)\n", nil
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return fmt.Sprintf("(file does not parse: %v)\n", err), nil
+	}
+
+	var b strings.Builder
+	for _, section := range cfg.Sections() {
+		if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "[%s]\n", section.Name())
+		for _, key := range section.Keys() {
+			fmt.Fprintf(&b, "%s = %s\n", key.Name(), HashValue(key.Value()))
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// RedactSettings lists dotted awsm settings with their values hashed.
+func RedactSettings(settings map[string]any) string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s = %s\n", key, HashValue(fmt.Sprint(settings[key])))
+	}
+	return b.String()
+}
+
+// ReplaceHome shortens paths under home to ~, keeping user names out of the report.
+func ReplaceHome(text, home string) string {
+	if home == "" || home == "/" {
+		return text
+	}
+	return strings.ReplaceAll(text, home, "~")
+}
+
+// WriteZip writes the files into a zip archive.
+func WriteZip(w io.Writer, files []File, created time.Time) error {
+	archive := zip.NewWriter(w)
+	for _, f := range files {
+		header := &zip.FileHeader{Name: f.Name, Method: zip.Deflate, Modified: created}
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, f.Content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
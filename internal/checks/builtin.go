@@ -0,0 +1,241 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"awsm/internal/aws"
+	"awsm/internal/config"
+
+	ini "gopkg.in/ini.v1"
+)
+
+// maxClockSkew is the difference AWS accepts on signed requests.
+const maxClockSkew = 5 * time.Minute
+
+func init() {
+	Register(Check{Name: "aws-cli", Title: "AWS CLI", Run: checkAWSCLI})
+	Register(Check{Name: "config-files", Title: "AWS config files", Run: checkConfigFiles})
+	Register(Check{Name: "file-permissions", Title: "File permissions", Run: checkFilePermissions})
+	Register(Check{Name: "sso-tokens", Title: "SSO tokens", Run: checkSSOTokens})
+	Register(Check{Name: "region", Title: "Region", Run: checkRegion})
+	Register(Check{Name: "clock-skew", Title: "Clock", Network: true, Run: checkClockSkew})
+	Register(Check{Name: "endpoints", Title: "AWS endpoints", Network: true, Run: checkEndpoints})
+}
+
+func checkAWSCLI(ctx context.Context) Result {
+	version, warning, err := aws.CheckAWSCLI()
+	if err != nil {
+		// Only SSO login needs the CLI, and awsm logs in by itself when native
+		// login is enabled
+		severity := Error
+		if sessions, _ := aws.ListSSOSessions(); len(sessions) == 0 || config.GetSSONativeLogin() {
+			severity = Warning
+		}
+		message, hint, _ := strings.Cut(err.Error(), "\n\n")
+		return Result{Severity: severity, Message: message, Hint: hint}
+	}
+	if warning != "" {
+		return Result{Severity: Warning, Message: warning}
+	}
+	return Result{Severity: OK, Message: fmt.Sprintf("AWS CLI %s at %s", version, version.Path)}
+}
+
+func checkConfigFiles(ctx context.Context) Result {
+	configPath, err := aws.GetAWSConfigPath()
+	if err != nil {
+		return Result{Severity: Error, Message: err.Error()}
+	}
+	credentialsPath, err := aws.GetAWSCredentialsPath()
+	if err != nil {
+		return Result{Severity: Error, Message: err.Error()}
+	}
+
+	var problems []string
+	for _, path := range []string{configPath, credentialsPath} {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if _, err := ini.Load(path); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", path, strings.TrimSpace(err.Error())))
+		}
+	}
+	if len(problems) > 0 {
+		return Result{Severity: Error, Message: strings.Join(problems, "\n"), Hint: "Fix the syntax error in the file, e.g. with 'awsm profile edit'."}
+	}
+	return Result{Severity: OK, Message: "config and credentials files parse"}
+}
+
+func checkFilePermissions(ctx context.Context) Result {
+	findings, err := aws.FilePermissionFindings()
+	if err != nil {
+		return Result{Severity: Warning, Message: err.Error()}
+	}
+	if len(findings) == 0 {
+		return Result{Severity: OK, Message: "credentials are only readable by you"}
+	}
+
+	var titles, fixes []string
+	for _, f := range findings {
+		titles = append(titles, f.Title)
+		fixes = append(fixes, f.FixDescription)
+	}
+	return Result{
+		Severity: Warning,
+		Message:  strings.Join(titles, "\n"),
+		Hint:     "Run 'awsm security review' to fix it.",
+		Fix: &Fix{
+			Description: strings.Join(fixes, ", "),
+			Apply: func() error {
+				for _, f := range findings {
+					if err := f.Fix(); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func checkSSOTokens(ctx context.Context) Result {
+	sessions, err := aws.ListSSOSessions()
+	if err != nil {
+		return Result{Severity: Warning, Message: err.Error()}
+	}
+	if len(sessions) == 0 {
+		return Result{Severity: Skipped, Message: "no SSO sessions configured"}
+	}
+
+	var expired []string
+	for _, session := range sessions {
+		status, err := aws.GetSSOTokenStatus(session.Name)
+		if err != nil {
+			return Result{Severity: Warning, Message: err.Error()}
+		}
+		if status.NeedsLogin {
+			expired = append(expired, session.Name)
+		}
+	}
+	if len(expired) > 0 {
+		return Result{
+			Severity: Warning,
+			Message:  fmt.Sprintf("%d of %d SSO session(s) need a login: %s", len(expired), len(sessions), strings.Join(expired, ", ")),
+			Hint:     fmt.Sprintf("Run 'awsm sso login %s'.", expired[0]),
+		}
+	}
+	return Result{Severity: OK, Message: fmt.Sprintf("%d SSO session(s) logged in", len(sessions))}
+}
+
+// activeRegion returns the region AWS calls go to and where it comes from: the
+// environment, else the active profile. Source is empty when no profile is active.
+func activeRegion() (region, source string) {
+	region, source = os.Getenv("AWS_REGION"), "AWS_REGION"
+	if region == "" {
+		region, source = os.Getenv("AWS_DEFAULT_REGION"), "AWS_DEFAULT_REGION"
+	}
+	if region == "" {
+		profile := os.Getenv("AWS_PROFILE")
+		if profile == "" {
+			profile = aws.GetCurrentProfileName()
+		}
+		if profile == "" {
+			return "", ""
+		}
+		region, _ = aws.GetProfileRegion(profile)
+		source = fmt.Sprintf("profile %s", profile)
+	}
+	return region, source
+}
+
+func checkRegion(ctx context.Context) Result {
+	region, source := activeRegion()
+	if source == "" {
+		return Result{Severity: Skipped, Message: "no profile active"}
+	}
+	if region == "" {
+		return Result{Severity: Warning, Message: fmt.Sprintf("no region set for %s", source), Hint: "Run 'awsm region set <region>'."}
+	}
+	if !aws.IsValidConsoleRegion(region) {
+		return Result{Severity: Error, Message: fmt.Sprintf("%s from %s is not an AWS region", region, source), Hint: "Run 'awsm region set <region>' or correct the variable."}
+	}
+	return Result{Severity: OK, Message: fmt.Sprintf("%s (from %s)", region, source)}
+}
+
+func checkClockSkew(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	skew, err := aws.MeasureClockSkew(ctx)
+	if err != nil {
+		return Result{Severity: Warning, Message: err.Error()}
+	}
+	return clockSkewResult(skew)
+}
+
+// clockSkewResult rates a difference to the AWS clock. Small differences are
+// latency; past maxClockSkew AWS rejects signed requests.
+func clockSkewResult(skew time.Duration) Result {
+	direction := "behind"
+	if skew < 0 {
+		direction = "ahead of"
+	}
+	message := fmt.Sprintf("local clock is %s %s AWS", skew.Abs().Round(time.Second), direction)
+	switch {
+	case skew.Abs() >= maxClockSkew:
+		return Result{Severity: Error, Message: message, Hint: "Sync your system clock (e.g. enable NTP), AWS rejects requests signed this far off."}
+	case skew.Abs() >= time.Minute:
+		return Result{Severity: Warning, Message: message, Hint: "Sync your system clock (e.g. enable NTP)."}
+	default:
+		return Result{Severity: OK, Message: "in sync with AWS"}
+	}
+}
+
+// endpointTimeout bounds each reachability probe; AWS answers in well under a second.
+const endpointTimeout = 5 * time.Second
+
+func checkEndpoints(ctx context.Context) Result {
+	region, _ := activeRegion()
+	if !aws.IsValidConsoleRegion(region) {
+		// The region check reports it
+		region = ""
+	}
+	sessions, _ := aws.ListSSOSessions()
+	endpoints := aws.RequiredEndpoints(region, sessions)
+
+	errs := make([]error, len(endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, endpointTimeout)
+			defer cancel()
+			errs[i] = aws.ProbeEndpoint(ctx, endpoint.URL)
+		}()
+	}
+	wg.Wait()
+
+	var unreachable []string
+	for i, err := range errs {
+		if err != nil {
+			unreachable = append(unreachable, fmt.Sprintf("%s (%s): %v", endpoints[i].Name, endpoints[i].URL, err))
+		}
+	}
+	if len(unreachable) > 0 {
+		return Result{
+			Severity: Error,
+			Message:  fmt.Sprintf("%d of %d endpoints unreachable\n%s", len(unreachable), len(endpoints), strings.Join(unreachable, "\n")),
+			Hint:     "Check your network connection, VPN and firewall. Behind a proxy, set HTTPS_PROXY.",
+		}
+	}
+	names := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		names[i] = endpoint.Name
+	}
+	return Result{Severity: OK, Message: "reachable: " + strings.Join(names, ", ")}
+}
@@ -0,0 +1,170 @@
+// Package checks holds awsm's environment checks: named functions that look at
+// the local setup (AWS CLI, file permissions, SSO tokens, clock, region) and
+// report a severity with an optional fix. Commands run them from one registry
+// instead of each carrying its own ad-hoc version.
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a check result is.
+type Severity int
+
+const (
+	// OK means the check passed.
+	OK Severity = iota
+	// Skipped means the check does not apply, e.g. no SSO sessions exist.
+	Skipped
+	// Warning means awsm works but something should be looked at.
+	Warning
+	// Error means commands relying on the checked part will fail.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Skipped:
+		return "skipped"
+	case Warning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Fix resolves the problem a check found.
+type Fix struct {
+	Description string
+	Apply       func() error
+}
+
+// Result is the outcome of running a check. Hint tells the user how to
+// resolve a problem by hand; Fix is nil when it can't be fixed automatically.
+type Result struct {
+	Severity Severity
+	Message  string
+	Hint     string
+	Fix      *Fix
+}
+
+// Check is a named environment check.
+type Check struct {
+	// Name identifies the check on the command line, e.g. "aws-cli".
+	Name string
+	// Title describes what is checked, e.g. "AWS CLI".
+	Title string
+	// Network checks call AWS and are left out of quick runs.
+	Network bool
+	Run     func(ctx context.Context) Result
+}
+
+// Report pairs a check with its result.
+type Report struct {
+	Check  Check
+	Result Result
+}
+
+var registry []Check
+
+// Register adds a check. Names must be unique.
+func Register(check Check) {
+	if _, ok := Get(check.Name); ok {
+		panic(fmt.Sprintf("check %q registered twice", check.Name))
+	}
+	registry = append(registry, check)
+}
+
+// All returns the registered checks in registration order.
+func All() []Check {
+	return append([]Check(nil), registry...)
+}
+
+// Names returns the names of the registered checks.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, check := range registry {
+		names[i] = check.Name
+	}
+	return names
+}
+
+// Get returns the check with the given name.
+func Get(name string) (Check, bool) {
+	for _, check := range registry {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+// Select returns the checks with the given names, or all checks when names is
+// empty. Network checks are left out unless network is true or they are named.
+func Select(names []string, network bool) ([]Check, error) {
+	if len(names) == 0 {
+		var selected []Check
+		for _, check := range registry {
+			if network || !check.Network {
+				selected = append(selected, check)
+			}
+		}
+		return selected, nil
+	}
+	selected := make([]Check, 0, len(names))
+	for _, name := range names {
+		check, ok := Get(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown check '%s' (available: %s)", name, strings.Join(Names(), ", "))
+		}
+		selected = append(selected, check)
+	}
+	return selected, nil
+}
+
+// Run runs the checks in order.
+func Run(ctx context.Context, checks []Check) []Report {
+	reports := make([]Report, len(checks))
+	for i, check := range checks {
+		reports[i] = Report{Check: check, Result: check.Run(ctx)}
+	}
+	return reports
+}
+
+// Worst returns the highest severity among the reports.
+func Worst(reports []Report) Severity {
+	worst := OK
+	for _, r := range reports {
+		if r.Result.Severity > worst {
+			worst = r.Result.Severity
+		}
+	}
+	return worst
+}
+
+// Preflight runs the named checks before a command does its work. It returns
+// the first error result as an error and passes warnings to warn.
+func Preflight(ctx context.Context, warn func(Report), names ...string) error {
+	selected, err := Select(names, true)
+	if err != nil {
+		return err
+	}
+	for _, r := range Run(ctx, selected) {
+		switch r.Result.Severity {
+		case Error:
+			if r.Result.Hint != "" {
+				return fmt.Errorf("%s: %s\n\n%s", r.Check.Title, r.Result.Message, r.Result.Hint)
+			}
+			return fmt.Errorf("%s: %s", r.Check.Title, r.Result.Message)
+		case Warning:
+			if warn != nil {
+				warn(r)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package checks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+	quick, err := Select(nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range quick {
+		if check.Network {
+			t.Errorf("quick selection includes network check %s", check.Name)
+		}
+	}
+	all, _ := Select(nil, true)
+	if len(all) != len(Names()) {
+		t.Errorf("Select(nil, true) returned %d checks, want %d", len(all), len(Names()))
+	}
+
+	named, err := Select([]string{"clock-skew"}, false)
+	if err != nil || len(named) != 1 || named[0].Name != "clock-skew" {
+		t.Errorf("Select(clock-skew) = %v, %v", named, err)
+	}
+	if _, err := Select([]string{"nope"}, false); err == nil {
+		t.Error("expected error for unknown check")
+	}
+}
+
+func TestPreflight(t *testing.T) {
+	registry = append(registry,
+		Check{Name: "test-warn", Title: "Warn", Run: func(context.Context) Result {
+			return Result{Severity: Warning, Message: "careful"}
+		}},
+		Check{Name: "test-error", Title: "Broken", Run: func(context.Context) Result {
+			return Result{Severity: Error, Message: "broken", Hint: "fix it"}
+		}},
+	)
+	defer func() { registry = registry[:len(registry)-2] }()
+
+	var warned []string
+	warn := func(r Report) { warned = append(warned, r.Check.Name) }
+
+	if err := Preflight(context.Background(), warn, "test-warn"); err != nil {
+		t.Errorf("warning should not fail the preflight: %v", err)
+	}
+	if len(warned) != 1 || warned[0] != "test-warn" {
+		t.Errorf("warned = %v", warned)
+	}
+
+	err := Preflight(context.Background(), warn, "test-error")
+	if err == nil || !strings.Contains(err.Error(), "broken") || !strings.Contains(err.Error(), "fix it") {
+		t.Errorf("Preflight() error = %v, want message and hint", err)
+	}
+}
+
+func TestCheckConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config")
+	t.Setenv("AWS_CONFIG_FILE", configPath)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	if r := checkConfigFiles(context.Background()); r.Severity != OK {
+		t.Errorf("missing files: got %s %q, want ok", r.Severity, r.Message)
+	}
+
+	if err := os.WriteFile(configPath, []byte("[profile dev\nregion = eu-west-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if r := checkConfigFiles(context.Background()); r.Severity != Error || !strings.Contains(r.Message, configPath) {
+		t.Errorf("broken config: got %s %q, want error naming the file", r.Severity, r.Message)
+	}
+}
+
+func TestClockSkewResult(t *testing.T) {
+	tests := []struct {
+		skew time.Duration
+		want Severity
+	}{
+		{2 * time.Second, OK},
+		{-90 * time.Second, Warning},
+		{2 * time.Minute, Warning},
+		{-6 * time.Minute, Error},
+	}
+	for _, tt := range tests {
+		if got := clockSkewResult(tt.skew); got.Severity != tt.want {
+			t.Errorf("clockSkewResult(%s) = %s, want %s", tt.skew, got.Severity, tt.want)
+		}
+	}
+}
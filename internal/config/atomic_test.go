@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(target, []byte("old"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	write := func(content string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, content)
+			return err
+		}
+	}
+
+	// Writing through a symlink replaces its target and keeps the link
+	if err := WriteFileAtomic(link, 0600, write("new")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected %s to stay a symlink", link)
+	}
+	data, _ := os.ReadFile(target)
+	if string(data) != "new" {
+		t.Errorf("Expected the target to be replaced, got %q", data)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected permissions 0640 to be kept, got %v", info.Mode().Perm())
+	}
+
+	// A failed write leaves the old content and no temporary file
+	err := WriteFileAtomic(target, 0600, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return errors.New("interrupted")
+	})
+	if err == nil {
+		t.Fatal("Expected the write error to be returned")
+	}
+	data, _ = os.ReadFile(target)
+	if string(data) != "new" {
+		t.Errorf("Expected a failed write to keep the old content, got %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("Expected no temporary files left, got %v", entries)
+	}
+}
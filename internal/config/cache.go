@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// DefaultCacheMaxAge is how long awsm keeps entries of its state
+	// directory (expired credentials, policy override log entries, stashed
+	// sessions) unless cache.max_age says otherwise.
+	DefaultCacheMaxAge = 30 * 24 * time.Hour
+	// DefaultCacheMaxSize is the size the state directory is compacted to
+	// unless cache.max_size says otherwise.
+	DefaultCacheMaxSize int64 = 10 << 20
+)
+
+// GetCacheMaxAge returns cache.max_age, e.g. "720h" or "30d". An invalid value
+// yields the default along with the error.
+func GetCacheMaxAge() (time.Duration, error) {
+	value := strings.TrimSpace(viper.GetString("cache.max_age"))
+	if value == "" {
+		return DefaultCacheMaxAge, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return DefaultCacheMaxAge, fmt.Errorf("invalid cache.max_age '%s', expected a duration such as 720h or 30d", value)
+}
+
+// GetCacheMaxSize returns cache.max_size in bytes, e.g. "10MB". An invalid
+// value yields the default along with the error.
+func GetCacheMaxSize() (int64, error) {
+	value := strings.TrimSpace(viper.GetString("cache.max_size"))
+	if value == "" {
+		return DefaultCacheMaxSize, nil
+	}
+	size, err := ParseByteSize(value)
+	if err != nil {
+		return DefaultCacheMaxSize, fmt.Errorf("invalid cache.max_size: %w", err)
+	}
+	return size, nil
+}
+
+// ParseByteSize reads a size in bytes with an optional KB, MB or GB suffix
+// (powers of 1024).
+func ParseByteSize(value string) (int64, error) {
+	number := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30}, {"B", 1}} {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier = strings.TrimSpace(trimmed), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("'%s' is not a size such as 512KB or 10MB", value)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int64
+		wantErr  bool
+	}{
+		{"1024", 1024, false},
+		{"512KB", 512 << 10, false},
+		{"10 mb", 10 << 20, false},
+		{"1GB", 1 << 30, false},
+		{"100B", 100, false},
+		{"0", 0, true},
+		{"-1MB", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseByteSize(%q) = %d, %v; expected %d", tt.value, got, err, tt.expected)
+		}
+	}
+}
+
+func TestGetCacheMaxAge(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"", DefaultCacheMaxAge, false},
+		{"48h", 48 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0d", DefaultCacheMaxAge, true},
+		{"soon", DefaultCacheMaxAge, true},
+	}
+
+	for _, tt := range tests {
+		viper.Set("cache.max_age", tt.value)
+		got, err := GetCacheMaxAge()
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("GetCacheMaxAge(%q) = %s, %v; expected %s", tt.value, got, err, tt.expected)
+		}
+	}
+}
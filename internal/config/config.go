@@ -3,7 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"sort"
 
 	"github.com/spf13/viper"
 )
@@ -11,16 +11,13 @@ import (
 // InitConfig initializes Viper to read the awsm configuration file.
 // It should be called once when the application starts.
 func InitConfig() {
-	home, err := os.UserHomeDir()
+	configPath, err := ConfigDir()
 	if err != nil {
 		// This is unlikely to fail, but handle it gracefully.
 		fmt.Fprintln(os.Stderr, "Warning: Could not find home directory. Chrome profile mapping will not work.")
 		return
 	}
 
-	// Set the path for the config file: ~/.config/awsm/
-	configPath := filepath.Join(home, ".config", "awsm")
-
 	// Set Viper's configuration
 	viper.AddConfigPath(configPath) // Where to look for the config file
 	viper.SetConfigName("config")   // Name of the config file (without extension)
@@ -51,3 +48,62 @@ func GetChromeProfileDirectory(alias string) string {
 	// No mapping found, assume the input is already a directory name.
 	return alias
 }
+
+// GetChromeProfileAliases returns the aliases configured in
+// [chrome_profiles], sorted.
+func GetChromeProfileAliases() []string {
+	var aliases []string
+	for alias := range viper.GetStringMapString("chrome_profiles") {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// GetSTSRegionalEndpoints returns the global default for which STS endpoint
+// awsm should call ("regional" or "legacy"). It is empty when not configured.
+func GetSTSRegionalEndpoints() string {
+	return viper.GetString("sts_regional_endpoints")
+}
+
+// GetSSOChromeProfile returns the Chrome profile (alias or directory) SSO
+// login pages are opened in. It is empty when the AWS CLI should use the
+// default browser.
+func GetSSOChromeProfile() string {
+	return viper.GetString("sso.chrome_profile")
+}
+
+// GetSSONoBrowser reports whether SSO logins should only print the login URL
+// and code, e.g. on a remote machine without a browser.
+func GetSSONoBrowser() bool {
+	return viper.GetBool("sso.no_browser")
+}
+
+// GetSSONativeLogin reports whether SSO logins should use awsm's built-in
+// device authorization flow even when the AWS CLI is installed.
+func GetSSONativeLogin() bool {
+	return viper.GetBool("sso.native_login")
+}
+
+// GetSSONameTemplate returns the template naming profiles generated from SSO
+// (sso.name_template), empty for the default <account>-<role> names.
+func GetSSONameTemplate() string {
+	return viper.GetString("sso.name_template")
+}
+
+// GetSSONameKeepCase reports whether generated profile names keep the case of
+// account and role names instead of lowercasing them.
+func GetSSONameKeepCase() bool {
+	return viper.GetBool("sso.name_keep_case")
+}
+
+// GetSSONameMaxLength returns the length generated profile names are shortened
+// to, 0 for no limit.
+func GetSSONameMaxLength() int {
+	return viper.GetInt("sso.name_max_length")
+}
+
+// GetAliases returns the command aliases set with 'awsm alias set', by name.
+func GetAliases() map[string]string {
+	return viper.GetStringMapString("aliases")
+}
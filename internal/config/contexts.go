@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// ContextEnv names the context to use instead of the one selected with
+	// 'awsm context use', e.g. for a single command.
+	ContextEnv = "AWSM_CONTEXT"
+	// DefaultContext is the context of the regular ~/.aws files (or whatever
+	// AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE point to).
+	DefaultContext = "default"
+)
+
+var contextNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// AWSContext is a named pair of AWS config and credentials files, e.g. to
+// keep work and personal accounts apart.
+type AWSContext struct {
+	Name            string `json:"name"`
+	ConfigFile      string `json:"config_file"`
+	CredentialsFile string `json:"credentials_file"`
+}
+
+// ValidateContextName checks that name can be used as a context name.
+func ValidateContextName(name string) error {
+	if name == DefaultContext {
+		return fmt.Errorf("'%s' is the context of the regular AWS files and can't be redefined", DefaultContext)
+	}
+	if !contextNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid context name '%s': use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ContextFromDir returns a context using the config and credentials files in dir.
+func ContextFromDir(name, dir string) (AWSContext, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return AWSContext{}, err
+	}
+	return AWSContext{Name: name, ConfigFile: filepath.Join(dir, "config"), CredentialsFile: filepath.Join(dir, "credentials")}, nil
+}
+
+// GetContexts returns the contexts defined in config.toml, sorted by name.
+func GetContexts() []AWSContext {
+	var contexts []AWSContext
+	for name := range viper.GetStringMap("contexts") {
+		if c, ok := GetContext(name); ok {
+			contexts = append(contexts, c)
+		}
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+	return contexts
+}
+
+// GetContext returns the context with the given name.
+func GetContext(name string) (AWSContext, bool) {
+	key := "contexts." + name
+	if !viper.IsSet(key) {
+		return AWSContext{}, false
+	}
+	return AWSContext{
+		Name:            name,
+		ConfigFile:      viper.GetString(key + ".config_file"),
+		CredentialsFile: viper.GetString(key + ".credentials_file"),
+	}, true
+}
+
+// SaveContext adds or replaces a context in config.toml.
+func SaveContext(c AWSContext) error {
+	if err := SetSetting("contexts."+c.Name+".config_file", c.ConfigFile); err != nil {
+		return err
+	}
+	return SetSetting("contexts."+c.Name+".credentials_file", c.CredentialsFile)
+}
+
+// RemoveContext deletes a context from config.toml, and deselects it when it
+// was the active one.
+func RemoveContext(name string) error {
+	if viper.GetString("context") == name {
+		if err := UnsetSetting("context", ""); err != nil {
+			return err
+		}
+	}
+	return UnsetSetting("contexts."+name, "")
+}
+
+// UseContext selects the context all awsm commands operate on.
+func UseContext(name string) error {
+	if name == DefaultContext {
+		return UnsetSetting("context", "")
+	}
+	if _, ok := GetContext(name); !ok {
+		return fmt.Errorf("context '%s' not found", name)
+	}
+	return SetSetting("context", name)
+}
+
+// ActiveContextName returns the context from AWSM_CONTEXT or the one selected
+// with 'awsm context use', DefaultContext when none is.
+func ActiveContextName() string {
+	if name := os.Getenv(ContextEnv); name != "" {
+		return name
+	}
+	if name := viper.GetString("context"); name != "" {
+		return name
+	}
+	return DefaultContext
+}
+
+// ApplyActiveContext points AWS_CONFIG_FILE and AWS_SHARED_CREDENTIALS_FILE at
+// the files of the active context, so awsm, the AWS SDK and the AWS CLI it
+// runs all use them. The default context leaves the environment alone.
+func ApplyActiveContext() error {
+	name := ActiveContextName()
+	if name == DefaultContext {
+		return nil
+	}
+	c, ok := GetContext(name)
+	if !ok {
+		return fmt.Errorf("context '%s' not found, run 'awsm context list'", name)
+	}
+	os.Setenv("AWS_CONFIG_FILE", c.ConfigFile)
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", c.CredentialsFile)
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestContexts(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(HomeEnv, dir)
+	t.Setenv(ContextEnv, "")
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+	t.Cleanup(viper.Reset)
+	InitConfig()
+
+	if got := ActiveContextName(); got != DefaultContext {
+		t.Errorf("Expected the default context, got %s", got)
+	}
+	if err := UseContext("work"); err == nil {
+		t.Error("Expected an error using an unknown context")
+	}
+
+	work, err := ContextFromDir("work", filepath.Join(dir, "aws-work"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveContext(work); err != nil {
+		t.Fatal(err)
+	}
+	if err := UseContext("work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyActiveContext(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("AWS_CONFIG_FILE"); got != filepath.Join(dir, "aws-work", "config") {
+		t.Errorf("Expected AWS_CONFIG_FILE of the work context, got %s", got)
+	}
+	if got := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); got != filepath.Join(dir, "aws-work", "credentials") {
+		t.Errorf("Expected AWS_SHARED_CREDENTIALS_FILE of the work context, got %s", got)
+	}
+
+	// AWSM_CONTEXT wins over the selected context
+	t.Setenv(ContextEnv, "missing")
+	if err := ApplyActiveContext(); err == nil {
+		t.Error("Expected an error for an unknown AWSM_CONTEXT")
+	}
+	t.Setenv(ContextEnv, "")
+
+	if err := RemoveContext("work"); err != nil {
+		t.Fatal(err)
+	}
+	if len(GetContexts()) != 0 {
+		t.Errorf("Expected no contexts after removal, got %v", GetContexts())
+	}
+	if got := ActiveContextName(); got != DefaultContext {
+		t.Errorf("Expected removing the active context to select the default, got %s", got)
+	}
+}
+
+func TestValidateContextName(t *testing.T) {
+	for _, name := range []string{"work", "client-a", "p_2"} {
+		if err := ValidateContextName(name); err != nil {
+			t.Errorf("ValidateContextName(%s) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "default", "Work", "a b", "-x"} {
+		if err := ValidateContextName(name); err == nil {
+			t.Errorf("ValidateContextName(%q) should fail", name)
+		}
+	}
+}
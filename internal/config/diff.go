@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// diffLine is a line of an edit script: ' ' kept, '-' removed or '+' added.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// UnifiedDiff returns the changes between two versions of a file in unified
+// diff format with contextLines of context around each change, or an empty
+// string when they are equal.
+func UnifiedDiff(fromName, toName, from, to string, contextLines int) string {
+	if from == to {
+		return ""
+	}
+	script := diffLines(splitLines(from), splitLines(to))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(script); {
+		// Find the next change and extend the hunk while the gaps between
+		// changes are short enough to share context
+		first := start
+		for first < len(script) && script[first].op == ' ' {
+			first++
+		}
+		if first == len(script) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(script); i++ {
+			if script[i].op == ' ' {
+				continue
+			}
+			if i-last-1 > 2*contextLines {
+				break
+			}
+			last = i
+		}
+		begin := max(first-contextLines, start)
+		end := min(last+contextLines+1, len(script))
+		writeHunk(&out, script, begin, end)
+		start = end
+	}
+	return out.String()
+}
+
+// writeHunk writes script[begin:end] with its @@ header.
+func writeHunk(out *strings.Builder, script []diffLine, begin, end int) {
+	fromLine, toLine := 0, 0
+	for _, l := range script[:begin] {
+		if l.op != '+' {
+			fromLine++
+		}
+		if l.op != '-' {
+			toLine++
+		}
+	}
+	fromCount, toCount := 0, 0
+	for _, l := range script[begin:end] {
+		if l.op != '+' {
+			fromCount++
+		}
+		if l.op != '-' {
+			toCount++
+		}
+	}
+	// Empty ranges point at the line before them
+	if fromCount > 0 {
+		fromLine++
+	}
+	if toCount > 0 {
+		toLine++
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", fromLine, fromCount, toLine, toCount)
+	for _, l := range script[begin:end] {
+		out.WriteByte(l.op)
+		out.WriteString(l.text)
+		out.WriteByte('\n')
+	}
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines computes the shortest edit script from a to b with Myers'
+// algorithm. Only the part of each round's frontier that the backtracking
+// reads is kept, so memory grows with the square of the edit distance rather
+// than with the size of the files.
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	// trace[d] holds v[offset-d-1 : offset+d+2] as it was before round d
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		trace = append(trace, slices.Clone(v[offset-d-1:offset+d+2]))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, a, b []string) []diffLine {
+	var script []diffLine
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			script = append(script, diffLine{' ', a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			script = append(script, diffLine{'+', b[prevY]})
+		} else {
+			script = append(script, diffLine{'-', a[prevX]})
+		}
+		x, y = prevX, prevY
+	}
+	slices.Reverse(script)
+	return script
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "[profile a]\nregion = us-east-1\n\n[profile b]\nregion = us-east-1\n"
+	to := "[profile a]\nregion = eu-west-1\n\n[profile b]\nregion = us-east-1\n\n[profile c]\nregion = us-east-1\n"
+
+	want := `--- old
++++ new
+@@ -1,5 +1,8 @@
+ [profile a]
+-region = us-east-1
++region = eu-west-1
+ 
+ [profile b]
+ region = us-east-1
++
++[profile c]
++region = us-east-1
+`
+	if got := UnifiedDiff("old", "new", from, to, 3); got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := UnifiedDiff("old", "new", from, from, 3); got != "" {
+		t.Errorf("expected no diff for equal content, got\n%s", got)
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	from := strings.Join(lines, "\n") + "\n"
+	lines[1] = "changed 2"
+	lines[17] = "changed 18"
+	to := strings.Join(lines, "\n") + "\n"
+
+	got := UnifiedDiff("old", "new", from, to, 1)
+	for _, header := range []string{"@@ -1,3 +1,3 @@", "@@ -17,3 +17,3 @@"} {
+		if !strings.Contains(got, header) {
+			t.Errorf("expected hunk %s in\n%s", header, got)
+		}
+	}
+	if strings.Count(got, "@@ -") != 2 {
+		t.Errorf("expected two hunks, got\n%s", got)
+	}
+
+	// Everything added to an empty file
+	if got := UnifiedDiff("old", "new", "", "a\nb\n", 3); !strings.Contains(got, "@@ -0,0 +1,2 @@\n+a\n+b\n") {
+		t.Errorf("unexpected diff from an empty file:\n%s", got)
+	}
+}
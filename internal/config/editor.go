@@ -2,11 +2,26 @@ package config
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strings"
 )
 
+// GeneratedProfileMarker is the comment 'awsm sso generate' writes into every profile
+// it creates, so generated profiles can be told apart from hand-written ones.
+const GeneratedProfileMarker = "# Managed by awsm sso generate"
+
+// IsGeneratedProfileContent reports whether a profile section carries the generated profile marker.
+func IsGeneratedProfileContent(profileContent string) bool {
+	for _, line := range strings.Split(profileContent, "\n") {
+		if strings.TrimSpace(line) == GeneratedProfileMarker {
+			return true
+		}
+	}
+	return false
+}
+
 // ExtractProfileConfig extracts just the configuration lines from a profile section
 func ExtractProfileConfig(profileContent string) string {
 	lines := strings.Split(profileContent, "\n")
@@ -31,22 +46,20 @@ func RemoveProfileFromConfig(config, profileName string) string {
 		return config
 	}
 
-	// Find the start of the profile
-	profileStart := match[0]
+	// Remove the profile section, up to the next section of any kind
+	return config[:match[0]] + config[sectionEnd(config, match[1]):]
+}
 
-	// Find the end of the profile (next profile or end of file)
-	nextProfileRegex := regexp.MustCompile(`(?m)^\[profile [^\]]+\]`)
-	nextMatches := nextProfileRegex.FindAllStringIndex(config[match[1]:], -1)
+// sectionHeaderRegex matches any ini section header, e.g. [profile x] or [sso-session y].
+var sectionHeaderRegex = regexp.MustCompile(`(?m)^\[[^\]]+\]`)
 
-	var profileEnd int
-	if len(nextMatches) > 0 {
-		profileEnd = match[1] + nextMatches[0][0]
-	} else {
-		profileEnd = len(config)
+// sectionEnd returns the offset where the section whose header ends at from stops:
+// the start of the next section header, or the end of the content.
+func sectionEnd(content string, from int) int {
+	if next := sectionHeaderRegex.FindStringIndex(content[from:]); next != nil {
+		return from + next[0]
 	}
-
-	// Remove the profile section
-	return config[:profileStart] + config[profileEnd:]
+	return len(content)
 }
 
 // ExtractProfileNamesFromContent extracts profile names from generated profile content
@@ -77,15 +90,8 @@ func ParseExistingProfiles(configContent string) (map[string]bool, map[string]st
 			profileName := match[1]
 			profileStart := strings.Index(configContent, match[0])
 			if profileStart != -1 {
-				// Find the end of this profile (next profile or end of file)
-				nextProfileStart := len(configContent)
-				for _, nextMatch := range profileHeaderRegex.FindAllStringSubmatch(configContent[profileStart+len(match[0]):], -1) {
-					if len(nextMatch) > 1 {
-						nextProfileStart = profileStart + len(match[0]) + strings.Index(configContent[profileStart+len(match[0]):], nextMatch[0])
-						break
-					}
-				}
-				existingProfileContent[profileName] = configContent[profileStart:nextProfileStart]
+				// The profile ends where the next section (of any kind) starts
+				existingProfileContent[profileName] = configContent[profileStart:sectionEnd(configContent, profileStart+len(match[0]))]
 			}
 		}
 	}
@@ -104,7 +110,11 @@ func ReadConfigFile(path string) (string, error) {
 	return string(data), nil
 }
 
-// WriteConfigFile writes the content to the file at the given path
+// WriteConfigFile replaces the file at the given path with content in one
+// step, keeping the permissions of an existing file.
 func WriteConfigFile(path, content string) error {
-	return os.WriteFile(path, []byte(content), 0600)
+	return WriteFileAtomic(path, 0600, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -83,3 +84,23 @@ region = us-west-2
 		t.Error("Expected content for p1")
 	}
 }
+
+func TestRemoveProfileFromConfigKeepsFollowingSession(t *testing.T) {
+	config := `[profile p1]
+region = us-east-1
+
+[sso-session corp]
+sso_region = eu-west-1
+`
+	expected := `[sso-session corp]
+sso_region = eu-west-1
+`
+	if result := RemoveProfileFromConfig(config, "p1"); result != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, result)
+	}
+
+	_, content := ParseExistingProfiles(config)
+	if strings.Contains(content["p1"], "sso-session") {
+		t.Errorf("Profile content should stop at the next section, got %q", content["p1"])
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// HomeEnv names a directory that replaces ~/.awsm and ~/.config/awsm, so
+	// awsm can run sandboxed without touching the user's own state.
+	HomeEnv = "AWSM_HOME"
+	// IsolateAWSEnv, when set to a true value, also moves the AWS config,
+	// credentials and SSO cache into the HomeEnv directory.
+	IsolateAWSEnv = "AWSM_ISOLATE_AWS"
+)
+
+// StateDir returns the directory for the awsm state and credential cache:
+// AWSM_HOME when set, ~/.awsm otherwise.
+func StateDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".awsm"), nil
+}
+
+// ConfigDir returns the directory holding config.toml and policy.toml:
+// AWSM_HOME when set, ~/.config/awsm otherwise.
+func ConfigDir() (string, error) {
+	if dir := os.Getenv(HomeEnv); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "awsm"), nil
+}
+
+// SetHome points awsm at dir for the rest of the process, as if AWSM_HOME
+// were set. With isolateAWS the home directory seen by awsm, the AWS SDK and
+// the AWS CLI it runs becomes dir/home, so ~/.aws and the SSO token cache live
+// in dir/home/.aws and AWS_CONFIG_FILE or AWS_SHARED_CREDENTIALS_FILE from the
+// environment no longer apply.
+func SetHome(dir string, isolateAWS bool) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", HomeEnv, err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	os.Setenv(HomeEnv, dir)
+	if !isolateAWS {
+		return nil
+	}
+
+	home := filepath.Join(dir, "home")
+	if err := os.MkdirAll(filepath.Join(home, ".aws"), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", home, err)
+	}
+	if runtime.GOOS == "windows" {
+		os.Setenv("USERPROFILE", home)
+	}
+	os.Setenv("HOME", home)
+	os.Setenv("AWS_CONFIG_FILE", filepath.Join(home, ".aws", "config"))
+	os.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(home, ".aws", "credentials"))
+	return nil
+}
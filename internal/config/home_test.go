@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHomeDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(HomeEnv, "")
+	t.Setenv("AWS_CONFIG_FILE", "")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "")
+
+	if dir, _ := StateDir(); dir != filepath.Join(home, ".awsm") {
+		t.Errorf("Expected state in ~/.awsm, got %s", dir)
+	}
+	if dir, _ := ConfigDir(); dir != filepath.Join(home, ".config", "awsm") {
+		t.Errorf("Expected config in ~/.config/awsm, got %s", dir)
+	}
+
+	sandbox := filepath.Join(t.TempDir(), "sandbox")
+	if err := SetHome(sandbox, false); err != nil {
+		t.Fatal(err)
+	}
+	if dir, _ := StateDir(); dir != sandbox {
+		t.Errorf("Expected state in %s, got %s", sandbox, dir)
+	}
+	if dir, _ := ConfigDir(); dir != sandbox {
+		t.Errorf("Expected config in %s, got %s", sandbox, dir)
+	}
+	if path, _ := StatePath(); path != filepath.Join(sandbox, "state.json") {
+		t.Errorf("Unexpected state path %s", path)
+	}
+	if got, _ := os.UserHomeDir(); got != home {
+		t.Errorf("Expected HOME to be kept without isolation, got %s", got)
+	}
+
+	if err := SetHome(sandbox, true); err != nil {
+		t.Fatal(err)
+	}
+	isolated := filepath.Join(sandbox, "home")
+	if got, _ := os.UserHomeDir(); got != isolated {
+		t.Errorf("Expected HOME %s, got %s", isolated, got)
+	}
+	if got := os.Getenv("AWS_CONFIG_FILE"); got != filepath.Join(isolated, ".aws", "config") {
+		t.Errorf("Unexpected AWS_CONFIG_FILE %s", got)
+	}
+	if info, err := os.Stat(filepath.Join(isolated, ".aws")); err != nil || !info.IsDir() {
+		t.Errorf("Expected %s/.aws to be created (%v)", isolated, err)
+	}
+}
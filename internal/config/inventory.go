@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// InventoryAccount is one account entry read from an external account inventory.
+// Name, Role and Region are optional and may be filled in from defaults by the caller.
+type InventoryAccount struct {
+	AccountID string
+	Name      string
+	Role      string
+	Region    string
+}
+
+var accountIDRegex = regexp.MustCompile(`^\d{12}$`)
+
+// profileNameRegex limits profile names from untrusted input to characters that
+// are safe both in an INI section header and in the cache file names derived from them.
+var profileNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// IsValidProfileName reports whether a profile name read from an inventory or
+// a shared file can safely be written to the AWS config.
+func IsValidProfileName(name string) bool {
+	return profileNameRegex.MatchString(name)
+}
+
+// ParseInventoryCSV reads accounts from a CSV file with a header row.
+// The account_id column is required; role, name and region are optional and
+// columns may appear in any order.
+func ParseInventoryCSV(r io.Reader) ([]InventoryAccount, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["account_id"]; !ok {
+		return nil, fmt.Errorf("CSV header must contain an 'account_id' column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var accounts []InventoryAccount
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+		account := InventoryAccount{
+			AccountID: field(record, "account_id"),
+			Name:      field(record, "name"),
+			Role:      field(record, "role"),
+			Region:    field(record, "region"),
+		}
+		if account.AccountID == "" {
+			continue
+		}
+		if !accountIDRegex.MatchString(account.AccountID) {
+			return nil, fmt.Errorf("invalid account ID '%s' on CSV line %d", account.AccountID, line)
+		}
+		if account.Name != "" && !IsValidProfileName(account.Name) {
+			return nil, fmt.Errorf("invalid profile name '%s' on CSV line %d: use letters, digits, '.', '_' and '-'", account.Name, line)
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+// ParseTerraformState extracts accounts from a Terraform state file (format v4),
+// using aws_organizations_account resources and the accounts attribute of
+// aws_organizations_organization.
+func ParseTerraformState(r io.Reader) ([]InventoryAccount, error) {
+	var state struct {
+		Resources []struct {
+			Mode      string `json:"mode"`
+			Type      string `json:"type"`
+			Instances []struct {
+				Attributes json.RawMessage `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to parse Terraform state: %w", err)
+	}
+
+	type tfAccount struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+
+	seen := make(map[string]bool)
+	var accounts []InventoryAccount
+	add := func(a tfAccount) {
+		if !accountIDRegex.MatchString(a.ID) || seen[a.ID] || (a.Status != "" && a.Status != "ACTIVE") {
+			return
+		}
+		seen[a.ID] = true
+		accounts = append(accounts, InventoryAccount{AccountID: a.ID, Name: a.Name})
+	}
+
+	for _, res := range state.Resources {
+		if res.Mode != "" && res.Mode != "managed" && res.Mode != "data" {
+			continue
+		}
+		for _, inst := range res.Instances {
+			switch res.Type {
+			case "aws_organizations_account":
+				var a tfAccount
+				if err := json.Unmarshal(inst.Attributes, &a); err != nil {
+					return nil, fmt.Errorf("failed to parse aws_organizations_account attributes: %w", err)
+				}
+				add(a)
+			case "aws_organizations_organization":
+				var org struct {
+					Accounts []tfAccount `json:"accounts"`
+				}
+				if err := json.Unmarshal(inst.Attributes, &org); err != nil {
+					return nil, fmt.Errorf("failed to parse aws_organizations_organization attributes: %w", err)
+				}
+				for _, a := range org.Accounts {
+					add(a)
+				}
+			}
+		}
+	}
+	return accounts, nil
+}
+
+// ParseOrganizationsExport reads the JSON output of `aws organizations list-accounts`.
+// Accounts that are not ACTIVE are skipped.
+func ParseOrganizationsExport(r io.Reader) ([]InventoryAccount, error) {
+	var export struct {
+		Accounts []struct {
+			ID     string `json:"Id"`
+			Name   string `json:"Name"`
+			Status string `json:"Status"`
+		} `json:"Accounts"`
+	}
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("failed to parse organizations export: %w", err)
+	}
+
+	var accounts []InventoryAccount
+	for _, a := range export.Accounts {
+		if a.Status != "" && a.Status != "ACTIVE" {
+			continue
+		}
+		if !accountIDRegex.MatchString(a.ID) {
+			return nil, fmt.Errorf("invalid account ID '%s' in organizations export", a.ID)
+		}
+		accounts = append(accounts, InventoryAccount{AccountID: a.ID, Name: a.Name})
+	}
+	return accounts, nil
+}
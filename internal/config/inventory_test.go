@@ -0,0 +1,79 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseInventoryCSV(t *testing.T) {
+	input := `name,account_id,role,region
+dev,111111111111,Admin,eu-west-1
+prod, 222222222222 ,ReadOnly,
+`
+	accounts, err := ParseInventoryCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseInventoryCSV failed: %v", err)
+	}
+	if len(accounts) != 2 {
+		t.Fatalf("Expected 2 accounts, got %d", len(accounts))
+	}
+	if accounts[0] != (InventoryAccount{AccountID: "111111111111", Name: "dev", Role: "Admin", Region: "eu-west-1"}) {
+		t.Errorf("Unexpected first account: %+v", accounts[0])
+	}
+	if accounts[1].AccountID != "222222222222" || accounts[1].Region != "" {
+		t.Errorf("Unexpected second account: %+v", accounts[1])
+	}
+
+	if _, err := ParseInventoryCSV(strings.NewReader("name,role\ndev,Admin\n")); err == nil {
+		t.Error("Expected error for missing account_id column")
+	}
+	if _, err := ParseInventoryCSV(strings.NewReader("account_id\n1234\n")); err == nil {
+		t.Error("Expected error for invalid account ID")
+	}
+	for _, name := range []string{"dev]\ncredential_process = evil", "../../etc/passwd", "my profile", ".hidden"} {
+		input := "account_id,name\n111111111111,\"" + name + "\"\n"
+		if _, err := ParseInventoryCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for invalid profile name %q", name)
+		}
+	}
+}
+
+func TestParseTerraformState(t *testing.T) {
+	input := `{
+  "version": 4,
+  "resources": [
+    {"mode": "managed", "type": "aws_organizations_account", "instances": [
+      {"attributes": {"id": "111111111111", "name": "Dev", "status": "ACTIVE"}}
+    ]},
+    {"mode": "managed", "type": "aws_organizations_organization", "instances": [
+      {"attributes": {"accounts": [
+        {"id": "111111111111", "name": "Dev", "status": "ACTIVE"},
+        {"id": "222222222222", "name": "Prod", "status": "ACTIVE"},
+        {"id": "333333333333", "name": "Old", "status": "SUSPENDED"}
+      ]}}
+    ]},
+    {"mode": "managed", "type": "aws_s3_bucket", "instances": [{"attributes": {"id": "bucket"}}]}
+  ]
+}`
+	accounts, err := ParseTerraformState(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTerraformState failed: %v", err)
+	}
+	if len(accounts) != 2 || accounts[0].Name != "Dev" || accounts[1].AccountID != "222222222222" {
+		t.Errorf("Unexpected accounts: %+v", accounts)
+	}
+}
+
+func TestParseOrganizationsExport(t *testing.T) {
+	input := `{"Accounts": [
+  {"Id": "111111111111", "Name": "Dev", "Status": "ACTIVE"},
+  {"Id": "222222222222", "Name": "Closed", "Status": "SUSPENDED"}
+]}`
+	accounts, err := ParseOrganizationsExport(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseOrganizationsExport failed: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Name != "Dev" {
+		t.Errorf("Unexpected accounts: %+v", accounts)
+	}
+}
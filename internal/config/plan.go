@@ -0,0 +1,294 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PlanVersion is the format version of saved plan files.
+const PlanVersion = 1
+
+// Plan actions.
+const (
+	PlanAdd    = "add"
+	PlanUpdate = "update"
+	PlanPrune  = "prune"
+)
+
+// DesiredProfile is a profile section that should exist in the config after applying a plan.
+type DesiredProfile struct {
+	Name    string
+	Content string
+}
+
+// PlanChange is a single change to a profile section.
+type PlanChange struct {
+	Action  string            `json:"action"`
+	Profile string            `json:"profile"`
+	Old     map[string]string `json:"old,omitempty"`
+	New     map[string]string `json:"new,omitempty"`
+	// Diff lists the keys an update changes, for reviewing the plan file.
+	Diff []KeyDiff `json:"diff,omitempty"`
+	// Content is the full section written for add and update changes.
+	Content string `json:"content,omitempty"`
+}
+
+// KeyDiff is a key whose value differs between the old and new version of a
+// profile. An empty Old means the key is added, an empty New that it is removed.
+type KeyDiff struct {
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// DiffKeys returns the keys whose value differs between two versions of a profile, sorted by key.
+func DiffKeys(oldKeys, newKeys map[string]string) []KeyDiff {
+	var diff []KeyDiff
+	for key, value := range newKeys {
+		if oldValue, ok := oldKeys[key]; !ok || oldValue != value {
+			diff = append(diff, KeyDiff{Key: key, Old: oldKeys[key], New: value})
+		}
+	}
+	for key, value := range oldKeys {
+		if _, ok := newKeys[key]; !ok {
+			diff = append(diff, KeyDiff{Key: key, Old: value})
+		}
+	}
+	slices.SortFunc(diff, func(a, b KeyDiff) int { return strings.Compare(a.Key, b.Key) })
+	return diff
+}
+
+// Plan is a reviewable set of changes to the AWS config file.
+type Plan struct {
+	Version    int          `json:"version"`
+	SSOSession string       `json:"sso_session"`
+	CreatedAt  time.Time    `json:"created_at"`
+	ConfigHash string       `json:"config_hash"`
+	Changes    []PlanChange `json:"changes"`
+	Unchanged  int          `json:"unchanged"`
+}
+
+// ContentHash fingerprints config content so a plan can detect that the file changed.
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ProfileKeys parses the key = value lines of a profile section.
+func ProfileKeys(content string) map[string]string {
+	keys := make(map[string]string)
+	for _, line := range strings.Split(ExtractProfileConfig(content), "\n") {
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			keys[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return keys
+}
+
+// BuildPlan compares the desired profiles with the config content. When
+// pruneSession is set, generated profiles of that session that are no longer
+// desired are scheduled for removal; profiles without the generated profile
+// marker are never pruned.
+func BuildPlan(configContent string, desired []DesiredProfile, pruneSession string) *Plan {
+	existing, existingContent := ParseExistingProfiles(configContent)
+	plan := &Plan{
+		Version:    PlanVersion,
+		SSOSession: pruneSession,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: ContentHash(configContent),
+		Changes:    []PlanChange{},
+	}
+
+	wanted := make(map[string]bool)
+	for _, d := range desired {
+		wanted[d.Name] = true
+		newKeys := ProfileKeys(d.Content)
+		if !existing[d.Name] {
+			plan.Changes = append(plan.Changes, PlanChange{Action: PlanAdd, Profile: d.Name, New: newKeys, Content: d.Content})
+			continue
+		}
+		if ExtractProfileConfig(existingContent[d.Name]) == ExtractProfileConfig(d.Content) {
+			plan.Unchanged++
+			continue
+		}
+		oldKeys := ProfileKeys(existingContent[d.Name])
+		plan.Changes = append(plan.Changes, PlanChange{
+			Action:  PlanUpdate,
+			Profile: d.Name,
+			Old:     oldKeys,
+			New:     newKeys,
+			Diff:    DiffKeys(oldKeys, newKeys),
+			Content: d.Content,
+		})
+	}
+
+	if pruneSession != "" {
+		for _, name := range ExtractProfileNamesFromContent(configContent) {
+			if wanted[name] {
+				continue
+			}
+			keys := ProfileKeys(existingContent[name])
+			if keys["sso_session"] == pruneSession && IsGeneratedProfileContent(existingContent[name]) {
+				plan.Changes = append(plan.Changes, PlanChange{Action: PlanPrune, Profile: name, Old: keys})
+			}
+		}
+	}
+	return plan
+}
+
+// FindOrphanedProfiles returns a plan that only prunes: it removes the
+// generated profiles of ssoSession whose account and role are not among the
+// desired profiles any more. Profiles are matched by account and role rather
+// than by name, so profiles named with another template are not mistaken for
+// orphans.
+func FindOrphanedProfiles(configContent string, desired []DesiredProfile, ssoSession string) *Plan {
+	_, existingContent := ParseExistingProfiles(configContent)
+	plan := &Plan{
+		Version:    PlanVersion,
+		SSOSession: ssoSession,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: ContentHash(configContent),
+		Changes:    []PlanChange{},
+	}
+
+	accessible := make(map[string]bool)
+	for _, d := range desired {
+		keys := ProfileKeys(d.Content)
+		accessible[keys["sso_account_id"]+"/"+keys["sso_role_name"]] = true
+	}
+	for _, name := range ExtractProfileNamesFromContent(configContent) {
+		keys := ProfileKeys(existingContent[name])
+		if keys["sso_session"] != ssoSession || !IsGeneratedProfileContent(existingContent[name]) {
+			continue
+		}
+		if accessible[keys["sso_account_id"]+"/"+keys["sso_role_name"]] {
+			plan.Unchanged++
+			continue
+		}
+		plan.Changes = append(plan.Changes, PlanChange{Action: PlanPrune, Profile: name, Old: keys})
+	}
+	return plan
+}
+
+// Validate checks that every change writes exactly the keys shown to reviewers,
+// so an edited plan file cannot smuggle unreviewed settings into the config.
+func (p *Plan) Validate() error {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case PlanAdd, PlanUpdate:
+			if headers := sectionHeaderRegex.FindAllString(c.Content, -1); len(headers) != 1 || headers[0] != "[profile "+c.Profile+"]" {
+				return fmt.Errorf("plan change for '%s' must contain exactly its own profile section", c.Profile)
+			}
+			if !maps.Equal(ProfileKeys(c.Content), c.New) {
+				return fmt.Errorf("plan change for '%s' writes keys that differ from the reviewed values", c.Profile)
+			}
+			if c.Diff != nil && !slices.Equal(c.Diff, DiffKeys(c.Old, c.New)) {
+				return fmt.Errorf("plan change for '%s' has a diff that doesn't match its old and new values", c.Profile)
+			}
+		case PlanPrune:
+			if c.Content != "" {
+				return fmt.Errorf("plan prune of '%s' must not carry content", c.Profile)
+			}
+		default:
+			return fmt.Errorf("unknown plan action '%s' for '%s'", c.Action, c.Profile)
+		}
+	}
+	return nil
+}
+
+// Apply returns the config content with the plan's changes applied.
+// Added and updated profiles are appended at the end of the file.
+func (p *Plan) Apply(configContent string) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	var appended strings.Builder
+	for _, c := range p.Changes {
+		// Added profiles are removed too, in case the file gained them since
+		// the plan was built
+		configContent = RemoveProfileFromConfig(configContent, c.Profile)
+		if c.Action == PlanAdd || c.Action == PlanUpdate {
+			appended.WriteString(c.Content)
+		}
+	}
+
+	if appended.Len() == 0 {
+		return configContent, nil
+	}
+	if len(configContent) > 0 {
+		if !strings.HasSuffix(configContent, "\n") {
+			configContent += "\n"
+		}
+		return configContent + "\n" + appended.String(), nil
+	}
+	return appended.String(), nil
+}
+
+// KeepAccounts drops the prune changes of profiles in the given accounts, for
+// accounts whose roles could not be listed. It returns the profiles kept.
+func (p *Plan) KeepAccounts(accountIDs ...string) []string {
+	var kept []string
+	changes := p.Changes[:0]
+	for _, c := range p.Changes {
+		if c.Action == PlanPrune && slices.Contains(accountIDs, c.Old["sso_account_id"]) {
+			kept = append(kept, c.Profile)
+			continue
+		}
+		changes = append(changes, c)
+	}
+	p.Changes = changes
+	return kept
+}
+
+// Count returns the number of changes with the given action.
+func (p *Plan) Count(action string) int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// SavePlan writes a plan file.
+func SavePlan(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write plan file: %w", err)
+	}
+	return nil
+}
+
+// LoadPlan reads a plan file.
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file: %w", err)
+	}
+	if plan.Version > PlanVersion {
+		return nil, fmt.Errorf("plan file uses format version %d, please update awsm", plan.Version)
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan file: %w", err)
+	}
+	return &plan, nil
+}
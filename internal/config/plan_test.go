@@ -0,0 +1,181 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildAndApplyPlan(t *testing.T) {
+	existing := `[sso-session corp]
+sso_region = eu-west-1
+
+[profile same]
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+region = eu-west-1
+
+[profile changed]
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+region = us-east-1
+
+[profile gone]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 333333333333
+sso_role_name = Admin
+region = eu-west-1
+
+[profile manual]
+region = eu-west-1
+
+[profile handwritten]
+sso_session = corp
+sso_account_id = 555555555555
+sso_role_name = Admin
+`
+	desired := []DesiredProfile{
+		{"same", "[profile same]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = Admin\nregion = eu-west-1\n\n"},
+		{"changed", "[profile changed]\nsso_session = corp\nsso_account_id = 222222222222\nsso_role_name = Admin\nregion = eu-west-1\n\n"},
+		{"new", "[profile new]\nsso_session = corp\nsso_account_id = 444444444444\nsso_role_name = Admin\nregion = eu-west-1\n\n"},
+	}
+
+	plan := BuildPlan(existing, desired, "corp")
+	if plan.Count(PlanAdd) != 1 || plan.Count(PlanUpdate) != 1 || plan.Count(PlanPrune) != 1 || plan.Unchanged != 1 {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	for _, c := range plan.Changes {
+		if c.Action == PlanUpdate && (c.Old["region"] != "us-east-1" || c.New["region"] != "eu-west-1") {
+			t.Errorf("Expected region change in update, got %+v", c)
+		}
+		if c.Action == PlanUpdate && (len(c.Diff) != 1 || c.Diff[0] != (KeyDiff{Key: "region", Old: "us-east-1", New: "eu-west-1"})) {
+			t.Errorf("Expected a region diff in update, got %+v", c.Diff)
+		}
+	}
+
+	result, err := plan.Apply(existing)
+	if err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	for _, want := range []string{"[sso-session corp]", "[profile same]", "[profile manual]", "[profile handwritten]", "[profile new]", "region = eu-west-1"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("Expected result to contain %q:\n%s", want, result)
+		}
+	}
+	if strings.Contains(result, "[profile gone]") || strings.Contains(result, "us-east-1") {
+		t.Errorf("Pruned or outdated content left in result:\n%s", result)
+	}
+
+	if again := BuildPlan(result, desired, "corp"); len(again.Changes) != 0 {
+		t.Errorf("Expected no changes after apply, got %+v", again.Changes)
+	}
+}
+
+func TestPlanRejectsUnreviewedContent(t *testing.T) {
+	content := "[profile dev]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = Admin\n"
+	reviewed := ProfileKeys(content)
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"Matching content", content, false},
+		{"Extra key", content + "credential_process = /tmp/evil\n", true},
+		{"Extra section", content + "\n[profile other]\nsso_session = corp\n", true},
+		{"Wrong section", strings.Replace(content, "[profile dev]", "[profile prod]", 1), true},
+	}
+	for _, tt := range tests {
+		plan := &Plan{Changes: []PlanChange{{Action: PlanAdd, Profile: "dev", New: reviewed, Content: tt.content}}}
+		if _, err := plan.Apply(""); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestFindOrphanedProfiles(t *testing.T) {
+	existing := `[profile kept]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+region = us-east-1
+
+[profile revoked]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+
+[profile Renamed/Admin]
+# Managed by awsm sso generate
+sso_session = corp
+sso_account_id = 111111111111
+sso_role_name = Admin
+
+[profile other-session]
+# Managed by awsm sso generate
+sso_session = other
+sso_account_id = 333333333333
+sso_role_name = Admin
+`
+	// An outdated region, another name and a new profile are left to 'sso generate'
+	desired := []DesiredProfile{
+		{"kept", "[profile kept]\nsso_session = corp\nsso_account_id = 111111111111\nsso_role_name = Admin\nregion = eu-west-1\n\n"},
+		{"new", "[profile new]\nsso_session = corp\nsso_account_id = 444444444444\nsso_role_name = Admin\nregion = eu-west-1\n\n"},
+	}
+
+	plan := FindOrphanedProfiles(existing, desired, "corp")
+	if len(plan.Changes) != 1 || plan.Changes[0].Action != PlanPrune || plan.Changes[0].Profile != "revoked" {
+		t.Fatalf("Expected only 'revoked' to be pruned, got %+v", plan.Changes)
+	}
+	if plan.Unchanged != 2 {
+		t.Errorf("Expected 2 generated profiles to stay, got %d", plan.Unchanged)
+	}
+}
+
+func TestPlanKeepAccounts(t *testing.T) {
+	plan := &Plan{Changes: []PlanChange{
+		{Action: PlanPrune, Profile: "a", Old: map[string]string{"sso_account_id": "111111111111"}},
+		{Action: PlanPrune, Profile: "b", Old: map[string]string{"sso_account_id": "222222222222"}},
+		{Action: PlanAdd, Profile: "c", New: map[string]string{"sso_account_id": "111111111111"}},
+	}}
+
+	kept := plan.KeepAccounts("111111111111")
+	if len(kept) != 1 || kept[0] != "a" {
+		t.Errorf("Expected profile 'a' to be kept, got %v", kept)
+	}
+	if plan.Count(PlanPrune) != 1 || plan.Count(PlanAdd) != 1 {
+		t.Errorf("Unexpected changes after KeepAccounts: %+v", plan.Changes)
+	}
+}
+
+func TestDiffKeys(t *testing.T) {
+	oldKeys := map[string]string{"region": "us-east-1", "output": "json", "sso_role_name": "Admin"}
+	newKeys := map[string]string{"region": "eu-west-1", "sso_role_name": "Admin", "sso_session": "corp"}
+
+	expected := []KeyDiff{
+		{Key: "output", Old: "json"},
+		{Key: "region", Old: "us-east-1", New: "eu-west-1"},
+		{Key: "sso_session", New: "corp"},
+	}
+	if diff := DiffKeys(oldKeys, newKeys); !slices.Equal(diff, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diff)
+	}
+
+	content := "[profile dev]\nregion = eu-west-1\n"
+	plan := &Plan{Changes: []PlanChange{{
+		Action:  PlanUpdate,
+		Profile: "dev",
+		Old:     map[string]string{"region": "us-east-1"},
+		New:     ProfileKeys(content),
+		Diff:    []KeyDiff{{Key: "region", Old: "us-east-1", New: "us-east-1"}},
+		Content: content,
+	}}}
+	if err := plan.Validate(); err == nil {
+		t.Error("Expected a diff that hides the real change to be rejected")
+	}
+}
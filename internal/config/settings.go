@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// listSettings are the settings that hold a list; 'awsm config set' adds to
+// them instead of replacing them.
+var listSettings = map[string]bool{
+	"warnings.suppress": true,
+}
+
+// ConfigFilePath returns the path of config.toml in the ConfigDir.
+func ConfigFilePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// GetSuppressedWarnings returns the warning IDs silenced with warnings.suppress.
+func GetSuppressedWarnings() []string {
+	return viper.GetStringSlice("warnings.suppress")
+}
+
+// SetSetting writes a dotted setting to config.toml. For list settings the
+// value is added to the list; "true" and "false" are stored as booleans.
+// Comments in config.toml are not preserved.
+func SetSetting(key, value string) error {
+	key = strings.ToLower(key)
+	return updateSettings(func(settings map[string]any) {
+		switch {
+		case listSettings[key]:
+			list := cast.ToStringSlice(getSetting(settings, key))
+			if !slices.Contains(list, value) {
+				list = append(list, value)
+			}
+			setSetting(settings, key, list)
+		case value == "true" || value == "false":
+			setSetting(settings, key, value == "true")
+		default:
+			setSetting(settings, key, value)
+		}
+	})
+}
+
+// UnsetSetting removes a dotted setting from config.toml. With a value, only
+// that value is removed from a list setting.
+func UnsetSetting(key, value string) error {
+	key = strings.ToLower(key)
+	return updateSettings(func(settings map[string]any) {
+		if value != "" && listSettings[key] {
+			list := cast.ToStringSlice(getSetting(settings, key))
+			setSetting(settings, key, slices.DeleteFunc(list, func(s string) bool { return s == value }))
+			return
+		}
+		deleteSetting(settings, key)
+	})
+}
+
+// updateSettings applies change to the settings in config.toml, writes them
+// back and reloads the config of the running process.
+func updateSettings(change func(settings map[string]any)) error {
+	path, err := ConfigFilePath()
+	if err != nil {
+		return err
+	}
+	current := viper.New()
+	current.SetConfigFile(path)
+	if err := current.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	settings := current.AllSettings()
+	change(settings)
+
+	updated := viper.New()
+	for key, value := range settings {
+		updated.Set(key, value)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create awsm config directory: %w", err)
+	}
+	if err := updated.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	viper.ReadInConfig()
+	return nil
+}
+
+// getSetting looks up a dotted key in nested settings.
+func getSetting(settings map[string]any, key string) any {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			return nil
+		}
+		settings = next
+	}
+	return settings[parts[len(parts)-1]]
+}
+
+// setSetting sets a dotted key in nested settings, creating tables as needed.
+func setSetting(settings map[string]any, key string, value any) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			settings[part] = next
+		}
+		settings = next
+	}
+	settings[parts[len(parts)-1]] = value
+}
+
+// deleteSetting removes a dotted key from nested settings.
+func deleteSetting(settings map[string]any, key string) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := settings[part].(map[string]any)
+		if !ok {
+			return
+		}
+		settings = next
+	}
+	delete(settings, parts[len(parts)-1])
+}
+
+// AllSettings returns every awsm setting by dotted key.
+func AllSettings() map[string]any {
+	settings := make(map[string]any)
+	for _, key := range viper.AllKeys() {
+		settings[key] = viper.Get(key)
+	}
+	return settings
+}
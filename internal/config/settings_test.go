@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSetAndUnsetSetting(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+	t.Cleanup(viper.Reset)
+	InitConfig()
+
+	if err := SetSetting("warnings.suppress", "W004"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting("warnings.suppress", "W002"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting("warnings.suppress", "W004"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetSetting("sso.no_browser", "true"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(GetSuppressedWarnings(), ","); got != "W004,W002" {
+		t.Errorf("Expected W004,W002 suppressed, got %s", got)
+	}
+	if !GetSSONoBrowser() {
+		t.Error("Expected sso.no_browser to be applied to the running process")
+	}
+
+	if err := UnsetSetting("warnings.suppress", "W004"); err != nil {
+		t.Fatal(err)
+	}
+	if err := UnsetSetting("sso.no_browser", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(GetSuppressedWarnings(), ","); got != "W002" {
+		t.Errorf("Expected W002 suppressed, got %s", got)
+	}
+
+	path, _ := ConfigFilePath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "no_browser") {
+		t.Errorf("Expected sso.no_browser to be removed, got:\n%s", data)
+	}
+}
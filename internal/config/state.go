@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State holds data awsm records about itself between runs, stored in state.json of the StateDir.
+// Unlike the user configuration it is written by awsm and not meant to be edited.
+type State struct {
+	SecurityReviewAt time.Time       `json:"security_review_at,omitempty"`
+	CacheCompactedAt time.Time       `json:"cache_compacted_at,omitempty"`
+	RecentProfiles   []RecentProfile `json:"recent_profiles,omitempty"`
+}
+
+// RecentProfile is a profile activated with 'awsm profile set', newest first in State.RecentProfiles.
+type RecentProfile struct {
+	Name   string    `json:"name"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+// MaxRecentProfiles is the number of recently used profiles that are remembered.
+const MaxRecentProfiles = 10
+
+// RecordProfileUse moves name to the front of the recently used profiles.
+func (s *State) RecordProfileUse(name string, at time.Time) {
+	recent := []RecentProfile{{Name: name, UsedAt: at}}
+	for _, p := range s.RecentProfiles {
+		if p.Name != name && len(recent) < MaxRecentProfiles {
+			recent = append(recent, p)
+		}
+	}
+	s.RecentProfiles = recent
+}
+
+// PreviousProfile returns the most recently used profile other than current,
+// like 'cd -' returns to the previous directory.
+func (s *State) PreviousProfile(current string) (string, bool) {
+	for _, p := range s.RecentProfiles {
+		if p.Name != current {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+// StatePath returns the path of the awsm state file.
+func StatePath() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// LoadState reads the awsm state. A missing state file yields an empty state.
+func LoadState() (*State, error) {
+	path, err := StatePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{}, nil
+		}
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// SaveState writes the awsm state.
+func SaveState(state *State) error {
+	path, err := StatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create awsm directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	return nil
+}
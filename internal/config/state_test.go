@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecentProfiles(t *testing.T) {
+	var state State
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := state.PreviousProfile(""); ok {
+		t.Error("Expected no previous profile without history")
+	}
+
+	state.RecordProfileUse("dev", start)
+	state.RecordProfileUse("prod", start.Add(time.Minute))
+	if prev, _ := state.PreviousProfile("prod"); prev != "dev" {
+		t.Errorf("Expected previous profile dev, got %s", prev)
+	}
+
+	// Re-using a profile moves it to the front instead of duplicating it
+	state.RecordProfileUse("dev", start.Add(2*time.Minute))
+	if len(state.RecentProfiles) != 2 || state.RecentProfiles[0].Name != "dev" {
+		t.Errorf("Unexpected recent profiles %v", state.RecentProfiles)
+	}
+	if prev, _ := state.PreviousProfile("dev"); prev != "prod" {
+		t.Errorf("Expected previous profile prod, got %s", prev)
+	}
+
+	for i := 0; i < MaxRecentProfiles+5; i++ {
+		state.RecordProfileUse(string(rune('a'+i)), start)
+	}
+	if len(state.RecentProfiles) != MaxRecentProfiles {
+		t.Errorf("Expected %d recent profiles, got %d", MaxRecentProfiles, len(state.RecentProfiles))
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ProfileTags maps profile names to their key=value tags. Tags live in
+// tags.json of the ConfigDir, so the AWS config files stay untouched.
+type ProfileTags map[string]map[string]string
+
+// TagsPath returns the path of the profile tags file.
+func TagsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tags.json"), nil
+}
+
+// SessionTagsPath returns the path of the file holding the STS session tags
+// of profiles, kept apart from the tags used to organize profiles.
+func SessionTagsPath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session-tags.json"), nil
+}
+
+// LoadProfileTags reads the profile tags. A missing file yields no tags.
+func LoadProfileTags() (ProfileTags, error) {
+	path, err := TagsPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadTagsFile(path)
+}
+
+// SaveProfileTags writes the profile tags.
+func SaveProfileTags(tags ProfileTags) error {
+	path, err := TagsPath()
+	if err != nil {
+		return err
+	}
+	return saveTagsFile(path, tags)
+}
+
+// LoadSessionTags reads the session tags of all profiles. A missing file yields no tags.
+func LoadSessionTags() (ProfileTags, error) {
+	path, err := SessionTagsPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadTagsFile(path)
+}
+
+// SaveSessionTags writes the session tags of all profiles.
+func SaveSessionTags(tags ProfileTags) error {
+	path, err := SessionTagsPath()
+	if err != nil {
+		return err
+	}
+	return saveTagsFile(path, tags)
+}
+
+func loadTagsFile(path string) (ProfileTags, error) {
+	tags := ProfileTags{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tags, nil
+		}
+		return nil, fmt.Errorf("failed to read tags file: %w", err)
+	}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("failed to parse tags file %s: %w", path, err)
+	}
+	return tags, nil
+}
+
+func saveTagsFile(path string, tags ProfileTags) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create awsm config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write tags file: %w", err)
+	}
+	return nil
+}
+
+// ParseTag splits a key=value tag. Keys must not be empty; values may be.
+func ParseTag(tag string) (string, string, error) {
+	key, value, ok := strings.Cut(tag, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid tag '%s', expected key=value", tag)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// MaxSessionTags is how many session tags STS accepts on one AssumeRole call.
+const MaxSessionTags = 50
+
+// sessionTagPattern is what STS accepts in session tag keys and values.
+var sessionTagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// ValidateSessionTag checks a tag against the STS limits for session tags:
+// keys of 1-128 and values of up to 256 letters, digits, spaces or _.:/=+-@.
+func ValidateSessionTag(key, value string) error {
+	if utf8.RuneCountInString(key) > 128 || !sessionTagPattern.MatchString(key) {
+		return fmt.Errorf("invalid session tag key '%s': use up to 128 letters, digits, spaces or _.:/=+-@", key)
+	}
+	if strings.HasPrefix(strings.ToLower(key), "aws:") {
+		return fmt.Errorf("invalid session tag key '%s': the aws: prefix is reserved", key)
+	}
+	if utf8.RuneCountInString(value) > 256 || !sessionTagPattern.MatchString(value) {
+		return fmt.Errorf("invalid value for session tag '%s': use up to 256 letters, digits, spaces or _.:/=+-@", key)
+	}
+	return nil
+}
+
+// Set tags a profile.
+func (t ProfileTags) Set(profile, key, value string) {
+	if t[profile] == nil {
+		t[profile] = map[string]string{}
+	}
+	t[profile][key] = value
+}
+
+// Remove drops a tag from a profile, and the profile once it has no tags left.
+func (t ProfileTags) Remove(profile, key string) {
+	delete(t[profile], key)
+	if len(t[profile]) == 0 {
+		delete(t, profile)
+	}
+}
+
+// Matches reports whether a profile has all filters, each either key=value or
+// just key for any value.
+func (t ProfileTags) Matches(profile string, filters []string) bool {
+	for _, filter := range filters {
+		key, value, hasValue := strings.Cut(filter, "=")
+		actual, ok := t[profile][key]
+		if !ok || (hasValue && actual != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Format renders the tags of a profile as sorted key=value pairs.
+func (t ProfileTags) Format(profile string) string {
+	pairs := make([]string, 0, len(t[profile]))
+	for key, value := range t[profile] {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,107 @@
+package config
+
+import "testing"
+
+func TestProfileTags(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	tags, err := LoadProfileTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatalf("Expected no tags without a tags file, got %v", tags)
+	}
+
+	tags.Set("prod-admin", "env", "prod")
+	tags.Set("prod-admin", "team", "platform")
+	tags.Set("dev", "env", "dev")
+	if err := SaveProfileTags(tags); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProfileTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Format("prod-admin"); got != "env=prod team=platform" {
+		t.Errorf("Expected env=prod team=platform, got %s", got)
+	}
+
+	tests := []struct {
+		profile string
+		filters []string
+		want    bool
+	}{
+		{"prod-admin", nil, true},
+		{"prod-admin", []string{"env=prod"}, true},
+		{"prod-admin", []string{"env=prod", "team"}, true},
+		{"dev", []string{"env=prod"}, false},
+		{"dev", []string{"team"}, false},
+		{"untagged", []string{"env"}, false},
+	}
+	for _, tt := range tests {
+		if got := loaded.Matches(tt.profile, tt.filters); got != tt.want {
+			t.Errorf("Matches(%s, %v) = %v, want %v", tt.profile, tt.filters, got, tt.want)
+		}
+	}
+
+	loaded.Remove("dev", "env")
+	if _, ok := loaded["dev"]; ok {
+		t.Error("Expected a profile without tags to be dropped")
+	}
+}
+
+func TestParseTag(t *testing.T) {
+	if key, value, err := ParseTag("env = prod"); err != nil || key != "env" || value != "prod" {
+		t.Errorf("Unexpected result %s, %s, %v", key, value, err)
+	}
+	for _, tag := range []string{"env", "=prod"} {
+		if _, _, err := ParseTag(tag); err == nil {
+			t.Errorf("Expected an error for %q", tag)
+		}
+	}
+}
+
+func TestSessionTagsAreSeparate(t *testing.T) {
+	t.Setenv(HomeEnv, t.TempDir())
+
+	tags := ProfileTags{}
+	tags.Set("prod-admin", "team", "platform")
+	if err := SaveSessionTags(tags); err != nil {
+		t.Fatal(err)
+	}
+	sessionTags, err := LoadSessionTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sessionTags.Format("prod-admin"); got != "team=platform" {
+		t.Errorf("Expected team=platform, got %s", got)
+	}
+	profileTags, err := LoadProfileTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profileTags) != 0 {
+		t.Errorf("Expected session tags to stay out of the profile tags, got %v", profileTags)
+	}
+}
+
+func TestValidateSessionTag(t *testing.T) {
+	valid := [][2]string{{"team", "platform"}, {"cost-center", "1234"}, {"owner", "jane.doe@example.com"}, {"note", ""}}
+	for _, tag := range valid {
+		if err := ValidateSessionTag(tag[0], tag[1]); err != nil {
+			t.Errorf("ValidateSessionTag(%q, %q) = %v", tag[0], tag[1], err)
+		}
+	}
+	long := make([]byte, 129)
+	for i := range long {
+		long[i] = 'a'
+	}
+	invalid := [][2]string{{"aws:team", "x"}, {string(long), "x"}, {"team", "a,b"}, {"team!", "x"}}
+	for _, tag := range invalid {
+		if err := ValidateSessionTag(tag[0], tag[1]); err == nil {
+			t.Errorf("expected ValidateSessionTag(%q, %q) to fail", tag[0], tag[1])
+		}
+	}
+}
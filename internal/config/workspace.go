@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WorkspaceProfileFile is the per-directory file naming the AWS profile a project uses.
+// It holds a single profile name and is looked up in the directory and its parents.
+const WorkspaceProfileFile = ".awsm-profile"
+
+// FindWorkspaceProfile returns the profile named by the closest WorkspaceProfileFile
+// in dir or one of its parents, and the path of that file. Both are empty when
+// no directory up to the filesystem root has one.
+func FindWorkspaceProfile(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, WorkspaceProfileFile)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			profile, _, _ := strings.Cut(strings.TrimSpace(string(data)), "\n")
+			profile = strings.TrimSpace(profile)
+			if profile == "" {
+				return "", "", fmt.Errorf("%s is empty", path)
+			}
+			return profile, path, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", nil
+		}
+		dir = parent
+	}
+}
+
+// SaveWorkspaceProfile writes the profile name to the WorkspaceProfileFile of dir.
+func SaveWorkspaceProfile(dir, profile string) (string, error) {
+	path := filepath.Join(dir, WorkspaceProfileFile)
+	if err := os.WriteFile(path, []byte(profile+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWorkspaceProfile(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if profile, path, err := FindWorkspaceProfile(nested); err != nil || profile != "" || path != "" {
+		t.Fatalf("Expected no workspace profile, got %q at %q (%v)", profile, path, err)
+	}
+
+	saved, err := SaveWorkspaceProfile(root, "prod-admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, path, err := FindWorkspaceProfile(nested)
+	if err != nil {
+		t.Fatalf("FindWorkspaceProfile failed: %v", err)
+	}
+	if profile != "prod-admin" || path != saved {
+		t.Errorf("Expected prod-admin from %s, got %q from %s", saved, profile, path)
+	}
+
+	if err := os.WriteFile(filepath.Join(nested, WorkspaceProfileFile), []byte("  \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := FindWorkspaceProfile(nested); err == nil {
+		t.Error("Expected an error for an empty workspace profile file")
+	}
+}
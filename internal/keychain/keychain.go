@@ -0,0 +1,76 @@
+// Package keychain stores small secrets, such as TOTP seeds, in the operating
+// system's credential store: the macOS keychain, the Secret Service (GNOME
+// Keyring, KWallet) through secret-tool on Linux, and the Windows Credential
+// Manager.
+package keychain
+
+import "errors"
+
+// Service is the service name every awsm secret is stored under.
+const Service = "awsm"
+
+var (
+	// ErrNotFound is returned by Get and Delete when no secret is stored for the account.
+	ErrNotFound = errors.New("secret not found in the keychain")
+	// ErrUnsupported is returned on platforms without a supported credential store.
+	ErrUnsupported = errors.New("no supported keychain on this platform")
+)
+
+// store is the credential store in use, replaced in tests by MockInit.
+var store interface {
+	get(account string) (string, error)
+	set(account, secret string) error
+	del(account string) error
+} = osStore{}
+
+// osStore is the platform's credential store.
+type osStore struct{}
+
+func (osStore) get(account string) (string, error) { return get(account) }
+func (osStore) set(account, secret string) error   { return set(account, secret) }
+func (osStore) del(account string) error           { return del(account) }
+
+// memoryStore keeps secrets in memory, for tests.
+type memoryStore map[string]string
+
+func (m memoryStore) get(account string) (string, error) {
+	secret, ok := m[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (m memoryStore) set(account, secret string) error {
+	m[account] = secret
+	return nil
+}
+
+func (m memoryStore) del(account string) error {
+	if _, ok := m[account]; !ok {
+		return ErrNotFound
+	}
+	delete(m, account)
+	return nil
+}
+
+// MockInit replaces the OS credential store with an empty in-memory one for
+// the rest of the process, so tests never touch the user's keychain.
+func MockInit() {
+	store = memoryStore{}
+}
+
+// Get returns the secret stored for account.
+func Get(account string) (string, error) {
+	return store.get(account)
+}
+
+// Set stores secret for account, replacing any previous secret.
+func Set(account, secret string) error {
+	return store.set(account, secret)
+}
+
+// Delete removes the secret stored for account.
+func Delete(account string) error {
+	return store.del(account)
+}
@@ -0,0 +1,48 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secret-tool is part of libsecret and talks to whichever Secret Service
+// provider runs in the session.
+
+func get(account string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", fmt.Errorf("%w: install secret-tool (libsecret-tools)", ErrUnsupported)
+	}
+	out, err := exec.Command("secret-tool", "lookup", "service", Service, "account", account).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to access the keychain: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func set(account, secret string) error {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return fmt.Errorf("%w: install secret-tool (libsecret-tools)", ErrUnsupported)
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", fmt.Sprintf("%s: %s", Service, account), "service", Service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to store the secret in the keychain: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func del(account string) error {
+	if _, err := get(account); err != nil {
+		return err
+	}
+	if out, err := exec.Command("secret-tool", "clear", "service", Service, "account", account).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete the secret from the keychain: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
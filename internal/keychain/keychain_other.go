@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package keychain
+
+func get(account string) (string, error) { return "", ErrUnsupported }
+
+func set(account, secret string) error { return ErrUnsupported }
+
+func del(account string) error { return ErrUnsupported }
@@ -0,0 +1,29 @@
+package keychain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMockStore(t *testing.T) {
+	MockInit()
+
+	if _, err := Get("static:dev"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before storing, got %v", err)
+	}
+	if err := Set("static:dev", "one"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set("static:dev", "two"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Get("static:dev"); err != nil || got != "two" {
+		t.Errorf("Expected the replaced secret, got %q, %v", got, err)
+	}
+	if err := Delete("static:dev"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete("static:dev"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+// Package output renders command results in the format chosen with the
+// global --output flag, so every listing awsm prints can also be scripted.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a way of printing results.
+type Format string
+
+const (
+	// Table is the human-readable default, with colors.
+	Table Format = "table"
+	// JSON renders the data of a result as indented JSON.
+	JSON Format = "json"
+	// YAML renders the same document as JSON, as YAML.
+	YAML Format = "yaml"
+	// Raw prints bare values without decoration, one per line, for shell pipelines.
+	Raw Format = "raw"
+)
+
+// Formats lists the valid values of --output.
+var Formats = []Format{Table, JSON, YAML, Raw}
+
+// Selected is the format of the global --output flag.
+var Selected = Table
+
+// Stdout is where results are printed; tests replace it.
+var Stdout io.Writer = os.Stdout
+
+// String implements pflag.Value.
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set implements pflag.Value, accepting the names in Formats.
+func (f *Format) Set(value string) error {
+	for _, format := range Formats {
+		if strings.EqualFold(value, string(format)) {
+			*f = format
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format '%s', expected one of %s", value, formatNames())
+}
+
+// Type implements pflag.Value.
+func (f *Format) Type() string {
+	return "format"
+}
+
+func formatNames() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Current returns the format to print in. Commands that had a --json flag
+// before --output existed pass it, and it still selects JSON.
+func Current(jsonFlag bool) Format {
+	if jsonFlag {
+		return JSON
+	}
+	return Selected
+}
+
+// Structured reports whether the format is rendered from a result's data
+// rather than printed by the command.
+func (f Format) Structured() bool {
+	return f == JSON || f == YAML
+}
+
+// Result is what a command prints in each format.
+type Result struct {
+	// Data is rendered as JSON or YAML, using its json tags in both.
+	Data any
+	// Table prints the human-readable view.
+	Table func() error
+	// Raw prints the bare values. Without it raw prints the table without colors.
+	Raw func(w io.Writer) error
+}
+
+// Render prints a result in format.
+func Render(format Format, r Result) error {
+	switch format {
+	case JSON, YAML:
+		return Write(Stdout, format, r.Data)
+	case Raw:
+		if r.Raw != nil {
+			return r.Raw(Stdout)
+		}
+		noColor := color.NoColor
+		color.NoColor = true
+		defer func() { color.NoColor = noColor }()
+	}
+	if r.Table == nil {
+		return Write(Stdout, JSON, r.Data)
+	}
+	return r.Table()
+}
+
+// Write renders data as JSON or YAML to w.
+func Write(w io.Writer, format Format, data any) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	if format != YAML {
+		_, err := w.Write(buf.Bytes())
+		return err
+	}
+
+	// JSON is YAML; going through it keeps the json tags and the field order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	blockStyle(&doc)
+	yamlEncoder := yaml.NewEncoder(w)
+	yamlEncoder.SetIndent(2)
+	if err := yamlEncoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	return yamlEncoder.Close()
+}
+
+// blockStyle drops the flow style and quotes parsed JSON comes with, so the
+// YAML looks hand-written. Strings that would read as another type stay quoted.
+func blockStyle(node *yaml.Node) {
+	if node.Kind == yaml.SequenceNode && len(node.Content) == 0 || node.Kind == yaml.MappingNode && len(node.Content) == 0 {
+		// [] and {} can't be written in block style
+		return
+	}
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
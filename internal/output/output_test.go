@@ -0,0 +1,93 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+type testItem struct {
+	Name      string   `json:"name"`
+	AccountID string   `json:"account_id"`
+	Tags      []string `json:"tags"`
+	Empty     []string `json:"empty"`
+	Active    bool     `json:"is_active"`
+}
+
+func TestFormatSet(t *testing.T) {
+	var f Format
+	if err := f.Set("YAML"); err != nil || f != YAML {
+		t.Errorf("Expected YAML, got %q, %v", f, err)
+	}
+	if err := f.Set("xml"); err == nil || !strings.Contains(err.Error(), "table, json, yaml, raw") {
+		t.Errorf("Expected the valid formats in the error, got %v", err)
+	}
+}
+
+func TestWriteYAMLUsesJSONNames(t *testing.T) {
+	var buf bytes.Buffer
+	items := []testItem{{Name: "prod", AccountID: "012345678901", Tags: []string{"team"}, Empty: []string{}, Active: true}}
+	if err := Write(&buf, YAML, items); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	want := `- name: prod
+  account_id: "012345678901"
+  tags:
+    - team
+  empty: []
+  is_active: true
+`
+	if buf.String() != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := Write(&buf, JSON, []testItem{}); err != nil || buf.String() != "[]\n" {
+		t.Errorf("Expected an empty JSON list, got %q, %v", buf.String(), err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	var buf bytes.Buffer
+	old := Stdout
+	t.Cleanup(func() { Stdout = old })
+	Stdout = &buf
+
+	tableErr := errors.New("table")
+	result := Result{
+		Data:  map[string]string{"name": "prod"},
+		Table: func() error { return tableErr },
+	}
+	if err := Render(Table, result); err != tableErr {
+		t.Errorf("Expected the table to be printed, got %v", err)
+	}
+	if err := Render(JSON, result); err != nil || !strings.Contains(buf.String(), `"name": "prod"`) {
+		t.Errorf("Expected JSON, got %q, %v", buf.String(), err)
+	}
+
+	// Raw without its own printer shows the table without colors
+	colored := color.NoColor
+	result.Table = func() error {
+		if !color.NoColor {
+			t.Error("Expected colors to be off in raw output")
+		}
+		return nil
+	}
+	Render(Raw, result)
+	if color.NoColor != colored {
+		t.Error("Expected the color setting to be restored")
+	}
+
+	buf.Reset()
+	result.Raw = func(w io.Writer) error {
+		_, err := io.WriteString(w, "prod\n")
+		return err
+	}
+	if err := Render(Raw, result); err != nil || buf.String() != "prod\n" {
+		t.Errorf("Expected raw values, got %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,298 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"awsm/internal/config"
+
+	"github.com/spf13/viper"
+)
+
+// Override lets config mutations proceed despite policy violations.
+// It is set from the global --override-policy flag; every override is logged.
+var Override bool
+
+// Policy describes organization rules awsm enforces when writing profiles.
+type Policy struct {
+	// AllowedRegions restricts the region of every profile. Empty allows all regions.
+	AllowedRegions []string `mapstructure:"allowed_regions"`
+	// ForbiddenRoles lists role names that must not be used by profiles.
+	ForbiddenRoles []string `mapstructure:"forbidden_roles"`
+	// NamePrefixes enforces profile name prefixes per class of accounts.
+	NamePrefixes []NamePrefixRule `mapstructure:"name_prefixes"`
+}
+
+// NamePrefixRule requires profiles for the listed accounts to start with Prefix.
+type NamePrefixRule struct {
+	AccountClass string   `mapstructure:"account_class"`
+	Accounts     []string `mapstructure:"accounts"`
+	Prefix       string   `mapstructure:"prefix"`
+}
+
+// Profile is the subset of a profile definition that policies apply to.
+// Empty fields are not checked.
+type Profile struct {
+	Name      string
+	Region    string
+	AccountID string
+	RoleName  string
+}
+
+// Path returns the location of the policy file. It can be moved with the
+// policy_file setting in the awsm config, e.g. to a synced directory.
+func Path() (string, error) {
+	if path := viper.GetString("policy_file"); path != "" {
+		return path, nil
+	}
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "policy.toml"), nil
+}
+
+// Load reads the policy file. It returns nil when no policy is configured.
+func Load() (*Policy, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read policy file %s: %w", path, err)
+	}
+	var p Policy
+	if err := v.Unmarshal(&p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Check returns a description of every rule the profile violates.
+func (p *Policy) Check(profile Profile) []string {
+	if p == nil {
+		return nil
+	}
+	var violations []string
+
+	if profile.Region != "" && len(p.AllowedRegions) > 0 && !slices.Contains(p.AllowedRegions, profile.Region) {
+		violations = append(violations, fmt.Sprintf("region '%s' is not allowed (allowed: %s)", profile.Region, strings.Join(p.AllowedRegions, ", ")))
+	}
+	if profile.RoleName != "" {
+		for _, role := range p.ForbiddenRoles {
+			if strings.EqualFold(role, profile.RoleName) {
+				violations = append(violations, fmt.Sprintf("role '%s' is forbidden", profile.RoleName))
+				break
+			}
+		}
+	}
+	if profile.AccountID != "" {
+		for _, rule := range p.NamePrefixes {
+			if slices.Contains(rule.Accounts, profile.AccountID) && !strings.HasPrefix(profile.Name, rule.Prefix) {
+				violations = append(violations, fmt.Sprintf("profiles for %s account %s must be named '%s...'", rule.AccountClass, profile.AccountID, rule.Prefix))
+			}
+		}
+	}
+	return violations
+}
+
+// Enforce checks the profiles against the configured policy. Violations are
+// returned as an error unless Override is set, in which case they are logged.
+func Enforce(profiles ...Profile) error {
+	p, err := Load()
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return nil
+	}
+
+	var violations []string
+	for _, profile := range profiles {
+		for _, v := range p.Check(profile) {
+			violations = append(violations, fmt.Sprintf("%s: %s", profile.Name, v))
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	if !Override {
+		return fmt.Errorf("policy violation:\n  %s\n\nUse --override-policy to write anyway (the override is logged)", strings.Join(violations, "\n  "))
+	}
+	if err := logOverride(violations); err != nil {
+		return fmt.Errorf("failed to log policy override: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: policy overridden:\n  %s\n", strings.Join(violations, "\n  "))
+	return nil
+}
+
+// OverrideEntry is a record of an overridden policy check.
+type OverrideEntry struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	Violations []string  `json:"violations"`
+}
+
+// overrideLogPath returns policy-overrides.log in the awsm state directory (~/.awsm).
+func overrideLogPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "policy-overrides.log"), nil
+}
+
+// logOverride appends a record of an overridden policy check to policy-overrides.log.
+func logOverride(violations []string) error {
+	path, err := overrideLogPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(OverrideEntry{time.Now().UTC(), strings.Join(os.Args, " "), violations})
+}
+
+// readOverrideLines returns the lines of policy-overrides.log, one JSON
+// entry each. A missing log has no lines.
+func readOverrideLines() ([]string, error) {
+	path, err := overrideLogPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return nil, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// RecentOverrides returns the last n logged policy overrides, oldest first.
+func RecentOverrides(n int) ([]OverrideEntry, error) {
+	lines, err := readOverrideLines()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []OverrideEntry
+	for _, line := range lines {
+		var entry OverrideEntry
+		if json.Unmarshal([]byte(line), &entry) == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
+
+// OverrideLogUsage returns the number of logged overrides and the size of the log in bytes.
+func OverrideLogUsage() (entries int, size int64, err error) {
+	path, err := overrideLogPath()
+	if err != nil {
+		return 0, 0, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	lines, err := readOverrideLines()
+	return len(lines), info.Size(), err
+}
+
+// TrimOverrideLog drops logged overrides from before a time, then the oldest
+// ones until the log fits in maxSize bytes (no limit when zero). Unreadable
+// lines are dropped too. It returns the number of dropped lines.
+func TrimOverrideLog(before time.Time, maxSize int64) (int, error) {
+	lines, err := readOverrideLines()
+	if err != nil || len(lines) == 0 {
+		return 0, err
+	}
+
+	var kept []string
+	var size int64
+	for _, line := range lines {
+		var entry OverrideEntry
+		if json.Unmarshal([]byte(line), &entry) != nil || entry.Time.Before(before) {
+			continue
+		}
+		kept = append(kept, line)
+		size += int64(len(line)) + 1
+	}
+	for maxSize > 0 && size > maxSize && len(kept) > 0 {
+		size -= int64(len(kept[0])) + 1
+		kept = kept[1:]
+	}
+	removed := len(lines) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	path, err := overrideLogPath()
+	if err != nil {
+		return 0, err
+	}
+	if len(kept) == 0 {
+		return removed, os.Remove(path)
+	}
+	content := strings.Join(kept, "\n") + "\n"
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0600); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return removed, nil
+}
+
+// RoleNameFromARN returns the role name of an IAM role ARN, without its path.
+func RoleNameFromARN(roleArn string) string {
+	_, resource, ok := strings.Cut(roleArn, ":role/")
+	if !ok {
+		return ""
+	}
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
+
+// AccountIDFromARN returns the account ID of an ARN.
+func AccountIDFromARN(arn string) string {
+	parts := strings.Split(arn, ":")
+	if len(parts) < 5 {
+		return ""
+	}
+	return parts[4]
+}
@@ -0,0 +1,122 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPolicy = `allowed_regions = ["eu-west-1", "eu-central-1"]
+forbidden_roles = ["AdministratorAccess"]
+
+[[name_prefixes]]
+account_class = "production"
+accounts = ["111111111111"]
+prefix = "prod-"
+`
+
+func setupPolicy(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".config", "awsm", "policy.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(testPolicy), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	setupPolicy(t)
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		profile    Profile
+		violations int
+	}{
+		{"Compliant", Profile{Name: "prod-admin", Region: "eu-west-1", AccountID: "111111111111", RoleName: "ReadOnly"}, 0},
+		{"Region", Profile{Name: "dev", Region: "us-east-1"}, 1},
+		{"Forbidden role", Profile{Name: "dev", RoleName: "administratoraccess"}, 1},
+		{"Missing prefix", Profile{Name: "admin", AccountID: "111111111111"}, 1},
+		{"Other account class", Profile{Name: "admin", AccountID: "222222222222"}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Check(tt.profile); len(got) != tt.violations {
+				t.Errorf("Expected %d violations, got %v", tt.violations, got)
+			}
+		})
+	}
+}
+
+func TestEnforceOverride(t *testing.T) {
+	setupPolicy(t)
+	bad := Profile{Name: "dev", Region: "us-east-1"}
+
+	if err := Enforce(bad); err == nil || !strings.Contains(err.Error(), "us-east-1") {
+		t.Fatalf("Expected policy violation error, got %v", err)
+	}
+
+	Override = true
+	defer func() { Override = false }()
+	if err := Enforce(bad); err != nil {
+		t.Fatalf("Expected override to allow the write, got %v", err)
+	}
+	home, _ := os.UserHomeDir()
+	data, err := os.ReadFile(filepath.Join(home, ".awsm", "policy-overrides.log"))
+	if err != nil || !strings.Contains(string(data), "us-east-1") {
+		t.Errorf("Expected override to be logged, got %q (%v)", data, err)
+	}
+}
+
+func TestTrimOverrideLog(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".awsm", "policy-overrides.log")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	log := `{"time":"2026-01-01T00:00:00Z","command":"awsm old","violations":["a"]}
+not json
+{"time":"2026-03-01T00:00:00Z","command":"awsm middle","violations":["b"]}
+{"time":"2026-04-01T00:00:00Z","command":"awsm new","violations":["c"]}
+`
+	if err := os.WriteFile(path, []byte(log), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := TrimOverrideLog(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil || removed != 2 {
+		t.Fatalf("Expected the old and the unreadable entry to be dropped, got %d, %v", removed, err)
+	}
+	entries, size, err := OverrideLogUsage()
+	if err != nil || entries != 2 {
+		t.Fatalf("Expected 2 entries left, got %d, %v", entries, err)
+	}
+
+	// A limit below the current size drops the oldest remaining entries
+	if removed, err := TrimOverrideLog(time.Time{}, size-1); err != nil || removed != 1 {
+		t.Fatalf("Expected one entry dropped for size, got %d, %v", removed, err)
+	}
+	recent, err := RecentOverrides(10)
+	if err != nil || len(recent) != 1 || recent[0].Command != "awsm new" {
+		t.Errorf("Expected only the newest entry to remain, got %v, %v", recent, err)
+	}
+}
+
+func TestRoleNameFromARN(t *testing.T) {
+	if got := RoleNameFromARN("arn:aws:iam::111111111111:role/path/to/Admin"); got != "Admin" {
+		t.Errorf("Expected Admin, got %q", got)
+	}
+	if got := AccountIDFromARN("arn:aws:iam::111111111111:role/Admin"); got != "111111111111" {
+		t.Errorf("Expected account ID, got %q", got)
+	}
+}
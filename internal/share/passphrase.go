@@ -0,0 +1,57 @@
+package share
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ageHeader starts every binary age file.
+const ageHeader = "age-encryption.org/"
+
+// IsEncrypted reports whether data is age ciphertext, armored or binary.
+func IsEncrypted(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return bytes.HasPrefix(data, []byte(armor.Header)) || bytes.HasPrefix(data, []byte(ageHeader))
+}
+
+// EncryptWithPassphrase writes payload as ASCII-armored age ciphertext that
+// can be decrypted with the passphrase. The key is derived with scrypt.
+func EncryptWithPassphrase(w io.Writer, payload []byte, passphrase string) error {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+	armored := armor.NewWriter(w)
+	encrypted, err := age.Encrypt(armored, recipient)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := encrypted.Write(payload); err != nil {
+		return fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := encrypted.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return armored.Close()
+}
+
+// DecryptWithPassphrase decrypts data written by EncryptWithPassphrase.
+func DecryptWithPassphrase(data []byte, passphrase string) ([]byte, error) {
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from passphrase: %w", err)
+	}
+	var src io.Reader = bytes.NewReader(data)
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(trimmed))
+	}
+	decrypted, err := age.Decrypt(src, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt, wrong passphrase? %w", err)
+	}
+	return io.ReadAll(decrypted)
+}
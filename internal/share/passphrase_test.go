@@ -0,0 +1,34 @@
+package share
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPassphraseRoundTrip(t *testing.T) {
+	payload := []byte(`{"profiles":[]}`)
+	var buf bytes.Buffer
+	if err := EncryptWithPassphrase(&buf, payload, "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(buf.Bytes()) {
+		t.Error("Expected the output to be recognized as encrypted")
+	}
+	if IsEncrypted(payload) {
+		t.Error("Expected plain JSON not to be recognized as encrypted")
+	}
+	if bytes.Contains(buf.Bytes(), payload) {
+		t.Error("Expected the payload not to appear in the ciphertext")
+	}
+
+	decrypted, err := DecryptWithPassphrase(buf.Bytes(), "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, payload) {
+		t.Errorf("Expected %s, got %s", payload, decrypted)
+	}
+	if _, err := DecryptWithPassphrase(buf.Bytes(), "wrong"); err == nil {
+		t.Error("Expected a wrong passphrase to fail")
+	}
+}
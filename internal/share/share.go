@@ -0,0 +1,139 @@
+package share
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+	"golang.org/x/crypto/ssh"
+)
+
+// BundleVersion is the format version written into shared bundles.
+const BundleVersion = 1
+
+// Bundle is the payload of a shared profile.
+type Bundle struct {
+	Version    int                 `json:"version"`
+	SharedAt   time.Time           `json:"shared_at"`
+	Profile    aws.ProfileInfo     `json:"profile"`
+	SSOSession *aws.SSOSessionInfo `json:"sso_session,omitempty"`
+}
+
+// ParseRecipient parses an age (age1...) or SSH public key recipient.
+// If value is the path of an existing file, the first recipient in it is used.
+func ParseRecipient(value string) (age.Recipient, error) {
+	value = strings.TrimSpace(value)
+	if data, err := os.ReadFile(value); err == nil {
+		value = strings.TrimSpace(string(data))
+		if i := strings.IndexByte(value, '\n'); i >= 0 {
+			value = strings.TrimSpace(value[:i])
+		}
+	}
+	if strings.HasPrefix(value, "age1") {
+		return age.ParseX25519Recipient(value)
+	}
+	if strings.HasPrefix(value, "ssh-") {
+		return agessh.ParseRecipient(value)
+	}
+	return nil, fmt.Errorf("unsupported recipient '%s' (expected an age1... or ssh-ed25519/ssh-rsa public key)", value)
+}
+
+// LoadIdentity reads an age identity file or an SSH private key.
+// passphrase is only called for passphrase-protected SSH keys.
+func LoadIdentity(path string, passphrase func() ([]byte, error)) ([]age.Identity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+
+	if bytes.Contains(data, []byte("AGE-SECRET-KEY-")) {
+		identities, err := age.ParseIdentities(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse age identity: %w", err)
+		}
+		return identities, nil
+	}
+
+	identity, err := agessh.ParseIdentity(data)
+	if err == nil {
+		return []age.Identity{identity}, nil
+	}
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		return nil, fmt.Errorf("failed to parse SSH identity: %w", err)
+	}
+
+	pubKey := missing.PublicKey
+	if pubKey == nil {
+		pubData, err := os.ReadFile(path + ".pub")
+		if err != nil {
+			return nil, fmt.Errorf("encrypted SSH key needs its public key at %s.pub: %w", path, err)
+		}
+		pubKey, _, _, _, err = ssh.ParseAuthorizedKey(pubData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s.pub: %w", path, err)
+		}
+	}
+	encrypted, err := agessh.NewEncryptedSSHIdentity(pubKey, data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encrypted SSH identity: %w", err)
+	}
+	return []age.Identity{encrypted}, nil
+}
+
+// Encrypt writes the bundle as ASCII-armored age ciphertext for the recipients.
+func Encrypt(w io.Writer, bundle *Bundle, recipients ...age.Recipient) error {
+	payload, err := json.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+
+	armored := armor.NewWriter(w)
+	encrypted, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt profile: %w", err)
+	}
+	if _, err := encrypted.Write(payload); err != nil {
+		return fmt.Errorf("failed to encrypt profile: %w", err)
+	}
+	if err := encrypted.Close(); err != nil {
+		return fmt.Errorf("failed to encrypt profile: %w", err)
+	}
+	return armored.Close()
+}
+
+// Decrypt reads an armored or binary age bundle and returns its contents.
+func Decrypt(r io.Reader, identities ...age.Identity) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read shared profile: %w", err)
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	decrypted, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt shared profile: %w", err)
+	}
+
+	var bundle Bundle
+	if err := json.NewDecoder(decrypted).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("failed to decode shared profile: %w", err)
+	}
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("shared profile uses format version %d, please update awsm", bundle.Version)
+	}
+	return &bundle, nil
+}
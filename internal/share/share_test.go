@@ -0,0 +1,50 @@
+package share
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"awsm/internal/aws"
+
+	"filippo.io/age"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient, err := ParseRecipient(identity.Recipient().String())
+	if err != nil {
+		t.Fatalf("ParseRecipient failed: %v", err)
+	}
+
+	bundle := &Bundle{
+		Version:    BundleVersion,
+		Profile:    aws.ProfileInfo{Name: "prod", Type: aws.ProfileTypeSSO, SSOSession: "corp", SSOAccountID: "111111111111"},
+		SSOSession: &aws.SSOSessionInfo{Name: "corp", Region: "eu-west-1"},
+	}
+
+	var buf bytes.Buffer
+	if err := Encrypt(&buf, bundle, recipient); err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "-----BEGIN AGE ENCRYPTED FILE-----") {
+		t.Errorf("Expected armored output, got %q", buf.String()[:30])
+	}
+
+	decoded, err := Decrypt(&buf, identity)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if decoded.Profile.Name != "prod" || decoded.SSOSession == nil || decoded.SSOSession.Region != "eu-west-1" {
+		t.Errorf("Unexpected bundle: %+v", decoded)
+	}
+}
+
+func TestParseRecipientInvalid(t *testing.T) {
+	if _, err := ParseRecipient("not-a-key"); err == nil {
+		t.Error("Expected error for invalid recipient")
+	}
+}
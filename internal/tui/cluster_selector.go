@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type ClusterItem struct {
+	name string
+}
+
+func (i ClusterItem) FilterValue() string {
+	return i.name
+}
+
+func (i ClusterItem) Title() string {
+	return i.name
+}
+
+func (i ClusterItem) Description() string {
+	return ""
+}
+
+type ClusterSelectorModel struct {
+	list     list.Model
+	choice   string
+	quitting bool
+}
+
+func NewClusterSelector(clusters []string, region string) ClusterSelectorModel {
+	items := make([]list.Item, len(clusters))
+	for i, cluster := range clusters {
+		items[i] = ClusterItem{name: cluster}
+	}
+
+	const defaultWidth = 80
+	const listHeight = 14
+
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = false
+	l := list.New(items, delegate, defaultWidth, listHeight)
+	l.Title = HeaderStyle.Render(fmt.Sprintf("☸ Select EKS Cluster (%s)", region))
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = HeaderStyle
+	l.Styles.PaginationStyle = MutedStyle
+	l.Styles.HelpStyle = MutedStyle
+
+	return ClusterSelectorModel{list: l}
+}
+
+func (m ClusterSelectorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ClusterSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetWidth(max(msg.Width-2, 40))
+		m.list.SetHeight(max(msg.Height-4, 10))
+		return m, nil
+
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "enter":
+			// Enter while typing a filter applies it instead of choosing
+			if m.list.FilterState() == list.Filtering {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(ClusterItem); ok {
+				m.choice = i.name
+			}
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m ClusterSelectorModel) View() string {
+	if m.choice != "" {
+		return SuccessStyle.Render(fmt.Sprintf("✓ Selected cluster: %s", m.choice))
+	}
+	if m.quitting {
+		return MutedStyle.Render("Operation cancelled.")
+	}
+	return "\n" + m.list.View()
+}
+
+// SelectCluster shows an interactive selector for the EKS clusters of a
+// region and returns the name of the chosen one.
+func SelectCluster(clusters []string, region string) (string, error) {
+	program := tea.NewProgram(NewClusterSelector(clusters, region), tea.WithAltScreen())
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if m, ok := finalModel.(ClusterSelectorModel); ok {
+		if m.choice == "" {
+			return "", fmt.Errorf("no cluster selected")
+		}
+		return m.choice, nil
+	}
+
+	return "", fmt.Errorf("unexpected model type")
+}
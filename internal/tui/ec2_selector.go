@@ -139,7 +139,11 @@ func SelectEC2Instance(profile, region string) (aws.EC2Instance, error) {
 	if len(instances) == 0 {
 		return aws.EC2Instance{}, fmt.Errorf("no running instances found in region %s", region)
 	}
+	return SelectEC2InstanceFrom(instances)
+}
 
+// SelectEC2InstanceFrom shows an interactive selector for the given instances
+func SelectEC2InstanceFrom(instances []aws.EC2Instance) (aws.EC2Instance, error) {
 	model := NewEC2Selector(instances)
 	program := tea.NewProgram(model, tea.WithAltScreen())
 
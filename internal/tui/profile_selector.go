@@ -2,9 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"awsm/internal/aws"
+	"awsm/internal/config"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
@@ -14,8 +16,18 @@ type ProfileItem struct {
 	profile aws.ProfileInfo
 }
 
-func (i ProfileItem) FilterValue() string { return i.profile.Name }
-func (i ProfileItem) Title() string       { return i.profile.Name }
+// FilterValue lets the fuzzy filter match the name, account, role, region and SSO session.
+func (i ProfileItem) FilterValue() string {
+	return strings.Join([]string{i.profile.Name, i.profile.AccountID(), i.profile.RoleName(), i.profile.Region, i.profile.SSOSession}, " ")
+}
+
+func (i ProfileItem) Title() string {
+	if i.profile.IsActive {
+		return i.profile.Name + " " + SuccessStyle.Render("(current)")
+	}
+	return i.profile.Name
+}
+
 func (i ProfileItem) Description() string {
 	var parts []string
 
@@ -27,6 +39,8 @@ func (i ProfileItem) Description() string {
 		parts = append(parts, ProfileIAM.Render("IAM"))
 	case aws.ProfileTypeKey:
 		parts = append(parts, ProfileKey.Render("Key"))
+	case aws.ProfileTypeProcess:
+		parts = append(parts, MutedStyle.Render("Process"))
 	}
 
 	// Add region
@@ -34,9 +48,12 @@ func (i ProfileItem) Description() string {
 		parts = append(parts, MutedStyle.Render(i.profile.Region))
 	}
 
-	// Add account ID for SSO
-	if i.profile.SSOAccountID != "" {
-		parts = append(parts, MutedStyle.Render("("+i.profile.SSOAccountID+")"))
+	// Add account and role for SSO and role profiles
+	if account := i.profile.AccountID(); account != "" {
+		parts = append(parts, MutedStyle.Render(account))
+	}
+	if role := i.profile.RoleName(); role != "" {
+		parts = append(parts, MutedStyle.Render(role))
 	}
 
 	return strings.Join(parts, " • ")
@@ -121,6 +138,25 @@ func (m ProfileSelectorModel) View() string {
 	return "\n" + m.list.View()
 }
 
+// orderProfiles puts the current profile first, followed by the recently used
+// ones newest first, so daily switching rarely needs a search.
+func orderProfiles(profiles []aws.ProfileInfo, recent []string) []aws.ProfileInfo {
+	rank := func(p aws.ProfileInfo) int {
+		if p.IsActive {
+			return -1
+		}
+		if i := slices.Index(recent, p.Name); i >= 0 {
+			return i
+		}
+		return len(recent)
+	}
+	ordered := slices.Clone(profiles)
+	slices.SortStableFunc(ordered, func(a, b aws.ProfileInfo) int {
+		return rank(a) - rank(b)
+	})
+	return ordered
+}
+
 // SelectProfile shows an interactive profile selector
 func SelectProfile() (string, error) {
 	profiles, err := aws.ListProfilesDetailed()
@@ -132,7 +168,14 @@ func SelectProfile() (string, error) {
 		return "", fmt.Errorf("no profiles found")
 	}
 
-	model := NewProfileSelector(profiles)
+	var recent []string
+	if state, err := config.LoadState(); err == nil {
+		for _, p := range state.RecentProfiles {
+			recent = append(recent, p.Name)
+		}
+	}
+
+	model := NewProfileSelector(orderProfiles(profiles, recent))
 	program := tea.NewProgram(model, tea.WithAltScreen())
 
 	finalModel, err := program.Run()
@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"awsm/internal/aws"
+)
+
+func TestOrderProfiles(t *testing.T) {
+	profiles := []aws.ProfileInfo{{Name: "a"}, {Name: "b"}, {Name: "c", IsActive: true}, {Name: "d"}, {Name: "e"}}
+	ordered := orderProfiles(profiles, []string{"c", "e", "b"})
+
+	var names []string
+	for _, p := range ordered {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "c,e,b,a,d" {
+		t.Errorf("Expected c,e,b,a,d, got %s", got)
+	}
+}
+
+func TestProfileItemFilterValue(t *testing.T) {
+	item := ProfileItem{profile: aws.ProfileInfo{Name: "prod", RoleARN: "arn:aws:iam::123456789012:role/Admin", Region: "eu-west-1"}}
+	for _, want := range []string{"prod", "123456789012", "Admin", "eu-west-1"} {
+		if !strings.Contains(item.FilterValue(), want) {
+			t.Errorf("Expected filter value %q to contain %q", item.FilterValue(), want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+)
+
+// progressBarWidth is the number of cells of the bar itself.
+const progressBarWidth = 30
+
+// Progress reports how far a long operation over a known number of items is.
+// On a terminal it redraws a single line on stderr; otherwise it prints a line
+// at every tenth of the work so logs stay short. It is safe for concurrent use.
+type Progress struct {
+	mu       sync.Mutex
+	out      io.Writer
+	tty      bool
+	label    string
+	total    int
+	done     int
+	lastStep int
+}
+
+// NewProgress starts reporting progress over total items.
+func NewProgress(label string, total int) *Progress {
+	p := &Progress{out: os.Stderr, tty: isatty.IsTerminal(os.Stderr.Fd()), label: label, total: total}
+	p.draw()
+	return p
+}
+
+// Increment marks one more item as done.
+func (p *Progress) Increment() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.draw()
+}
+
+// Finish ends the progress line, so later output starts on a new line.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tty {
+		fmt.Fprintln(p.out)
+	}
+}
+
+func (p *Progress) draw() {
+	if p.tty {
+		fmt.Fprintf(p.out, "\r%s %s", InfoStyle.Render(ProgressBar(p.done, p.total, progressBarWidth)), p.label)
+		return
+	}
+	if p.total == 0 {
+		return
+	}
+	if step := p.done * 10 / p.total; step > p.lastStep {
+		p.lastStep = step
+		fmt.Fprintf(p.out, "  ... %d/%d %s\n", p.done, p.total, p.label)
+	}
+}
+
+// ProgressBar renders done out of total as a bar of width cells followed by
+// the count, e.g. "[=========>          ] 42/120".
+func ProgressBar(done, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = min(done*width/total, width)
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	return fmt.Sprintf("[%s] %d/%d", bar, done, total)
+}
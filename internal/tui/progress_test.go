@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        string
+	}{
+		{0, 4, "[>       ] 0/4"},
+		{2, 4, "[====>   ] 2/4"},
+		{4, 4, "[========] 4/4"},
+		{0, 0, "[========] 0/0"},
+	}
+	for _, tt := range tests {
+		if got := ProgressBar(tt.done, tt.total, 8); got != tt.want {
+			t.Errorf("ProgressBar(%d, %d) = %q, want %q", tt.done, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestProgressWithoutTerminal(t *testing.T) {
+	var out bytes.Buffer
+	p := &Progress{out: &out, label: "accounts", total: 200}
+	for range 200 {
+		p.Increment()
+	}
+	p.Finish()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 10 || !strings.Contains(lines[9], "200/200 accounts") {
+		t.Errorf("Expected a line per tenth of the work, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"awsm/internal/aws"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type RegionItem struct {
+	region aws.RegionInfo
+}
+
+func (i RegionItem) FilterValue() string {
+	return i.region.Name + " " + i.region.Description
+}
+
+func (i RegionItem) Title() string {
+	return i.region.Name
+}
+
+func (i RegionItem) Description() string {
+	var parts []string
+	if i.region.Description != "" {
+		parts = append(parts, i.region.Description)
+	}
+	if !i.region.Enabled() {
+		parts = append(parts, WarningStyle.Render("not enabled"))
+	}
+	if i.region.Latency > 0 {
+		parts = append(parts, MutedStyle.Render(FormatLatency(i.region.Latency)))
+	}
+	return strings.Join(parts, " • ")
+}
+
+type RegionSelectorModel struct {
+	list     list.Model
+	choice   string
+	quitting bool
+}
+
+func NewRegionSelector(regions []aws.RegionInfo) RegionSelectorModel {
+	items := make([]list.Item, len(regions))
+	for i, region := range regions {
+		items[i] = RegionItem{region: region}
+	}
+
+	const defaultWidth = 80
+	const listHeight = 14
+
+	l := list.New(items, list.NewDefaultDelegate(), defaultWidth, listHeight)
+	l.Title = HeaderStyle.Render("🌍 Select AWS Region")
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(true)
+	l.Styles.Title = HeaderStyle
+	l.Styles.PaginationStyle = MutedStyle
+	l.Styles.HelpStyle = MutedStyle
+
+	return RegionSelectorModel{list: l}
+}
+
+func (m RegionSelectorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m RegionSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetWidth(max(msg.Width-2, 40))
+		m.list.SetHeight(max(msg.Height-4, 10))
+		return m, nil
+
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "enter":
+			// Enter while typing a filter applies it instead of choosing
+			if m.list.FilterState() == list.Filtering {
+				break
+			}
+			if i, ok := m.list.SelectedItem().(RegionItem); ok {
+				m.choice = i.region.Name
+			}
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m RegionSelectorModel) View() string {
+	if m.choice != "" {
+		return SuccessStyle.Render(fmt.Sprintf("✓ Selected region: %s", m.choice))
+	}
+	if m.quitting {
+		return MutedStyle.Render("Operation cancelled.")
+	}
+	return "\n" + m.list.View()
+}
+
+// SelectRegion shows an interactive selector for the given regions and
+// returns the name of the chosen one.
+func SelectRegion(regions []aws.RegionInfo) (string, error) {
+	program := tea.NewProgram(NewRegionSelector(regions), tea.WithAltScreen())
+
+	finalModel, err := program.Run()
+	if err != nil {
+		return "", err
+	}
+
+	if m, ok := finalModel.(RegionSelectorModel); ok {
+		if m.choice == "" {
+			return "", fmt.Errorf("no region selected")
+		}
+		return m.choice, nil
+	}
+
+	return "", fmt.Errorf("unexpected model type")
+}
+
+// FormatLatency renders a latency in whole milliseconds, e.g. "23ms".
+func FormatLatency(latency time.Duration) string {
+	if latency < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", latency.Milliseconds())
+}
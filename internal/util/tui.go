@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/fatih/color"
 )
 
@@ -18,16 +19,49 @@ var (
 	BoldColor    = color.New(color.Bold)
 )
 
+// stdin is shared by all prompts, so answers piped in together aren't lost to
+// the buffer of an earlier prompt.
+var stdin = bufio.NewReader(os.Stdin)
+
 func PromptForInput(prompt string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
 	fmt.Print(prompt)
-	input, err := reader.ReadString('\n')
+	input, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(input), nil
+}
+
+// PromptForInputStderr behaves like PromptForInput but writes the prompt to stderr,
+// keeping stdout clean for commands whose output is meant to be eval'd or piped.
+func PromptForInputStderr(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	input, err := stdin.ReadString('\n')
 	if err != nil {
 		return "", err
 	}
 	return strings.TrimSpace(input), nil
 }
 
+// PromptForSecret reads a value from the terminal without echoing it.
+// The prompt is written to stderr so stdout stays clean.
+func PromptForSecret(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		input, err := stdin.ReadString('\n')
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(input), nil
+	}
+	secret, err := term.ReadPassword(os.Stdin.Fd())
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
 // SortBy sorts a slice using the provided less function
 func SortBy[T any](slice []T, less func(T, T) bool) {
 	sort.Slice(slice, func(i, j int) bool {
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WarningID identifies a kind of warning, so it can be looked up in the docs
+// and silenced with the warnings.suppress setting.
+type WarningID string
+
+const (
+	WarnCredentialsExpired    WarningID = "W001"
+	WarnNoRegion              WarningID = "W002"
+	WarnDurationCapped        WarningID = "W003"
+	WarnClockSkew             WarningID = "W004"
+	WarnAWSCLIVersion         WarningID = "W005"
+	WarnPrivilegedPort        WarningID = "W006"
+	WarnIdentityUnverified    WarningID = "W007"
+	WarnSSORefreshFailed      WarningID = "W008"
+	WarnStateNotSaved         WarningID = "W009"
+	WarnSecurityReviewSkipped WarningID = "W010"
+	WarnPreflight             WarningID = "W011"
+)
+
+// Warnings describes every warning ID, in order.
+var Warnings = []struct {
+	ID          WarningID
+	Description string
+}{
+	{WarnCredentialsExpired, "Credentials expired and the session is refreshed"},
+	{WarnNoRegion, "No region configured, a default region is used"},
+	{WarnDurationCapped, "A role does not allow the requested session duration"},
+	{WarnClockSkew, "The local clock differs from AWS, requests are retried with the server time"},
+	{WarnAWSCLIVersion, "The installed AWS CLI is missing features awsm uses"},
+	{WarnPrivilegedPort, "Port forwarding to a privileged local port"},
+	{WarnIdentityUnverified, "The identity of activated credentials could not be verified"},
+	{WarnSSORefreshFailed, "The SSO token could not be refreshed silently"},
+	{WarnStateNotSaved, "awsm could not save its state, e.g. recently used profiles"},
+	{WarnSecurityReviewSkipped, "The first-run security review could not run"},
+	{WarnPreflight, "A check run before a command found a problem, see 'awsm status'"},
+}
+
+var suppressedWarnings = map[WarningID]bool{}
+
+// SuppressWarnings silences the given warning IDs for the rest of the process.
+func SuppressWarnings(ids []string) {
+	for _, id := range ids {
+		suppressedWarnings[WarningID(strings.ToUpper(strings.TrimSpace(id)))] = true
+	}
+}
+
+// IsWarningSuppressed reports whether a warning ID was silenced.
+func IsWarningSuppressed(id WarningID) bool {
+	return suppressedWarnings[id]
+}
+
+// Warn prints a warning with its ID to stderr unless the ID is suppressed.
+// The message should not end with a newline.
+func Warn(id WarningID, format string, args ...any) {
+	if IsWarningSuppressed(id) {
+		return
+	}
+	WarnColor.Fprintf(os.Stderr, "Warning %s: %s\n", id, fmt.Sprintf(format, args...))
+}
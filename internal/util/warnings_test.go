@@ -0,0 +1,23 @@
+package util
+
+import "testing"
+
+func TestSuppressWarnings(t *testing.T) {
+	t.Cleanup(func() { suppressedWarnings = map[WarningID]bool{} })
+
+	SuppressWarnings([]string{" w004 ", "W002"})
+	if !IsWarningSuppressed(WarnClockSkew) || !IsWarningSuppressed(WarnNoRegion) {
+		t.Error("Expected W004 and W002 to be suppressed")
+	}
+	if IsWarningSuppressed(WarnDurationCapped) {
+		t.Error("Expected W003 not to be suppressed")
+	}
+
+	seen := map[WarningID]bool{}
+	for _, w := range Warnings {
+		if seen[w.ID] {
+			t.Errorf("Duplicate warning ID %s", w.ID)
+		}
+		seen[w.ID] = true
+	}
+}
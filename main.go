@@ -10,7 +10,6 @@ package main
 
 import (
 	"awsm/cmd"
-	"awsm/internal/config"
 )
 
 var (
@@ -20,7 +19,6 @@ var (
 )
 
 func main() {
-	config.InitConfig()
 	cmd.SetVersionInfo(version, commit, date)
 	cmd.Execute()
 }